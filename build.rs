@@ -1,6 +1,16 @@
 fn main() {
     // 为tree-sitter语言支持编译
     println!("cargo:rerun-if-changed=build.rs");
+    println!("cargo:rerun-if-changed=proto/codegraph.proto");
+
+    // gRPC服务的消息和服务代码。没有设置 PROTOC 时使用 protoc-bin-vendored 自带的 protoc，
+    // 构建不需要系统安装 protoc
+    println!("cargo:rerun-if-env-changed=PROTOC");
+    if std::env::var_os("PROTOC").is_none() {
+        let protoc = protoc_bin_vendored::protoc_bin_path().expect("Failed to locate the vendored protoc");
+        std::env::set_var("PROTOC", protoc);
+    }
+    tonic_build::compile_protos("proto/codegraph.proto").expect("Failed to compile proto/codegraph.proto");
     
     // 确保tree-sitter语言库被正确链接
     println!("cargo:rustc-link-lib=tree-sitter");
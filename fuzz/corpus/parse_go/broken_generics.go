@@ -0,0 +1,7 @@
+package main
+
+func Map[T any, ](xs []T) []T { return xs }
+
+type Box[T] struct{ v T }
+
+func (b Box[) Get() T { return b.v }
@@ -0,0 +1,6 @@
+package main
+
+import
+
+import (
+	"fmt"
@@ -0,0 +1,10 @@
+package main
+
+var a, b = 1
+const = 3
+var _ , x
+
+func f() {
+	x, := g()
+	:= 1
+}
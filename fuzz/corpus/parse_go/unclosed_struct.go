@@ -0,0 +1,5 @@
+package main
+
+type Point struct {
+	X int
+	Y
@@ -0,0 +1,27 @@
+//! 向 Go 解析器输入任意字节：解析只能返回符号图或错误，不能 panic。
+//!
+//! 运行（需要 nightly 和 cargo-fuzz），已有的 Go 测试用例作为额外的种子语料：
+//!
+//! ```sh
+//! cargo fuzz run parse_go fuzz/corpus/parse_go src/codegraph/treesitter/parsers/tests/cases/go
+//! ```
+#![no_main]
+
+use std::path::PathBuf;
+
+use libfuzzer_sys::fuzz_target;
+
+use codegraph_cli::codegraph::symbol_graph::{go_build_constraints, parse_code, parse_stream, BuildTarget, GeneratedCodeMatcher};
+
+fuzz_target!(|data: &[u8]| {
+    // 无效的 UTF-8 按替换字符处理，与读取文件时的行为无关，只是让所有输入都能到达解析器
+    let code = String::from_utf8_lossy(data);
+    let path = PathBuf::from("/fuzz.go");
+    if let Ok(graph) = parse_code(&code, &path) {
+        graph.to_json().expect("serialize parsed graph");
+    }
+    let _ = parse_stream(&code, &path, |_node| Ok(()), |_nodes, _edges| Ok(()));
+    // parse_dir 在解析之前读取的文件头
+    BuildTarget::new("linux", "amd64").matches(&go_build_constraints(&path, &code));
+    GeneratedCodeMatcher::new(&[]).unwrap().is_generated(&code);
+});
@@ -3,6 +3,7 @@ pub mod parser;
 pub mod types;
 pub mod treesitter;
 pub mod repository;
+pub mod symbol_graph;
 
 pub use graph::CodeGraph;
 pub use types::{
@@ -11,4 +12,5 @@ pub use types::{
     FileMetadata, FileIndex, SnippetIndex, SnippetInfo
 };
 pub use treesitter::TreeSitterParser;
-pub use repository::{RepositoryManager, RepositoryStats, SearchResult};
\ No newline at end of file
+pub use repository::{RepositoryManager, RepositoryStats, SearchResult};
+pub use symbol_graph::{SymbolEdge, SymbolEdgeKind, SymbolGraph, SymbolKind, SymbolNode};
\ No newline at end of file
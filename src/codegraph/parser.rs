@@ -74,7 +74,8 @@ impl CodeParser {
                 "rs" |
                 "ts" |
                 "tsx" |
-                "go"
+                "go" |
+                "kt" | "kts"
             )
         } else {
             false
@@ -0,0 +1,330 @@
+use std::collections::BTreeMap;
+use std::io::{Cursor, Read};
+use std::path::{Component, Path, PathBuf};
+
+use crate::codegraph::symbol_graph::dir::{parse_and_merge, select_files, ExcludeGlobs, FileError, ParseOptions};
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::treesitter::parsers::registry::language_for;
+use crate::codegraph::treesitter::parsers::ParserError;
+
+/// 归档格式
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum ArchiveFormat {
+    /// 未压缩的 tar
+    Tar,
+    Zip,
+}
+
+/// 读取归档时的大小限制，防止异常的归档（例如压缩炸弹）耗尽内存
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct ArchiveLimits {
+    /// 单个条目解压后的最大字节数，超过的条目记为该文件的错误
+    pub max_entry_bytes: u64,
+    /// 所有保留条目解压后的总字节数，zip 归档本身的大小也受此限制，超过时整个归档失败
+    pub max_total_bytes: u64,
+}
+
+impl Default for ArchiveLimits {
+    fn default() -> Self {
+        Self {
+            max_entry_bytes: 16 << 20,
+            max_total_bytes: 1 << 30,
+        }
+    }
+}
+
+/// 解析 tar 或 zip 归档中的源文件，不解压到磁盘，使用默认的大小限制
+pub fn parse_archive<R: Read>(reader: R, format: ArchiveFormat, root: &Path, options: &ParseOptions) -> Result<(SymbolGraph, Vec<FileError>), ParserError> {
+    parse_archive_with_limits(reader, format, root, options, &ArchiveLimits::default())
+}
+
+/// 与 `parse_dir` 相同，但文件来自归档：条目路径拼接到 `root` 后按目录中的规则选择文件
+/// （隐藏路径、深度限制、排除表达式、注册的解析器），按路径排序后解析合并，结果与条目顺序无关。
+/// 同一路径出现多次时使用最后一个条目；`options.overlay` 中已有的路径优先于归档中的内容。
+/// 归档中的 `go.mod` 优先于磁盘上同一位置的文件。
+///
+/// 只读取普通文件，目录和链接被忽略。条目路径是绝对路径或包含 `..` 时整个归档失败；
+/// 超过大小限制或不是 UTF-8 的源文件记为该文件的错误，与解析错误一起按路径顺序返回
+pub fn parse_archive_with_limits<R: Read>(
+    reader: R,
+    format: ArchiveFormat,
+    root: &Path,
+    options: &ParseOptions,
+    limits: &ArchiveLimits,
+) -> Result<(SymbolGraph, Vec<FileError>), ParserError> {
+    let (entries, errors) = match format {
+        ArchiveFormat::Tar => read_tar(reader, root, limits)?,
+        ArchiveFormat::Zip => read_zip(reader, root, limits)?,
+    };
+    let mut options = options.clone();
+    // 覆盖层中已有的路径不使用归档中的内容，也不报告归档中的错误
+    let mut errors = errors.into_iter().filter(|e| !options.overlay.contains_key(&e.path)).collect::<Vec<_>>();
+    for (path, code) in entries {
+        options.overlay.entry(path).or_insert(code);
+    }
+    let excludes = ExcludeGlobs::new(&options.exclude_globs)?;
+    let mut files = select_files(root, options.overlay.keys(), &options, &excludes);
+    files.sort();
+    let selected = select_files(root, errors.iter().map(|e| &e.path), &options, &excludes);
+    errors.retain(|e| selected.contains(&e.path));
+
+    let (graph, parse_errors) = parse_and_merge(files, &options)?;
+    errors.extend(parse_errors);
+    errors.sort_by(|a, b| a.path.cmp(&b.path));
+    Ok((graph, errors))
+}
+
+/// 归档中读出的源文件（路径 -> 内容）和无法读取的条目
+type ArchiveEntries = (BTreeMap<PathBuf, String>, Vec<FileError>);
+
+fn archive_error(message: String) -> ParserError {
+    ParserError { message }
+}
+
+/// 条目在 `root` 下的路径，目录穿越（zip-slip）返回错误
+fn entry_path(root: &Path, name: &Path) -> Result<PathBuf, ParserError> {
+    let mut path = root.to_path_buf();
+    for component in name.components() {
+        match component {
+            Component::Normal(part) => path.push(part),
+            Component::CurDir => {}
+            _ => return Err(archive_error(format!("Archive entry {} is outside the archive root", name.display()))),
+        }
+    }
+    Ok(path)
+}
+
+/// 读取一个条目的内容：没有解析器的条目跳过（`go.mod` 除外，用于查找包的导入路径），
+/// 超过限制或不是 UTF-8 的条目记为错误
+fn read_entry<R: Read>(
+    entry: R,
+    path: PathBuf,
+    size: u64,
+    limits: &ArchiveLimits,
+    total: &mut u64,
+    (entries, errors): &mut ArchiveEntries,
+) -> Result<(), ParserError> {
+    if language_for(&path).is_none() && path.file_name().map_or(true, |name| name != "go.mod") {
+        return Ok(());
+    }
+    let too_large = || FileError {
+        error: archive_error(format!("Archive entry {} exceeds {} bytes", path.display(), limits.max_entry_bytes)),
+        path: path.clone(),
+    };
+    if size > limits.max_entry_bytes {
+        entries.remove(&path);
+        errors.push(too_large());
+        return Ok(());
+    }
+    // 声明的大小不可信，按实际读到的字节数检查
+    let mut data = vec![];
+    entry.take(limits.max_entry_bytes + 1).read_to_end(&mut data)
+        .map_err(|e| archive_error(format!("Failed to read archive entry {}: {}", path.display(), e)))?;
+    if data.len() as u64 > limits.max_entry_bytes {
+        entries.remove(&path);
+        errors.push(too_large());
+        return Ok(());
+    }
+    *total += data.len() as u64;
+    if *total > limits.max_total_bytes {
+        return Err(archive_error(format!("Archive contents exceed {} bytes", limits.max_total_bytes)));
+    }
+    match String::from_utf8(data) {
+        Ok(code) => {
+            errors.retain(|e| e.path != path);
+            entries.insert(path, code);
+        }
+        Err(_) => {
+            entries.remove(&path);
+            errors.push(FileError {
+                error: archive_error(format!("Archive entry {} is not valid UTF-8", path.display())),
+                path,
+            });
+        }
+    }
+    Ok(())
+}
+
+fn read_tar<R: Read>(reader: R, root: &Path, limits: &ArchiveLimits) -> Result<ArchiveEntries, ParserError> {
+    let read_error = |e: std::io::Error| archive_error(format!("Failed to read tar archive: {}", e));
+    let mut archive = tar::Archive::new(reader);
+    let mut result = (BTreeMap::new(), vec![]);
+    let mut total = 0;
+    for entry in archive.entries().map_err(read_error)? {
+        let entry = entry.map_err(read_error)?;
+        let path = entry_path(root, &entry.path().map_err(read_error)?)?;
+        if !entry.header().entry_type().is_file() {
+            continue;
+        }
+        let size = entry.size();
+        read_entry(entry, path, size, limits, &mut total, &mut result)?;
+    }
+    Ok(result)
+}
+
+/// zip 的目录在文件末尾，先把整个归档读入内存（受总大小限制）
+fn read_zip<R: Read>(reader: R, root: &Path, limits: &ArchiveLimits) -> Result<ArchiveEntries, ParserError> {
+    let mut data = vec![];
+    reader.take(limits.max_total_bytes + 1).read_to_end(&mut data)
+        .map_err(|e| archive_error(format!("Failed to read zip archive: {}", e)))?;
+    if data.len() as u64 > limits.max_total_bytes {
+        return Err(archive_error(format!("Archive exceeds {} bytes", limits.max_total_bytes)));
+    }
+    let read_error = |e: zip::result::ZipError| archive_error(format!("Failed to read zip archive: {}", e));
+    let mut archive = zip::ZipArchive::new(Cursor::new(data)).map_err(read_error)?;
+    let mut result = (BTreeMap::new(), vec![]);
+    let mut total = 0;
+    for idx in 0..archive.len() {
+        let entry = archive.by_index(idx).map_err(read_error)?;
+        let path = entry_path(root, Path::new(entry.name()))?;
+        if entry.is_dir() || entry.is_symlink() {
+            continue;
+        }
+        let size = entry.size();
+        read_entry(entry, path, size, limits, &mut total, &mut result)?;
+    }
+    Ok(result)
+}
+
+#[cfg(test)]
+mod tests {
+    use std::fs;
+    use std::io::{Cursor, Write};
+    use std::path::{Path, PathBuf};
+
+    use zip::write::SimpleFileOptions;
+
+    use crate::codegraph::symbol_graph::archive::{parse_archive, parse_archive_with_limits, ArchiveFormat, ArchiveLimits};
+    use crate::codegraph::symbol_graph::dir::{parse_dir, ParseOptions};
+    use crate::codegraph::symbol_graph::types::SymbolKind;
+
+    fn cases_dir() -> PathBuf {
+        PathBuf::from(env!("CARGO_MANIFEST_DIR")).join("src/codegraph/treesitter/parsers/tests/cases")
+    }
+
+    /// 测试用例目录下的 (相对路径, 内容)，倒序排列，验证结果与条目顺序无关
+    fn case_files() -> Vec<(String, Vec<u8>)> {
+        let mut files = vec![];
+        for lang_dir in fs::read_dir(cases_dir()).unwrap().flatten() {
+            for file in fs::read_dir(lang_dir.path()).unwrap().flatten() {
+                let name = format!("{}/{}", lang_dir.file_name().to_string_lossy(), file.file_name().to_string_lossy());
+                files.push((name, fs::read(file.path()).unwrap()));
+            }
+        }
+        files.sort();
+        files.reverse();
+        files
+    }
+
+    fn zip_of(files: &[(String, Vec<u8>)]) -> Vec<u8> {
+        let mut writer = zip::ZipWriter::new(Cursor::new(vec![]));
+        for (name, data) in files {
+            writer.start_file(name.as_str(), SimpleFileOptions::default()).unwrap();
+            writer.write_all(data).unwrap();
+        }
+        writer.finish().unwrap().into_inner()
+    }
+
+    fn tar_of(files: &[(String, Vec<u8>)]) -> Vec<u8> {
+        let mut builder = tar::Builder::new(vec![]);
+        for (name, data) in files {
+            let mut header = tar::Header::new_gnu();
+            header.set_size(data.len() as u64);
+            header.set_mode(0o644);
+            builder.append_data(&mut header, name, data.as_slice()).unwrap();
+        }
+        builder.into_inner().unwrap()
+    }
+
+    /// `tar::Builder` 拒绝写入包含 `..` 的路径，直接写头部中的名称
+    fn tar_of_raw(files: &[(String, Vec<u8>)]) -> Vec<u8> {
+        let mut builder = tar::Builder::new(vec![]);
+        for (name, data) in files {
+            let mut header = tar::Header::new_old();
+            header.as_old_mut().name[..name.len()].copy_from_slice(name.as_bytes());
+            header.set_size(data.len() as u64);
+            header.set_mode(0o644);
+            header.set_entry_type(tar::EntryType::Regular);
+            header.set_cksum();
+            builder.append(&header, data.as_slice()).unwrap();
+        }
+        builder.into_inner().unwrap()
+    }
+
+    #[test]
+    fn same_graph_as_parse_dir_test() {
+        let dir = tempfile::tempdir().unwrap();
+        let files = case_files();
+        for (name, data) in &files {
+            let path = dir.path().join(name);
+            fs::create_dir_all(path.parent().unwrap()).unwrap();
+            fs::write(path, data).unwrap();
+        }
+        let options = ParseOptions { compute_interface_satisfaction: true, ..Default::default() };
+        let (expected, expected_errors) = parse_dir(dir.path(), &options).unwrap();
+        assert!(expected.node_count() > 0);
+
+        for (format, data) in [(ArchiveFormat::Zip, zip_of(&files)), (ArchiveFormat::Tar, tar_of(&files))] {
+            let (graph, errors) = parse_archive(data.as_slice(), format, dir.path(), &options).unwrap();
+            assert_eq!(graph.to_json().unwrap(), expected.to_json().unwrap(), "{:?}", format);
+            assert_eq!(errors, expected_errors);
+
+            // 根目录不需要在磁盘上存在
+            let root = Path::new("/snapshot");
+            let (graph, _) = parse_archive(data.as_slice(), format, root, &options).unwrap();
+            assert_eq!(graph.node_count(), expected.node_count());
+            assert_eq!(graph.edge_count(), expected.edge_count());
+            assert!(graph.nodes().all(|n| n.file_path.starts_with(root)));
+        }
+    }
+
+    #[test]
+    fn go_mod_entry_test() {
+        let files = vec![
+            ("go.mod".to_string(), b"module example.com/shapes\n".to_vec()),
+            ("main.go".to_string(), b"package main\n".to_vec()),
+            ("geo/geo.go".to_string(), b"package geo\n".to_vec()),
+        ];
+        let root = Path::new("/snapshot");
+        for (format, data) in [(ArchiveFormat::Zip, zip_of(&files)), (ArchiveFormat::Tar, tar_of(&files))] {
+            let (graph, errors) = parse_archive(data.as_slice(), format, root, &ParseOptions::default()).unwrap();
+            assert!(errors.is_empty(), "{:?}", errors);
+            let mut packages = graph.nodes_of_kind(SymbolKind::Package).iter().map(|n| n.qualified_name.clone()).collect::<Vec<_>>();
+            packages.sort();
+            assert_eq!(packages, vec!["example.com/shapes", "example.com/shapes/geo"], "{:?}", format);
+        }
+    }
+
+    #[test]
+    fn zip_slip_test() {
+        let root = Path::new("/snapshot");
+        for name in ["../evil.go", "/etc/evil.go", "a/../../evil.go"] {
+            let files = vec![(name.to_string(), b"package evil\n".to_vec())];
+            let error = parse_archive(tar_of_raw(&files).as_slice(), ArchiveFormat::Tar, root, &ParseOptions::default()).unwrap_err();
+            assert!(error.message.contains("outside the archive root"), "{}", error.message);
+            let error = parse_archive(zip_of(&files).as_slice(), ArchiveFormat::Zip, root, &ParseOptions::default()).unwrap_err();
+            assert!(error.message.contains("outside the archive root"), "{}", error.message);
+        }
+    }
+
+    #[test]
+    fn entry_limits_test() {
+        let root = Path::new("/snapshot");
+        let files = vec![
+            ("big.go".to_string(), format!("package big\n\n// {}\n", "x".repeat(256)).into_bytes()),
+            ("small.go".to_string(), b"package small\n\nfunc Small() {}\n".to_vec()),
+            ("binary.go".to_string(), vec![0xff, 0xfe, 0x00]),
+            ("notes.bin".to_string(), vec![0u8; 1024]),
+        ];
+        let limits = ArchiveLimits { max_entry_bytes: 128, ..Default::default() };
+        let (graph, errors) = parse_archive_with_limits(zip_of(&files).as_slice(), ArchiveFormat::Zip, root, &ParseOptions::default(), &limits).unwrap();
+        assert_eq!(graph.find_nodes_by_name("Small").len(), 1);
+        // 没有解析器的条目不读取，也不报错
+        assert_eq!(errors.iter().map(|e| e.path.clone()).collect::<Vec<_>>(), vec![root.join("big.go"), root.join("binary.go")]);
+        assert!(errors[0].error.message.contains("exceeds 128 bytes"));
+        assert!(errors[1].error.message.contains("UTF-8"));
+
+        let limits = ArchiveLimits { max_total_bytes: 16, ..Default::default() };
+        assert!(parse_archive_with_limits(tar_of(&files).as_slice(), ArchiveFormat::Tar, root, &ParseOptions::default(), &limits).is_err());
+    }
+}
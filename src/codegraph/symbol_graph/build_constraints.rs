@@ -0,0 +1,257 @@
+use std::path::Path;
+
+/// Go 支持的操作系统，用于识别 `_linux.go` 这类文件名后缀
+const KNOWN_GOOS: [&str; 18] = [
+    "aix", "android", "darwin", "dragonfly", "freebsd", "hurd", "illumos", "ios", "js", "linux",
+    "nacl", "netbsd", "openbsd", "plan9", "solaris", "wasip1", "windows", "zos",
+];
+
+/// Go 支持的处理器架构
+const KNOWN_GOARCH: [&str; 24] = [
+    "386", "amd64", "amd64p32", "arm", "armbe", "arm64", "arm64be", "loong64", "mips", "mipsle",
+    "mips64", "mips64le", "mips64p32", "mips64p32le", "ppc", "ppc64", "ppc64le", "riscv", "riscv64",
+    "s390", "s390x", "sparc", "sparc64", "wasm",
+];
+
+/// 满足 `unix` 约束的操作系统
+const UNIX_GOOS: [&str; 12] = [
+    "aix", "android", "darwin", "dragonfly", "freebsd", "hurd", "illumos", "ios", "linux", "netbsd",
+    "openbsd", "solaris",
+];
+
+/// Go 构建目标，用于判断文件的构建约束是否满足
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct BuildTarget {
+    pub goos: String,
+    pub goarch: String,
+    /// 额外的构建标签（`-tags`），例如 `cgo`、`integration`
+    pub tags: Vec<String>,
+}
+
+impl BuildTarget {
+    pub fn new(goos: &str, goarch: &str) -> Self {
+        Self {
+            goos: goos.to_string(),
+            goarch: goarch.to_string(),
+            tags: vec![],
+        }
+    }
+
+    /// 目标下为真的标签，规则与 go 命令一致：`android` 也满足 `linux`，`ios` 也满足 `darwin`，
+    /// `illumos` 也满足 `solaris`；`gc` 和所有 `go1.N` 版本标签为真
+    fn has_tag(&self, tag: &str) -> bool {
+        tag == self.goos
+            || tag == self.goarch
+            || self.tags.iter().any(|t| t == tag)
+            || (tag == "unix" && UNIX_GOOS.contains(&self.goos.as_str()))
+            || (tag == "linux" && self.goos == "android")
+            || (tag == "darwin" && self.goos == "ios")
+            || (tag == "solaris" && self.goos == "illumos")
+            || tag == "gc"
+            || tag.strip_prefix("go1.").map_or(false, |minor| minor.chars().all(|c| c.is_ascii_digit()))
+    }
+
+    /// 所有约束表达式都为真时文件参与构建，无法解析的表达式视为满足
+    pub fn matches(&self, constraints: &[String]) -> bool {
+        constraints.iter().all(|expr| evaluate(expr, &|tag| self.has_tag(tag)).unwrap_or(true))
+    }
+}
+
+/// Go 文件的构建约束表达式：文件名后缀（`_linux.go` 为 `linux`，`_linux_amd64.go` 为
+/// `linux && amd64`）以及 package 子句之前的 `//go:build` 行。旧式 `// +build` 行不处理
+pub fn go_build_constraints(path: &Path, code: &str) -> Vec<String> {
+    let mut constraints = vec![];
+    if let Some(suffix) = filename_constraint(path) {
+        constraints.push(suffix);
+    }
+    for line in code.lines() {
+        let line = line.trim();
+        if let Some(expr) = line.strip_prefix("//go:build") {
+            if expr.starts_with(char::is_whitespace) {
+                constraints.push(expr.trim().to_string());
+            }
+        } else if !line.is_empty() && !line.starts_with("//") {
+            // 约束只能出现在 package 子句（以及块注释）之前
+            break;
+        }
+    }
+    constraints
+}
+
+fn filename_constraint(path: &Path) -> Option<String> {
+    let stem = path.file_stem()?.to_str()?;
+    let stem = stem.strip_suffix("_test").unwrap_or(stem);
+    let parts = stem.split('_').collect::<Vec<_>>();
+    // 第一段是文件名本身，`linux.go` 没有约束
+    match parts.as_slice() {
+        [_, .., goos, goarch] if KNOWN_GOOS.contains(goos) && KNOWN_GOARCH.contains(goarch) => {
+            Some(format!("{} && {}", goos, goarch))
+        }
+        [_, .., last] if KNOWN_GOOS.contains(last) || KNOWN_GOARCH.contains(last) => Some(last.to_string()),
+        _ => None,
+    }
+}
+
+/// 计算约束表达式：标签、`!`、`&&`、`||` 和括号，`&&` 优先于 `||`
+fn evaluate(expr: &str, has_tag: &dyn Fn(&str) -> bool) -> Result<bool, String> {
+    let tokens = tokenize(expr)?;
+    let mut parser = ExprParser { tokens: &tokens, pos: 0, depth: 0, has_tag };
+    let value = parser.or()?;
+    if parser.pos != tokens.len() {
+        return Err(format!("Unexpected token in build constraint: {}", expr));
+    }
+    Ok(value)
+}
+
+fn tokenize(expr: &str) -> Result<Vec<String>, String> {
+    let mut tokens = vec![];
+    let mut chars = expr.chars().peekable();
+    while let Some(&c) = chars.peek() {
+        match c {
+            _ if c.is_whitespace() => {
+                chars.next();
+            }
+            '!' | '(' | ')' => {
+                tokens.push(c.to_string());
+                chars.next();
+            }
+            '&' | '|' => {
+                chars.next();
+                if chars.next() != Some(c) {
+                    return Err(format!("Invalid operator in build constraint: {}", expr));
+                }
+                tokens.push(format!("{}{}", c, c));
+            }
+            _ if c.is_alphanumeric() || c == '_' || c == '.' => {
+                let mut tag = String::new();
+                while let Some(&c) = chars.peek().filter(|c| c.is_alphanumeric() || **c == '_' || **c == '.') {
+                    tag.push(c);
+                    chars.next();
+                }
+                tokens.push(tag);
+            }
+            _ => return Err(format!("Invalid character in build constraint: {}", expr)),
+        }
+    }
+    Ok(tokens)
+}
+
+/// `!` 和括号的最大嵌套层数，超过时视为无法解析
+const MAX_NESTING: usize = 64;
+
+struct ExprParser<'a> {
+    tokens: &'a [String],
+    pos: usize,
+    /// 当前 `!` 和括号的嵌套层数
+    depth: usize,
+    has_tag: &'a dyn Fn(&str) -> bool,
+}
+
+impl<'a> ExprParser<'a> {
+    fn peek(&self) -> Option<&str> {
+        self.tokens.get(self.pos).map(|t| t.as_str())
+    }
+
+    fn or(&mut self) -> Result<bool, String> {
+        let mut value = self.and()?;
+        while self.peek() == Some("||") {
+            self.pos += 1;
+            value |= self.and()?;
+        }
+        Ok(value)
+    }
+
+    fn and(&mut self) -> Result<bool, String> {
+        let mut value = self.not()?;
+        while self.peek() == Some("&&") {
+            self.pos += 1;
+            value &= self.not()?;
+        }
+        Ok(value)
+    }
+
+    fn not(&mut self) -> Result<bool, String> {
+        match self.peek() {
+            Some("!") => {
+                self.pos += 1;
+                Ok(!self.nested(Self::not)?)
+            }
+            Some("(") => {
+                self.pos += 1;
+                let value = self.nested(Self::or)?;
+                if self.peek() != Some(")") {
+                    return Err("Missing ) in build constraint".to_string());
+                }
+                self.pos += 1;
+                Ok(value)
+            }
+            Some(tag) if !matches!(tag, ")" | "&&" | "||") => {
+                let value = (self.has_tag)(tag);
+                self.pos += 1;
+                Ok(value)
+            }
+            _ => Err("Missing tag in build constraint".to_string()),
+        }
+    }
+
+    fn nested(&mut self, parse: fn(&mut Self) -> Result<bool, String>) -> Result<bool, String> {
+        if self.depth == MAX_NESTING {
+            return Err("Build constraint nested too deeply".to_string());
+        }
+        self.depth += 1;
+        let value = parse(self);
+        self.depth -= 1;
+        value
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use std::path::Path;
+
+    use crate::codegraph::symbol_graph::build_constraints::{go_build_constraints, BuildTarget};
+
+    #[test]
+    fn filename_constraint_test() {
+        let constraints = |name: &str| go_build_constraints(Path::new(name), "package x\n");
+        assert_eq!(constraints("/src/x_linux.go"), vec!["linux"]);
+        assert_eq!(constraints("/src/x_windows_amd64.go"), vec!["windows && amd64"]);
+        assert_eq!(constraints("/src/x_arm64_test.go"), vec!["arm64"]);
+        assert!(constraints("/src/linux.go").is_empty());
+        assert!(constraints("/src/my_helper.go").is_empty());
+    }
+
+    #[test]
+    fn go_build_line_test() {
+        let code = "// Copyright notice\n\n//go:build (linux || darwin) && !cgo\n\npackage x\n\n//go:build ignore\n";
+        assert_eq!(go_build_constraints(Path::new("/src/x.go"), code), vec!["(linux || darwin) && !cgo"]);
+        assert!(go_build_constraints(Path::new("/src/x.go"), "//go:buildx\npackage x\n").is_empty());
+    }
+
+    #[test]
+    fn target_matches_test() {
+        let linux = BuildTarget::new("linux", "amd64");
+        let expr = |e: &str| vec![e.to_string()];
+        assert!(linux.matches(&expr("linux")));
+        assert!(!linux.matches(&expr("windows")));
+        assert!(linux.matches(&expr("(linux || darwin) && !cgo")));
+        assert!(linux.matches(&expr("unix && go1.21")));
+        assert!(!linux.matches(&vec!["linux".to_string(), "arm64".to_string()]));
+
+        let mut cgo = linux.clone();
+        cgo.tags.push("cgo".to_string());
+        assert!(!cgo.matches(&expr("linux && !cgo")));
+        assert!(BuildTarget::new("android", "arm64").matches(&expr("linux")));
+        // 无法解析的表达式不排除文件
+        assert!(linux.matches(&expr("linux &&")));
+    }
+
+    #[test]
+    fn nesting_limit_test() {
+        let linux = BuildTarget::new("linux", "amd64");
+        assert!(!linux.matches(&vec![format!("{}windows{}", "(".repeat(64), ")".repeat(64))]));
+        // 超过嵌套层数时按无法解析处理，不会耗尽调用栈
+        assert!(linux.matches(&vec![format!("{}windows", "!".repeat(100_000))]));
+        assert!(linux.matches(&vec![format!("{}windows{}", "(".repeat(100_000), ")".repeat(100_000))]));
+    }
+}
@@ -0,0 +1,892 @@
+use std::cmp::Reverse;
+use std::collections::{BTreeMap, HashMap};
+use std::path::{Path, PathBuf};
+use std::sync::Arc;
+
+use serde_json::json;
+use tree_sitter::{Node, Tree};
+use uuid::Uuid;
+
+use crate::codegraph::symbol_graph::complexity::record_complexity;
+use crate::codegraph::symbol_graph::diff::record_body_hashes;
+use crate::codegraph::symbol_graph::docs::attach_doc_comments;
+use crate::codegraph::symbol_graph::fields::link_field_accesses;
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::symbol_graph::incremental::top_level_nodes;
+use crate::codegraph::symbol_graph::normalize::{unresolved_id, DefaultNormalizer, IdentifierNormalizer};
+use crate::codegraph::symbol_graph::packages::{link_packages, GoModule, GoModuleResolver};
+use crate::codegraph::symbol_graph::promotion::link_promotions;
+use crate::codegraph::symbol_graph::references::link_type_references;
+use crate::codegraph::symbol_graph::selectors::link_package_selectors;
+use crate::codegraph::symbol_graph::signatures::link_signature_types;
+use crate::codegraph::symbol_graph::span::Span;
+use crate::codegraph::symbol_graph::syntax::record_syntax_errors;
+use crate::codegraph::symbol_graph::types::{stable_id, ImportKind, ReceiverKind, SymbolEdge, SymbolEdgeKind, SymbolKind, SymbolNode};
+use crate::codegraph::symbol_graph::visibility::attach_visibility;
+use crate::codegraph::treesitter::ast_instance_structs::{AstSymbolInstance, AstSymbolInstanceArc, ClassFieldDeclaration, FunctionDeclaration, ImportDeclaration, StructDeclaration, StructKind, TypeDef, VariableDefinition, VariableKind};
+use crate::codegraph::treesitter::language_id::LanguageId;
+use crate::codegraph::treesitter::parsers::{get_ast_parser_by_filename, ParserError};
+use crate::codegraph::treesitter::structs::SymbolType;
+
+/// Go 内置函数，调用它们不产生调用边
+const GO_BUILTINS: [&str; 15] = [
+    "append", "cap", "clear", "close", "complex", "copy", "delete", "imag", "len", "make",
+    "max", "min", "new", "panic", "print",
+];
+
+impl SymbolGraph {
+    /// 从AST符号构建符号图
+    pub fn from_symbols(symbols: &[AstSymbolInstanceArc]) -> Self {
+        Self::from_symbols_with_context(symbols, &ParseContext::default())
+    }
+
+    pub(crate) fn from_symbols_with_context(symbols: &[AstSymbolInstanceArc], context: &ParseContext) -> Self {
+        SymbolGraphBuilder::new(symbols, context.normalizer()).build()
+    }
+}
+
+/// 构建单个文件的符号图时用到的、文件内容以外的信息。由 `parse_dir` 等调用方按目录查找一次后传入，
+/// 构建过程本身不读取磁盘；上下文不同时同一份源码的结果可能不同，缓存键包含上下文
+#[derive(Debug, Clone, Default)]
+pub struct ParseContext {
+    /// 文件所属的 Go 模块，None 时 Go 包节点的限定名为目录
+    pub go_module: Option<GoModule>,
+    /// 标识符规范化规则，None 时使用 `DefaultNormalizer`
+    pub normalizer: Option<Arc<dyn IdentifierNormalizer>>,
+}
+
+impl ParseContext {
+    /// 文件所在目录的上下文：`overlay` 中的 `go.mod` 优先于磁盘
+    pub fn for_file(path: &Path, overlay: &HashMap<PathBuf, String>) -> Self {
+        Self::resolve(path, &mut GoModuleResolver::new(overlay))
+    }
+
+    pub(crate) fn resolve(path: &Path, modules: &mut GoModuleResolver) -> Self {
+        let is_go = path.extension().map_or(false, |ext| ext == "go");
+        Self { go_module: path.parent().filter(|_| is_go).and_then(|dir| modules.resolve(dir)), normalizer: None }
+    }
+
+    pub fn normalizer(&self) -> &dyn IdentifierNormalizer {
+        self.normalizer.as_deref().unwrap_or(&DefaultNormalizer)
+    }
+}
+
+/// 解析源码并构建符号图，不读取磁盘；Go 包节点的限定名为目录，需要导入路径时使用 `parse_code_with_context`
+pub fn parse_code(code: &str, path: &PathBuf) -> Result<SymbolGraph, ParserError> {
+    parse_code_with_context(code, path, &ParseContext::default())
+}
+
+/// 与 `parse_code` 相同，使用调用方给出的上下文
+pub fn parse_code_with_context(code: &str, path: &PathBuf, context: &ParseContext) -> Result<SymbolGraph, ParserError> {
+    parse_source(code, path, context).map(|(graph, _tree, _language_id)| graph)
+}
+
+/// 构建符号图，同时返回使用的语法树（解析器不生成语法树时为 None）
+pub(crate) fn parse_source(code: &str, path: &PathBuf, context: &ParseContext) -> Result<(SymbolGraph, Option<Tree>, LanguageId), ParserError> {
+    parse_source_until(code, path, context, &|| false)
+}
+
+/// 与 `parse_source` 相同，`should_abort` 返回 true 时内置解析器中止解析并返回错误
+pub(crate) fn parse_source_until(
+    code: &str,
+    path: &PathBuf,
+    context: &ParseContext,
+    should_abort: &dyn Fn() -> bool,
+) -> Result<(SymbolGraph, Option<Tree>, LanguageId), ParserError> {
+    let (mut parser, language_id) = get_ast_parser_by_filename(path)?;
+    // 只解析一次：符号从语法树的顶层节点提取；不提供语法树的解析器（例如注册的自定义解析器）走 `parse`
+    let tree = parser.parse_tree_until(code, None, should_abort);
+    if tree.is_none() && should_abort() {
+        return Err(ParserError { message: format!("Parsing {} was aborted", path.display()) });
+    }
+    let graph = match &tree {
+        Some(tree) => {
+            let root = tree.root_node();
+            let symbols = parser.parse_top_level(&top_level_nodes(&root), code, path);
+            let mut graph = SymbolGraph::from_symbols_with_context(&symbols, context);
+            link_source(&mut graph, &root, code, path, context);
+            graph
+        }
+        None => {
+            let symbols = parser.parse(code, path);
+            let mut graph = SymbolGraph::from_symbols_with_context(&symbols, context);
+            attach_doc_comments(&mut graph, code, path);
+            record_body_hashes(&mut graph, code, path);
+            graph
+        }
+    };
+    Ok((graph, tree, language_id))
+}
+
+/// 由符号构建图之后，需要语法树和源码的处理：类型引用、包、包选择器、字段访问、文档注释、函数体哈希和圈复杂度
+pub(crate) fn link_source(graph: &mut SymbolGraph, root: &Node, code: &str, path: &PathBuf, context: &ParseContext) {
+    record_syntax_errors(graph, root, code, path);
+    link_type_references(graph, root, code, path);
+    link_signature_types(graph, root, code, path, context.normalizer());
+    link_packages(graph, root, code, path, context.go_module.as_ref());
+    link_package_selectors(graph, root, code, path, context.normalizer());
+    link_field_accesses(graph, root, code, path);
+    attach_doc_comments(graph, code, path);
+    record_body_hashes(graph, code, path);
+    record_complexity(graph, root, code, path);
+}
+
+/// 读取文件并构建符号图
+pub fn parse_file(path: &PathBuf) -> Result<SymbolGraph, ParserError> {
+    parse_file_with_overlay(path, &HashMap::new())
+}
+
+/// 与 `parse_file` 相同，但 `overlay` 中有该路径时使用其中的内容（例如编辑器中未保存的缓冲区），
+/// 文件在磁盘上不存在也可以解析。Go 模块按 `ParseContext::for_file` 查找
+pub fn parse_file_with_overlay(path: &PathBuf, overlay: &HashMap<PathBuf, String>) -> Result<SymbolGraph, ParserError> {
+    let code = read_source(path, overlay)?;
+    parse_code_with_context(&code, path, &ParseContext::for_file(path, overlay))
+}
+
+/// 文件内容，`overlay` 中的内容优先于磁盘
+pub(crate) fn read_source(path: &PathBuf, overlay: &HashMap<PathBuf, String>) -> Result<String, ParserError> {
+    if let Some(code) = overlay.get(path) {
+        return Ok(code.clone());
+    }
+    std::fs::read_to_string(path)
+        .map_err(|e| ParserError {
+            message: format!("Failed to read file {}: {}", path.display(), e)
+        })
+}
+
+/// 装饰器属性名：Python 装饰器为 `decorators`，Java 注解为 `annotations`
+fn decorators_attribute(language: LanguageId) -> &'static str {
+    match language {
+        LanguageId::Java => "annotations",
+        _ => "decorators",
+    }
+}
+
+/// impl 块的限定名：`impl Point`、`impl Display for Point`
+fn impl_qualified_name(attributes: &BTreeMap<String, serde_json::Value>) -> String {
+    let self_type = attributes.get("self_type").and_then(|t| t.as_str()).unwrap_or_default();
+    match attributes.get("trait").and_then(|t| t.as_str()) {
+        Some(trait_name) => format!("impl {} for {}", trait_name, self_type),
+        None => format!("impl {}", self_type),
+    }
+}
+
+/// impl 块中方法的限定名前缀：`Point`、`<Point as Display>`
+fn impl_method_prefix(attributes: &BTreeMap<String, serde_json::Value>) -> String {
+    let self_type = attributes.get("self_type").and_then(|t| t.as_str()).unwrap_or_default();
+    match attributes.get("trait").and_then(|t| t.as_str()) {
+        Some(trait_name) => format!("<{} as {}>", self_type, trait_name),
+        None => self_type.to_string(),
+    }
+}
+
+/// 类型在签名中的写法。Java 的基本类型没有名称，使用源码文本
+/// （其他语言的基本类型信息来自默认值，不是类型）
+fn signature_type(type_: &TypeDef, language: LanguageId) -> Option<String> {
+    match &type_.name {
+        Some(name) => Some(name.clone()),
+        None if language == LanguageId::Java && type_.is_pod => type_.inference_info.clone(),
+        None => None,
+    }
+}
+
+/// 函数去掉名称后的签名，例如 `(int, string) (int, error)`，用于比较 Go 方法集、
+/// 区分 Java 重载方法和计算稳定ID。没有写类型的参数使用参数名
+fn function_signature(decl: &FunctionDeclaration) -> String {
+    let language = decl.ast_fields.language;
+    let params = decl.args.iter()
+        .map(|arg| arg.type_.as_ref().and_then(|t| signature_type(t, language)).unwrap_or_else(|| arg.name.clone()))
+        .collect::<Vec<_>>();
+    match decl.return_type.as_ref().and_then(|t| signature_type(t, language)) {
+        Some(result) => format!("({}) {}", params.join(", "), result),
+        None => format!("({})", params.join(", ")),
+    }
+}
+
+/// 文件节点，不存在时创建
+pub(crate) fn add_file_node(graph: &mut SymbolGraph, file_path: &PathBuf, language: LanguageId) -> Uuid {
+    let id = stable_id(file_path, SymbolKind::File, &file_path.display().to_string(), 0, None);
+    if graph.get_node(&id).is_none() {
+        let name = file_path.file_name()
+            .map(|name| name.to_string_lossy().to_string())
+            .unwrap_or_else(|| file_path.display().to_string());
+        graph.add_node(SymbolNode {
+            id,
+            kind: SymbolKind::File,
+            name,
+            qualified_name: file_path.display().to_string(),
+            language,
+            file_path: file_path.clone(),
+            span: Span::default(),
+            declaration_span: Span::default(),
+            doc: None,
+            attributes: BTreeMap::new(),
+        });
+    }
+    id
+}
+
+struct SymbolGraphBuilder<'a> {
+    /// 按文件和位置排序的符号，保证父符号先于子符号处理
+    symbols: Vec<&'a AstSymbolInstanceArc>,
+    guid_to_symbol: HashMap<Uuid, &'a AstSymbolInstanceArc>,
+    /// AST符号guid -> 节点ID
+    guid_to_node: HashMap<Uuid, Uuid>,
+    /// 节点ID -> AST符号guid
+    node_to_guid: HashMap<Uuid, Uuid>,
+    /// 占位节点按规范化后的名称合并
+    normalizer: &'a dyn IdentifierNormalizer,
+    graph: SymbolGraph,
+}
+
+impl<'a> SymbolGraphBuilder<'a> {
+    fn new(symbols: &'a [AstSymbolInstanceArc], normalizer: &'a dyn IdentifierNormalizer) -> Self {
+        let mut sorted = symbols.iter().collect::<Vec<_>>();
+        sorted.sort_by_key(|s| {
+            let s = s.read();
+            (s.file_path().clone(), s.full_range().start_byte, Reverse(s.full_range().end_byte))
+        });
+        let guid_to_symbol = symbols.iter()
+            .map(|s| (s.read().guid().clone(), s))
+            .collect::<HashMap<_, _>>();
+        Self {
+            symbols: sorted,
+            guid_to_symbol,
+            guid_to_node: HashMap::new(),
+            node_to_guid: HashMap::new(),
+            normalizer,
+            graph: SymbolGraph::new(),
+        }
+    }
+
+    fn build(mut self) -> SymbolGraph {
+        self.add_declarations();
+        self.link_methods();
+        self.link_inheritance();
+        self.link_constraints();
+        self.link_imports();
+        link_promotions(&mut self.graph);
+        self.link_calls();
+        self.link_variable_types();
+        attach_visibility(&mut self.graph);
+        self.graph
+    }
+
+    /// 节点对应的AST符号
+    fn node_symbol(&self, node_id: &Uuid) -> Option<&'a AstSymbolInstanceArc> {
+        self.node_to_guid.get(node_id)
+            .and_then(|guid| self.guid_to_symbol.get(guid))
+            .copied()
+    }
+
+    /// 最近的已加入图的祖先符号对应的节点ID
+    fn enclosing_node_id(&self, symbol: &AstSymbolInstanceArc) -> Option<Uuid> {
+        let mut parent_mb = symbol.read().parent_guid().clone();
+        while let Some(parent_guid) = parent_mb {
+            if let Some(node_id) = self.guid_to_node.get(&parent_guid) {
+                return Some(*node_id);
+            }
+            parent_mb = self.guid_to_symbol.get(&parent_guid)
+                .and_then(|s| s.read().parent_guid().clone());
+        }
+        None
+    }
+
+    /// 声明节点以及包含关系
+    fn add_declarations(&mut self) {
+        let mut occurrences: HashMap<(PathBuf, SymbolKind, String, Option<String>), usize> = HashMap::new();
+        for symbol in self.symbols.clone() {
+            let mut parent_id = self.enclosing_node_id(symbol);
+            let mut parent = parent_id.and_then(|id| self.graph.get_node(&id)).cloned();
+            let sym = symbol.read();
+
+            let mut attributes = BTreeMap::new();
+            // C++ 类外定义的成员 `void Circle::area()` 属于之前声明的类或命名空间
+            if let Some(owner) = self.out_of_line_owner(&**sym, parent.as_ref()) {
+                attributes.insert("out_of_line".to_string(), json!(true));
+                parent_id = Some(owner.id);
+                parent = Some(owner);
+            }
+            let mut receiver_name: Option<String> = None;
+            let mut type_params: Vec<TypeDef> = vec![];
+            let kind = match sym.symbol_type() {
+                SymbolType::StructDeclaration => {
+                    match sym.as_any().downcast_ref::<StructDeclaration>() {
+                        Some(decl) => {
+                            if !decl.decorators.is_empty() {
+                                attributes.insert(decorators_attribute(*sym.language()).to_string(), json!(decl.decorators));
+                            }
+                            if decl.kind == StructKind::Interface && *sym.language() == LanguageId::Go && !decl.inherited_types.is_empty() {
+                                let embeds = decl.inherited_types.iter()
+                                    .filter_map(|t| t.name.clone())
+                                    .collect::<Vec<_>>();
+                                attributes.insert("embeds".to_string(), json!(embeds));
+                            }
+                            if matches!(*sym.language(), LanguageId::Go | LanguageId::Cpp) {
+                                type_params = decl.template_types.clone();
+                            }
+                            if !decl.generated_members.is_empty() {
+                                attributes.insert("generated_members".to_string(), json!(decl.generated_members));
+                            }
+                            match decl.kind {
+                                StructKind::Struct => SymbolKind::Struct,
+                                StructKind::Interface => SymbolKind::Interface,
+                                StructKind::Enum => SymbolKind::Enum,
+                                StructKind::Union => SymbolKind::Union,
+                                StructKind::Namespace => SymbolKind::Namespace,
+                                StructKind::Object => SymbolKind::Object,
+                                StructKind::Impl => {
+                                    attributes.insert("self_type".to_string(), json!(sym.name()));
+                                    if let Some(trait_name) = decl.implemented_types.first().and_then(|t| t.name.clone()) {
+                                        attributes.insert("trait".to_string(), json!(trait_name));
+                                    }
+                                    SymbolKind::Impl
+                                }
+                            }
+                        }
+                        None => SymbolKind::Struct,
+                    }
+                }
+                SymbolType::TypeAlias => SymbolKind::TypeAlias,
+                SymbolType::ClassFieldDeclaration => {
+                    let decl = sym.as_any().downcast_ref::<ClassFieldDeclaration>();
+                    if let Some(decl) = decl.filter(|decl| decl.embedded) {
+                        attributes.insert("embedded".to_string(), json!(true));
+                        if let Some(type_name) = &decl.type_.name {
+                            attributes.insert("type".to_string(), json!(type_name));
+                        }
+                    }
+                    if let Some(access) = decl.and_then(|decl| decl.access.as_ref()) {
+                        attributes.insert("access".to_string(), json!(access));
+                    }
+                    SymbolKind::Field
+                }
+                SymbolType::FunctionDeclaration => {
+                    let decl = sym.as_any().downcast_ref::<FunctionDeclaration>();
+                    if let Some(decl) = decl {
+                        if !decl.decorators.is_empty() {
+                            attributes.insert(decorators_attribute(*sym.language()).to_string(), json!(decl.decorators));
+                        }
+                        attributes.insert("signature".to_string(), json!(function_signature(decl)));
+                        if decl.file_local {
+                            attributes.insert("file_local".to_string(), json!(true));
+                        }
+                        if let Some(access) = &decl.access {
+                            attributes.insert("access".to_string(), json!(access));
+                        }
+                        if matches!(*sym.language(), LanguageId::Go | LanguageId::Cpp) {
+                            type_params = decl.template_types.clone();
+                        }
+                        if let Some(receiver) = &decl.receiver {
+                            let type_name = receiver.type_.name.clone().unwrap_or_default();
+                            receiver_name = Some(if *sym.language() == LanguageId::Kotlin {
+                                // 扩展函数 `fun Point.moved()` 的限定名为 `Point.moved`
+                                attributes.insert("extension".to_string(), json!(true));
+                                type_name
+                            } else if receiver.is_pointer {
+                                format!("(*{})", type_name)
+                            } else {
+                                format!("({})", type_name)
+                            });
+                        }
+                    }
+                    let in_struct = parent.as_ref().map_or(false, |p| matches!(p.kind, SymbolKind::Struct | SymbolKind::Interface | SymbolKind::Enum | SymbolKind::Impl | SymbolKind::Object));
+                    if receiver_name.is_some() || in_struct {
+                        SymbolKind::Method
+                    } else {
+                        SymbolKind::Function
+                    }
+                }
+                // C 的全局变量和宏、Go 的变量和常量；其他语言的变量定义是局部变量，不加入图
+                SymbolType::VariableDefinition if matches!(*sym.language(), LanguageId::C | LanguageId::Go) => {
+                    let decl = sym.as_any().downcast_ref::<VariableDefinition>();
+                    match decl.map(|decl| decl.kind) {
+                        Some(VariableKind::Macro) => SymbolKind::Macro,
+                        Some(VariableKind::FunctionMacro) => {
+                            attributes.insert("function_like".to_string(), json!(true));
+                            SymbolKind::Macro
+                        }
+                        kind => {
+                            if kind == Some(VariableKind::Constant) {
+                                attributes.insert("constant".to_string(), json!(true));
+                            }
+                            if let Some(type_name) = decl.and_then(|decl| decl.type_.name.clone()) {
+                                attributes.insert("type".to_string(), json!(type_name));
+                            }
+                            SymbolKind::Variable
+                        }
+                    }
+                }
+                _ => continue,
+            };
+
+            let qualified_name = match (&receiver_name, &parent) {
+                _ if kind == SymbolKind::Impl => impl_qualified_name(&attributes),
+                (Some(receiver_name), _) => format!("{}.{}", receiver_name, sym.name()),
+                (None, Some(parent)) if parent.kind == SymbolKind::Impl => {
+                    format!("{}.{}", impl_method_prefix(&parent.attributes), sym.name())
+                }
+                (None, Some(parent)) => format!("{}.{}", parent.qualified_name, sym.name()),
+                (None, None) => sym.name().to_string(),
+            };
+
+            // 语法错误区域中恢复出的声明
+            if sym.is_error() {
+                attributes.insert("syntax_error".to_string(), json!(true));
+            }
+
+            // 同一文件中类型、限定名和签名都相同的声明按源码顺序编号
+            let signature = attributes.get("signature").and_then(|s| s.as_str()).map(|s| s.to_string());
+            let occurrence = occurrences.entry((sym.file_path().clone(), kind, qualified_name.clone(), signature.clone())).or_insert(0);
+            if *occurrence > 0 {
+                attributes.insert("index".to_string(), json!(*occurrence));
+            }
+            let id = stable_id(sym.file_path(), kind, &qualified_name, *occurrence, signature.as_deref());
+            *occurrence += 1;
+            self.guid_to_node.insert(sym.guid().clone(), id);
+            self.node_to_guid.insert(id, sym.guid().clone());
+            let node = SymbolNode {
+                id,
+                kind,
+                name: sym.name().to_string(),
+                qualified_name,
+                language: sym.language().clone(),
+                file_path: sym.file_path().clone(),
+                span: Span::from(sym.full_range()),
+                declaration_span: Span::from(sym.declaration_range()),
+                doc: None,
+                attributes,
+            };
+            self.graph.add_node(node.clone());
+            if let Some(parent_id) = parent_id {
+                let _ = self.graph.add_edge(SymbolEdge::new(parent_id, id, SymbolEdgeKind::Contains));
+            }
+            self.add_type_parameters(&node, &type_params);
+        }
+    }
+
+    /// C++ 限定名定义的函数（`double Circle::area() const`、`void geo::reset()`）所属的类或命名空间：
+    /// 同一文件中已经加入图的同名节点，先在所在的命名空间中查找，再从顶层查找
+    fn out_of_line_owner(&self, sym: &dyn AstSymbolInstance, parent: Option<&SymbolNode>) -> Option<SymbolNode> {
+        if *sym.language() != LanguageId::Cpp || sym.symbol_type() != SymbolType::FunctionDeclaration || sym.namespace().is_empty() {
+            return None;
+        }
+        if parent.map_or(false, |p| p.kind != SymbolKind::Namespace) {
+            return None;
+        }
+        let scope = sym.namespace().replace("::", ".");
+        let candidates = parent.map(|p| format!("{}.{}", p.qualified_name, scope)).into_iter().chain([scope.clone()]);
+        for qualified_name in candidates {
+            let owner = self.graph.find_nodes_by_qualified_name(&qualified_name).into_iter()
+                .find(|n| &n.file_path == sym.file_path() && matches!(n.kind, SymbolKind::Struct | SymbolKind::Union | SymbolKind::Namespace));
+            if let Some(owner) = owner {
+                return Some(owner.clone());
+            }
+        }
+        None
+    }
+
+    /// Go 泛型声明的类型参数，作为声明的子节点，位置为声明头部。
+    /// 约束类型的名称记在 `constraints` 属性中，由 `link_constraints` 连接
+    fn add_type_parameters(&mut self, owner: &SymbolNode, type_params: &[TypeDef]) {
+        for param in type_params {
+            let name = match &param.name {
+                Some(name) => name.clone(),
+                None => continue,
+            };
+            let constraints = param.nested_types.iter()
+                .filter_map(|t| t.name.clone())
+                .collect::<Vec<_>>();
+            let qualified_name = format!("{}.{}", owner.qualified_name, name);
+            let id = stable_id(&owner.file_path, SymbolKind::TypeParameter, &qualified_name, 0, None);
+            let mut attributes = BTreeMap::new();
+            attributes.insert("constraints".to_string(), json!(constraints));
+            self.graph.add_node(SymbolNode {
+                id,
+                kind: SymbolKind::TypeParameter,
+                name,
+                qualified_name,
+                language: owner.language,
+                file_path: owner.file_path.clone(),
+                span: owner.declaration_span,
+                declaration_span: owner.declaration_span,
+                doc: None,
+                attributes,
+            });
+            let _ = self.graph.add_edge(SymbolEdge::new(owner.id, id, SymbolEdgeKind::Contains));
+        }
+    }
+
+    /// 类型参数 -> 约束类型。约束在同一文件内按名称查找，找不到时（`any`、`comparable`、
+    /// `~int`、其他包的类型）指向占位节点
+    fn link_constraints(&mut self) {
+        let types_by_name = self.types_by_name();
+        let params = self.graph.nodes()
+            .filter(|n| n.kind == SymbolKind::TypeParameter)
+            .cloned()
+            .collect::<Vec<_>>();
+        for param in params {
+            let constraints = param.attributes.get("constraints")
+                .and_then(|c| c.as_array())
+                .cloned()
+                .unwrap_or_default();
+            for constraint in constraints.iter().filter_map(|c| c.as_str()) {
+                let target = match types_by_name.get(&(param.file_path.clone(), constraint.to_string())) {
+                    Some(type_id) => *type_id,
+                    None => {
+                        let id = unresolved_id(&param.file_path, param.language, constraint, self.normalizer);
+                        self.graph.add_node(SymbolNode {
+                            id,
+                            kind: SymbolKind::Unresolved,
+                            name: constraint.to_string(),
+                            qualified_name: constraint.to_string(),
+                            language: param.language,
+                            file_path: param.file_path.clone(),
+                            span: param.span,
+                            declaration_span: param.span,
+                            doc: None,
+                            attributes: BTreeMap::new(),
+                        });
+                        id
+                    }
+                };
+                let _ = self.graph.add_edge(SymbolEdge::new(param.id, target, SymbolEdgeKind::ConstrainedBy));
+            }
+        }
+    }
+
+    /// 同一文件内按名称索引的类型节点
+    fn types_by_name(&self) -> HashMap<(PathBuf, String), Uuid> {
+        let mut types_by_name: HashMap<(PathBuf, String), Uuid> = HashMap::new();
+        for node in self.graph.nodes() {
+            if matches!(node.kind, SymbolKind::Struct | SymbolKind::Interface | SymbolKind::Enum | SymbolKind::Union | SymbolKind::TypeAlias | SymbolKind::Object) {
+                types_by_name.entry((node.file_path.clone(), node.name.clone())).or_insert(node.id);
+            }
+        }
+        types_by_name
+    }
+
+    /// 方法 -> 接收者类型。Kotlin 扩展函数的接收者类型不在同一文件中时（例如 `String`），
+    /// 改为 扩展函数 -> 占位节点 的引用边
+    fn link_methods(&mut self) {
+        let types_by_name = self.types_by_name();
+
+        let mut edges = vec![];
+        let mut placeholders = vec![];
+        for node in self.graph.nodes_of_kind(SymbolKind::Method) {
+            let symbol = match self.node_symbol(&node.id) {
+                Some(symbol) => symbol.read(),
+                None => continue,
+            };
+            let receiver = symbol.as_any().downcast_ref::<FunctionDeclaration>()
+                .and_then(|decl| decl.receiver.clone());
+            match receiver {
+                Some(receiver) if node.language == LanguageId::Kotlin => {
+                    let type_name = receiver.type_.name.clone().unwrap_or_default();
+                    match types_by_name.get(&(node.file_path.clone(), type_name.clone())) {
+                        Some(type_id) => edges.push(SymbolEdge::new(node.id, *type_id, SymbolEdgeKind::MethodOf)),
+                        None => {
+                            let id = unresolved_id(&node.file_path, node.language, &type_name, self.normalizer);
+                            placeholders.push(SymbolNode {
+                                id,
+                                kind: SymbolKind::Unresolved,
+                                name: type_name.clone(),
+                                qualified_name: type_name,
+                                language: node.language,
+                                file_path: node.file_path.clone(),
+                                span: node.declaration_span,
+                                declaration_span: node.declaration_span,
+                                doc: None,
+                                attributes: BTreeMap::new(),
+                            });
+                            edges.push(SymbolEdge::new(node.id, id, SymbolEdgeKind::References));
+                        }
+                    }
+                }
+                Some(receiver) => {
+                    let type_name = receiver.type_.name.clone().unwrap_or_default();
+                    if let Some(type_id) = types_by_name.get(&(node.file_path.clone(), type_name)) {
+                        let receiver_kind = if receiver.is_pointer { ReceiverKind::Pointer } else { ReceiverKind::Value };
+                        let mut edge = SymbolEdge::new(node.id, *type_id, SymbolEdgeKind::MethodOf);
+                        edge.metadata = Some(json!({"receiver": receiver_kind.as_str()}));
+                        edges.push(edge);
+                    }
+                }
+                None => match self.graph.parent_of(&node.id) {
+                    // impl 块中的方法属于实现的类型
+                    Some(parent) if parent.kind == SymbolKind::Impl => {
+                        let self_type = parent.attributes.get("self_type").and_then(|t| t.as_str()).unwrap_or_default();
+                        if let Some(type_id) = types_by_name.get(&(node.file_path.clone(), self_type.to_string())) {
+                            edges.push(SymbolEdge::new(node.id, *type_id, SymbolEdgeKind::MethodOf));
+                        }
+                    }
+                    Some(parent) => edges.push(SymbolEdge::new(node.id, parent.id, SymbolEdgeKind::MethodOf)),
+                    None => {}
+                },
+            }
+        }
+        for placeholder in placeholders {
+            self.graph.add_node(placeholder);
+        }
+        for edge in edges {
+            let _ = self.graph.add_edge(edge);
+        }
+    }
+
+    /// extends / implements 关系，只连接同一文件内能找到的类型。
+    /// Rust 的 `impl Trait for Type` 产生 Type -> Trait 的 implements 边
+    fn link_inheritance(&mut self) {
+        let types_by_name = self.types_by_name();
+
+        let mut edges = vec![];
+        for node in self.graph.nodes() {
+            let source_id = match node.kind {
+                SymbolKind::Struct | SymbolKind::Interface | SymbolKind::Enum | SymbolKind::Object => node.id,
+                SymbolKind::Impl => {
+                    let self_type = node.attributes.get("self_type").and_then(|t| t.as_str()).unwrap_or_default();
+                    match types_by_name.get(&(node.file_path.clone(), self_type.to_string())) {
+                        Some(type_id) => *type_id,
+                        None => continue,
+                    }
+                }
+                _ => continue,
+            };
+            let symbol = match self.node_symbol(&node.id) {
+                Some(symbol) => symbol.read(),
+                None => continue,
+            };
+            let decl = match symbol.as_any().downcast_ref::<StructDeclaration>() {
+                Some(decl) => decl,
+                None => continue,
+            };
+            let bases = decl.inherited_types.iter().map(|t| (t, SymbolEdgeKind::Extends))
+                .chain(decl.implemented_types.iter().map(|t| (t, SymbolEdgeKind::Implements)));
+            for (type_, kind) in bases {
+                let type_name = match &type_.name {
+                    Some(name) => name.clone(),
+                    None => continue,
+                };
+                if let Some(type_id) = types_by_name.get(&(node.file_path.clone(), type_name)) {
+                    if *type_id != source_id {
+                        edges.push(SymbolEdge::new(source_id, *type_id, kind));
+                    }
+                }
+            }
+        }
+        for edge in edges {
+            let _ = self.graph.add_edge(edge);
+        }
+    }
+
+    /// 导入节点以及 文件 -> 导入 的边
+    fn link_imports(&mut self) {
+        let mut occurrences: HashMap<(PathBuf, String), usize> = HashMap::new();
+        for symbol in self.symbols.clone() {
+            let sym = symbol.read();
+            let decl = match sym.as_any().downcast_ref::<ImportDeclaration>() {
+                Some(decl) => decl,
+                None => continue,
+            };
+            let path = match &decl.path {
+                Some(path) => path.clone(),
+                None => continue,
+            };
+            let file_path = sym.file_path().clone();
+            let file_id = self.file_node(&file_path, sym.language().clone());
+
+            let import_kind = ImportKind::from_alias(decl.alias.as_deref());
+            let mut attributes = BTreeMap::new();
+            attributes.insert("path".to_string(), json!(path));
+            attributes.insert("import_kind".to_string(), json!(import_kind.as_str()));
+            if let Some(alias) = &decl.alias {
+                attributes.insert("alias".to_string(), json!(alias));
+            }
+            let occurrence = occurrences.entry((file_path.clone(), path.clone())).or_insert(0);
+            if *occurrence > 0 {
+                attributes.insert("index".to_string(), json!(*occurrence));
+            }
+            let id = stable_id(&file_path, SymbolKind::Import, &path, *occurrence, None);
+            *occurrence += 1;
+            self.guid_to_node.insert(sym.guid().clone(), id);
+            self.node_to_guid.insert(id, sym.guid().clone());
+            self.graph.add_node(SymbolNode {
+                id,
+                kind: SymbolKind::Import,
+                name: path.clone(),
+                qualified_name: path,
+                language: sym.language().clone(),
+                file_path,
+                span: Span::from(sym.full_range()),
+                declaration_span: Span::from(sym.full_range()),
+                doc: None,
+                attributes,
+            });
+            let _ = self.graph.add_edge(SymbolEdge::new(file_id, id, SymbolEdgeKind::Imports));
+        }
+    }
+
+    /// 文件节点，不存在时创建
+    fn file_node(&mut self, file_path: &PathBuf, language: LanguageId) -> Uuid {
+        add_file_node(&mut self.graph, file_path, language)
+    }
+
+    /// 调用边：调用所在的函数 -> 被调用的函数或方法，无法解析时指向占位节点。
+    /// C 中同一文件定义的函数式宏优先于同名函数（预处理先展开宏），宏的使用记为引用边
+    fn link_calls(&mut self) {
+        let functions = self.functions_by_name();
+        let mut methods: HashMap<(PathBuf, String, String), Uuid> = HashMap::new();
+        let mut macros: HashMap<(PathBuf, String), Uuid> = HashMap::new();
+        for node in self.graph.nodes() {
+            match node.kind {
+                SymbolKind::Macro if node.attributes.contains_key("function_like") => {
+                    macros.entry((node.file_path.clone(), node.name.clone())).or_insert(node.id);
+                }
+                SymbolKind::Method => {
+                    if let Some(edge) = self.graph.outgoing_edges(&node.id, Some(SymbolEdgeKind::MethodOf)).first() {
+                        let type_name = self.graph.get_node(&edge.target).unwrap().name.clone();
+                        methods.entry((node.file_path.clone(), type_name, node.name.clone())).or_insert(node.id);
+                    }
+                }
+                _ => {}
+            }
+        }
+        // 自身没有声明的方法通过嵌入字段提升
+        for edge in self.graph.edges_of_kind(SymbolEdgeKind::Promotes) {
+            let outer = self.graph.get_node(&edge.source).unwrap();
+            let method = self.graph.get_node(&edge.target).unwrap();
+            methods.entry((outer.file_path.clone(), outer.name.clone(), method.name.clone())).or_insert(method.id);
+        }
+
+        let mut unresolved: HashMap<(PathBuf, String), Uuid> = HashMap::new();
+        for symbol in self.symbols.clone() {
+            let sym = symbol.read();
+            // 目前只解析 Go（按选择器语义）和 C 的调用目标
+            if sym.symbol_type() != SymbolType::FunctionCall || !matches!(*sym.language(), LanguageId::Go | LanguageId::C) {
+                continue;
+            }
+            let caller_id = match self.enclosing_node_id(symbol) {
+                Some(id) if matches!(self.graph.get_node(&id).map(|n| n.kind), Some(SymbolKind::Function | SymbolKind::Method)) => id,
+                _ => continue,
+            };
+            let file_path = sym.file_path().clone();
+            let namespace = sym.namespace().to_string();
+            if sym.name().is_empty() || (namespace.is_empty() && *sym.language() == LanguageId::Go && GO_BUILTINS.contains(&sym.name())) {
+                continue;
+            }
+            if namespace.is_empty() {
+                if let Some(macro_id) = macros.get(&(file_path.clone(), sym.name().to_string())) {
+                    let mut edge = SymbolEdge::new(caller_id, *macro_id, SymbolEdgeKind::References);
+                    edge.metadata = Some(json!({"span": Span::from(sym.full_range())}));
+                    let _ = self.graph.add_edge(edge);
+                    continue;
+                }
+            }
+            let callee_id = if namespace.is_empty() {
+                functions.get(&(file_path.clone(), sym.name().to_string())).copied()
+            } else {
+                sym.get_linked_decl_type().as_ref()
+                    .and_then(|type_| self.resolve_type_name(type_, &file_path, &functions))
+                    .and_then(|type_name| methods.get(&(file_path.clone(), type_name, sym.name().to_string())).copied())
+            };
+            let callee_id = match callee_id {
+                Some(id) => id,
+                None => {
+                    let qualified_name = if namespace.is_empty() {
+                        sym.name().to_string()
+                    } else {
+                        format!("{}.{}", namespace, sym.name())
+                    };
+                    let (graph, normalizer) = (&mut self.graph, self.normalizer);
+                    let key = normalizer.normalize(*sym.language(), &qualified_name);
+                    *unresolved.entry((file_path.clone(), key)).or_insert_with(|| {
+                        let id = unresolved_id(&file_path, *sym.language(), &qualified_name, normalizer);
+                        graph.add_node(SymbolNode {
+                            id,
+                            kind: SymbolKind::Unresolved,
+                            name: sym.name().to_string(),
+                            qualified_name,
+                            language: sym.language().clone(),
+                            file_path: file_path.clone(),
+                            span: Span::from(sym.full_range()),
+                            declaration_span: Span::from(sym.full_range()),
+                            doc: None,
+                            attributes: BTreeMap::new(),
+                        });
+                        id
+                    })
+                }
+            };
+            let mut edge = SymbolEdge::new(caller_id, callee_id, SymbolEdgeKind::Calls);
+            edge.metadata = Some(json!({"span": Span::from(sym.full_range())}));
+            let _ = self.graph.add_edge(edge);
+        }
+    }
+
+    /// (文件, 名称) -> 同一文件中第一个同名函数
+    fn functions_by_name(&self) -> HashMap<(PathBuf, String), Uuid> {
+        let mut functions: HashMap<(PathBuf, String), Uuid> = HashMap::new();
+        for node in self.graph.nodes_of_kind(SymbolKind::Function) {
+            functions.entry((node.file_path.clone(), node.name.clone())).or_insert(node.id);
+        }
+        functions
+    }
+
+    /// 类型边：变量 -> 同一文件中声明的类型，函数内声明的同名类型优先。
+    /// 没有写出类型时按初始值推断（`p := NewPoint(1, 2)` 取 NewPoint 的返回类型），边上记录 `inferred`；
+    /// 其他包的类型和内置类型不加边
+    fn link_variable_types(&mut self) {
+        let functions = self.functions_by_name();
+        let mut types: HashMap<(PathBuf, String), Uuid> = HashMap::new();
+        for node in self.graph.nodes() {
+            if matches!(node.kind, SymbolKind::Struct | SymbolKind::Interface | SymbolKind::Enum | SymbolKind::Union | SymbolKind::TypeAlias) {
+                types.entry((node.file_path.clone(), node.qualified_name.clone())).or_insert(node.id);
+            }
+        }
+        let mut edges = vec![];
+        for node in self.graph.nodes_of_kind(SymbolKind::Variable) {
+            let symbol = match self.node_symbol(&node.id) {
+                Some(symbol) => symbol.read(),
+                None => continue,
+            };
+            let type_ = match symbol.as_any().downcast_ref::<VariableDefinition>() {
+                Some(decl) => &decl.type_,
+                None => continue,
+            };
+            if !type_.namespace.is_empty() {
+                continue;
+            }
+            let type_name = match self.resolve_type_name(type_, &node.file_path, &functions) {
+                Some(type_name) => type_name.trim_end_matches('*').trim().to_string(),
+                None => continue,
+            };
+            if type_name.contains('.') {
+                continue;
+            }
+            let scoped = node.qualified_name.rsplit_once('.')
+                .and_then(|(scope, _)| types.get(&(node.file_path.clone(), format!("{}.{}", scope, type_name))));
+            if let Some(type_id) = scoped.or_else(|| types.get(&(node.file_path.clone(), type_name))) {
+                let mut edge = SymbolEdge::new(node.id, *type_id, SymbolEdgeKind::HasType);
+                if type_.name.is_none() {
+                    edge.metadata = Some(json!({"inferred": true}));
+                }
+                edges.push(edge);
+            }
+        }
+        for edge in edges {
+            let _ = self.graph.add_edge(edge);
+        }
+    }
+
+    /// 解析推断的类型名，`NewPoint(1, 2)` 这类调用取被调函数的返回类型
+    fn resolve_type_name(&self, type_: &TypeDef, file_path: &PathBuf, functions: &HashMap<(PathBuf, String), Uuid>) -> Option<String> {
+        if let Some(name) = &type_.name {
+            return Some(name.trim_start_matches('*').to_string());
+        }
+        let inference_info = type_.inference_info.as_ref()?;
+        let callee = inference_info.split('(').next()?.trim();
+        let function_id = functions.get(&(file_path.clone(), callee.to_string()))?;
+        let symbol = self.node_symbol(function_id)?.read();
+        let return_type = symbol.as_any().downcast_ref::<FunctionDeclaration>()?.return_type.clone()?;
+        return_type.name.map(|name| name.trim_start_matches('*').to_string())
+    }
+}
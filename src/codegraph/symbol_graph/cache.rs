@@ -0,0 +1,317 @@
+use std::collections::{BTreeMap, HashMap};
+use std::fmt::Debug;
+use std::fs;
+use std::path::{Path, PathBuf};
+use std::sync::Mutex;
+
+use crate::codegraph::symbol_graph::builder::ParseContext;
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::symbol_graph::json::SYMBOL_GRAPH_SCHEMA_VERSION;
+
+/// 解析器版本，提取规则变化（新的节点、边或属性）时递增，旧版本的缓存随之失效
+pub const PARSER_VERSION: u32 = 12;
+
+/// 单个文件解析结果的缓存，值为序列化后的文件符号图。
+/// 需要能在多个解析线程间共享
+pub trait Cache: Send + Sync + Debug {
+    fn get(&self, key: &str) -> Option<String>;
+    fn put(&self, key: &str, value: String);
+}
+
+/// 缓存键：解析器版本、存储格式版本、文件路径、构建上下文和文件内容的哈希。
+/// 节点ID由路径计算，内容相同但路径不同的文件不能共用结果；
+/// 包节点的导入路径来自上下文中的 Go 模块，修改 `go.mod` 或更换规范化规则后旧的结果随之失效
+pub fn cache_key(path: &Path, code: &str, context: &ParseContext) -> String {
+    versioned_key(PARSER_VERSION, path, code, context)
+}
+
+fn versioned_key(parser_version: u32, path: &Path, code: &str, context: &ParseContext) -> String {
+    let module = match &context.go_module {
+        Some(module) => format!("{}\0{}", module.root.display(), module.path),
+        None => String::new(),
+    };
+    let digest = md5::compute(format!(
+        "{}\0{}\0{}\0{}\0{}\0{}",
+        parser_version, SYMBOL_GRAPH_SCHEMA_VERSION, path.display(), module, context.normalizer().name(), code
+    ));
+    format!("{:x}", digest)
+}
+
+/// 读取缓存的文件符号图，条目无法解析（例如旧的存储格式）时视为未命中
+pub(crate) fn load(cache: &dyn Cache, key: &str) -> Option<SymbolGraph> {
+    cache.get(key).and_then(|json| SymbolGraph::from_json(&json).ok())
+}
+
+/// 保存文件符号图，序列化失败时不缓存
+pub(crate) fn store(cache: &dyn Cache, key: &str, graph: &SymbolGraph) {
+    if let Ok(json) = graph.to_json() {
+        cache.put(key, json);
+    }
+}
+
+/// 进程内缓存，适合常驻进程中反复解析同一个目录。
+/// 设置了容量时超出后淘汰最久未使用的条目，否则条目一直保留
+#[derive(Debug, Default)]
+pub struct MemoryCache {
+    entries: Mutex<MemoryEntries>,
+    capacity: Option<usize>,
+}
+
+/// 键 -> (值, 最近一次使用的序号)，以及按序号排列的键
+#[derive(Debug, Default)]
+struct MemoryEntries {
+    values: HashMap<String, (String, u64)>,
+    recent: BTreeMap<u64, String>,
+    tick: u64,
+}
+
+impl MemoryEntries {
+    fn touch(&mut self, key: &str) -> Option<String> {
+        self.tick += 1;
+        let (value, used) = self.values.get_mut(key)?;
+        self.recent.remove(used);
+        *used = self.tick;
+        self.recent.insert(self.tick, key.to_string());
+        Some(value.clone())
+    }
+}
+
+impl MemoryCache {
+    pub fn new() -> Self {
+        Self::default()
+    }
+
+    /// 最多保留 `entries` 个条目的缓存
+    pub fn with_capacity(entries: usize) -> Self {
+        Self { entries: Mutex::default(), capacity: Some(entries) }
+    }
+
+    pub fn len(&self) -> usize {
+        self.entries.lock().unwrap().values.len()
+    }
+
+    pub fn is_empty(&self) -> bool {
+        self.len() == 0
+    }
+}
+
+impl Cache for MemoryCache {
+    fn get(&self, key: &str) -> Option<String> {
+        self.entries.lock().unwrap().touch(key)
+    }
+
+    fn put(&self, key: &str, value: String) {
+        let mut entries = self.entries.lock().unwrap();
+        entries.tick += 1;
+        let tick = entries.tick;
+        if let Some((_, used)) = entries.values.insert(key.to_string(), (value, tick)) {
+            entries.recent.remove(&used);
+        }
+        entries.recent.insert(tick, key.to_string());
+        while self.capacity.map_or(false, |capacity| entries.values.len() > capacity) {
+            let Some((_, oldest)) = entries.recent.pop_first() else { break };
+            entries.values.remove(&oldest);
+        }
+    }
+}
+
+/// 磁盘缓存，每个条目是目录下的一个 `<键>.json` 文件，跨进程保留。
+/// 读写失败都按未命中处理，不影响解析
+#[derive(Debug, Clone)]
+pub struct DiskCache {
+    dir: PathBuf,
+}
+
+impl DiskCache {
+    /// 使用 `dir` 作为缓存目录，不存在时创建
+    pub fn new(dir: impl Into<PathBuf>) -> Result<Self, String> {
+        let dir = dir.into();
+        fs::create_dir_all(&dir)
+            .map_err(|e| format!("Failed to create cache directory {}: {}", dir.display(), e))?;
+        Ok(Self { dir })
+    }
+
+    fn entry_path(&self, key: &str) -> PathBuf {
+        self.dir.join(format!("{}.json", key))
+    }
+}
+
+impl Cache for DiskCache {
+    fn get(&self, key: &str) -> Option<String> {
+        fs::read_to_string(self.entry_path(key)).ok()
+    }
+
+    fn put(&self, key: &str, value: String) {
+        // 先写临时文件再改名，并发的读取不会看到写了一半的条目
+        let tmp = self.dir.join(format!("{}.{}.tmp", key, std::process::id()));
+        if fs::write(&tmp, value).is_ok() && fs::rename(&tmp, self.entry_path(key)).is_err() {
+            let _ = fs::remove_file(&tmp);
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use std::collections::HashMap;
+    use std::fs;
+    use std::path::{Path, PathBuf};
+    use std::sync::atomic::{AtomicUsize, Ordering};
+    use std::sync::Arc;
+
+    use crate::codegraph::symbol_graph::builder::ParseContext;
+    use crate::codegraph::symbol_graph::cache::{cache_key, versioned_key, Cache, DiskCache, MemoryCache, PARSER_VERSION};
+    use crate::codegraph::symbol_graph::dir::{parse_dir, ParseOptions};
+    use crate::codegraph::symbol_graph::normalize::CaseInsensitiveNormalizer;
+    use crate::codegraph::symbol_graph::packages::GoModule;
+    use crate::codegraph::treesitter::language_id::LanguageId;
+
+    const MAIN_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/main.go");
+    const CALLS_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/calls.go");
+
+    /// 记录命中和写入次数的缓存
+    #[derive(Debug)]
+    struct CountingCache<C: Cache> {
+        inner: C,
+        hits: AtomicUsize,
+        puts: AtomicUsize,
+    }
+
+    impl<C: Cache> CountingCache<C> {
+        fn new(inner: C) -> Self {
+            Self { inner, hits: AtomicUsize::new(0), puts: AtomicUsize::new(0) }
+        }
+
+        /// 返回并清零 (命中次数, 写入次数)
+        fn take(&self) -> (usize, usize) {
+            (self.hits.swap(0, Ordering::SeqCst), self.puts.swap(0, Ordering::SeqCst))
+        }
+    }
+
+    impl<C: Cache> Cache for CountingCache<C> {
+        fn get(&self, key: &str) -> Option<String> {
+            let value = self.inner.get(key);
+            if value.is_some() {
+                self.hits.fetch_add(1, Ordering::SeqCst);
+            }
+            value
+        }
+
+        fn put(&self, key: &str, value: String) {
+            self.puts.fetch_add(1, Ordering::SeqCst);
+            self.inner.put(key, value);
+        }
+    }
+
+    fn write_sources(dir: &Path) {
+        fs::write(dir.join("main.go"), MAIN_GO_CODE).unwrap();
+        fs::write(dir.join("calls.go"), CALLS_GO_CODE).unwrap();
+    }
+
+    fn parse_with(dir: &Path, cache: Arc<dyn Cache>) -> String {
+        let options = ParseOptions { cache: Some(cache), ..Default::default() };
+        let (graph, errors) = parse_dir(dir, &options).unwrap();
+        assert!(errors.is_empty(), "{:?}", errors);
+        graph.to_json().unwrap()
+    }
+
+    #[test]
+    fn memory_cache_hit_test() {
+        let dir = tempfile::tempdir().unwrap();
+        write_sources(dir.path());
+        let cache = Arc::new(CountingCache::new(MemoryCache::new()));
+        let uncached = parse_dir(dir.path(), &ParseOptions::default()).unwrap().0.to_json().unwrap();
+
+        assert_eq!(parse_with(dir.path(), cache.clone()), uncached);
+        assert_eq!(cache.take(), (0, 2));
+        assert_eq!(parse_with(dir.path(), cache.clone()), uncached);
+        assert_eq!(cache.take(), (2, 0));
+
+        // 只重新解析内容变化的文件
+        fs::write(dir.path().join("calls.go"), format!("{}\nfunc extra() {{}}\n", CALLS_GO_CODE)).unwrap();
+        let changed = parse_with(dir.path(), cache.clone());
+        assert_eq!(cache.take(), (1, 1));
+        assert!(changed.contains("\"extra\""));
+        assert_eq!(cache.inner.len(), 3);
+    }
+
+    #[test]
+    fn disk_cache_test() {
+        let dir = tempfile::tempdir().unwrap();
+        let cache_dir = tempfile::tempdir().unwrap();
+        write_sources(dir.path());
+
+        let first = Arc::new(CountingCache::new(DiskCache::new(cache_dir.path()).unwrap()));
+        let expected = parse_with(dir.path(), first.clone());
+        assert_eq!(first.take(), (0, 2));
+        assert_eq!(fs::read_dir(cache_dir.path()).unwrap().count(), 2);
+
+        // 新的缓存实例（例如进程重启后）读取同一个目录
+        let second = Arc::new(CountingCache::new(DiskCache::new(cache_dir.path()).unwrap()));
+        assert_eq!(parse_with(dir.path(), second.clone()), expected);
+        assert_eq!(second.take(), (2, 0));
+    }
+
+    #[test]
+    fn memory_cache_capacity_test() {
+        let cache = MemoryCache::with_capacity(2);
+        cache.put("a", "1".to_string());
+        cache.put("b", "2".to_string());
+        // 读取 a 后 b 成为最久未使用的条目
+        assert_eq!(cache.get("a").as_deref(), Some("1"));
+        cache.put("c", "3".to_string());
+        assert_eq!(cache.len(), 2);
+        assert_eq!(cache.get("b"), None);
+        assert_eq!(cache.get("a").as_deref(), Some("1"));
+        assert_eq!(cache.get("c").as_deref(), Some("3"));
+        // 覆盖已有的键不会淘汰其他条目
+        cache.put("c", "4".to_string());
+        assert_eq!((cache.len(), cache.get("a").as_deref()), (2, Some("1")));
+    }
+
+    #[test]
+    fn stale_entries_test() {
+        let path = PathBuf::from("/main.go");
+        let none = ParseContext::default();
+        let module = ParseContext {
+            go_module: Some(GoModule { root: PathBuf::from("/"), path: "example.com/shapes".to_string() }),
+            ..Default::default()
+        };
+        let normalizer = ParseContext { normalizer: Some(Arc::new(CaseInsensitiveNormalizer { languages: vec![LanguageId::Go] })), ..Default::default() };
+        // 解析器版本、路径、上下文或内容变化时键都不同
+        assert_ne!(versioned_key(PARSER_VERSION + 1, &path, MAIN_GO_CODE, &none), cache_key(&path, MAIN_GO_CODE, &none));
+        assert_ne!(cache_key(&PathBuf::from("/other.go"), MAIN_GO_CODE, &none), cache_key(&path, MAIN_GO_CODE, &none));
+        assert_ne!(cache_key(&path, MAIN_GO_CODE, &module), cache_key(&path, MAIN_GO_CODE, &none));
+        assert_ne!(cache_key(&path, MAIN_GO_CODE, &normalizer), cache_key(&path, MAIN_GO_CODE, &none));
+        assert_eq!(cache_key(&path, MAIN_GO_CODE, &none), cache_key(&path, MAIN_GO_CODE, &none));
+
+        // 同一个键下旧格式的条目不会被使用，而是重新解析并覆盖
+        let dir = tempfile::tempdir().unwrap();
+        write_sources(dir.path());
+        let cache = Arc::new(CountingCache::new(MemoryCache::new()));
+        let expected = parse_with(dir.path(), cache.clone());
+        let main = dir.path().join("main.go");
+        let key = cache_key(&main, MAIN_GO_CODE, &ParseContext::for_file(&main, &HashMap::new()));
+        cache.inner.put(&key, "{\"schema_version\": 0, \"nodes\": [], \"edges\": []}".to_string());
+        cache.take();
+        assert_eq!(parse_with(dir.path(), cache.clone()), expected);
+        assert_eq!(cache.take(), (2, 1));
+        assert!(cache.inner.get(&key).unwrap().contains("NewPoint"));
+    }
+
+    #[test]
+    fn go_mod_change_test() {
+        let dir = tempfile::tempdir().unwrap();
+        write_sources(dir.path());
+        fs::write(dir.path().join("go.mod"), "module example.com/shapes\n").unwrap();
+        let cache = Arc::new(CountingCache::new(MemoryCache::new()));
+        parse_with(dir.path(), cache.clone());
+        cache.take();
+
+        // 源码没有变化，但模块路径变了，缓存的导入路径不能再使用
+        fs::write(dir.path().join("go.mod"), "module example.com/geometry\n").unwrap();
+        let graph = parse_with(dir.path(), cache.clone());
+        assert_eq!(cache.take(), (0, 2));
+        assert!(graph.contains("example.com/geometry"));
+        assert!(!graph.contains("example.com/shapes"));
+    }
+}
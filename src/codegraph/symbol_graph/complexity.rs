@@ -0,0 +1,107 @@
+use std::collections::HashMap;
+use std::path::PathBuf;
+
+use petgraph::graph::NodeIndex;
+use serde_json::json;
+use tree_sitter::Node;
+
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::symbol_graph::types::SymbolKind;
+use crate::codegraph::treesitter::parsers::{complexity_rule, ComplexityRule};
+
+/// 为文件中的函数和方法记录 `complexity` 属性（圈复杂度），规则见 `ComplexityRule`，
+/// 由节点语言的解析器提供。`root` 可以是整棵语法树，也可以是单个顶层声明
+pub fn record_complexity(graph: &mut SymbolGraph, root: &Node, code: &str, file_path: &PathBuf) {
+    let functions = graph.graph.node_indices()
+        .filter(|index| {
+            let node = &graph.graph[*index];
+            &node.file_path == file_path && matches!(node.kind, SymbolKind::Function | SymbolKind::Method)
+        })
+        .filter_map(|index| {
+            let node = &graph.graph[index];
+            complexity_rule(node.language).map(|rule| (node.span.start_byte, (index, rule)))
+        })
+        .collect::<HashMap<usize, (NodeIndex, &ComplexityRule)>>();
+    if functions.is_empty() {
+        return;
+    }
+
+    let mut scores = vec![];
+    let mut stack = vec![*root];
+    while let Some(node) = stack.pop() {
+        if let Some((index, rule)) = functions.get(&node.start_byte()) {
+            if rule.functions.contains(&node.kind()) {
+                scores.push((*index, complexity(&node, rule, code)));
+            }
+        }
+        for i in 0..node.child_count() {
+            stack.push(node.child(i).unwrap());
+        }
+    }
+    for (index, score) in scores {
+        graph.graph[index].attributes.insert("complexity".to_string(), json!(score));
+    }
+}
+
+fn complexity(function: &Node, rule: &ComplexityRule, code: &str) -> usize {
+    let mut score = 1;
+    let mut stack = (0..function.child_count()).filter_map(|i| function.child(i)).collect::<Vec<_>>();
+    while let Some(node) = stack.pop() {
+        let kind = node.kind();
+        if rule.functions.contains(&kind) {
+            continue;
+        }
+        if rule.decisions.contains(&kind) {
+            score += 1;
+        } else if kind == rule.binary_expression {
+            let operator = node.child_by_field_name("operator").map(|op| &code[op.byte_range()]);
+            if operator.map_or(false, |op| rule.boolean_operators.contains(&op)) {
+                score += 1;
+            }
+        }
+        for i in 0..node.child_count() {
+            stack.push(node.child(i).unwrap());
+        }
+    }
+    score
+}
+
+#[cfg(test)]
+mod tests {
+    use std::path::PathBuf;
+
+    use crate::codegraph::symbol_graph::builder::parse_code;
+    use crate::codegraph::symbol_graph::graph::SymbolGraph;
+
+    const MAIN_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/main.go");
+    const COMPLEXITY_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/complexity.go");
+
+    fn complexity_of(graph: &SymbolGraph, qualified_name: &str) -> Option<u64> {
+        let nodes = graph.find_nodes_by_qualified_name(qualified_name);
+        assert_eq!(nodes.len(), 1, "{}", qualified_name);
+        nodes[0].attributes.get("complexity").and_then(|v| v.as_u64())
+    }
+
+    #[test]
+    fn simple_functions_test() {
+        let graph = parse_code(MAIN_GO_CODE, &PathBuf::from("/main.go")).unwrap();
+        for name in ["NewPoint", "(*Point).Move", "main"] {
+            assert_eq!(complexity_of(&graph, name), Some(1), "{}", name);
+        }
+        // 只有函数和方法有复杂度
+        assert_eq!(complexity_of(&graph, "Point"), None);
+    }
+
+    #[test]
+    fn decision_points_test() {
+        let graph = parse_code(COMPLEXITY_GO_CODE, &PathBuf::from("/complexity.go")).unwrap();
+        // for、两个 if、&&、||、两个非 default 的 case
+        assert_eq!(complexity_of(&graph, "classify"), Some(8));
+        // 类型 switch 的两个 case，一个 case 中的多个类型只算一次
+        assert_eq!(complexity_of(&graph, "describe"), Some(3));
+        // 函数字面量中的 if 计入外层函数
+        assert_eq!(complexity_of(&graph, "retry"), Some(4));
+        // select 的 case 和 for
+        assert_eq!(complexity_of(&graph, "(*worker).drain"), Some(4));
+    }
+}
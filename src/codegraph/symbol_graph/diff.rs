@@ -0,0 +1,222 @@
+use std::collections::{HashMap, HashSet};
+use std::path::PathBuf;
+
+use serde_json::json;
+use uuid::Uuid;
+
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::symbol_graph::types::{SymbolKind, SymbolNode};
+
+/// 修改或重命名前后的同一个符号
+#[derive(Debug, Clone, PartialEq)]
+pub struct SymbolChange<'a> {
+    pub old: &'a SymbolNode,
+    pub new: &'a SymbolNode,
+    pub signature_changed: bool,
+    pub body_changed: bool,
+}
+
+/// 两个符号图之间符号级别的变化
+#[derive(Debug, Clone, PartialEq, Default)]
+pub struct GraphDiff<'a> {
+    /// 按新图中的顺序
+    pub added: Vec<&'a SymbolNode>,
+    /// 按旧图中的顺序
+    pub removed: Vec<&'a SymbolNode>,
+    /// 签名或函数体变化的符号，按新图中的顺序
+    pub modified: Vec<SymbolChange<'a>>,
+    /// 函数体相同、名称不同的符号，按新图中的顺序
+    pub renamed: Vec<SymbolChange<'a>>,
+}
+
+impl<'a> GraphDiff<'a> {
+    pub fn is_empty(&self) -> bool {
+        self.added.is_empty() && self.removed.is_empty() && self.modified.is_empty() && self.renamed.is_empty()
+    }
+}
+
+/// 为文件中声明记录 `body_hash` 属性：声明头部之后到声明结束的源码的 md5，
+/// 没有函数体（声明头部就是整个声明）的节点不记录
+pub fn record_body_hashes(graph: &mut SymbolGraph, code: &str, file_path: &PathBuf) {
+    for node in graph.graph.node_weights_mut() {
+        if &node.file_path != file_path || !is_declaration(node) {
+            continue;
+        }
+        let body = code.get(node.declaration_span.end_byte..node.span.end_byte).unwrap_or_default().trim();
+        if body.is_empty() {
+            node.attributes.remove("body_hash");
+        } else {
+            node.attributes.insert("body_hash".to_string(), json!(format!("{:x}", md5::compute(body))));
+        }
+    }
+}
+
+fn is_declaration(node: &SymbolNode) -> bool {
+    !matches!(node.kind, SymbolKind::Package | SymbolKind::File | SymbolKind::Unresolved | SymbolKind::Builtin | SymbolKind::TypeParameter)
+}
+
+fn body_hash(node: &SymbolNode) -> Option<&str> {
+    node.attributes.get("body_hash").and_then(|h| h.as_str())
+}
+
+fn signature(node: &SymbolNode) -> Option<&str> {
+    node.attributes.get("signature").and_then(|s| s.as_str())
+}
+
+/// 节点ID包含签名，签名变化后按 (文件, 类型, 限定名, 同名序号) 对应
+fn declaration_key(node: &SymbolNode) -> (PathBuf, SymbolKind, String, u64) {
+    let index = node.attributes.get("index").and_then(|i| i.as_u64()).unwrap_or(0);
+    (node.file_path.clone(), node.kind, node.qualified_name.clone(), index)
+}
+
+/// 函数体是否变化。有 `body_hash` 时比较内容，否则比较声明的长度，
+/// 只移动位置不算变化
+fn body_changed(old: &SymbolNode, new: &SymbolNode) -> bool {
+    match (body_hash(old), body_hash(new)) {
+        (None, None) => old.span.len() != new.span.len(),
+        (old_hash, new_hash) => old_hash != new_hash,
+    }
+}
+
+/// 比较两个符号图中的声明（不含文件、占位和类型参数节点）：
+///
+/// 1. 稳定ID相同的符号是同一个符号，函数体变化时为修改；ID不含位置，在文件内移动不算变化；
+/// 2. ID不同但文件、类型、限定名相同的符号是签名变化的修改；
+/// 3. 剩余的删除和新增符号中，同一文件、同一类型且 `body_hash` 相同的按顺序一一配对为重命名。
+///    函数体很短时（例如都只有 `return 0`）可能误判，需要 `record_body_hashes` 记录过函数体；
+/// 4. 其余为新增和删除。
+pub fn diff_graphs<'a>(old: &'a SymbolGraph, new: &'a SymbolGraph) -> GraphDiff<'a> {
+    let mut diff = GraphDiff::default();
+    let old_nodes = old.nodes().filter(|n| is_declaration(n)).collect::<Vec<_>>();
+    let new_nodes = new.nodes().filter(|n| is_declaration(n)).collect::<Vec<_>>();
+    let old_by_id = old_nodes.iter().map(|n| (n.id, *n)).collect::<HashMap<Uuid, &SymbolNode>>();
+    let new_ids = new_nodes.iter().map(|n| n.id).collect::<HashSet<_>>();
+
+    let mut removed_by_key: HashMap<(PathBuf, SymbolKind, String, u64), &SymbolNode> = HashMap::new();
+    for node in old_nodes.iter().filter(|n| !new_ids.contains(&n.id)) {
+        removed_by_key.entry(declaration_key(node)).or_insert(*node);
+    }
+
+    let mut matched_old: HashSet<Uuid> = HashSet::new();
+    let mut unmatched_new = vec![];
+    for &node in &new_nodes {
+        if let Some(&old_node) = old_by_id.get(&node.id) {
+            if body_changed(old_node, node) {
+                diff.modified.push(SymbolChange { old: old_node, new: node, signature_changed: false, body_changed: true });
+            }
+            continue;
+        }
+        match removed_by_key.remove(&declaration_key(node)) {
+            Some(old_node) => {
+                matched_old.insert(old_node.id);
+                diff.modified.push(SymbolChange {
+                    old: old_node,
+                    new: node,
+                    signature_changed: signature(old_node) != signature(node),
+                    body_changed: body_changed(old_node, node),
+                });
+            }
+            None => unmatched_new.push(node),
+        }
+    }
+
+    let mut unmatched_old = old_nodes.iter()
+        .filter(|n| !new_ids.contains(&n.id) && !matched_old.contains(&n.id))
+        .copied()
+        .collect::<Vec<_>>();
+    for node in unmatched_new {
+        let renamed_from = body_hash(node).and_then(|hash| unmatched_old.iter().position(|old_node| {
+            old_node.file_path == node.file_path && old_node.kind == node.kind && body_hash(old_node) == Some(hash)
+        }));
+        match renamed_from {
+            Some(position) => {
+                let old_node = unmatched_old.remove(position);
+                diff.renamed.push(SymbolChange {
+                    old: old_node,
+                    new: node,
+                    signature_changed: signature(old_node) != signature(node),
+                    body_changed: false,
+                });
+            }
+            None => diff.added.push(node),
+        }
+    }
+    diff.removed = unmatched_old;
+    diff
+}
+
+#[cfg(test)]
+mod tests {
+    use std::path::PathBuf;
+
+    use crate::codegraph::symbol_graph::builder::parse_code;
+    use crate::codegraph::symbol_graph::diff::{diff_graphs, GraphDiff};
+    use crate::codegraph::symbol_graph::graph::SymbolGraph;
+
+    const BASE: &str = "package main
+
+func area(w int, h int) int {
+	return w * h
+}
+
+func perimeter(w int, h int) int {
+	return 2 * (w + h)
+}
+";
+
+    fn graph(code: &str) -> SymbolGraph {
+        parse_code(code, &PathBuf::from("/shapes.go")).unwrap()
+    }
+
+    fn names(diff: &GraphDiff) -> (Vec<String>, Vec<String>, Vec<String>, Vec<(String, String)>) {
+        (
+            diff.added.iter().map(|n| n.qualified_name.clone()).collect(),
+            diff.removed.iter().map(|n| n.qualified_name.clone()).collect(),
+            diff.modified.iter().map(|c| c.new.qualified_name.clone()).collect(),
+            diff.renamed.iter().map(|c| (c.old.qualified_name.clone(), c.new.qualified_name.clone())).collect(),
+        )
+    }
+
+    #[test]
+    fn add_and_remove_test() {
+        let old = graph(BASE);
+        let new = graph(&BASE.replace("func perimeter(w int, h int) int {\n\treturn 2 * (w + h)\n}\n", "func volume(w int, h int, d int) int {\n\treturn w * h * d\n}\n"));
+        let diff = diff_graphs(&old, &new);
+        assert_eq!(names(&diff), (vec!["volume".to_string()], vec!["perimeter".to_string()], vec![], vec![]));
+        assert!(diff_graphs(&old, &graph(BASE)).is_empty());
+    }
+
+    #[test]
+    fn moved_function_test() {
+        let old = graph(BASE);
+        // 交换两个函数的位置并插入空行
+        let moved = "package main\n\n\nfunc perimeter(w int, h int) int {\n\treturn 2 * (w + h)\n}\n\nfunc area(w int, h int) int {\n\treturn w * h\n}\n";
+        assert!(diff_graphs(&old, &graph(moved)).is_empty());
+    }
+
+    #[test]
+    fn signature_change_test() {
+        let old = graph(BASE);
+        let new = graph(&BASE.replace("func area(w int, h int) int {", "func area(w int, h int) float64 {"));
+        let diff = diff_graphs(&old, &new);
+        assert_eq!(names(&diff), (vec![], vec![], vec!["area".to_string()], vec![]));
+        assert!(diff.modified[0].signature_changed);
+        assert!(!diff.modified[0].body_changed);
+        assert_ne!(diff.modified[0].old.id, diff.modified[0].new.id);
+
+        let body_only = graph(&BASE.replace("return w * h\n", "return h * w\n"));
+        let diff = diff_graphs(&old, &body_only);
+        assert_eq!(names(&diff).2, vec!["area"]);
+        assert!(!diff.modified[0].signature_changed);
+        assert!(diff.modified[0].body_changed);
+    }
+
+    #[test]
+    fn rename_test() {
+        let old = graph(BASE);
+        let new = graph(&BASE.replace("func area(", "func surface("));
+        let diff = diff_graphs(&old, &new);
+        assert_eq!(names(&diff), (vec![], vec![], vec![], vec![("area".to_string(), "surface".to_string())]));
+        assert!(!diff.renamed[0].signature_changed);
+    }
+}
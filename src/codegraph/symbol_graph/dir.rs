@@ -0,0 +1,831 @@
+use std::collections::HashMap;
+use std::fs;
+use std::panic::{catch_unwind, AssertUnwindSafe};
+use std::path::{Path, PathBuf};
+use std::sync::atomic::{AtomicBool, AtomicUsize, Ordering};
+use std::sync::mpsc::{self, RecvTimeoutError};
+use std::sync::{Arc, Mutex};
+use std::thread;
+use std::time::{Duration, Instant};
+
+use glob::{MatchOptions, Pattern};
+
+use crate::codegraph::symbol_graph::build_constraints::{go_build_constraints, BuildTarget};
+use crate::codegraph::symbol_graph::builder::{parse_source_until, read_source, ParseContext};
+use crate::codegraph::symbol_graph::cache::{self, cache_key, Cache};
+use crate::codegraph::symbol_graph::generated::{GeneratedCodeMatcher, GeneratedFiles};
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::symbol_graph::normalize::{DefaultNormalizer, IdentifierNormalizer};
+use crate::codegraph::symbol_graph::package_scope::resolve_package_references;
+use crate::codegraph::symbol_graph::packages::GoModuleResolver;
+use crate::codegraph::symbol_graph::promotion::link_promotions;
+use crate::codegraph::symbol_graph::satisfaction::link_interface_satisfaction;
+use crate::codegraph::treesitter::parsers::registry::language_for;
+use crate::codegraph::treesitter::parsers::{get_language_id_by_filename, ParserError};
+
+/// 目录解析选项
+#[derive(Debug, Clone)]
+pub struct ParseOptions {
+    /// 并行解析的线程数，0 表示使用可用的CPU数
+    pub workers: usize,
+    /// 计算 Go 类型满足哪些接口（Satisfies 边），需要比较包内所有类型和接口的方法集
+    pub compute_interface_satisfaction: bool,
+    /// Go 构建目标，设置后跳过文件名后缀或 `//go:build` 约束不满足的 Go 文件；
+    /// None 时解析所有文件，带约束文件中的节点记录 `build_constraints` 属性
+    pub build_target: Option<BuildTarget>,
+    /// 单文件解析结果的缓存，内容没有变化的文件直接使用缓存的符号图
+    pub cache: Option<Arc<dyn Cache>>,
+    /// 文件头标记为生成代码（`// Code generated ... DO NOT EDIT.`）的文件的处理方式
+    pub generated_files: GeneratedFiles,
+    /// 识别生成文件的额外正则表达式，与文件开头注释中的每一行匹配
+    pub generated_patterns: Vec<String>,
+    /// 路径 -> 文件内容，优先于磁盘上的文件（例如编辑器中未保存的缓冲区）。
+    /// 根目录下的路径即使磁盘上不存在也会被解析；路径需要与根目录的写法一致（都是绝对路径或都是相对路径）
+    pub overlay: HashMap<PathBuf, String>,
+    /// 目录递归的最大深度，0 表示只解析根目录中的文件，None 表示不限制
+    pub max_depth: Option<usize>,
+    /// 跳过的路径，glob 表达式相对于根目录（`vendor/**`、`**/node_modules/**`、`**/*_test.go`），
+    /// `*` 不匹配 `/`。匹配目录的表达式（包括去掉结尾 `/**` 后匹配的）使整个目录不被遍历
+    pub exclude_globs: Vec<String>,
+    /// 单个文件的解析时限，超时的文件记为该文件的错误，其余文件照常解析。
+    /// 内置解析器超时后中止解析；注册的自定义解析器不能被中断，在后台线程中运行到结束，结果被丢弃
+    pub timeout: Option<Duration>,
+    /// 取消标志，置为 true 后不再开始解析新的文件，`parse_dir` 和 `parse_dir_each` 返回错误。
+    /// 设置了 `timeout` 时正在等待的文件也立即放弃
+    pub cancel: Option<Arc<AtomicBool>>,
+    /// 标识符规范化规则，用于占位节点的合并和包内引用解析，None 时使用 `DefaultNormalizer`。
+    /// 规则的名称是缓存键的一部分
+    pub normalizer: Option<Arc<dyn IdentifierNormalizer>>,
+}
+
+impl Default for ParseOptions {
+    fn default() -> Self {
+        Self {
+            workers: 0,
+            compute_interface_satisfaction: false,
+            build_target: None,
+            cache: None,
+            generated_files: GeneratedFiles::Parse,
+            generated_patterns: vec![],
+            overlay: HashMap::new(),
+            max_depth: None,
+            exclude_globs: vec![],
+            timeout: None,
+            cancel: None,
+            normalizer: None,
+        }
+    }
+}
+
+impl ParseOptions {
+    fn worker_count(&self, files: usize) -> usize {
+        let workers = if self.workers == 0 {
+            thread::available_parallelism().map(|n| n.get()).unwrap_or(1)
+        } else {
+            self.workers
+        };
+        workers.min(files).max(1)
+    }
+
+    fn is_cancelled(&self) -> bool {
+        self.cancel.as_ref().map_or(false, |cancel| cancel.load(Ordering::Relaxed))
+    }
+}
+
+/// 等待超时的解析时检查取消标志的间隔
+const CANCEL_POLL_INTERVAL: Duration = Duration::from_millis(20);
+
+fn cancelled_error() -> ParserError {
+    ParserError { message: "Parsing cancelled".to_string() }
+}
+
+/// 编译后的排除表达式：(完整表达式, 去掉结尾 `/**` 后匹配目录的表达式)
+pub(crate) struct ExcludeGlobs(Vec<(Pattern, Option<Pattern>)>);
+
+impl ExcludeGlobs {
+    const MATCH_OPTIONS: MatchOptions = MatchOptions {
+        case_sensitive: true,
+        require_literal_separator: true,
+        require_literal_leading_dot: false,
+    };
+
+    pub(crate) fn new(globs: &[String]) -> Result<Self, ParserError> {
+        let compile = |glob: &str| Pattern::new(glob).map_err(|e| ParserError {
+            message: format!("Invalid exclude glob {}: {}", glob, e)
+        });
+        let mut patterns = vec![];
+        for glob in globs {
+            let dir = glob.strip_suffix("/**").map(compile).transpose()?;
+            patterns.push((compile(glob)?, dir));
+        }
+        Ok(Self(patterns))
+    }
+
+    fn excludes_file(&self, relative: &Path) -> bool {
+        self.0.iter().any(|(pattern, _)| pattern.matches_path_with(relative, Self::MATCH_OPTIONS))
+    }
+
+    fn excludes_dir(&self, relative: &Path) -> bool {
+        self.0.iter().any(|(pattern, dir)| {
+            pattern.matches_path_with(relative, Self::MATCH_OPTIONS)
+                || dir.as_ref().map_or(false, |dir| dir.matches_path_with(relative, Self::MATCH_OPTIONS))
+        })
+    }
+}
+
+/// 单个文件的解析错误
+#[derive(Debug, PartialEq, Eq)]
+pub struct FileError {
+    pub path: PathBuf,
+    pub error: ParserError,
+}
+
+/// 并行解析目录下所有支持的文件并合并为一个符号图。
+/// 文件按路径排序后依次合并，结果与线程数和调度顺序无关；
+/// 单个文件失败或子目录无法读取不会中断整体解析，错误按路径顺序返回；根目录无法读取时返回错误。
+/// 设置了缓存时按文件路径和内容查找，只解析变化过的文件。
+/// 生成的文件按选项跳过或标记；额外的生成代码表达式无效时返回错误。
+/// 超过深度限制和匹配排除表达式的路径不解析，超时的文件记为错误；取消时返回错误。
+/// 合并后在包内解析跨文件的调用和约束，再连接一次跨文件的提升方法，并按选项计算接口满足关系
+pub fn parse_dir(root: &Path, options: &ParseOptions) -> Result<(SymbolGraph, Vec<FileError>), ParserError> {
+    let (files, mut errors) = collect_files(root, options)?;
+    let (graph, parse_errors) = parse_and_merge(files, options)?;
+    errors.extend(parse_errors);
+    errors.sort_by(|a, b| a.path.cmp(&b.path));
+    Ok((graph, errors))
+}
+
+/// 解析已经收集好的文件（按路径排序）并合并，`parse_dir` 和 `parse_archive` 共用
+pub(crate) fn parse_and_merge(files: Vec<PathBuf>, options: &ParseOptions) -> Result<(SymbolGraph, Vec<FileError>), ParserError> {
+    let matcher = generated_matcher(options)?;
+    let results = files.iter().map(|_| Mutex::new(None)).collect::<Vec<_>>();
+    parse_files(&files, options, matcher.as_ref(), &|idx, result| {
+        *results[idx].lock().unwrap() = Some(result);
+    });
+    if options.is_cancelled() {
+        return Err(cancelled_error());
+    }
+    let results = results.into_iter().map(|result| result.into_inner().unwrap().unwrap());
+
+    let mut graph = SymbolGraph::new();
+    let mut errors = vec![];
+    for (path, result) in files.into_iter().zip(results) {
+        match result {
+            Ok(Some(file_graph)) => graph.merge(&file_graph),
+            Ok(None) => {}
+            Err(error) => errors.push(FileError { path, error }),
+        }
+    }
+    resolve_package_references(&mut graph, options.normalizer.as_deref().unwrap_or(&DefaultNormalizer));
+    // 嵌入的类型可能声明在同一个包的其他文件中
+    link_promotions(&mut graph);
+    if options.compute_interface_satisfaction {
+        link_interface_satisfaction(&mut graph);
+    }
+    Ok((graph, errors))
+}
+
+/// 与 `parse_dir` 一样选择和解析文件，但不合并：每个文件解析完成后立即把它自己的符号图
+/// （或错误）交给 `on_file`，调用顺序取决于线程调度。单文件的图没有跨文件的链接
+/// （包内的调用解析、提升方法、接口满足关系）；被构建约束排除和跳过的生成文件不会回调。
+/// 无法读取的子目录在解析开始前以目录路径和错误回调。取消后不再回调，返回错误
+pub fn parse_dir_each<F>(root: &Path, options: &ParseOptions, on_file: F) -> Result<(), ParserError>
+where
+    F: Fn(&PathBuf, Result<SymbolGraph, ParserError>) + Sync,
+{
+    let matcher = generated_matcher(options)?;
+    let (files, errors) = collect_files(root, options)?;
+    for FileError { path, error } in errors {
+        on_file(&path, Err(error));
+    }
+    parse_files(&files, options, matcher.as_ref(), &|idx, result| {
+        if options.is_cancelled() {
+            return;
+        }
+        if let Some(result) = result.transpose() {
+            on_file(&files[idx], result);
+        }
+    });
+    match options.is_cancelled() {
+        true => Err(cancelled_error()),
+        false => Ok(()),
+    }
+}
+
+fn generated_matcher(options: &ParseOptions) -> Result<Option<GeneratedCodeMatcher>, ParserError> {
+    match options.generated_files {
+        GeneratedFiles::Parse => Ok(None),
+        GeneratedFiles::Tag | GeneratedFiles::Skip => GeneratedCodeMatcher::new(&options.generated_patterns).map(Some),
+    }
+}
+
+/// 递归收集有解析器（包括注册的解析器）的源文件，跳过隐藏文件和目录、超过深度限制的目录
+/// 和匹配排除表达式的路径，结果按路径排序。覆盖层中根目录下的文件同样按这些规则加入。
+/// 指向目录的符号链接不遍历（链接成环时会无限递归）；无法读取的子目录记为该目录的错误，
+/// 根目录无法读取时返回错误
+fn collect_files(root: &Path, options: &ParseOptions) -> Result<(Vec<PathBuf>, Vec<FileError>), ParserError> {
+    let excludes = ExcludeGlobs::new(&options.exclude_globs)?;
+    let relative = |path: &Path| path.strip_prefix(root).unwrap_or(path).to_path_buf();
+    let mut files = vec![];
+    let mut errors = vec![];
+    let mut dirs = vec![(root.to_path_buf(), 0)];
+    while let Some((dir, depth)) = dirs.pop() {
+        let entries = match fs::read_dir(&dir) {
+            Ok(entries) => entries,
+            Err(e) => {
+                let error = ParserError { message: format!("Failed to read directory {}: {}", dir.display(), e) };
+                if dir == root {
+                    return Err(error);
+                }
+                errors.push(FileError { path: dir, error });
+                continue;
+            }
+        };
+        for entry in entries.flatten() {
+            let path = entry.path();
+            if entry.file_name().to_string_lossy().starts_with('.') {
+                continue;
+            }
+            let Ok(file_type) = entry.file_type() else { continue };
+            if file_type.is_symlink() && path.is_dir() {
+                continue;
+            }
+            if file_type.is_dir() {
+                if options.max_depth.map_or(true, |max| depth < max) && !excludes.excludes_dir(&relative(&path)) {
+                    dirs.push((path, depth + 1));
+                }
+            } else if language_for(&path).is_some() && !excludes.excludes_file(&relative(&path)) {
+                files.push(path);
+            }
+        }
+    }
+    files.extend(select_files(root, options.overlay.keys(), options, &excludes));
+    files.sort();
+    files.dedup();
+    errors.sort_by(|a, b| a.path.cmp(&b.path));
+    Ok((files, errors))
+}
+
+/// 不在磁盘上的路径（覆盖层、归档条目）中根目录下按 `collect_files` 的规则应当解析的文件，未排序
+pub(crate) fn select_files<'a>(
+    root: &Path,
+    paths: impl Iterator<Item = &'a PathBuf>,
+    options: &ParseOptions,
+    excludes: &ExcludeGlobs,
+) -> Vec<PathBuf> {
+    let mut files = vec![];
+    for path in paths {
+        // 根目录以外的文件和隐藏路径不加入
+        let skipped = path.strip_prefix(root).map_or(true, |relative| {
+            let depth = relative.components().count().saturating_sub(1);
+            relative.components().any(|c| c.as_os_str().to_string_lossy().starts_with('.'))
+                || options.max_depth.map_or(false, |max| depth > max)
+                || relative.ancestors().skip(1).any(|dir| !dir.as_os_str().is_empty() && excludes.excludes_dir(dir))
+                || excludes.excludes_file(relative)
+        });
+        if !skipped && language_for(path).is_some() {
+            files.push(path.clone());
+        }
+    }
+    files
+}
+
+/// 多个线程从共享的下标中领取文件，每个文件完成后在解析它的线程上以文件下标调用 `on_done`，
+/// 被构建约束排除或跳过的生成文件结果为 None。
+/// 开始前按目录查找一次各文件的上下文（`go.mod` 先查覆盖层再查磁盘），解析单个文件时不再读取
+fn parse_files(
+    files: &[PathBuf],
+    options: &ParseOptions,
+    matcher: Option<&GeneratedCodeMatcher>,
+    on_done: &(dyn Fn(usize, Result<Option<SymbolGraph>, ParserError>) + Sync),
+) {
+    let mut modules = GoModuleResolver::new(&options.overlay);
+    let contexts = files.iter()
+        .map(|path| ParseContext { normalizer: options.normalizer.clone(), ..ParseContext::resolve(path, &mut modules) })
+        .collect::<Vec<_>>();
+    let next = AtomicUsize::new(0);
+    thread::scope(|scope| {
+        for _ in 0..options.worker_count(files.len()) {
+            scope.spawn(|| loop {
+                let idx = next.fetch_add(1, Ordering::Relaxed);
+                if idx >= files.len() || options.is_cancelled() {
+                    break;
+                }
+                on_done(idx, parse_file_guarded(&files[idx], &contexts[idx], options, matcher));
+            });
+        }
+    });
+}
+
+/// 解析器在异常输入上 panic 时转换为该文件的错误
+fn parse_file_guarded(
+    path: &PathBuf,
+    context: &ParseContext,
+    options: &ParseOptions,
+    matcher: Option<&GeneratedCodeMatcher>,
+) -> Result<Option<SymbolGraph>, ParserError> {
+    catch_unwind(AssertUnwindSafe(|| parse_matched_file(path, context, options, matcher))).unwrap_or_else(|_| Err(ParserError {
+        message: format!("Parser panicked on {}", path.display())
+    }))
+}
+
+/// 被构建约束排除的文件和跳过的生成文件不解析；生成文件的标记不写入缓存，
+/// 同一份缓存可以用于不同的选项
+fn parse_matched_file(
+    path: &PathBuf,
+    context: &ParseContext,
+    options: &ParseOptions,
+    matcher: Option<&GeneratedCodeMatcher>,
+) -> Result<Option<SymbolGraph>, ParserError> {
+    let code = read_source(path, &options.overlay)?;
+    let is_go = path.extension().map_or(false, |e| e == "go");
+    if is_go && options.build_target.as_ref().map_or(false, |target| !target.matches(&go_build_constraints(path, &code))) {
+        return Ok(None);
+    }
+    let generated = matcher.map_or(false, |matcher| matcher.is_generated(&code));
+    if generated && options.generated_files == GeneratedFiles::Skip {
+        return Ok(None);
+    }
+    let mut graph = match options.timeout {
+        Some(timeout) if get_language_id_by_filename(path).is_some() => parse_until_deadline(path, &code, context, is_go, options, timeout)?,
+        Some(timeout) => parse_with_timeout(path, code, context, is_go, options, timeout)?,
+        None => parse_cached_file(path, &code, context, is_go, options.cache.as_deref(), &|| false)?,
+    };
+    if generated {
+        for node in graph.graph.node_weights_mut() {
+            node.attributes.insert("generated".to_string(), serde_json::json!(true));
+        }
+    }
+    Ok(Some(graph))
+}
+
+/// 在当前线程解析，超过时限或取消时由 tree-sitter 的进度回调中止解析
+fn parse_until_deadline(
+    path: &PathBuf,
+    code: &str,
+    context: &ParseContext,
+    is_go: bool,
+    options: &ParseOptions,
+    timeout: Duration,
+) -> Result<SymbolGraph, ParserError> {
+    let deadline = Instant::now() + timeout;
+    let should_abort = || options.is_cancelled() || Instant::now() >= deadline;
+    match parse_cached_file(path, code, context, is_go, options.cache.as_deref(), &should_abort) {
+        Err(_) if options.is_cancelled() => Err(cancelled_error()),
+        Err(_) if should_abort() => Err(timeout_error(path, timeout)),
+        result => result,
+    }
+}
+
+/// 自定义解析器不能被中断，在单独的线程中解析，超过时限或取消时不再等待
+fn parse_with_timeout(
+    path: &PathBuf,
+    code: String,
+    context: &ParseContext,
+    is_go: bool,
+    options: &ParseOptions,
+    timeout: Duration,
+) -> Result<SymbolGraph, ParserError> {
+    let (sender, receiver) = mpsc::channel();
+    let (thread_path, context, cache) = (path.clone(), context.clone(), options.cache.clone());
+    thread::spawn(move || {
+        let _ = sender.send(parse_cached_file(&thread_path, &code, &context, is_go, cache.as_deref(), &|| false));
+    });
+    let deadline = Instant::now() + timeout;
+    loop {
+        let remaining = deadline.saturating_duration_since(Instant::now());
+        match receiver.recv_timeout(remaining.min(CANCEL_POLL_INTERVAL)) {
+            Ok(result) => return result,
+            // 解析线程 panic 时发送端被丢弃
+            Err(RecvTimeoutError::Disconnected) => return Err(ParserError {
+                message: format!("Parser panicked on {}", path.display())
+            }),
+            Err(RecvTimeoutError::Timeout) if options.is_cancelled() => return Err(cancelled_error()),
+            Err(RecvTimeoutError::Timeout) if remaining.is_zero() => return Err(timeout_error(path, timeout)),
+            Err(RecvTimeoutError::Timeout) => {}
+        }
+    }
+}
+
+fn timeout_error(path: &PathBuf, timeout: Duration) -> ParserError {
+    ParserError { message: format!("Parsing {} timed out after {:?}", path.display(), timeout) }
+}
+
+/// 解析成功的结果写回缓存
+fn parse_cached_file(
+    path: &PathBuf,
+    code: &str,
+    context: &ParseContext,
+    is_go: bool,
+    cache: Option<&dyn Cache>,
+    should_abort: &dyn Fn() -> bool,
+) -> Result<SymbolGraph, ParserError> {
+    let cache = match cache {
+        Some(cache) => cache,
+        None => return parse_constrained_file(path, code, context, is_go, should_abort),
+    };
+    let key = cache_key(path, code, context);
+    if let Some(graph) = cache::load(cache, &key) {
+        return Ok(graph);
+    }
+    let graph = parse_constrained_file(path, code, context, is_go, should_abort)?;
+    cache::store(cache, &key, &graph);
+    Ok(graph)
+}
+
+/// Go 文件的节点记录文件的构建约束表达式
+fn parse_constrained_file(path: &PathBuf, code: &str, context: &ParseContext, is_go: bool, should_abort: &dyn Fn() -> bool) -> Result<SymbolGraph, ParserError> {
+    let (mut graph, _tree, _language_id) = parse_source_until(code, path, context, should_abort)?;
+    if !is_go {
+        return Ok(graph);
+    }
+    let constraints = go_build_constraints(path, code);
+    if !constraints.is_empty() {
+        for node in graph.graph.node_weights_mut() {
+            node.attributes.insert("build_constraints".to_string(), serde_json::json!(constraints));
+        }
+    }
+    Ok(graph)
+}
+
+#[cfg(test)]
+mod tests {
+    use std::fs;
+    use std::path::{Path, PathBuf};
+    use std::sync::atomic::AtomicBool;
+    use std::sync::{Arc, Mutex};
+    use std::thread;
+    use std::time::{Duration, Instant};
+
+    use tree_sitter::{Node, Tree};
+
+    use crate::codegraph::symbol_graph::build_constraints::BuildTarget;
+    use crate::codegraph::symbol_graph::builder::{parse_file_with_overlay, ParseContext};
+    use crate::codegraph::symbol_graph::dir::{parse_dir, parse_dir_each, parse_until_deadline, ParseOptions};
+    use crate::codegraph::symbol_graph::generated::GeneratedFiles;
+    use crate::codegraph::symbol_graph::types::SymbolKind;
+    use crate::codegraph::treesitter::ast_instance_structs::AstSymbolInstanceArc;
+    use crate::codegraph::treesitter::parsers::registry::register_parser;
+    use crate::codegraph::treesitter::parsers::AstLanguageParser;
+
+    fn cases_dir() -> PathBuf {
+        PathBuf::from(env!("CARGO_MANIFEST_DIR")).join("src/codegraph/treesitter/parsers/tests/cases")
+    }
+
+    /// 把测试用例目录复制 `copies` 份到 `dest`
+    fn copy_cases(dest: &Path, copies: usize) {
+        for copy in 0..copies {
+            for lang_dir in fs::read_dir(cases_dir()).unwrap().flatten() {
+                let target = dest.join(format!("copy{}", copy)).join(lang_dir.file_name());
+                fs::create_dir_all(&target).unwrap();
+                for file in fs::read_dir(lang_dir.path()).unwrap().flatten() {
+                    fs::copy(file.path(), target.join(file.file_name())).unwrap();
+                }
+            }
+        }
+    }
+
+    #[test]
+    fn parse_dir_test() {
+        let dir = tempfile::tempdir().unwrap();
+        copy_cases(dir.path(), 1);
+        let (graph, errors) = parse_dir(dir.path(), &ParseOptions { workers: 4, ..Default::default() }).unwrap();
+        assert!(errors.is_empty(), "{:?}", errors);
+
+        let go_dir = dir.path().join("copy0/go");
+        let new_point = graph.find_nodes_by_name("NewPoint").into_iter()
+            .find(|n| n.file_path == go_dir.join("main.go"))
+            .unwrap();
+        assert_eq!(new_point.kind, SymbolKind::Function);
+        assert!(graph.nodes().any(|n| n.file_path == dir.path().join("copy0/ts/widget.tsx")));
+        assert!(graph.nodes().all(|n| !n.file_path.to_string_lossy().ends_with(".json")));
+    }
+
+    #[test]
+    fn deterministic_merge_test() {
+        let dir = tempfile::tempdir().unwrap();
+        copy_cases(dir.path(), 3);
+        let (expected, _) = parse_dir(dir.path(), &ParseOptions { workers: 1, ..Default::default() }).unwrap();
+        for workers in [2, 4, 8] {
+            let (graph, _) = parse_dir(dir.path(), &ParseOptions { workers, ..Default::default() }).unwrap();
+            assert_eq!(graph.to_json().unwrap(), expected.to_json().unwrap(), "workers {}", workers);
+        }
+    }
+
+    #[test]
+    fn parse_dir_each_test() {
+        let dir = tempfile::tempdir().unwrap();
+        copy_cases(dir.path(), 1);
+        fs::write(dir.path().join("broken.go"), [0xff, 0xfe, 0x00]).unwrap();
+        let files = Mutex::new(vec![]);
+        parse_dir_each(dir.path(), &ParseOptions { workers: 4, ..Default::default() }, |path, result| {
+            let nodes = result.as_ref().map_or(0, |graph| graph.node_count());
+            // 每个图只有自己文件中的声明
+            if let Ok(graph) = &result {
+                assert!(graph.nodes()
+                    .filter(|n| !matches!(n.kind, SymbolKind::Package | SymbolKind::Builtin))
+                    .all(|n| &n.file_path == path));
+            }
+            files.lock().unwrap().push((path.clone(), result.is_ok(), nodes));
+        }).unwrap();
+        let mut files = files.into_inner().unwrap();
+        files.sort();
+
+        let (graph, errors) = parse_dir(dir.path(), &ParseOptions::default()).unwrap();
+        assert_eq!(files.iter().filter(|(_, ok, _)| !ok).map(|(path, _, _)| path.clone()).collect::<Vec<_>>(),
+                   errors.iter().map(|e| e.path.clone()).collect::<Vec<_>>());
+        let main_go = dir.path().join("copy0/go/main.go");
+        let main_nodes = files.iter().find(|(path, _, _)| path == &main_go).unwrap().2;
+        assert!(main_nodes > 0);
+        assert!(graph.node_count() > main_nodes);
+    }
+
+    #[test]
+    fn file_errors_test() {
+        let dir = tempfile::tempdir().unwrap();
+        copy_cases(dir.path(), 1);
+        fs::write(dir.path().join("broken.go"), [0xff, 0xfe, 0x00]).unwrap();
+        fs::write(dir.path().join("copy0/broken.py"), [0xc3, 0x28]).unwrap();
+
+        let (graph, errors) = parse_dir(dir.path(), &ParseOptions::default()).unwrap();
+        assert_eq!(
+            errors.iter().map(|e| e.path.clone()).collect::<Vec<_>>(),
+            vec![dir.path().join("broken.go"), dir.path().join("copy0/broken.py")]
+        );
+        assert!(errors[0].error.message.contains("broken.go"));
+        // 其余文件照常解析
+        assert!(!graph.find_nodes_by_name("NewPoint").is_empty());
+
+        assert!(parse_dir(&dir.path().join("missing"), &ParseOptions::default()).is_err());
+    }
+
+    #[cfg(unix)]
+    #[test]
+    fn unreadable_dir_test() {
+        use std::os::unix::fs::PermissionsExt;
+
+        let dir = tempfile::tempdir().unwrap();
+        write_file(&dir.path().join("main.go"), "package main\n\nfunc main() {}\n");
+        let locked = dir.path().join("locked");
+        write_file(&locked.join("inner.go"), "package locked\n\nfunc Inner() {}\n");
+        fs::set_permissions(&locked, fs::Permissions::from_mode(0o000)).unwrap();
+        // root 用户不受权限限制，无法构造读取失败的目录
+        let readable = fs::read_dir(&locked).is_ok();
+        let result = parse_dir(dir.path(), &ParseOptions::default());
+        fs::set_permissions(&locked, fs::Permissions::from_mode(0o755)).unwrap();
+        if readable {
+            return;
+        }
+
+        let (graph, errors) = result.unwrap();
+        assert_eq!(errors.iter().map(|e| e.path.clone()).collect::<Vec<_>>(), vec![locked.clone()]);
+        assert!(errors[0].error.message.contains("Failed to read directory"));
+        // 其余文件照常解析
+        assert_eq!(graph.find_nodes_by_name("main").len(), 1);
+    }
+
+    #[cfg(unix)]
+    #[test]
+    fn symlink_cycle_test() {
+        let dir = tempfile::tempdir().unwrap();
+        write_file(&dir.path().join("pkg/util.go"), "package pkg\n\nfunc Util() {}\n");
+        // pkg/loop -> ..，跟随链接时没有深度限制会无限递归
+        std::os::unix::fs::symlink("..", dir.path().join("pkg/loop")).unwrap();
+        assert_eq!(function_names(dir.path(), &ParseOptions::default()), vec!["Util"]);
+    }
+
+    #[test]
+    fn build_target_test() {
+        let dir = tempfile::tempdir().unwrap();
+        for name in ["x_linux.go", "x_windows.go", "x_other.go"] {
+            fs::copy(cases_dir().join("go").join(name), dir.path().join(name)).unwrap();
+        }
+        let platform_files = |options: &ParseOptions| {
+            let (graph, errors) = parse_dir(dir.path(), options).unwrap();
+            assert!(errors.is_empty(), "{:?}", errors);
+            graph.find_nodes_by_name("platformName").iter()
+                .map(|n| (n.file_path.file_name().unwrap().to_string_lossy().to_string(), n.attributes["build_constraints"].clone()))
+                .collect::<Vec<_>>()
+        };
+
+        // 默认解析所有文件，节点记录各自的约束
+        let all = platform_files(&ParseOptions::default());
+        assert_eq!(all, vec![
+            ("x_linux.go".to_string(), serde_json::json!(["linux", "!cgo"])),
+            ("x_other.go".to_string(), serde_json::json!(["!linux && !windows"])),
+            ("x_windows.go".to_string(), serde_json::json!(["windows"])),
+        ]);
+
+        let linux = ParseOptions { build_target: Some(BuildTarget::new("linux", "amd64")), ..Default::default() };
+        assert_eq!(platform_files(&linux).into_iter().map(|(file, _)| file).collect::<Vec<_>>(), vec!["x_linux.go"]);
+        let windows = ParseOptions { build_target: Some(BuildTarget::new("windows", "arm64")), ..Default::default() };
+        assert_eq!(platform_files(&windows).into_iter().map(|(file, _)| file).collect::<Vec<_>>(), vec!["x_windows.go"]);
+
+        let mut cgo_target = BuildTarget::new("linux", "amd64");
+        cgo_target.tags.push("cgo".to_string());
+        let cgo = ParseOptions { build_target: Some(cgo_target), ..Default::default() };
+        assert!(platform_files(&cgo).is_empty());
+    }
+
+    #[test]
+    fn generated_files_test() {
+        let dir = tempfile::tempdir().unwrap();
+        for name in ["level.go", "level_string.go"] {
+            fs::copy(cases_dir().join("go").join(name), dir.path().join(name)).unwrap();
+        }
+        let names = |options: &ParseOptions| {
+            let (graph, errors) = parse_dir(dir.path(), options).unwrap();
+            assert!(errors.is_empty(), "{:?}", errors);
+            let mut names = graph.nodes()
+                .filter(|n| matches!(n.kind, SymbolKind::Function | SymbolKind::Method | SymbolKind::Variable))
+                .map(|n| (n.qualified_name.clone(), n.is_generated()))
+                .collect::<Vec<_>>();
+            names.sort();
+            names
+        };
+
+        // 默认不检查
+        assert!(names(&ParseOptions::default()).iter().all(|(_, generated)| !generated));
+        let tag = ParseOptions { generated_files: GeneratedFiles::Tag, ..Default::default() };
+        assert_eq!(names(&tag), vec![
+            ("(Level).String".to_string(), true),
+            ("levelLabel".to_string(), false),
+            ("levelNames".to_string(), true),
+        ]);
+        let skip = ParseOptions { generated_files: GeneratedFiles::Skip, ..Default::default() };
+        assert_eq!(names(&skip), vec![("levelLabel".to_string(), false)]);
+
+        // 额外的表达式
+        let custom = ParseOptions {
+            generated_files: GeneratedFiles::Skip,
+            generated_patterns: vec![r"^// The String method lives in".to_string()],
+            ..Default::default()
+        };
+        assert!(names(&custom).is_empty());
+        let invalid = ParseOptions { generated_patterns: vec!["[".to_string()], ..custom };
+        assert!(parse_dir(dir.path(), &invalid).is_err());
+    }
+
+    #[test]
+    fn overlay_test() {
+        let dir = tempfile::tempdir().unwrap();
+        let main_go = cases_dir().join("go/main.go");
+        fs::copy(&main_go, dir.path().join("main.go")).unwrap();
+        fs::copy(cases_dir().join("go/shape.go"), dir.path().join("shape.go")).unwrap();
+
+        let modified = fs::read_to_string(&main_go).unwrap().replace("NewPoint", "MakePoint");
+        let mut options = ParseOptions::default();
+        options.overlay.insert(dir.path().join("main.go"), modified.clone());
+        // 未保存的新文件，以及磁盘上已删除的文件
+        options.overlay.insert(dir.path().join("draft.go"), "package main\n\nfunc draft() {}\n".to_string());
+        options.overlay.insert(dir.path().join("shape.go"), "package main\n\ntype Circle struct{}\n".to_string());
+        fs::remove_file(dir.path().join("shape.go")).unwrap();
+        // 根目录以外和隐藏目录中的文件不解析
+        options.overlay.insert(PathBuf::from("/elsewhere/outside.go"), "package main\n\nfunc outside() {}\n".to_string());
+        options.overlay.insert(dir.path().join(".git/hidden.go"), "package main\n\nfunc hidden() {}\n".to_string());
+
+        let (graph, errors) = parse_dir(dir.path(), &options).unwrap();
+        assert!(errors.is_empty(), "{:?}", errors);
+        assert_eq!(graph.find_nodes_by_name("MakePoint").len(), 1);
+        assert!(graph.find_nodes_by_name("NewPoint").is_empty());
+        assert_eq!(graph.find_nodes_by_name("draft").len(), 1);
+        assert_eq!(graph.find_nodes_by_name("Circle").len(), 1);
+        assert!(graph.find_nodes_by_name("Rectangle").is_empty());
+        assert!(graph.find_nodes_by_name("outside").is_empty());
+        assert!(graph.find_nodes_by_name("hidden").is_empty());
+        // 调用边按覆盖层中的内容解析
+        let make_point = graph.find_nodes_by_name("MakePoint")[0].id;
+        assert_eq!(graph.callers_of(&make_point).iter().map(|n| n.name.as_str()).collect::<Vec<_>>(), vec!["main"]);
+
+        let file = parse_file_with_overlay(&dir.path().join("main.go"), &options.overlay).unwrap();
+        assert_eq!(file.find_nodes_by_name("MakePoint").len(), 1);
+        assert!(parse_file_with_overlay(&dir.path().join("missing.go"), &options.overlay).is_err());
+    }
+
+    /// 基准：同一个目录分别用 1、2、4、8 个线程解析的耗时和相对单线程的加速比。
+    /// 运行 `cargo test --release parse_dir_scaling_benchmark -- --ignored --nocapture`
+    #[test]
+    #[ignore]
+    fn parse_dir_scaling_benchmark() {
+        const ITERATIONS: u32 = 5;
+        let dir = tempfile::tempdir().unwrap();
+        copy_cases(dir.path(), 50);
+
+        let mut single = None;
+        for workers in [1, 2, 4, 8] {
+            let options = ParseOptions { workers, ..Default::default() };
+            let mut elapsed = Duration::ZERO;
+            for _ in 0..ITERATIONS {
+                let timer = Instant::now();
+                let (_, errors) = parse_dir(dir.path(), &options).unwrap();
+                elapsed += timer.elapsed();
+                assert!(errors.is_empty(), "{:?}", errors);
+            }
+            let elapsed = elapsed / ITERATIONS;
+            let single = *single.get_or_insert(elapsed);
+            println!("{} workers: {:?} ({:.2}x)", workers, elapsed, single.as_secs_f64() / elapsed.as_secs_f64());
+        }
+    }
+
+    fn write_file(path: &Path, content: &str) {
+        fs::create_dir_all(path.parent().unwrap()).unwrap();
+        fs::write(path, content).unwrap();
+    }
+
+    fn function_names(root: &Path, options: &ParseOptions) -> Vec<String> {
+        let (graph, errors) = parse_dir(root, options).unwrap();
+        assert!(errors.is_empty(), "{:?}", errors);
+        let mut names = graph.nodes()
+            .filter(|n| n.kind == SymbolKind::Function)
+            .map(|n| n.name.clone())
+            .collect::<Vec<_>>();
+        names.sort();
+        names
+    }
+
+    #[test]
+    fn exclude_and_depth_test() {
+        let dir = tempfile::tempdir().unwrap();
+        write_file(&dir.path().join("main.go"), "package main\n\nfunc main() {}\n");
+        write_file(&dir.path().join("pkg/util.go"), "package pkg\n\nfunc Util() {}\n");
+        write_file(&dir.path().join("pkg/util_test.go"), "package pkg\n\nfunc TestUtil() {}\n");
+        write_file(&dir.path().join("pkg/deep/deep.go"), "package deep\n\nfunc Deep() {}\n");
+        write_file(&dir.path().join("vendor/acme/acme.go"), "package acme\n\nfunc Vendored() {}\n");
+        assert_eq!(function_names(dir.path(), &ParseOptions::default()), vec!["Deep", "TestUtil", "Util", "Vendored", "main"]);
+
+        let mut options = ParseOptions {
+            exclude_globs: vec!["vendor/**".to_string(), "**/*_test.go".to_string()],
+            ..Default::default()
+        };
+        // 覆盖层中被排除目录下的文件同样跳过
+        options.overlay.insert(dir.path().join("vendor/acme/extra.go"), "package acme\n\nfunc Extra() {}\n".to_string());
+        assert_eq!(function_names(dir.path(), &options), vec!["Deep", "Util", "main"]);
+
+        let shallow = ParseOptions { max_depth: Some(1), ..options.clone() };
+        assert_eq!(function_names(dir.path(), &shallow), vec!["Util", "main"]);
+        let root_only = ParseOptions { max_depth: Some(0), ..options.clone() };
+        assert_eq!(function_names(dir.path(), &root_only), vec!["main"]);
+
+        let invalid = ParseOptions { exclude_globs: vec!["[".to_string()], ..Default::default() };
+        assert!(parse_dir(dir.path(), &invalid).is_err());
+    }
+
+    /// 解析前等待一段时间
+    struct SlowParser;
+
+    impl AstLanguageParser for SlowParser {
+        fn parse(&mut self, _code: &str, _path: &PathBuf) -> Vec<AstSymbolInstanceArc> {
+            thread::sleep(Duration::from_secs(2));
+            vec![]
+        }
+
+        fn parse_tree(&mut self, _code: &str, _old_tree: Option<&Tree>) -> Option<Tree> {
+            None
+        }
+
+        fn parse_top_level(&mut self, _nodes: &[Node], _code: &str, _path: &PathBuf) -> Vec<AstSymbolInstanceArc> {
+            vec![]
+        }
+    }
+
+    #[test]
+    fn timeout_test() {
+        register_parser("slow", &["slow"], || Ok(Box::new(SlowParser) as Box<dyn AstLanguageParser>)).unwrap();
+        let dir = tempfile::tempdir().unwrap();
+        write_file(&dir.path().join("main.go"), "package main\n\nfunc main() {}\n");
+        write_file(&dir.path().join("stuck.slow"), "anything\n");
+
+        let options = ParseOptions { workers: 1, timeout: Some(Duration::from_millis(100)), ..Default::default() };
+        let timer = Instant::now();
+        let (graph, errors) = parse_dir(dir.path(), &options).unwrap();
+        assert!(timer.elapsed() < Duration::from_secs(2));
+        assert_eq!(errors.iter().map(|e| e.path.clone()).collect::<Vec<_>>(), vec![dir.path().join("stuck.slow")]);
+        assert!(errors[0].error.message.contains("timed out"), "{}", errors[0].error.message);
+        // 其余文件照常解析
+        assert_eq!(graph.find_nodes_by_qualified_name("main").len(), 1);
+    }
+
+    #[test]
+    fn builtin_parser_aborts_on_timeout_test() {
+        let functions = (0..20000).map(|i| format!("func F{}() {{}}\n", i)).collect::<String>();
+        let code = format!("package main\n\n{}", functions);
+        let path = PathBuf::from("/big.go");
+        let options = ParseOptions::default();
+        // 时限为零时 tree-sitter 在第一次进度回调时中止解析
+        let error = parse_until_deadline(&path, &code, &ParseContext::default(), true, &options, Duration::ZERO).unwrap_err();
+        assert!(error.message.contains("timed out"), "{}", error.message);
+        let graph = parse_until_deadline(&path, &code, &ParseContext::default(), true, &options, Duration::from_secs(60)).unwrap();
+        assert_eq!(graph.find_nodes_by_qualified_name("F19999").len(), 1);
+    }
+
+    #[test]
+    fn cancel_test() {
+        let dir = tempfile::tempdir().unwrap();
+        copy_cases(dir.path(), 1);
+        let options = ParseOptions { cancel: Some(Arc::new(AtomicBool::new(true))), ..Default::default() };
+        assert!(parse_dir(dir.path(), &options).is_err());
+        let called = Mutex::new(false);
+        assert!(parse_dir_each(dir.path(), &options, |_, _| *called.lock().unwrap() = true).is_err());
+        assert!(!*called.lock().unwrap());
+    }
+}
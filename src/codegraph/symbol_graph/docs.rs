@@ -0,0 +1,165 @@
+use std::path::PathBuf;
+
+use serde_json::json;
+
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::symbol_graph::types::SymbolKind;
+use crate::codegraph::treesitter::language_id::LanguageId;
+
+/// 语言的注释语法：行注释前缀，以及是否有 `/* */` 块注释
+fn comment_style(language: LanguageId) -> Option<(&'static str, bool)> {
+    match language {
+        LanguageId::Python => Some(("#", false)),
+        LanguageId::C | LanguageId::Cpp | LanguageId::CSharp | LanguageId::Go | LanguageId::Java
+        | LanguageId::JavaScript | LanguageId::Kotlin | LanguageId::Rust | LanguageId::Scala
+        | LanguageId::Swift | LanguageId::TypeScript | LanguageId::TypeScriptReact => Some(("//", true)),
+        _ => None,
+    }
+}
+
+/// 为文件中的声明附加注释：紧邻声明上方的 `//` 注释行或 `/* */` 块记为 `doc`，
+/// 中间隔着空行的注释不附加；声明结束后同一行的注释记为 `trailing_comment` 属性。
+/// Go 的 `//go:` 指令和 Rust 的 `#[...]` 属性不算文档，但不会打断上方的注释
+pub fn attach_doc_comments(graph: &mut SymbolGraph, code: &str, file_path: &PathBuf) {
+    for node in graph.graph.node_weights_mut() {
+        if &node.file_path != file_path
+            || matches!(node.kind, SymbolKind::Package | SymbolKind::File | SymbolKind::Import | SymbolKind::Unresolved | SymbolKind::TypeParameter) {
+            continue;
+        }
+        let (prefix, blocks) = match comment_style(node.language) {
+            Some(style) => style,
+            None => continue,
+        };
+        if node.span.end_byte > code.len() {
+            continue;
+        }
+        node.doc = leading_comment(code, node.span.start_byte, node.language, prefix, blocks);
+        match trailing_comment(code, node.span.end_byte, prefix, blocks) {
+            Some(comment) => node.attributes.insert("trailing_comment".to_string(), json!(comment)),
+            None => node.attributes.remove("trailing_comment"),
+        };
+    }
+}
+
+fn line_start(code: &str, byte: usize) -> usize {
+    code[..byte].rfind('\n').map_or(0, |i| i + 1)
+}
+
+fn leading_comment(code: &str, start_byte: usize, language: LanguageId, prefix: &str, blocks: bool) -> Option<String> {
+    let mut end = line_start(code, start_byte);
+    // 同一行前面还有其他代码，例如 `int a; int b;` 中的 b
+    if !code[end..start_byte].trim().is_empty() {
+        return None;
+    }
+    let mut lines = vec![];
+    while end > 0 {
+        let start = line_start(code, end - 1);
+        let raw_line = &code[start..end - 1];
+        let line = raw_line.trim();
+        end = start;
+        if (language == LanguageId::Go && line.starts_with("//go:")) || (language == LanguageId::Rust && line.starts_with("#[")) {
+            continue;
+        }
+        if line.starts_with(prefix) {
+            lines.push(strip_line_comment(line, prefix));
+        } else if blocks && lines.is_empty() && line.ends_with("*/") {
+            let block_end = start + raw_line.trim_end().len();
+            let block_start = code[..block_end].rfind("/*")?;
+            // 块注释前面有代码时是上一条语句的尾注释
+            if !code[line_start(code, block_start)..block_start].trim().is_empty() {
+                return None;
+            }
+            return Some(strip_block_comment(&code[block_start..block_end]));
+        } else {
+            break;
+        }
+    }
+    if lines.is_empty() {
+        return None;
+    }
+    lines.reverse();
+    Some(lines.join("\n"))
+}
+
+fn trailing_comment(code: &str, end_byte: usize, prefix: &str, blocks: bool) -> Option<String> {
+    let line_end = code[end_byte..].find('\n').map_or(code.len(), |i| end_byte + i);
+    // C 的字段和枚举值后面可能还有不在声明范围内的 `;`、`,`
+    let rest = code[end_byte..line_end].trim().trim_start_matches(|c| c == ';' || c == ',').trim_start();
+    if rest.starts_with(prefix) {
+        Some(strip_line_comment(rest, prefix))
+    } else if blocks && rest.starts_with("/*") && rest.ends_with("*/") {
+        Some(strip_block_comment(rest))
+    } else {
+        None
+    }
+}
+
+/// 去掉 `//`、`///`、`//!`、`#` 前缀和其后的一个空格
+fn strip_line_comment(line: &str, prefix: &str) -> String {
+    let marker = prefix.chars().next().unwrap_or('/');
+    let text = line.trim_start_matches(marker);
+    let text = text.strip_prefix('!').unwrap_or(text);
+    text.strip_prefix(' ').unwrap_or(text).trim_end().to_string()
+}
+
+/// 去掉 `/*`、`*/` 以及每行开头的 `*`，首尾的空行不保留
+fn strip_block_comment(block: &str) -> String {
+    let inner = block.trim_start_matches("/*").trim_start_matches('*').trim_end_matches("*/");
+    let lines = inner.lines()
+        .map(|line| {
+            let line = line.trim();
+            let line = line.strip_prefix('*').unwrap_or(line);
+            line.strip_prefix(' ').unwrap_or(line).trim_end()
+        })
+        .collect::<Vec<_>>();
+    lines.join("\n").trim().to_string()
+}
+
+#[cfg(test)]
+mod tests {
+    use std::path::PathBuf;
+
+    use serde_json::json;
+
+    use crate::codegraph::symbol_graph::builder::parse_code;
+
+    const SHAPE_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/shape.go");
+    const DOCS_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/docs.go");
+
+    #[test]
+    fn leading_line_comments_test() {
+        let graph = parse_code(SHAPE_GO_CODE, &PathBuf::from("/shape.go")).unwrap();
+        let shape = graph.find_nodes_by_qualified_name("Shape")[0];
+        assert_eq!(shape.doc.as_deref(), Some("Shape represents a geometric shape"));
+        let rectangle = graph.find_nodes_by_qualified_name("Rectangle")[0];
+        assert_eq!(rectangle.doc.as_deref(), Some("Rectangle is a shape with width and height"));
+        assert_eq!(graph.find_nodes_by_qualified_name("(Shape).Area")[0].doc, None);
+    }
+
+    #[test]
+    fn block_and_separated_comments_test() {
+        let graph = parse_code(DOCS_GO_CODE, &PathBuf::from("/docs.go")).unwrap();
+        let doc_of = |name: &str| graph.find_nodes_by_qualified_name(name)[0].doc.clone();
+        assert_eq!(doc_of("Palette").as_deref(), Some("Palette holds the colors used for drawing.\nIt is shared by all renderers."));
+        // 空行隔开的注释不属于下面的函数
+        assert_eq!(doc_of("defaultPalette"), None);
+        // `//go:` 指令不进入文档
+        assert_eq!(doc_of("paletteSize").as_deref(), Some("paletteSize counts the colors in a palette.\nEmpty colors are skipped."));
+        assert_eq!(doc_of("Palette.Accent").as_deref(), Some("Accent highlights the selected shape"));
+    }
+
+    #[test]
+    fn trailing_comments_test() {
+        let graph = parse_code(DOCS_GO_CODE, &PathBuf::from("/docs.go")).unwrap();
+        let field = |name: &str| graph.find_nodes_by_qualified_name(name)[0];
+        let background = field("Palette.Background");
+        assert_eq!(background.doc, None);
+        assert_eq!(background.attributes["trailing_comment"], json!("fill behind every shape"));
+        assert_eq!(field("Palette.Foreground").attributes["trailing_comment"], json!("stroke color"));
+        // 上一行的尾注释不是下一个字段的文档
+        assert_eq!(field("Palette.Foreground").doc, None);
+        assert_eq!(field("Palette.Accent").attributes.get("trailing_comment"), None);
+        // 函数体内的注释不是函数的尾注释
+        assert_eq!(field("paletteSize").attributes.get("trailing_comment"), None);
+    }
+}
@@ -0,0 +1,123 @@
+use std::collections::HashMap;
+use std::io::{self, Write};
+
+use uuid::Uuid;
+
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::symbol_graph::types::{SymbolEdgeKind, SymbolKind};
+
+/// DOT 导出选项
+#[derive(Debug, Clone, Default)]
+pub struct DotOptions {
+    /// 只输出这些类型的节点，None 表示全部
+    pub node_kinds: Option<Vec<SymbolKind>>,
+    /// 只输出这些类型的边，None 表示全部
+    pub edge_kinds: Option<Vec<SymbolEdgeKind>>,
+    /// 最多输出的节点数，按插入顺序截断，None 表示不限制
+    pub max_nodes: Option<usize>,
+}
+
+/// 节点形状：类型为方框，函数和方法为椭圆
+fn node_shape(kind: SymbolKind) -> &'static str {
+    match kind {
+        SymbolKind::Struct | SymbolKind::Interface | SymbolKind::Enum | SymbolKind::Union | SymbolKind::TypeAlias | SymbolKind::Impl | SymbolKind::Object => "box",
+        SymbolKind::Function | SymbolKind::Method | SymbolKind::Unresolved => "ellipse",
+        SymbolKind::Field | SymbolKind::Variable | SymbolKind::TypeParameter | SymbolKind::Builtin => "plaintext",
+        SymbolKind::Macro => "hexagon",
+        SymbolKind::Package | SymbolKind::Namespace => "tab",
+        SymbolKind::File => "folder",
+        SymbolKind::Import => "note",
+    }
+}
+
+fn escape(text: &str) -> String {
+    text.replace('\\', "\\\\").replace('"', "\\\"")
+}
+
+impl SymbolGraph {
+    /// 以 Graphviz DOT 格式输出，节点标签为 `类型 限定名`，边标签为关系类型。
+    /// 节点和边按插入顺序输出，两端都被输出的边才会输出
+    pub fn write_dot<W: Write>(&self, writer: &mut W, options: &DotOptions) -> io::Result<()> {
+        let nodes = self.nodes()
+            .filter(|n| options.node_kinds.as_ref().map_or(true, |kinds| kinds.contains(&n.kind)))
+            .collect::<Vec<_>>();
+        let limit = options.max_nodes.unwrap_or(nodes.len()).min(nodes.len());
+
+        writeln!(writer, "digraph symbols {{")?;
+        writeln!(writer, "  rankdir=LR;")?;
+        if limit < nodes.len() {
+            writeln!(writer, "  // truncated to {} of {} nodes", limit, nodes.len())?;
+        }
+        let mut names: HashMap<Uuid, String> = HashMap::new();
+        for (idx, node) in nodes[..limit].iter().enumerate() {
+            let name = format!("n{}", idx);
+            let style = if node.kind == SymbolKind::Unresolved { ", style=dashed" } else { "" };
+            writeln!(writer, "  {} [label=\"{} {}\", shape={}{}];",
+                     name, node.kind, escape(&node.qualified_name), node_shape(node.kind), style)?;
+            names.insert(node.id, name);
+        }
+        for edge in self.edges() {
+            if !options.edge_kinds.as_ref().map_or(true, |kinds| kinds.contains(&edge.kind)) {
+                continue;
+            }
+            if let (Some(source), Some(target)) = (names.get(&edge.source), names.get(&edge.target)) {
+                writeln!(writer, "  {} -> {} [label=\"{}\"];", source, target, edge.kind)?;
+            }
+        }
+        writeln!(writer, "}}")
+    }
+
+    /// 以 DOT 格式导出为字符串
+    pub fn to_dot(&self, options: &DotOptions) -> String {
+        let mut buffer = vec![];
+        // 写入内存不会失败
+        self.write_dot(&mut buffer, options).unwrap();
+        String::from_utf8(buffer).unwrap()
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use std::path::PathBuf;
+
+    use crate::codegraph::symbol_graph::builder::parse_code;
+    use crate::codegraph::symbol_graph::dot::DotOptions;
+    use crate::codegraph::symbol_graph::types::{SymbolEdgeKind, SymbolKind};
+
+    const SHAPE_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/shape.go");
+    const SHAPE_GO_DOT: &str = include_str!("../treesitter/parsers/tests/cases/go/shape.go.dot");
+    const MAIN_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/main.go");
+
+    #[test]
+    fn golden_dot_test() {
+        let graph = parse_code(SHAPE_GO_CODE, &PathBuf::from("/shape.go")).unwrap();
+        assert_eq!(graph.to_dot(&DotOptions::default()), SHAPE_GO_DOT);
+    }
+
+    #[test]
+    fn filtered_dot_test() {
+        let graph = parse_code(MAIN_GO_CODE, &PathBuf::from("/main.go")).unwrap();
+        let options = DotOptions {
+            node_kinds: Some(vec![SymbolKind::Struct, SymbolKind::Function, SymbolKind::Method]),
+            edge_kinds: Some(vec![SymbolEdgeKind::Calls]),
+            ..Default::default()
+        };
+        let dot = graph.to_dot(&options);
+        assert!(dot.contains("[label=\"Struct Point\", shape=box];"));
+        assert!(dot.contains("[label=\"Function NewPoint\", shape=ellipse];"));
+        assert!(!dot.contains("Field"));
+        assert!(!dot.contains("label=\"Contains\""));
+        // fmt.Println 未解析，被过滤掉，main 到它的调用边也不输出
+        assert!(!dot.contains("fmt.Println"));
+        assert_eq!(dot.matches("label=\"Calls\"").count(), 2);
+    }
+
+    #[test]
+    fn max_nodes_test() {
+        let graph = parse_code(MAIN_GO_CODE, &PathBuf::from("/main.go")).unwrap();
+        let dot = graph.to_dot(&DotOptions { max_nodes: Some(2), ..Default::default() });
+        assert_eq!(dot.matches("shape=").count(), 2);
+        assert!(dot.contains(&format!("// truncated to 2 of {} nodes", graph.node_count())));
+        assert!(dot.ends_with("}\n"));
+    }
+}
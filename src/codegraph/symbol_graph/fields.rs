@@ -0,0 +1,197 @@
+use std::collections::HashMap;
+use std::path::PathBuf;
+
+use serde_json::json;
+use tree_sitter::Node;
+use uuid::Uuid;
+
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::symbol_graph::span::Span;
+use crate::codegraph::symbol_graph::types::{FieldAccess, SymbolEdge, SymbolEdgeKind, SymbolKind};
+use crate::codegraph::treesitter::language_id::LanguageId;
+
+/// 为 Go 函数和方法添加 函数 -> 字段 的 AccessesField 边：`p.X` 中 `p` 的类型是文件中的结构体、
+/// `X` 是它的字段时产生边。`p` 的类型来自方法接收者、参数（`T` 或 `*T`）以及有 HasType 边的局部变量；
+/// 不考虑嵌入字段提升和块内遮蔽。同一函数对同一字段的多次访问合并为一条边，
+/// 边上记录访问方式（见 `FieldAccess`）和第一次访问的位置
+pub fn link_field_accesses(graph: &mut SymbolGraph, root: &Node, code: &str, file_path: &PathBuf) {
+    let functions = graph.nodes()
+        .filter(|n| &n.file_path == file_path && n.language == LanguageId::Go)
+        .filter(|n| matches!(n.kind, SymbolKind::Function | SymbolKind::Method))
+        .map(|n| (n.span.start_byte, n.id))
+        .collect::<HashMap<_, _>>();
+    if functions.is_empty() {
+        return;
+    }
+    let mut structs: HashMap<&str, Uuid> = HashMap::new();
+    for node in graph.nodes_of_kind(SymbolKind::Struct) {
+        let top_level = graph.parent_of(&node.id).map_or(true, |p| matches!(p.kind, SymbolKind::Package | SymbolKind::File));
+        if &node.file_path == file_path && top_level {
+            structs.entry(node.name.as_str()).or_insert(node.id);
+        }
+    }
+
+    let mut edges = vec![];
+    for i in 0..root.child_count() {
+        let declaration = root.child(i).unwrap();
+        if !matches!(declaration.kind(), "function_declaration" | "method_declaration") {
+            continue;
+        }
+        let (Some(function_id), Some(body)) = (functions.get(&declaration.start_byte()), declaration.child_by_field_name("body")) else {
+            continue;
+        };
+
+        let mut bindings: HashMap<String, Uuid> = HashMap::new();
+        for variable in graph.children_of(function_id).into_iter().filter(|n| n.kind == SymbolKind::Variable) {
+            let type_ = graph.outgoing_edges(&variable.id, Some(SymbolEdgeKind::HasType)).first().map(|e| e.target);
+            if let Some(type_) = type_.filter(|t| graph.get_node(t).map_or(false, |n| n.kind == SymbolKind::Struct)) {
+                bindings.insert(variable.name.clone(), type_);
+            }
+        }
+        let receiver_type = graph.outgoing_edges(function_id, Some(SymbolEdgeKind::MethodOf)).first().map(|e| e.target);
+        for (list, is_receiver) in [("receiver", true), ("parameters", false)] {
+            let Some(list) = declaration.child_by_field_name(list) else { continue };
+            for parameter in (0..list.named_child_count()).filter_map(|i| list.named_child(i)) {
+                let type_ = match is_receiver {
+                    true => receiver_type,
+                    false => parameter.child_by_field_name("type")
+                        .and_then(|t| struct_name(&t, code))
+                        .and_then(|name| structs.get(name).copied()),
+                };
+                let Some(type_) = type_ else { continue };
+                let mut cursor = parameter.walk();
+                for name in parameter.children_by_field_name("name", &mut cursor) {
+                    bindings.insert(code[name.byte_range()].to_string(), type_);
+                }
+            }
+        }
+        if bindings.is_empty() {
+            continue;
+        }
+
+        let mut accesses: Vec<(Uuid, FieldAccess, Span)> = vec![];
+        let mut stack = vec![body];
+        while let Some(node) = stack.pop() {
+            if node.kind() == "selector_expression" {
+                let operand = node.child_by_field_name("operand").filter(|o| o.kind() == "identifier");
+                let field = node.child_by_field_name("field");
+                if let (Some(operand), Some(field)) = (operand, field) {
+                    let field_id = bindings.get(&code[operand.byte_range()])
+                        .and_then(|type_| field_of(graph, type_, &code[field.byte_range()]));
+                    if let Some(field_id) = field_id {
+                        let access = access_of(&node, code);
+                        match accesses.iter_mut().find(|(id, _, _)| *id == field_id) {
+                            Some(existing) => existing.1 = existing.1.merge(access),
+                            None => accesses.push((field_id, access, Span::from(node.range()))),
+                        }
+                    }
+                }
+            }
+            for i in (0..node.child_count()).rev() {
+                stack.push(node.child(i).unwrap());
+            }
+        }
+        for (field_id, access, span) in accesses {
+            let mut edge = SymbolEdge::new(*function_id, field_id, SymbolEdgeKind::AccessesField);
+            edge.metadata = Some(json!({"access": access.as_str(), "span": span}));
+            edges.push(edge);
+        }
+    }
+    for edge in edges {
+        let _ = graph.add_edge(edge);
+    }
+}
+
+/// 参数类型 `T` 或 `*T` 中的类型名
+fn struct_name<'a>(type_: &Node, code: &'a str) -> Option<&'a str> {
+    let type_ = match type_.kind() {
+        "pointer_type" => type_.named_child(0)?,
+        _ => *type_,
+    };
+    (type_.kind() == "type_identifier").then(|| &code[type_.byte_range()])
+}
+
+fn field_of(graph: &SymbolGraph, struct_id: &Uuid, name: &str) -> Option<Uuid> {
+    graph.children_of(struct_id).into_iter()
+        .find(|n| n.kind == SymbolKind::Field && n.name == name)
+        .map(|n| n.id)
+}
+
+/// 选择器出现在赋值左侧时是写：`=` 只写，复合赋值读写；`++`/`--` 读写
+fn access_of(selector: &Node, code: &str) -> FieldAccess {
+    let Some(parent) = selector.parent() else { return FieldAccess::Read };
+    match parent.kind() {
+        "inc_statement" | "dec_statement" => FieldAccess::ReadWrite,
+        "expression_list" => {
+            let assignment = parent.parent().filter(|a| a.kind() == "assignment_statement");
+            match assignment {
+                Some(assignment) if assignment.child_by_field_name("left") == Some(parent) => {
+                    let operator = assignment.child_by_field_name("operator").map(|op| &code[op.byte_range()]);
+                    match operator {
+                        Some("=") => FieldAccess::Write,
+                        _ => FieldAccess::ReadWrite,
+                    }
+                }
+                _ => FieldAccess::Read,
+            }
+        }
+        _ => FieldAccess::Read,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use std::path::PathBuf;
+
+    use crate::codegraph::symbol_graph::builder::parse_code;
+    use crate::codegraph::symbol_graph::graph::SymbolGraph;
+    use crate::codegraph::symbol_graph::types::{FieldAccess, SymbolEdgeKind};
+
+    const MAIN_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/main.go");
+    const FIELDS_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/fields.go");
+
+    /// 函数访问的字段（限定名）和访问方式
+    fn accesses(graph: &SymbolGraph, function: &str) -> Vec<(String, FieldAccess)> {
+        let function = graph.find_nodes_by_qualified_name(function)[0];
+        let mut accesses = graph.outgoing_edges(&function.id, Some(SymbolEdgeKind::AccessesField)).iter()
+            .map(|e| (graph.get_node(&e.target).unwrap().qualified_name.clone(), e.field_access().unwrap()))
+            .collect::<Vec<_>>();
+        accesses.sort();
+        accesses
+    }
+
+    #[test]
+    fn receiver_field_writes_test() {
+        let graph = parse_code(MAIN_GO_CODE, &PathBuf::from("/main.go")).unwrap();
+        let move_ = accesses(&graph, "(*Point).Move");
+        assert_eq!(move_, vec![
+            ("Point.X".to_string(), FieldAccess::ReadWrite),
+            ("Point.Y".to_string(), FieldAccess::ReadWrite),
+        ]);
+        assert!(move_.iter().all(|(_, access)| access.is_write()));
+        // 局部变量的类型由 NewPoint 的返回值推断
+        assert_eq!(accesses(&graph, "main"), vec![
+            ("Point.X".to_string(), FieldAccess::Read),
+            ("Point.Y".to_string(), FieldAccess::Read),
+        ]);
+        // 复合字面量的键不是字段访问
+        assert!(accesses(&graph, "NewPoint").is_empty());
+    }
+
+    #[test]
+    fn access_kinds_test() {
+        let graph = parse_code(FIELDS_GO_CODE, &PathBuf::from("/fields.go")).unwrap();
+        assert_eq!(accesses(&graph, "(*Counter).Reset"), vec![
+            ("Counter.hits".to_string(), FieldAccess::Write),
+            ("Counter.name".to_string(), FieldAccess::Write),
+        ]);
+        assert_eq!(accesses(&graph, "(*Counter).Hit"), vec![("Counter.hits".to_string(), FieldAccess::ReadWrite)]);
+        // 读和写合并为读写，同一字段只有一条边
+        assert_eq!(accesses(&graph, "rename"), vec![
+            ("Counter.hits".to_string(), FieldAccess::Read),
+            ("Counter.name".to_string(), FieldAccess::ReadWrite),
+        ]);
+        // 切片参数的元素不按结构体解析，方法值不是字段
+        assert!(accesses(&graph, "total").is_empty());
+    }
+}
@@ -0,0 +1,94 @@
+use regex::Regex;
+
+use crate::codegraph::treesitter::parsers::ParserError;
+
+/// Go 约定的生成代码标记，见 https://go.dev/s/generatedcode
+pub const GO_GENERATED_HEADER: &str = r"^// Code generated .* DO NOT EDIT\.$";
+
+/// 标记最多出现在文件的前多少行
+const HEADER_LINES: usize = 20;
+
+/// 目录解析时如何处理生成的文件
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Default)]
+pub enum GeneratedFiles {
+    /// 不检查，和普通文件一样解析
+    #[default]
+    Parse,
+    /// 解析，节点带 `generated` 属性
+    Tag,
+    /// 不解析
+    Skip,
+}
+
+/// 识别生成文件的文件头
+#[derive(Debug, Clone)]
+pub struct GeneratedCodeMatcher {
+    patterns: Vec<Regex>,
+}
+
+impl GeneratedCodeMatcher {
+    /// Go 的标准标记加上额外的正则表达式，表达式无效时返回错误
+    pub fn new(extra_patterns: &[String]) -> Result<Self, ParserError> {
+        let mut patterns = vec![Regex::new(GO_GENERATED_HEADER).unwrap()];
+        for pattern in extra_patterns {
+            patterns.push(Regex::new(pattern).map_err(|e| ParserError {
+                message: format!("Invalid generated code pattern {:?}: {}", pattern, e)
+            })?);
+        }
+        Ok(Self { patterns })
+    }
+
+    /// 文件开头的注释中有一行匹配任一表达式。和 Go 的约定一样，只看第一段非注释内容
+    /// （通常是 package 子句）之前的行，并且最多看前 `HEADER_LINES` 行
+    pub fn is_generated(&self, code: &str) -> bool {
+        for line in code.lines().take(HEADER_LINES) {
+            let line = line.trim_end_matches('\r');
+            let trimmed = line.trim();
+            if trimmed.is_empty() {
+                continue;
+            }
+            if !["//", "/*", "*", "#"].iter().any(|prefix| trimmed.starts_with(prefix)) {
+                break;
+            }
+            if self.patterns.iter().any(|pattern| pattern.is_match(line)) {
+                return true;
+            }
+        }
+        false
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use crate::codegraph::symbol_graph::generated::GeneratedCodeMatcher;
+
+    const LEVEL_STRING_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/level_string.go");
+    const LEVEL_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/level.go");
+
+    #[test]
+    fn go_header_test() {
+        let matcher = GeneratedCodeMatcher::new(&[]).unwrap();
+        assert!(matcher.is_generated(LEVEL_STRING_GO_CODE));
+        assert!(matcher.is_generated("// Code generated by protoc-gen-go. DO NOT EDIT.\r\n// source: api.proto\r\n\r\npackage api\r\n"));
+        // 标记在 package 子句之后，不是生成文件
+        assert!(!matcher.is_generated(LEVEL_GO_CODE));
+        // 必须是完整的一行
+        assert!(!matcher.is_generated("// Code generated by hand, please DO NOT EDIT.s\npackage x\n"));
+        assert!(!matcher.is_generated("/* Code generated by x. DO NOT EDIT. */\npackage x\n"));
+
+        let late = format!("{}// Code generated by x. DO NOT EDIT.\npackage x\n", "//\n".repeat(20));
+        assert!(!matcher.is_generated(&late));
+    }
+
+    #[test]
+    fn custom_patterns_test() {
+        let matcher = GeneratedCodeMatcher::new(&[r"^# @generated$".to_string(), r"autogenerated".to_string()]).unwrap();
+        assert!(matcher.is_generated("#!/usr/bin/env python\n# @generated\nimport os\n"));
+        assert!(matcher.is_generated("/*\n * This file is autogenerated by the build.\n */\nint x;\n"));
+        assert!(matcher.is_generated(LEVEL_STRING_GO_CODE));
+        assert!(!matcher.is_generated("import os\n# @generated\n"));
+
+        let error = GeneratedCodeMatcher::new(&["(".to_string()]).unwrap_err();
+        assert!(error.message.contains("Invalid generated code pattern"));
+    }
+}
@@ -0,0 +1,200 @@
+use std::collections::HashMap;
+use std::sync::OnceLock;
+
+use petgraph::graph::{DiGraph, NodeIndex};
+use petgraph::visit::EdgeRef;
+use petgraph::Direction;
+use uuid::Uuid;
+
+use crate::codegraph::symbol_graph::lookup::{NameIndex, SpanIndex};
+use crate::codegraph::symbol_graph::syntax::SyntaxError;
+use crate::codegraph::symbol_graph::types::{SymbolEdge, SymbolEdgeKind, SymbolKind, SymbolNode};
+
+/// 符号图（声明级别的节点以及它们之间的结构关系）
+#[derive(Debug, Clone)]
+pub struct SymbolGraph {
+    /// petgraph有向图
+    pub graph: DiGraph<SymbolNode, SymbolEdge>,
+    /// 符号ID -> 节点索引映射
+    pub symbol_to_node: HashMap<Uuid, NodeIndex>,
+    /// 按名称查找的索引，第一次查询时建立，添加节点后失效
+    pub(crate) name_index: OnceLock<NameIndex>,
+    /// 按位置查找的索引，与名称索引一样在添加节点后失效
+    pub(crate) span_index: OnceLock<SpanIndex>,
+    /// 构建时在语法树中发现的错误，按文件和源码顺序
+    pub(crate) syntax_errors: Vec<SyntaxError>,
+}
+
+impl SymbolGraph {
+    pub fn new() -> Self {
+        Self {
+            graph: DiGraph::new(),
+            symbol_to_node: HashMap::new(),
+            name_index: OnceLock::new(),
+            span_index: OnceLock::new(),
+            syntax_errors: vec![],
+        }
+    }
+
+    /// 添加符号节点，ID已存在时返回已有节点
+    pub fn add_node(&mut self, node: SymbolNode) -> NodeIndex {
+        if let Some(&node_index) = self.symbol_to_node.get(&node.id) {
+            return node_index;
+        }
+        let id = node.id;
+        self.name_index.take();
+        self.span_index.take();
+        let node_index = self.graph.add_node(node);
+        self.symbol_to_node.insert(id, node_index);
+        node_index
+    }
+
+    /// 添加符号边
+    pub fn add_edge(&mut self, edge: SymbolEdge) -> Result<(), String> {
+        let source_node = self.symbol_to_node.get(&edge.source)
+            .ok_or_else(|| format!("Source symbol {} not found", edge.source))?;
+        let target_node = self.symbol_to_node.get(&edge.target)
+            .ok_or_else(|| format!("Target symbol {} not found", edge.target))?;
+
+        self.graph.add_edge(*source_node, *target_node, edge);
+        Ok(())
+    }
+
+    /// 合并另一个图，ID已存在的节点保留当前图中的版本，节点和边保持 `other` 中的顺序
+    pub fn merge(&mut self, other: &SymbolGraph) {
+        for node in other.nodes() {
+            self.add_node(node.clone());
+        }
+        for edge in other.edges() {
+            let _ = self.add_edge(edge.clone());
+        }
+        for error in &other.syntax_errors {
+            if !self.syntax_errors.contains(error) {
+                self.syntax_errors.push(error.clone());
+            }
+        }
+    }
+
+    /// 语法错误，图中的符号是从有错误的源码中尽量恢复出来的
+    pub fn syntax_errors(&self) -> &[SyntaxError] {
+        &self.syntax_errors
+    }
+
+    /// 根据符号ID获取节点索引
+    pub fn get_node_index(&self, symbol_id: &Uuid) -> Option<NodeIndex> {
+        self.symbol_to_node.get(symbol_id).copied()
+    }
+
+    /// 根据符号ID获取节点
+    pub fn get_node(&self, symbol_id: &Uuid) -> Option<&SymbolNode> {
+        self.symbol_to_node.get(symbol_id)
+            .and_then(|&node_index| self.graph.node_weight(node_index))
+    }
+
+    pub fn node_count(&self) -> usize {
+        self.graph.node_count()
+    }
+
+    pub fn edge_count(&self) -> usize {
+        self.graph.edge_count()
+    }
+
+    /// 所有节点（按插入顺序）
+    pub fn nodes(&self) -> impl Iterator<Item = &SymbolNode> {
+        self.graph.node_weights()
+    }
+
+    /// 所有边（按插入顺序）
+    pub fn edges(&self) -> impl Iterator<Item = &SymbolEdge> {
+        self.graph.edge_weights()
+    }
+
+    /// 指定类型的边
+    pub fn edges_of_kind(&self, kind: SymbolEdgeKind) -> impl Iterator<Item = &SymbolEdge> {
+        self.graph.edge_weights().filter(move |edge| edge.kind == kind)
+    }
+
+    /// 调用边
+    pub fn call_edges(&self) -> impl Iterator<Item = &SymbolEdge> {
+        self.edges_of_kind(SymbolEdgeKind::Calls)
+    }
+
+    /// 符号直接调用的符号
+    pub fn callees_of(&self, symbol_id: &Uuid) -> Vec<&SymbolNode> {
+        self.outgoing_edges(symbol_id, Some(SymbolEdgeKind::Calls)).iter()
+            .filter_map(|edge| self.get_node(&edge.target))
+            .collect()
+    }
+
+    /// 直接调用该符号的符号
+    pub fn callers_of(&self, symbol_id: &Uuid) -> Vec<&SymbolNode> {
+        self.incoming_edges(symbol_id, Some(SymbolEdgeKind::Calls)).iter()
+            .filter_map(|edge| self.get_node(&edge.source))
+            .collect()
+    }
+
+    /// 从符号出发的边
+    pub fn outgoing_edges(&self, symbol_id: &Uuid, kind: Option<SymbolEdgeKind>) -> Vec<&SymbolEdge> {
+        self.directed_edges(symbol_id, kind, Direction::Outgoing)
+    }
+
+    /// 指向符号的边
+    pub fn incoming_edges(&self, symbol_id: &Uuid, kind: Option<SymbolEdgeKind>) -> Vec<&SymbolEdge> {
+        self.directed_edges(symbol_id, kind, Direction::Incoming)
+    }
+
+    fn directed_edges(&self, symbol_id: &Uuid, kind: Option<SymbolEdgeKind>, direction: Direction) -> Vec<&SymbolEdge> {
+        let mut edges = Vec::new();
+        if let Some(&node_index) = self.symbol_to_node.get(symbol_id) {
+            for edge in self.graph.edges_directed(node_index, direction) {
+                if kind.map_or(true, |k| edge.weight().kind == k) {
+                    edges.push(edge.weight());
+                }
+            }
+        }
+        // edges_directed 按插入的逆序返回
+        edges.reverse();
+        edges
+    }
+
+    /// 根据名称查找节点
+    pub fn find_nodes_by_name(&self, name: &str) -> Vec<&SymbolNode> {
+        self.graph.node_weights().filter(|node| node.name == name).collect()
+    }
+
+    /// 根据限定名查找节点
+    pub fn find_nodes_by_qualified_name(&self, qualified_name: &str) -> Vec<&SymbolNode> {
+        self.graph.node_weights().filter(|node| node.qualified_name == qualified_name).collect()
+    }
+
+    /// 指定类型的节点
+    pub fn nodes_of_kind(&self, kind: SymbolKind) -> Vec<&SymbolNode> {
+        self.graph.node_weights().filter(|node| node.kind == kind).collect()
+    }
+
+    /// 类型上声明的方法
+    pub fn methods_of(&self, type_id: &Uuid) -> Vec<&SymbolNode> {
+        self.incoming_edges(type_id, Some(SymbolEdgeKind::MethodOf)).iter()
+            .filter_map(|edge| self.get_node(&edge.source))
+            .collect()
+    }
+
+    /// 直接包含该符号的父符号
+    pub fn parent_of(&self, symbol_id: &Uuid) -> Option<&SymbolNode> {
+        self.incoming_edges(symbol_id, Some(SymbolEdgeKind::Contains)).first()
+            .and_then(|edge| self.get_node(&edge.source))
+    }
+
+    /// 直接子符号
+    pub fn children_of(&self, symbol_id: &Uuid) -> Vec<&SymbolNode> {
+        self.outgoing_edges(symbol_id, Some(SymbolEdgeKind::Contains)).iter()
+            .filter_map(|edge| self.get_node(&edge.target))
+            .collect()
+    }
+}
+
+impl Default for SymbolGraph {
+    fn default() -> Self {
+        Self::new()
+    }
+}
@@ -0,0 +1,214 @@
+use std::collections::HashMap;
+use std::io::{self, Write};
+
+use uuid::Uuid;
+
+use crate::codegraph::symbol_graph::dot::DotOptions;
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+
+/// GraphML 属性声明：(id, 所属元素, 类型)，id 同时作为属性名
+const GRAPHML_KEYS: [(&str, &str, &str); 11] = [
+    ("kind", "node", "string"),
+    ("name", "node", "string"),
+    ("qualified_name", "node", "string"),
+    ("language", "node", "string"),
+    ("file", "node", "string"),
+    ("start_line", "node", "int"),
+    ("start_column", "node", "int"),
+    ("end_line", "node", "int"),
+    ("end_column", "node", "int"),
+    ("symbol_id", "node", "string"),
+    ("relationship", "edge", "string"),
+];
+
+fn escape(text: &str) -> String {
+    text.replace('&', "&amp;")
+        .replace('<', "&lt;")
+        .replace('>', "&gt;")
+        .replace('"', "&quot;")
+        .replace('\'', "&apos;")
+}
+
+impl SymbolGraph {
+    /// 以 GraphML 格式输出，供 Gephi、yEd 等工具导入。过滤和截断选项与 DOT 导出相同，
+    /// 节点和边按插入顺序输出，两端都被输出的边才会输出；行列与 `Span` 相同，从0开始
+    pub fn write_graphml<W: Write>(&self, writer: &mut W, options: &DotOptions) -> io::Result<()> {
+        let nodes = self.nodes()
+            .filter(|n| options.node_kinds.as_ref().map_or(true, |kinds| kinds.contains(&n.kind)))
+            .collect::<Vec<_>>();
+        let limit = options.max_nodes.unwrap_or(nodes.len()).min(nodes.len());
+
+        writeln!(writer, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>")?;
+        writeln!(writer, "<graphml xmlns=\"http://graphml.graphdrawing.org/xmlns\" \
+                          xmlns:xsi=\"http://www.w3.org/2001/XMLSchema-instance\" \
+                          xsi:schemaLocation=\"http://graphml.graphdrawing.org/xmlns \
+                          http://graphml.graphdrawing.org/xmlns/1.0/graphml.xsd\">")?;
+        for (id, domain, kind) in GRAPHML_KEYS {
+            writeln!(writer, "  <key id=\"{}\" for=\"{}\" attr.name=\"{}\" attr.type=\"{}\"/>", id, domain, id, kind)?;
+        }
+        writeln!(writer, "  <graph id=\"symbols\" edgedefault=\"directed\">")?;
+        if limit < nodes.len() {
+            writeln!(writer, "    <!-- truncated to {} of {} nodes -->", limit, nodes.len())?;
+        }
+        let mut names: HashMap<Uuid, String> = HashMap::new();
+        for (idx, node) in nodes[..limit].iter().enumerate() {
+            let name = format!("n{}", idx);
+            writeln!(writer, "    <node id=\"{}\">", name)?;
+            let data = [
+                ("kind", node.kind.to_string()),
+                ("name", escape(&node.name)),
+                ("qualified_name", escape(&node.qualified_name)),
+                ("language", node.language.to_string()),
+                ("file", escape(&node.file_path.to_string_lossy())),
+                ("start_line", node.span.start_line.to_string()),
+                ("start_column", node.span.start_column.to_string()),
+                ("end_line", node.span.end_line.to_string()),
+                ("end_column", node.span.end_column.to_string()),
+                ("symbol_id", node.id.to_string()),
+            ];
+            for (key, value) in data {
+                writeln!(writer, "      <data key=\"{}\">{}</data>", key, value)?;
+            }
+            writeln!(writer, "    </node>")?;
+            names.insert(node.id, name);
+        }
+        let mut count = 0;
+        for edge in self.edges() {
+            if !options.edge_kinds.as_ref().map_or(true, |kinds| kinds.contains(&edge.kind)) {
+                continue;
+            }
+            if let (Some(source), Some(target)) = (names.get(&edge.source), names.get(&edge.target)) {
+                writeln!(writer, "    <edge id=\"e{}\" source=\"{}\" target=\"{}\">", count, source, target)?;
+                writeln!(writer, "      <data key=\"relationship\">{}</data>", edge.kind)?;
+                writeln!(writer, "    </edge>")?;
+                count += 1;
+            }
+        }
+        writeln!(writer, "  </graph>")?;
+        writeln!(writer, "</graphml>")
+    }
+
+    /// 以 GraphML 格式导出为字符串
+    pub fn to_graphml(&self, options: &DotOptions) -> String {
+        let mut buffer = vec![];
+        // 写入内存不会失败
+        self.write_graphml(&mut buffer, options).unwrap();
+        String::from_utf8(buffer).unwrap()
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use std::collections::HashMap;
+    use std::path::PathBuf;
+
+    use quick_xml::events::Event;
+    use quick_xml::Reader;
+
+    use crate::codegraph::symbol_graph::builder::parse_code;
+    use crate::codegraph::symbol_graph::dot::DotOptions;
+    use crate::codegraph::symbol_graph::types::{SymbolEdgeKind, SymbolKind};
+
+    const MAIN_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/main.go");
+
+    /// 解析出的 GraphML：属性声明 id -> (所属元素, 类型)，节点和边的属性
+    #[derive(Default)]
+    struct Parsed {
+        keys: HashMap<String, (String, String)>,
+        nodes: Vec<HashMap<String, String>>,
+        edges: Vec<(String, String, HashMap<String, String>)>,
+    }
+
+    fn attribute(element: &quick_xml::events::BytesStart, name: &str) -> String {
+        let value = element.try_get_attribute(name).unwrap().unwrap();
+        value.unescape_value().unwrap().into_owned()
+    }
+
+    fn parse_graphml(text: &str) -> Parsed {
+        let mut reader = Reader::from_str(text);
+        reader.config_mut().trim_text(true);
+        let mut parsed = Parsed::default();
+        let mut key = None;
+        let mut value = String::new();
+        loop {
+            match reader.read_event().unwrap() {
+                Event::Empty(e) if e.name().as_ref() == b"key" => {
+                    let id = attribute(&e, "id");
+                    assert_eq!(attribute(&e, "attr.name"), id);
+                    parsed.keys.insert(id, (attribute(&e, "for"), attribute(&e, "attr.type")));
+                }
+                Event::Start(e) if e.name().as_ref() == b"node" => parsed.nodes.push(HashMap::new()),
+                Event::Start(e) if e.name().as_ref() == b"edge" => {
+                    parsed.edges.push((attribute(&e, "source"), attribute(&e, "target"), HashMap::new()));
+                }
+                Event::Start(e) if e.name().as_ref() == b"data" => key = Some(attribute(&e, "key")),
+                Event::Text(t) if key.is_some() => value = t.unescape().unwrap().into_owned(),
+                Event::End(e) if e.name().as_ref() == b"data" => {
+                    // 空值没有文本事件
+                    let key = key.take().unwrap();
+                    let value = std::mem::take(&mut value);
+                    let (domain, _) = &parsed.keys[&key];
+                    match domain.as_str() {
+                        "node" => parsed.nodes.last_mut().unwrap().insert(key, value),
+                        _ => parsed.edges.last_mut().unwrap().2.insert(key, value),
+                    };
+                }
+                Event::Eof => break,
+                _ => {}
+            }
+        }
+        parsed
+    }
+
+    #[test]
+    fn round_trip_test() {
+        let graph = parse_code(MAIN_GO_CODE, &PathBuf::from("/main.go")).unwrap();
+        let parsed = parse_graphml(&graph.to_graphml(&DotOptions::default()));
+        assert_eq!(parsed.nodes.len(), graph.node_count());
+        assert_eq!(parsed.edges.len(), graph.edges().count());
+        assert_eq!(parsed.keys["start_line"], ("node".to_string(), "int".to_string()));
+        assert_eq!(parsed.keys["relationship"], ("edge".to_string(), "string".to_string()));
+
+        // 每个属性都已声明，int 属性都是整数
+        for data in parsed.nodes.iter().chain(parsed.edges.iter().map(|(_, _, data)| data)) {
+            for (key, value) in data {
+                if parsed.keys[key].1 == "int" {
+                    assert!(value.parse::<usize>().is_ok(), "{}={}", key, value);
+                }
+            }
+        }
+
+        let point = graph.find_nodes_by_qualified_name("Point")[0];
+        let exported = parsed.nodes.iter().find(|n| n["symbol_id"] == point.id.to_string()).unwrap();
+        assert_eq!(exported["kind"], "Struct");
+        assert_eq!(exported["file"], "/main.go");
+        assert_eq!(exported["start_line"], point.span.start_line.to_string());
+    }
+
+    #[test]
+    fn escaped_names_test() {
+        let graph = parse_code(MAIN_GO_CODE, &PathBuf::from("/a&b/<main>.go")).unwrap();
+        let parsed = parse_graphml(&graph.to_graphml(&DotOptions::default()));
+        assert!(parsed.nodes.iter().all(|n| n["file"] == "/a&b/<main>.go"));
+    }
+
+    #[test]
+    fn filtered_graphml_test() {
+        let graph = parse_code(MAIN_GO_CODE, &PathBuf::from("/main.go")).unwrap();
+        let options = DotOptions {
+            node_kinds: Some(vec![SymbolKind::Struct, SymbolKind::Function, SymbolKind::Method]),
+            edge_kinds: Some(vec![SymbolEdgeKind::Calls]),
+            ..Default::default()
+        };
+        let parsed = parse_graphml(&graph.to_graphml(&options));
+        let kinds = parsed.nodes.iter().map(|n| n["kind"].as_str()).collect::<Vec<_>>();
+        assert!(kinds.iter().all(|k| ["Struct", "Function", "Method"].contains(k)));
+        // fmt.Println 未解析，被过滤掉，main 到它的调用边也不输出
+        assert_eq!(parsed.edges.len(), 2);
+        assert!(parsed.edges.iter().all(|(_, _, data)| data["relationship"] == "Calls"));
+
+        let truncated = graph.to_graphml(&DotOptions { max_nodes: Some(2), ..Default::default() });
+        assert_eq!(parse_graphml(&truncated).nodes.len(), 2);
+        assert!(truncated.contains(&format!("<!-- truncated to 2 of {} nodes -->", graph.node_count())));
+    }
+}
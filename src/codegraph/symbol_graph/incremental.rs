@@ -0,0 +1,413 @@
+use std::collections::HashMap;
+use std::path::PathBuf;
+
+use tree_sitter::{InputEdit, Node, Point, Range, Tree};
+
+use crate::codegraph::symbol_graph::builder::{link_source, ParseContext};
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::treesitter::ast_instance_structs::AstSymbolInstanceArc;
+use crate::codegraph::treesitter::parsers::{get_ast_parser_by_filename, AstLanguageParser, ParserError};
+
+/// 根据替换操作生成 tree-sitter 编辑以及编辑后的源码，列号按字节计算
+pub fn replace_range(code: &str, start_byte: usize, old_end_byte: usize, new_text: &str) -> (InputEdit, String) {
+    let mut new_code = String::with_capacity(code.len() - (old_end_byte - start_byte) + new_text.len());
+    new_code.push_str(&code[..start_byte]);
+    new_code.push_str(new_text);
+    new_code.push_str(&code[old_end_byte..]);
+    let new_end_byte = start_byte + new_text.len();
+    let edit = InputEdit {
+        start_byte,
+        old_end_byte,
+        new_end_byte,
+        start_position: point_at(code, start_byte),
+        old_end_position: point_at(code, old_end_byte),
+        new_end_position: point_at(&new_code, new_end_byte),
+    };
+    (edit, new_code)
+}
+
+fn point_at(code: &str, byte: usize) -> Point {
+    let prefix = &code.as_bytes()[..byte];
+    let row = prefix.iter().filter(|&&b| b == b'\n').count();
+    let line_start = prefix.iter().rposition(|&b| b == b'\n').map_or(0, |pos| pos + 1);
+    Point { row, column: byte - line_start }
+}
+
+/// 最近一次编辑的处理情况
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq)]
+pub struct EditStats {
+    /// 直接沿用上次符号的顶层节点数
+    pub reused: usize,
+    /// 重新提取符号的顶层节点数
+    pub reparsed: usize,
+}
+
+/// 一个顶层语法节点以及从中提取的符号
+struct ParsedUnit {
+    kind: &'static str,
+    range: Range,
+    symbols: Vec<AstSymbolInstanceArc>,
+}
+
+/// 单个文件的增量解析器，缓存语法树和按顶层节点划分的符号，
+/// 编辑后只重新提取发生变化的顶层节点。
+///
+/// 只有符号提取是增量的：每次编辑后仍由全部符号重新构建图，并对整个语法树重新运行
+/// `link_source`（类型引用、包选择器、字段访问、文档注释、函数体哈希和圈复杂度），
+/// 这些处理的耗时与文件大小成正比
+pub struct IncrementalParser {
+    path: PathBuf,
+    parser: Box<dyn AstLanguageParser>,
+    tree: Option<Tree>,
+    units: Vec<ParsedUnit>,
+    graph: SymbolGraph,
+    stats: EditStats,
+    context: ParseContext,
+}
+
+impl IncrementalParser {
+    pub fn new(path: &PathBuf) -> Result<Self, ParserError> {
+        let (parser, _language_id) = get_ast_parser_by_filename(path)?;
+        Ok(Self {
+            path: path.clone(),
+            parser,
+            tree: None,
+            units: vec![],
+            graph: SymbolGraph::new(),
+            stats: EditStats::default(),
+            context: ParseContext::default(),
+        })
+    }
+
+    /// 构建时使用的上下文（例如文件所属的 Go 模块），在下一次 `parse` 或 `edit` 时生效
+    pub fn with_context(mut self, context: ParseContext) -> Self {
+        self.context = context;
+        self
+    }
+
+    pub fn graph(&self) -> &SymbolGraph {
+        &self.graph
+    }
+
+    pub fn tree(&self) -> Option<&Tree> {
+        self.tree.as_ref()
+    }
+
+    pub fn stats(&self) -> EditStats {
+        self.stats
+    }
+
+    /// 完整解析
+    pub fn parse(&mut self, code: &str) -> Result<&SymbolGraph, ParserError> {
+        let tree = self.parser.parse_tree(code, None)
+            .ok_or_else(|| ParserError { message: format!("Failed to parse {}", self.path.display()) })?;
+        let root = tree.root_node();
+        let mut units = vec![];
+        for node in top_level_nodes(&root) {
+            units.push(self.parse_unit(&node, code));
+        }
+        self.stats = EditStats { reused: 0, reparsed: units.len() };
+        self.graph = SymbolGraph::from_symbols_with_context(&collect_symbols(&units), &self.context);
+        link_source(&mut self.graph, &root, code, &self.path, &self.context);
+        self.units = units;
+        self.tree = Some(tree);
+        Ok(&self.graph)
+    }
+
+    /// 把编辑应用到上一次的语法树上并增量解析 `new_code`，
+    /// `edits` 按发生顺序给出，每个编辑的位置基于前一个编辑之后的文本。
+    /// 未变化的顶层节点沿用上次的符号，图和源码相关的链接整体重建
+    pub fn edit(&mut self, edits: &[InputEdit], new_code: &str) -> Result<&SymbolGraph, ParserError> {
+        let mut old_tree = match self.tree.take() {
+            Some(tree) => tree,
+            None => return self.parse(new_code),
+        };
+        for edit in edits {
+            old_tree.edit(edit);
+        }
+        let tree = self.parser.parse_tree(new_code, Some(&old_tree))
+            .ok_or_else(|| ParserError { message: format!("Failed to parse {}", self.path.display()) })?;
+        let changed_ranges = old_tree.changed_ranges(&tree).collect::<Vec<_>>();
+
+        // 没有被编辑触及的旧顶层节点，按编辑后的位置索引
+        let mut clean_units: HashMap<(usize, usize, &'static str), usize> = HashMap::new();
+        let old_root = old_tree.root_node();
+        for (idx, node) in top_level_nodes(&old_root).iter().enumerate() {
+            let unit = match self.units.get(idx) {
+                Some(unit) if unit.kind == node.kind() => unit,
+                _ => continue,
+            };
+            let touched = node.has_changes() || changed_ranges.iter()
+                .any(|r| r.start_byte < node.end_byte() && node.start_byte() < r.end_byte);
+            if !touched {
+                clean_units.insert((node.start_byte(), node.end_byte(), unit.kind), idx);
+            }
+        }
+
+        let mut old_units = std::mem::take(&mut self.units).into_iter().map(Some).collect::<Vec<_>>();
+        let mut units = vec![];
+        let mut stats = EditStats::default();
+        let root = tree.root_node();
+        for node in top_level_nodes(&root) {
+            let reused = clean_units.get(&(node.start_byte(), node.end_byte(), node.kind()))
+                .and_then(|idx| old_units[*idx].take());
+            match reused {
+                Some(unit) => {
+                    units.push(shift_unit(unit, node.range()));
+                    stats.reused += 1;
+                }
+                None => {
+                    units.push(self.parse_unit(&node, new_code));
+                    stats.reparsed += 1;
+                }
+            }
+        }
+        self.stats = stats;
+        self.graph = SymbolGraph::from_symbols_with_context(&collect_symbols(&units), &self.context);
+        link_source(&mut self.graph, &root, new_code, &self.path, &self.context);
+        self.units = units;
+        self.tree = Some(tree);
+        Ok(&self.graph)
+    }
+
+    fn parse_unit(&mut self, node: &Node, code: &str) -> ParsedUnit {
+        ParsedUnit {
+            kind: node.kind(),
+            range: node.range(),
+            symbols: self.parser.parse_top_level(&[*node], code, &self.path),
+        }
+    }
+}
+
+pub(crate) fn top_level_nodes<'a>(root: &Node<'a>) -> Vec<Node<'a>> {
+    (0..root.child_count()).filter_map(|i| root.child(i)).collect()
+}
+
+fn collect_symbols(units: &[ParsedUnit]) -> Vec<AstSymbolInstanceArc> {
+    units.iter().flat_map(|unit| unit.symbols.iter().cloned()).collect()
+}
+
+/// 把顶层节点及其符号整体移动到新位置，节点内部的文本没有变化
+fn shift_unit(unit: ParsedUnit, new_range: Range) -> ParsedUnit {
+    if unit.range == new_range {
+        return unit;
+    }
+    for symbol in unit.symbols.iter() {
+        let mut symbol = symbol.write();
+        let fields = symbol.fields_mut();
+        shift_range(&mut fields.full_range, &unit.range, &new_range);
+        shift_range(&mut fields.declaration_range, &unit.range, &new_range);
+        shift_range(&mut fields.definition_range, &unit.range, &new_range);
+    }
+    ParsedUnit { range: new_range, ..unit }
+}
+
+fn shift_range(range: &mut Range, from: &Range, to: &Range) {
+    range.start_byte = shift_byte(range.start_byte, from, to);
+    range.end_byte = shift_byte(range.end_byte, from, to);
+    range.start_point = shift_point(range.start_point, from, to);
+    range.end_point = shift_point(range.end_point, from, to);
+}
+
+fn shift_byte(byte: usize, from: &Range, to: &Range) -> usize {
+    byte - from.start_byte + to.start_byte
+}
+
+/// 只有与节点起始位置同一行的列号会变化
+fn shift_point(point: Point, from: &Range, to: &Range) -> Point {
+    let row = point.row - from.start_point.row + to.start_point.row;
+    let column = if point.row == from.start_point.row {
+        point.column - from.start_point.column + to.start_point.column
+    } else {
+        point.column
+    };
+    Point { row, column }
+}
+
+#[cfg(test)]
+mod tests {
+    use std::collections::BTreeSet;
+    use std::path::PathBuf;
+    use std::time::{Duration, Instant};
+
+    use crate::codegraph::symbol_graph::builder::parse_code;
+    use crate::codegraph::symbol_graph::graph::SymbolGraph;
+    use crate::codegraph::symbol_graph::incremental::{replace_range, IncrementalParser};
+    use crate::codegraph::symbol_graph::span::Span;
+    use crate::codegraph::symbol_graph::types::{SymbolEdgeKind, SymbolKind};
+
+    const CALLS_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/calls.go");
+
+    /// 与ID无关的图结构：节点 (类型, 限定名, 位置) 和边 (源限定名, 目标限定名, 类型)
+    fn structure(graph: &SymbolGraph) -> (BTreeSet<(String, String, Span, Span)>, BTreeSet<(String, String, String)>) {
+        let nodes = graph.nodes()
+            .map(|n| (n.kind.to_string(), n.qualified_name.clone(), n.span, n.declaration_span))
+            .collect();
+        let edges = graph.edges()
+            .map(|e| (
+                graph.get_node(&e.source).unwrap().qualified_name.clone(),
+                graph.get_node(&e.target).unwrap().qualified_name.clone(),
+                e.kind.to_string(),
+            ))
+            .collect();
+        (nodes, edges)
+    }
+
+    fn id_of(graph: &SymbolGraph, qualified_name: &str) -> uuid::Uuid {
+        let nodes = graph.find_nodes_by_qualified_name(qualified_name);
+        assert_eq!(nodes.len(), 1, "node {}", qualified_name);
+        nodes[0].id
+    }
+
+    /// 增量结果必须与完整解析新源码的结果一致
+    fn assert_matches_full_parse(parser: &IncrementalParser, code: &str, path: &PathBuf) {
+        let full = parse_code(code, path).unwrap();
+        assert_eq!(structure(parser.graph()), structure(&full));
+    }
+
+    fn large_go_file(functions: usize) -> String {
+        let mut code = String::from("package main\n\ntype Counter struct {\n\tn int\n}\n\nfunc (c *Counter) Inc() {\n\tc.n++\n}\n");
+        for i in 0..functions {
+            code.push_str(&format!("\n// f{i} increments twice\nfunc f{i}(c *Counter) int {{\n\tc.Inc()\n\tc.Inc()\n\treturn c.n + {i}\n}}\n"));
+        }
+        code
+    }
+
+    #[test]
+    fn body_edit_test() {
+        let path = PathBuf::from("/calls.go");
+        let mut parser = IncrementalParser::new(&path).unwrap();
+        let before = parser.parse(CALLS_GO_CODE).unwrap().clone();
+        let units = parser.stats().reparsed;
+
+        let start = CALLS_GO_CODE.find("c.Inc()").unwrap();
+        let (edit, code) = replace_range(CALLS_GO_CODE, start, start, "helper()\n\t");
+        let after = parser.edit(&[edit], &code).unwrap().clone();
+        assert_eq!(parser.stats().reparsed, 1);
+        assert_eq!(parser.stats().reused, units - 1);
+        assert_matches_full_parse(&parser, &code, &path);
+
+        // 所有声明的类型和限定名都没变，ID全部沿用
+        let ids = |g: &SymbolGraph| g.nodes().map(|n| n.id).collect::<BTreeSet<_>>();
+        assert_eq!(ids(&before), ids(&after));
+    }
+
+    #[test]
+    fn edit_across_function_boundary_test() {
+        let path = PathBuf::from("/calls.go");
+        let mut parser = IncrementalParser::new(&path).unwrap();
+        let before = parser.parse(CALLS_GO_CODE).unwrap().clone();
+        let inc = id_of(&before, "(*Counter).Inc");
+        let new_counter = id_of(&before, "NewCounter");
+
+        // 从 Add 的函数体中间删到 NewCounter 的函数体中间，两个函数合并为 Add
+        let start = CALLS_GO_CODE.find("func (c *Counter) Add").unwrap();
+        let start = start + CALLS_GO_CODE[start..].find('{').unwrap() + 1;
+        let end = CALLS_GO_CODE.find("func NewCounter").unwrap();
+        let end = end + CALLS_GO_CODE[end..].find('{').unwrap() + 1;
+        let (edit, code) = replace_range(CALLS_GO_CODE, start, end, "");
+        let after = parser.edit(&[edit], &code).unwrap().clone();
+        assert!(parser.stats().reparsed >= 1);
+        assert_matches_full_parse(&parser, &code, &path);
+
+        assert!(after.find_nodes_by_name("NewCounter").iter().all(|n| n.kind == SymbolKind::Unresolved));
+        assert!(after.get_node(&new_counter).is_none());
+        assert_eq!(id_of(&after, "(*Counter).Inc"), inc);
+        assert_eq!(id_of(&after, "(*Counter).Add"), id_of(&before, "(*Counter).Add"));
+    }
+
+    #[test]
+    fn comment_only_edit_test() {
+        let path = PathBuf::from("/calls.go");
+        let mut parser = IncrementalParser::new(&path).unwrap();
+        let before = parser.parse(CALLS_GO_CODE).unwrap().clone();
+
+        // package 子句后插入注释：后面所有节点整体下移，只有新注释需要解析
+        let start = CALLS_GO_CODE.find("\n\ntype").unwrap() + 1;
+        let (edit, code) = replace_range(CALLS_GO_CODE, start, start, "// Counter counts things.\n");
+        let after = parser.edit(&[edit], &code).unwrap().clone();
+        assert_eq!(parser.stats().reparsed, 1);
+        assert_matches_full_parse(&parser, &code, &path);
+
+        // 函数体内的注释
+        let start = code.find("func run").unwrap();
+        let start = start + code[start..].find('{').unwrap() + 1;
+        let (edit, code) = replace_range(&code, start, start, " // entry point");
+        let last = parser.edit(&[edit], &code).unwrap().clone();
+        assert_eq!(parser.stats().reparsed, 1);
+        assert_matches_full_parse(&parser, &code, &path);
+
+        for graph in [&after, &last] {
+            for node in before.nodes() {
+                let moved = graph.get_node(&node.id).expect("id reused");
+                assert_eq!(moved.qualified_name, node.qualified_name);
+                assert_eq!(moved.span.start_line, node.span.start_line + 1);
+            }
+            assert_eq!(graph.node_count(), before.node_count());
+            assert_eq!(graph.edges_of_kind(SymbolEdgeKind::Calls).count(), before.edges_of_kind(SymbolEdgeKind::Calls).count());
+        }
+    }
+
+    #[test]
+    fn multiple_edits_test() {
+        let path = PathBuf::from("/calls.go");
+        let mut parser = IncrementalParser::new(&path).unwrap();
+        parser.parse(CALLS_GO_CODE).unwrap();
+
+        let (first, code) = replace_range(CALLS_GO_CODE, 0, 0, "// header\n");
+        let start = code.rfind("helper").unwrap();
+        let (second, code) = replace_range(&code, start, start + "helper".len(), "assist");
+        parser.edit(&[first, second], &code).unwrap();
+        assert_matches_full_parse(&parser, &code, &path);
+    }
+
+    #[test]
+    fn large_file_single_unit_test() {
+        let path = PathBuf::from("/large.go");
+        let code = large_go_file(2000);
+        let mut parser = IncrementalParser::new(&path).unwrap();
+        parser.parse(&code).unwrap();
+
+        let start = code.find("return c.n + 1000").unwrap();
+        let (edit, new_code) = replace_range(&code, start, start, "c.Inc()\n\t");
+
+        let full = parse_code(&new_code, &path).unwrap();
+        parser.edit(&[edit], &new_code).unwrap();
+        assert_eq!(parser.stats().reparsed, 1);
+        assert_eq!(structure(parser.graph()), structure(&full));
+    }
+
+    /// 基准：大文件中改动一个函数后，增量解析与完整解析的耗时对比。
+    /// 运行 `cargo test --release incremental_vs_full_parse_benchmark -- --ignored --nocapture`
+    #[test]
+    #[ignore]
+    fn incremental_vs_full_parse_benchmark() {
+        const ITERATIONS: u32 = 20;
+        let path = PathBuf::from("/large.go");
+        let code = large_go_file(2000);
+        let start = code.find("return c.n + 1000").unwrap();
+        let (edit, new_code) = replace_range(&code, start, start, "c.Inc()\n\t");
+
+        let (mut full, mut incremental) = (Duration::ZERO, Duration::ZERO);
+        for _ in 0..ITERATIONS {
+            let timer = Instant::now();
+            let expected = parse_code(&new_code, &path).unwrap();
+            full += timer.elapsed();
+
+            // 首次完整解析不计入增量的耗时
+            let mut parser = IncrementalParser::new(&path).unwrap();
+            parser.parse(&code).unwrap();
+            let timer = Instant::now();
+            parser.edit(&[edit], &new_code).unwrap();
+            incremental += timer.elapsed();
+
+            assert_eq!(parser.stats().reparsed, 1);
+            assert_eq!(structure(parser.graph()), structure(&expected));
+        }
+        let (full, incremental) = (full / ITERATIONS, incremental / ITERATIONS);
+        println!(
+            "full reparse: {:?}, incremental edit: {:?} ({:.1}x)",
+            full, incremental, full.as_secs_f64() / incremental.as_secs_f64()
+        );
+    }
+}
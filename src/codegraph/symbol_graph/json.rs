@@ -0,0 +1,177 @@
+use std::collections::BTreeMap;
+use std::path::PathBuf;
+
+use serde::{Deserialize, Serialize};
+use uuid::Uuid;
+
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::symbol_graph::span::Span;
+use crate::codegraph::symbol_graph::syntax::SyntaxError;
+use crate::codegraph::symbol_graph::types::{SymbolEdge, SymbolEdgeKind, SymbolKind, SymbolNode};
+use crate::codegraph::treesitter::language_id::LanguageId;
+
+/// JSON格式版本，字段含义变化时递增
+pub const SYMBOL_GRAPH_SCHEMA_VERSION: u32 = 2;
+
+/// 符号图的JSON存储格式
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct SymbolGraphJson {
+    pub schema_version: u32,
+    pub nodes: Vec<SymbolNodeJson>,
+    pub edges: Vec<SymbolEdgeJson>,
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub syntax_errors: Vec<SyntaxError>,
+}
+
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct SymbolNodeJson {
+    pub id: Uuid,
+    pub kind: SymbolKind,
+    pub name: String,
+    pub qualified_name: String,
+    pub language: LanguageId,
+    pub file_path: PathBuf,
+    pub span: Span,
+    pub declaration_span: Span,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub doc: Option<String>,
+    #[serde(default, skip_serializing_if = "BTreeMap::is_empty")]
+    pub attributes: BTreeMap<String, serde_json::Value>,
+}
+
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct SymbolEdgeJson {
+    pub source: Uuid,
+    pub target: Uuid,
+    pub kind: SymbolEdgeKind,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub metadata: Option<serde_json::Value>,
+}
+
+impl SymbolGraphJson {
+    /// 从SymbolGraph创建存储格式，节点和边保持图中的插入顺序
+    pub fn from_graph(graph: &SymbolGraph) -> Self {
+        let nodes = graph.nodes().map(|node| SymbolNodeJson {
+            id: node.id,
+            kind: node.kind,
+            name: node.name.clone(),
+            qualified_name: node.qualified_name.clone(),
+            language: node.language,
+            file_path: node.file_path.clone(),
+            span: node.span,
+            declaration_span: node.declaration_span,
+            doc: node.doc.clone(),
+            attributes: node.attributes.clone(),
+        }).collect();
+        let edges = graph.edges().map(|edge| SymbolEdgeJson {
+            source: edge.source,
+            target: edge.target,
+            kind: edge.kind,
+            metadata: edge.metadata.clone(),
+        }).collect();
+        Self {
+            schema_version: SYMBOL_GRAPH_SCHEMA_VERSION,
+            nodes,
+            edges,
+            syntax_errors: graph.syntax_errors.clone(),
+        }
+    }
+
+    /// 转换为SymbolGraph
+    pub fn to_graph(&self) -> Result<SymbolGraph, String> {
+        if self.schema_version != SYMBOL_GRAPH_SCHEMA_VERSION {
+            return Err(format!("Unsupported symbol graph schema version {}, expected {}",
+                               self.schema_version, SYMBOL_GRAPH_SCHEMA_VERSION));
+        }
+        let mut graph = SymbolGraph::new();
+        for node in &self.nodes {
+            graph.add_node(SymbolNode {
+                id: node.id,
+                kind: node.kind,
+                name: node.name.clone(),
+                qualified_name: node.qualified_name.clone(),
+                language: node.language,
+                file_path: node.file_path.clone(),
+                span: node.span,
+                declaration_span: node.declaration_span,
+                doc: node.doc.clone(),
+                attributes: node.attributes.clone(),
+            });
+        }
+        for edge in &self.edges {
+            graph.add_edge(SymbolEdge {
+                source: edge.source,
+                target: edge.target,
+                kind: edge.kind,
+                metadata: edge.metadata.clone(),
+            })?;
+        }
+        graph.syntax_errors = self.syntax_errors.clone();
+        Ok(graph)
+    }
+}
+
+impl SymbolGraph {
+    /// 导出为JSON格式
+    pub fn to_json(&self) -> Result<String, String> {
+        serde_json::to_string_pretty(&SymbolGraphJson::from_graph(self))
+            .map_err(|e| format!("Failed to serialize symbol graph: {}", e))
+    }
+
+    /// 从JSON格式加载
+    pub fn from_json(json_str: &str) -> Result<Self, String> {
+        let storage: SymbolGraphJson = serde_json::from_str(json_str)
+            .map_err(|e| format!("Failed to deserialize symbol graph: {}", e))?;
+        storage.to_graph()
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use std::path::PathBuf;
+
+    use crate::codegraph::symbol_graph::builder::parse_code;
+    use crate::codegraph::symbol_graph::graph::SymbolGraph;
+    use crate::codegraph::symbol_graph::json::{SymbolGraphJson, SYMBOL_GRAPH_SCHEMA_VERSION};
+
+    const MAIN_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/main.go");
+
+    fn assert_same_structure(left: &SymbolGraph, right: &SymbolGraph) {
+        assert_eq!(left.nodes().collect::<Vec<_>>(), right.nodes().collect::<Vec<_>>());
+        assert_eq!(left.edges().collect::<Vec<_>>(), right.edges().collect::<Vec<_>>());
+    }
+
+    #[test]
+    fn round_trip_test() {
+        let graph = parse_code(MAIN_GO_CODE, &PathBuf::from("/main.go")).unwrap();
+        let json = graph.to_json().unwrap();
+        let loaded = SymbolGraph::from_json(&json).unwrap();
+        assert_same_structure(&graph, &loaded);
+        assert_eq!(loaded.to_json().unwrap(), json);
+
+        let value: serde_json::Value = serde_json::from_str(&json).unwrap();
+        assert_eq!(value["schema_version"], SYMBOL_GRAPH_SCHEMA_VERSION);
+        let new_point = value["nodes"].as_array().unwrap().iter()
+            .find(|n| n["name"] == "NewPoint")
+            .unwrap();
+        assert_eq!(new_point["kind"], "Function");
+        assert_eq!(new_point["span"]["start_byte"], 70);
+        assert_eq!(new_point["span"]["start_line"], 11);
+    }
+
+    #[test]
+    fn deterministic_ids_test() {
+        let first = parse_code(MAIN_GO_CODE, &PathBuf::from("/main.go")).unwrap();
+        let second = parse_code(MAIN_GO_CODE, &PathBuf::from("/main.go")).unwrap();
+        assert_same_structure(&first, &second);
+        assert_eq!(first.to_json().unwrap(), second.to_json().unwrap());
+    }
+
+    #[test]
+    fn schema_version_mismatch_test() {
+        let graph = parse_code(MAIN_GO_CODE, &PathBuf::from("/main.go")).unwrap();
+        let mut storage = SymbolGraphJson::from_graph(&graph);
+        storage.schema_version = SYMBOL_GRAPH_SCHEMA_VERSION + 1;
+        assert!(storage.to_graph().is_err());
+    }
+}
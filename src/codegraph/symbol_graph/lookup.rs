@@ -0,0 +1,259 @@
+use std::collections::HashMap;
+use std::path::PathBuf;
+
+use petgraph::graph::NodeIndex;
+
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::symbol_graph::types::{SymbolKind, SymbolNode};
+
+/// 按种类和名称前缀查找符号的条件
+#[derive(Debug, Clone, Default)]
+pub struct SymbolFilter {
+    /// 符号种类，为空时匹配除文件、包、导入、内置类型和占位节点以外的所有种类
+    pub kinds: Vec<SymbolKind>,
+    /// 名称（不是限定名）前缀，为空时匹配所有名称
+    pub prefix: String,
+    /// 忽略大小写比较前缀
+    pub case_insensitive: bool,
+}
+
+impl SymbolFilter {
+    fn matches_kind(&self, kind: SymbolKind) -> bool {
+        if self.kinds.is_empty() {
+            !matches!(
+                kind,
+                SymbolKind::File
+                    | SymbolKind::Package
+                    | SymbolKind::Import
+                    | SymbolKind::Builtin
+                    | SymbolKind::Unresolved
+            )
+        } else {
+            self.kinds.contains(&kind)
+        }
+    }
+}
+
+/// 按名称排序的节点索引，大小写敏感和忽略大小写各一份，前缀查询用二分查找定位起点
+#[derive(Debug, Clone, Default)]
+pub(crate) struct NameIndex {
+    exact: Vec<(String, NodeIndex)>,
+    folded: Vec<(String, NodeIndex)>,
+}
+
+impl NameIndex {
+    fn new(graph: &SymbolGraph) -> Self {
+        let order = |node: &SymbolNode| (node.qualified_name.clone(), node.file_path.clone(), node.span.start_byte);
+        let mut exact = graph.graph.node_indices()
+            .map(|index| (graph.graph[index].name.clone(), index))
+            .collect::<Vec<_>>();
+        exact.sort_by_cached_key(|(name, index)| (name.clone(), order(&graph.graph[*index])));
+        let mut folded = exact.iter()
+            .map(|(name, index)| (name.to_lowercase(), *index))
+            .collect::<Vec<_>>();
+        // 忽略大小写相同时按原名称排序，稳定排序保留其余的顺序
+        folded.sort_by(|(a, a_index), (b, b_index)| a.cmp(b).then_with(|| graph.graph[*a_index].name.cmp(&graph.graph[*b_index].name)));
+        Self { exact, folded }
+    }
+
+    fn with_prefix(&self, prefix: &str, case_insensitive: bool) -> impl Iterator<Item = NodeIndex> + '_ {
+        let (entries, prefix) = if case_insensitive {
+            (&self.folded, prefix.to_lowercase())
+        } else {
+            (&self.exact, prefix.to_string())
+        };
+        let start = entries.partition_point(|(name, _)| name.as_str() < prefix.as_str());
+        entries[start..].iter()
+            .take_while(move |(name, _)| name.starts_with(prefix.as_str()))
+            .map(|(_, index)| *index)
+    }
+}
+
+/// 按文件分组、按起点排序的节点位置索引。父链指向排在前面且包含自身起点的最近一项，
+/// 声明的范围互相嵌套时沿父链向上就是由内向外的包含链
+#[derive(Debug, Clone, Default)]
+pub(crate) struct SpanIndex {
+    files: HashMap<PathBuf, Vec<SpanEntry>>,
+}
+
+#[derive(Debug, Clone)]
+struct SpanEntry {
+    start: usize,
+    end: usize,
+    index: NodeIndex,
+    parent: Option<usize>,
+}
+
+impl SpanIndex {
+    fn new(graph: &SymbolGraph) -> Self {
+        let mut files: HashMap<PathBuf, Vec<SpanEntry>> = HashMap::new();
+        for index in graph.graph.node_indices() {
+            let node = &graph.graph[index];
+            // 文件、包、导入和占位节点不是源码中的声明
+            if matches!(node.kind, SymbolKind::File | SymbolKind::Package | SymbolKind::Import
+                | SymbolKind::Unresolved | SymbolKind::Builtin) || node.span.len() == 0 {
+                continue;
+            }
+            files.entry(node.file_path.clone()).or_default().push(SpanEntry {
+                start: node.span.start_byte,
+                end: node.span.end_byte,
+                index,
+                parent: None,
+            });
+        }
+        for entries in files.values_mut() {
+            // 起点相同时外层在前
+            entries.sort_by_key(|e| (e.start, std::cmp::Reverse(e.end), e.index));
+            let mut stack: Vec<usize> = vec![];
+            for i in 0..entries.len() {
+                while stack.last().map_or(false, |&top| entries[top].end <= entries[i].start) {
+                    stack.pop();
+                }
+                entries[i].parent = stack.last().copied();
+                stack.push(i);
+            }
+        }
+        Self { files }
+    }
+
+    fn enclosing(&self, file_path: &PathBuf, offset: usize) -> Option<NodeIndex> {
+        let entries = self.files.get(file_path)?;
+        let mut current = entries.partition_point(|e| e.start <= offset).checked_sub(1);
+        while let Some(i) = current {
+            if offset < entries[i].end {
+                return Some(entries[i].index);
+            }
+            current = entries[i].parent;
+        }
+        None
+    }
+}
+
+impl SymbolGraph {
+    /// 包含字节偏移的最内层符号，例如方法体中的位置返回方法而不是类型或文件。
+    /// 文件、包、导入和占位节点不参与查找，不在任何声明中的位置返回 None。
+    /// 索引与 [`SymbolGraph::symbols`] 一样在第一次查询时建立，添加节点后失效
+    pub fn enclosing_symbol(&self, file_path: &PathBuf, offset: usize) -> Option<&SymbolNode> {
+        let index = self.span_index.get_or_init(|| SpanIndex::new(self));
+        index.enclosing(file_path, offset).map(|index| &self.graph[index])
+    }
+
+    /// 名称以前缀开头的符号，按名称排序，名称相同时按限定名、文件和位置排序。
+    /// 索引在第一次查询时建立，之后添加节点时失效并在下次查询时重建；
+    /// 直接修改 `graph` 中节点的名称不会更新索引
+    pub fn symbols(&self, filter: &SymbolFilter) -> Vec<&SymbolNode> {
+        let index = self.name_index.get_or_init(|| NameIndex::new(self));
+        index.with_prefix(&filter.prefix, filter.case_insensitive)
+            .map(|index| &self.graph[index])
+            .filter(|node| filter.matches_kind(node.kind))
+            .collect()
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use std::path::PathBuf;
+
+    use crate::codegraph::symbol_graph::builder::parse_code;
+    use crate::codegraph::symbol_graph::graph::SymbolGraph;
+    use crate::codegraph::symbol_graph::lookup::SymbolFilter;
+    use crate::codegraph::symbol_graph::types::SymbolKind;
+
+    const MAIN_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/main.go");
+    const SHAPE_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/shape.go");
+    const VARIABLES_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/variables.go");
+
+    fn fixtures_graph() -> SymbolGraph {
+        let mut graph = parse_code(MAIN_GO_CODE, &PathBuf::from("/main.go")).unwrap();
+        graph.merge(&parse_code(SHAPE_GO_CODE, &PathBuf::from("/shape.go")).unwrap());
+        graph.merge(&parse_code(VARIABLES_GO_CODE, &PathBuf::from("/variables.go")).unwrap());
+        graph
+    }
+
+    fn names(graph: &SymbolGraph, filter: &SymbolFilter) -> Vec<String> {
+        graph.symbols(filter).iter().map(|n| n.qualified_name.clone()).collect()
+    }
+
+    #[test]
+    fn prefix_query_test() {
+        let graph = fixtures_graph();
+        let functions = |prefix: &str, case_insensitive: bool| SymbolFilter {
+            kinds: vec![SymbolKind::Function, SymbolKind::Method],
+            prefix: prefix.to_string(),
+            case_insensitive,
+        };
+        assert_eq!(names(&graph, &functions("New", false)), vec!["NewPoint"]);
+        assert_eq!(names(&graph, &functions("n", false)), vec!["newCell"]);
+        assert_eq!(names(&graph, &functions("n", true)), vec!["newCell", "NewPoint"]);
+        // 大小写敏感时大写字母排在小写字母之前
+        assert_eq!(names(&graph, &functions("", false)), vec![
+            "(Rectangle).Area", "(Shape).Area", "(*Point).Move", "NewPoint", "main", "newCell", "shadow",
+        ]);
+        assert!(names(&graph, &functions("Zz", true)).is_empty());
+
+        let types = SymbolFilter {
+            kinds: vec![SymbolKind::Struct],
+            ..Default::default()
+        };
+        assert_eq!(names(&graph, &types), vec!["Cell", "Point", "Rectangle", "Shape"]);
+
+        // 不限种类时包括字段和变量
+        let any = SymbolFilter { prefix: "c".to_string(), case_insensitive: true, ..Default::default() };
+        assert_eq!(names(&graph, &any), vec!["shadow.c", "Cell", "Cell.Col", "cursor"]);
+
+        // 参数类型 int 是内置类型节点，main 是包节点，默认都不返回
+        let builtin = SymbolFilter { prefix: "int".to_string(), ..Default::default() };
+        assert!(names(&graph, &builtin).is_empty());
+        let builtins = SymbolFilter { kinds: vec![SymbolKind::Builtin], ..builtin };
+        assert_eq!(names(&graph, &builtins), vec!["int"]);
+        let main = SymbolFilter { prefix: "main".to_string(), ..Default::default() };
+        assert_eq!(names(&graph, &main), vec!["main"]);
+        assert!(graph.symbols(&main).iter().all(|n| n.kind == SymbolKind::Function));
+    }
+
+    /// `pattern` 在源码中第一次出现的位置加上 `offset`
+    fn offset_of(code: &str, pattern: &str, offset: usize) -> usize {
+        code.find(pattern).unwrap() + offset
+    }
+
+    fn enclosing(graph: &SymbolGraph, path: &str, offset: usize) -> Option<String> {
+        graph.enclosing_symbol(&PathBuf::from(path), offset).map(|n| n.qualified_name.clone())
+    }
+
+    #[test]
+    fn enclosing_symbol_test() {
+        let graph = fixtures_graph();
+        let at = |pattern: &str, offset: usize| enclosing(&graph, "/main.go", offset_of(MAIN_GO_CODE, pattern, offset));
+        // 方法体中的位置属于方法，不属于接收者类型或文件
+        assert_eq!(at("p.X += dx", 4).as_deref(), Some("(*Point).Move"));
+        assert_eq!(at("p.Y += dy", 0).as_deref(), Some("(*Point).Move"));
+        // 字段声明中的位置属于字段，字段之间属于结构体
+        assert_eq!(at("X int", 2).as_deref(), Some("Point.X"));
+        assert_eq!(at("Y int", 0).as_deref(), Some("Point.Y"));
+        assert_eq!(at("struct {", 7).as_deref(), Some("Point"));
+        // 调用的占位节点不参与查找
+        assert_eq!(at("fmt.Println(p.X", 4).as_deref(), Some("main"));
+
+        // 不在任何声明中
+        assert_eq!(at("\"fmt\"", 1), None);
+        assert_eq!(at("package main", 0), None);
+        assert_eq!(enclosing(&graph, "/main.go", MAIN_GO_CODE.len() + 10), None);
+        assert_eq!(enclosing(&graph, "/other.go", offset_of(MAIN_GO_CODE, "p.X += dx", 0)), None);
+        // 同一偏移在不同文件中各自查找
+        assert_eq!(enclosing(&graph, "/shape.go", offset_of(SHAPE_GO_CODE, "width  int", 0)).as_deref(), Some("Rectangle.width"));
+    }
+
+    #[test]
+    fn index_rebuilt_after_add_test() {
+        let mut graph = parse_code(MAIN_GO_CODE, &PathBuf::from("/main.go")).unwrap();
+        let filter = SymbolFilter { prefix: "New".to_string(), ..Default::default() };
+        assert_eq!(names(&graph, &filter), vec!["NewPoint"]);
+        graph.merge(&parse_code("package main\n\nfunc NewOrigin() {}\n", &PathBuf::from("/origin.go")).unwrap());
+        assert_eq!(names(&graph, &filter), vec!["NewOrigin", "NewPoint"]);
+
+        let path = PathBuf::from("/extra.go");
+        assert!(graph.enclosing_symbol(&path, 20).is_none());
+        graph.merge(&parse_code("package main\n\nfunc extra() {}\n", &path).unwrap());
+        assert_eq!(graph.enclosing_symbol(&path, 20).map(|n| n.name.as_str()), Some("extra"));
+    }
+}
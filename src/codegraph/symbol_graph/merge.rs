@@ -0,0 +1,86 @@
+use std::collections::HashMap;
+
+use uuid::Uuid;
+
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::treesitter::language_id::LanguageId;
+
+/// 合并后的节点ID：对 `语言\0原ID` 取 md5，只由语言和原ID决定，
+/// 调用方可以用它找到合并图中的节点，再添加跨语言的边
+pub fn namespaced_id(language: LanguageId, id: &Uuid) -> Uuid {
+    Uuid::from_bytes(md5::compute(format!("{}\u{0}{}", language, id)).0)
+}
+
+/// 合并各语言分别构建的符号图。节点ID按节点语言加上命名空间（见 [`namespaced_id`]），
+/// 不同语言的图中相同的ID不会冲突；合并后的节点 `id` 不再等于 `stable_id()`。
+///
+/// 节点和边按参数顺序、图内的插入顺序加入，所有边都保留，结果与线程和调用次数无关。
+/// 加上命名空间后仍然重复的节点（例如同一个图传入两次）返回错误，不会被丢弃
+pub fn merge_graphs(graphs: &[&SymbolGraph]) -> Result<SymbolGraph, String> {
+    let mut merged = SymbolGraph::new();
+    for (idx, graph) in graphs.iter().enumerate() {
+        let mut ids: HashMap<Uuid, Uuid> = HashMap::new();
+        for node in graph.nodes() {
+            let id = namespaced_id(node.language, &node.id);
+            if merged.get_node(&id).is_some() {
+                return Err(format!("Duplicate symbol {} {} ({}) in graph {}", node.kind, node.qualified_name, node.id, idx));
+            }
+            ids.insert(node.id, id);
+            let mut node = node.clone();
+            node.id = id;
+            merged.add_node(node);
+        }
+        for edge in graph.edges() {
+            let mut edge = edge.clone();
+            edge.source = ids[&edge.source];
+            edge.target = ids[&edge.target];
+            merged.add_edge(edge)?;
+        }
+        merged.syntax_errors.extend(graph.syntax_errors().iter().cloned());
+    }
+    Ok(merged)
+}
+
+#[cfg(test)]
+mod tests {
+    use std::collections::HashSet;
+    use std::path::PathBuf;
+
+    use crate::codegraph::symbol_graph::builder::parse_code;
+    use crate::codegraph::symbol_graph::merge::{merge_graphs, namespaced_id};
+    use crate::codegraph::treesitter::language_id::LanguageId;
+
+    const MAIN_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/main.go");
+
+    #[test]
+    fn merge_languages_test() {
+        let go = parse_code(MAIN_GO_CODE, &PathBuf::from("/service/main.go")).unwrap();
+        let python = parse_code("def report(points):\n    print(points)\n", &PathBuf::from("/scripts/report.py")).unwrap();
+        let merged = merge_graphs(&[&go, &python]).unwrap();
+
+        assert_eq!(merged.node_count(), go.node_count() + python.node_count());
+        assert_eq!(merged.edges().count(), go.edges().count() + python.edges().count());
+        let ids = merged.nodes().map(|n| n.id).collect::<HashSet<_>>();
+        assert_eq!(ids.len(), merged.node_count());
+
+        // 边的两端仍然是原来的节点
+        let new_point = go.find_nodes_by_qualified_name("NewPoint")[0];
+        let merged_new_point = merged.get_node(&namespaced_id(LanguageId::Go, &new_point.id)).unwrap();
+        assert_eq!(merged_new_point.qualified_name, "NewPoint");
+        assert_eq!(merged.callers_of(&merged_new_point.id).iter().map(|n| n.name.as_str()).collect::<Vec<_>>(), vec!["main"]);
+        let report = python.find_nodes_by_name("report")[0];
+        assert!(merged.get_node(&namespaced_id(LanguageId::Python, &report.id)).is_some());
+
+        // 合并结果稳定
+        let again = merge_graphs(&[&go, &python]).unwrap();
+        assert_eq!(again.to_json().unwrap(), merged.to_json().unwrap());
+    }
+
+    #[test]
+    fn duplicate_ids_test() {
+        let go = parse_code(MAIN_GO_CODE, &PathBuf::from("/service/main.go")).unwrap();
+        let error = merge_graphs(&[&go, &go]).unwrap_err();
+        assert!(error.contains("in graph 1"), "{}", error);
+        assert_eq!(merge_graphs(&[]).unwrap().node_count(), 0);
+    }
+}
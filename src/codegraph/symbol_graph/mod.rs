@@ -0,0 +1,69 @@
+pub mod types;
+pub mod span;
+pub mod graph;
+pub mod builder;
+pub mod json;
+pub mod incremental;
+pub mod references;
+pub mod dir;
+pub mod archive;
+pub mod promotion;
+pub mod satisfaction;
+pub mod dot;
+pub mod unused;
+pub mod build_constraints;
+pub mod docs;
+pub mod diff;
+pub mod stream;
+pub mod package_scope;
+pub mod walk;
+pub mod packages;
+pub mod cache;
+pub mod signatures;
+pub mod generated;
+pub mod recursion;
+pub mod lookup;
+pub mod visibility;
+pub mod normalize;
+pub mod complexity;
+pub mod graphml;
+pub mod selectors;
+pub mod fields;
+pub mod merge;
+pub mod stats;
+pub mod syntax;
+pub mod tree;
+
+pub use types::{stable_id, FieldAccess, ImportKind, ReceiverKind, SymbolEdge, SymbolEdgeKind, SymbolKind, SymbolNode};
+pub use span::{ColumnEncoding, Span};
+pub use graph::SymbolGraph;
+pub use builder::{parse_code, parse_code_with_context, parse_file, parse_file_with_overlay, ParseContext};
+pub use json::{SymbolGraphJson, SYMBOL_GRAPH_SCHEMA_VERSION};
+pub use incremental::{replace_range, EditStats, IncrementalParser};
+pub use references::{link_type_references, Reference, ReferenceKind};
+pub use dir::{parse_dir, parse_dir_each, FileError, ParseOptions};
+pub use archive::{parse_archive, parse_archive_with_limits, ArchiveFormat, ArchiveLimits};
+pub use promotion::link_promotions;
+pub use satisfaction::link_interface_satisfaction;
+pub use dot::DotOptions;
+pub use unused::UnreferencedOptions;
+pub use build_constraints::{go_build_constraints, BuildTarget};
+pub use docs::attach_doc_comments;
+pub use diff::{diff_graphs, record_body_hashes, GraphDiff, SymbolChange};
+pub use stream::{parse_stream, parse_stream_with_context};
+pub use package_scope::resolve_package_references;
+pub use packages::{link_packages, parse_go_mod, GoModule};
+pub use selectors::link_package_selectors;
+pub use fields::link_field_accesses;
+pub use merge::{merge_graphs, namespaced_id};
+pub use stats::{FunctionSize, GraphStats};
+pub use syntax::{collect_syntax_errors, record_syntax_errors, SyntaxError};
+pub use tree::{open_syntax_trees, parse_code_with_tree, parse_file_with_tree, SyntaxTree};
+pub use walk::{EdgeFilter, WalkAction, WalkOptions, WalkOrder};
+pub use signatures::{add_builtin_node, link_signature_types};
+pub use cache::{cache_key, Cache, DiskCache, MemoryCache, PARSER_VERSION};
+pub use generated::{GeneratedCodeMatcher, GeneratedFiles, GO_GENERATED_HEADER};
+pub use lookup::SymbolFilter;
+pub use complexity::record_complexity;
+pub use normalize::{case_insensitive_identifier, trim_identifier, CaseInsensitiveNormalizer, DefaultNormalizer, IdentifierNormalizer};
+pub use visibility::{attach_visibility, register_visibility_rule, CppVisibility, CVisibility, GoVisibility, VisibilityRule};
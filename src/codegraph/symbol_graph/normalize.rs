@@ -0,0 +1,122 @@
+use std::fmt::Debug;
+use std::path::PathBuf;
+
+use uuid::Uuid;
+
+use crate::codegraph::symbol_graph::types::{stable_id, SymbolKind};
+use crate::codegraph::treesitter::language_id::LanguageId;
+
+/// 标识符规范化：名称用作匹配键（占位节点的ID、包内引用解析）之前的转换，
+/// 不影响节点上显示的名称和限定名。通过 `ParseOptions::normalizer` 传入
+pub trait IdentifierNormalizer: Send + Sync + Debug {
+    /// 规则的标识，写入缓存键，规则不同时必须不同
+    fn name(&self) -> String;
+    fn normalize(&self, language: LanguageId, name: &str) -> String;
+}
+
+/// 默认规则：去掉首尾空白，大小写敏感
+pub fn trim_identifier(name: &str) -> String {
+    name.trim().to_string()
+}
+
+/// 大小写不敏感的语言：去掉首尾空白后转为小写
+pub fn case_insensitive_identifier(name: &str) -> String {
+    name.trim().to_lowercase()
+}
+
+/// 所有语言都使用 `trim_identifier`
+#[derive(Debug, Clone, Copy, Default)]
+pub struct DefaultNormalizer;
+
+impl IdentifierNormalizer for DefaultNormalizer {
+    fn name(&self) -> String {
+        "trim".to_string()
+    }
+
+    fn normalize(&self, _language: LanguageId, name: &str) -> String {
+        trim_identifier(name)
+    }
+}
+
+/// 给出的语言使用 `case_insensitive_identifier`，其余语言使用 `trim_identifier`
+#[derive(Debug, Clone, Default)]
+pub struct CaseInsensitiveNormalizer {
+    pub languages: Vec<LanguageId>,
+}
+
+impl IdentifierNormalizer for CaseInsensitiveNormalizer {
+    fn name(&self) -> String {
+        format!("case-insensitive:{:?}", self.languages)
+    }
+
+    fn normalize(&self, language: LanguageId, name: &str) -> String {
+        match self.languages.contains(&language) {
+            true => case_insensitive_identifier(name),
+            false => trim_identifier(name),
+        }
+    }
+}
+
+/// 占位节点的ID，由文件和规范化后的限定名决定，规范化后相同的引用共用一个占位节点
+pub(crate) fn unresolved_id(file_path: &PathBuf, language: LanguageId, qualified_name: &str, normalizer: &dyn IdentifierNormalizer) -> Uuid {
+    stable_id(file_path, SymbolKind::Unresolved, &normalizer.normalize(language, qualified_name), 0, None)
+}
+
+#[cfg(test)]
+mod tests {
+    use std::fs;
+    use std::path::PathBuf;
+    use std::sync::Arc;
+
+    use crate::codegraph::symbol_graph::builder::parse_code;
+    use crate::codegraph::symbol_graph::dir::{parse_dir, ParseOptions};
+    use crate::codegraph::symbol_graph::normalize::{case_insensitive_identifier, trim_identifier, CaseInsensitiveNormalizer, DefaultNormalizer, IdentifierNormalizer};
+    use crate::codegraph::symbol_graph::types::{SymbolEdgeKind, SymbolKind};
+    use crate::codegraph::treesitter::language_id::LanguageId;
+
+    #[test]
+    fn default_rules_test() {
+        assert_eq!(trim_identifier(" Point\t"), "Point");
+        assert_eq!(case_insensitive_identifier(" Point "), "point");
+        assert_eq!(DefaultNormalizer.normalize(LanguageId::Go, "Point "), "Point");
+        let kotlin = CaseInsensitiveNormalizer { languages: vec![LanguageId::Kotlin] };
+        assert_eq!(kotlin.normalize(LanguageId::Kotlin, "String"), "string");
+        assert_eq!(kotlin.normalize(LanguageId::Go, "String"), "String");
+        assert_ne!(kotlin.name(), DefaultNormalizer.name());
+    }
+
+    #[test]
+    fn go_stays_case_sensitive_test() {
+        let code = "package main\n\nfunc main() {\n\thelper()\n\tHelper()\n}\n";
+        let graph = parse_code(code, &PathBuf::from("/main.go")).unwrap();
+        let mut unresolved = graph.nodes_of_kind(SymbolKind::Unresolved).iter().map(|n| n.name.clone()).collect::<Vec<_>>();
+        unresolved.sort();
+        assert_eq!(unresolved, vec!["Helper", "helper"]);
+    }
+
+    #[test]
+    fn case_insensitive_placeholders_test() {
+        // 内置语言都区分大小写，这里用 Kotlin 扩展函数的接收者演示
+        let dir = tempfile::tempdir().unwrap();
+        fs::write(dir.path().join("text.kt"), "fun String.shout(): String = uppercase()\n\nfun STRING.whisper(): String = lowercase()\n").unwrap();
+        let options = ParseOptions {
+            normalizer: Some(Arc::new(CaseInsensitiveNormalizer { languages: vec![LanguageId::Kotlin] })),
+            ..Default::default()
+        };
+        let (graph, errors) = parse_dir(dir.path(), &options).unwrap();
+        assert!(errors.is_empty(), "{:?}", errors);
+        let unresolved = graph.nodes_of_kind(SymbolKind::Unresolved);
+        assert_eq!(unresolved.len(), 1);
+        // 显示的名称保留第一次出现时的写法
+        assert_eq!(unresolved[0].name, "String");
+        for name in ["String.shout", "STRING.whisper"] {
+            let function = graph.find_nodes_by_qualified_name(name)[0];
+            let references = graph.outgoing_edges(&function.id, Some(SymbolEdgeKind::References));
+            assert_eq!(references.iter().map(|e| e.target).collect::<Vec<_>>(), vec![unresolved[0].id], "{}", name);
+        }
+
+        // 默认规则下是两个占位节点
+        let (graph, _) = parse_dir(dir.path(), &ParseOptions::default()).unwrap();
+        assert_eq!(graph.nodes_of_kind(SymbolKind::Unresolved).len(), 2);
+    }
+}
@@ -0,0 +1,138 @@
+use std::collections::{HashMap, HashSet};
+use std::path::PathBuf;
+
+use uuid::Uuid;
+
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::symbol_graph::normalize::IdentifierNormalizer;
+use crate::codegraph::symbol_graph::promotion::package_dir;
+use crate::codegraph::symbol_graph::types::{SymbolEdgeKind, SymbolKind};
+use crate::codegraph::treesitter::language_id::LanguageId;
+
+/// 各类引用边可以指向的声明类型
+fn target_kinds(edge_kind: SymbolEdgeKind) -> &'static [SymbolKind] {
+    match edge_kind {
+        SymbolEdgeKind::Calls => &[SymbolKind::Function],
+        SymbolEdgeKind::ConstrainedBy | SymbolEdgeKind::ParamType | SymbolEdgeKind::ReturnType => &[SymbolKind::Interface, SymbolKind::Struct, SymbolKind::TypeAlias],
+        _ => &[],
+    }
+}
+
+/// 在同一个包（同一目录下的 Go 文件）内解析单文件解析时留下的占位节点：
+/// 未限定的调用（`NewPoint(1, 2)`）、类型约束和签名中的类型按 `normalizer` 规范化后的名称指向包内其他文件中唯一的同名声明。
+///
+/// 带选择器的引用（`fmt.Println`）指向其他包，仍然保留为占位节点，限定名就是选择器路径；
+/// 包内有多个同名声明（例如不同构建约束下的文件）时存在歧义，也不解析。
+/// 所有引用都解析掉的占位节点从图中删除，其余节点和边保持原来的顺序
+pub fn resolve_package_references(graph: &mut SymbolGraph, normalizer: &dyn IdentifierNormalizer) {
+    let mut declarations: HashMap<(Option<PathBuf>, String), Vec<(Uuid, SymbolKind, &PathBuf)>> = HashMap::new();
+    for node in graph.nodes() {
+        if node.language == LanguageId::Go && !matches!(node.kind, SymbolKind::Unresolved | SymbolKind::Builtin | SymbolKind::Package | SymbolKind::File | SymbolKind::Import) {
+            declarations.entry((package_dir(node), normalizer.normalize(node.language, &node.name)))
+                .or_default()
+                .push((node.id, node.kind, &node.file_path));
+        }
+    }
+
+    let mut targets: HashMap<(Uuid, SymbolEdgeKind), Uuid> = HashMap::new();
+    for node in graph.nodes() {
+        if node.kind != SymbolKind::Unresolved || node.language != LanguageId::Go || node.qualified_name.contains('.') {
+            continue;
+        }
+        let candidates = match declarations.get(&(package_dir(node), normalizer.normalize(node.language, &node.name))) {
+            Some(candidates) => candidates,
+            None => continue,
+        };
+        for edge in graph.incoming_edges(&node.id, None) {
+            let kinds = target_kinds(edge.kind);
+            let matching = candidates.iter()
+                .filter(|(_, kind, file_path)| kinds.contains(kind) && *file_path != &node.file_path)
+                .collect::<Vec<_>>();
+            if let [(target, _, _)] = matching.as_slice() {
+                targets.insert((node.id, edge.kind), *target);
+            }
+        }
+    }
+    if targets.is_empty() {
+        return;
+    }
+
+    let edges = graph.edges()
+        .map(|edge| {
+            let mut edge = edge.clone();
+            if let Some(target) = targets.get(&(edge.target, edge.kind)) {
+                edge.target = *target;
+            }
+            edge
+        })
+        .collect::<Vec<_>>();
+    let referenced = edges.iter().flat_map(|e| [e.source, e.target]).collect::<HashSet<_>>();
+    let resolved = targets.keys().map(|(id, _)| *id).collect::<HashSet<_>>();
+
+    let mut scoped = SymbolGraph::new();
+    for node in graph.nodes() {
+        if !resolved.contains(&node.id) || referenced.contains(&node.id) {
+            scoped.add_node(node.clone());
+        }
+    }
+    for edge in edges {
+        let _ = scoped.add_edge(edge);
+    }
+    scoped.syntax_errors = std::mem::take(&mut graph.syntax_errors);
+    *graph = scoped;
+}
+
+#[cfg(test)]
+mod tests {
+    use std::fs;
+
+    use crate::codegraph::symbol_graph::dir::{parse_dir, ParseOptions};
+    use crate::codegraph::symbol_graph::types::SymbolKind;
+
+    const SPLIT_GEOMETRY_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/split_geometry.go");
+    const SPLIT_RESIZE_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/split_resize.go");
+
+    #[test]
+    fn cross_file_call_test() {
+        let dir = tempfile::tempdir().unwrap();
+        fs::write(dir.path().join("split_geometry.go"), SPLIT_GEOMETRY_GO_CODE).unwrap();
+        fs::write(dir.path().join("split_resize.go"), SPLIT_RESIZE_GO_CODE).unwrap();
+        let (graph, errors) = parse_dir(dir.path(), &ParseOptions::default()).unwrap();
+        assert!(errors.is_empty(), "{:?}", errors);
+
+        let resize = graph.find_nodes_by_name("resize")[0];
+        let callees = graph.callees_of(&resize.id).iter()
+            .map(|n| (n.qualified_name.clone(), n.kind, n.file_path.file_name().unwrap().to_string_lossy().to_string()))
+            .collect::<Vec<_>>();
+        assert_eq!(callees, vec![
+            ("scale".to_string(), SymbolKind::Function, "split_geometry.go".to_string()),
+            ("fmt.Println".to_string(), SymbolKind::Unresolved, "split_resize.go".to_string()),
+        ]);
+        // 已解析的占位节点被删除，其他包的引用保留
+        let unresolved = graph.nodes_of_kind(SymbolKind::Unresolved).iter().map(|n| n.qualified_name.clone()).collect::<Vec<_>>();
+        assert_eq!(unresolved, vec!["fmt.Println"]);
+        let scale = graph.find_nodes_by_name("scale")[0];
+        assert_eq!(graph.callers_of(&scale.id)[0].id, resize.id);
+    }
+
+    #[test]
+    fn other_package_and_ambiguous_test() {
+        let dir = tempfile::tempdir().unwrap();
+        let other = dir.path().join("other");
+        fs::create_dir(&other).unwrap();
+        fs::write(other.join("split_geometry.go"), SPLIT_GEOMETRY_GO_CODE).unwrap();
+        fs::write(dir.path().join("split_resize.go"), SPLIT_RESIZE_GO_CODE).unwrap();
+        let (graph, _) = parse_dir(dir.path(), &ParseOptions::default()).unwrap();
+        // 其他目录中的同名函数不属于这个包
+        let resize = graph.find_nodes_by_name("resize")[0];
+        assert!(graph.callees_of(&resize.id).iter().all(|n| n.kind == SymbolKind::Unresolved));
+
+        // 同一个包中有两个 scale 时不解析
+        fs::write(dir.path().join("split_geometry.go"), SPLIT_GEOMETRY_GO_CODE).unwrap();
+        fs::write(dir.path().join("split_scale.go"), SPLIT_GEOMETRY_GO_CODE).unwrap();
+        fs::remove_dir_all(&other).unwrap();
+        let (graph, _) = parse_dir(dir.path(), &ParseOptions::default()).unwrap();
+        let resize = graph.find_nodes_by_name("resize")[0];
+        assert!(graph.callees_of(&resize.id).iter().all(|n| n.kind == SymbolKind::Unresolved));
+    }
+}
@@ -0,0 +1,149 @@
+use std::collections::{BTreeMap, HashMap};
+use std::fs;
+use std::path::{Path, PathBuf};
+
+use serde_json::json;
+use tree_sitter::Node;
+
+use crate::codegraph::symbol_graph::builder::add_file_node;
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::symbol_graph::span::Span;
+use crate::codegraph::symbol_graph::types::{stable_id, SymbolEdge, SymbolEdgeKind, SymbolKind, SymbolNode};
+use crate::codegraph::treesitter::language_id::LanguageId;
+
+/// 文件开头声明的包：Java `package com.example.shapes;`，Go `package main`
+fn package_declaration<'a>(root: &Node<'a>, code: &str, language: LanguageId) -> Option<(String, Node<'a>)> {
+    let (declaration_kind, name_kinds): (&str, &[&str]) = match language {
+        LanguageId::Java => ("package_declaration", &["scoped_identifier", "identifier"]),
+        LanguageId::Go => ("package_clause", &["package_identifier"]),
+        _ => return None,
+    };
+    for i in 0..root.child_count() {
+        let child = root.child(i).unwrap();
+        if child.kind() != declaration_kind {
+            continue;
+        }
+        for j in 0..child.child_count() {
+            let name = child.child(j).unwrap();
+            if name_kinds.contains(&name.kind()) {
+                return Some((code[name.byte_range()].to_string(), child));
+            }
+        }
+    }
+    None
+}
+
+/// Go 模块：`go.mod` 所在的目录和其中声明的模块路径
+#[derive(Debug, Clone, PartialEq, Eq, Hash)]
+pub struct GoModule {
+    pub root: PathBuf,
+    pub path: String,
+}
+
+impl GoModule {
+    /// 包目录的导入路径：模块路径加上目录相对于模块根目录的路径，目录不在模块中时为 None
+    pub fn import_path(&self, dir: &Path) -> Option<String> {
+        let relative = dir.strip_prefix(&self.root).ok()?;
+        Some(match relative.as_os_str().is_empty() {
+            true => self.path.clone(),
+            false => format!("{}/{}", self.path, relative.to_string_lossy().replace('\\', "/")),
+        })
+    }
+}
+
+/// `go.mod` 中的模块路径（`module example.com/shapes`，可以带引号）
+pub fn parse_go_mod(go_mod: &str) -> Option<String> {
+    go_mod.lines().find_map(|line| {
+        let mut words = line.split_whitespace();
+        (words.next() == Some("module")).then(|| words.next()).flatten()
+    }).map(|module| module.trim_matches('"').to_string())
+}
+
+/// 按目录向上查找最近的 `go.mod`，覆盖层中的内容优先于磁盘，每个目录只查找一次。
+/// 最近的 `go.mod` 没有模块声明时该目录不属于任何模块
+pub(crate) struct GoModuleResolver<'a> {
+    overlay: &'a HashMap<PathBuf, String>,
+    modules: HashMap<PathBuf, Option<GoModule>>,
+}
+
+impl<'a> GoModuleResolver<'a> {
+    pub(crate) fn new(overlay: &'a HashMap<PathBuf, String>) -> Self {
+        Self { overlay, modules: HashMap::new() }
+    }
+
+    pub(crate) fn resolve(&mut self, dir: &Path) -> Option<GoModule> {
+        if let Some(module) = self.modules.get(dir) {
+            return module.clone();
+        }
+        let go_mod = dir.join("go.mod");
+        let content = self.overlay.get(&go_mod).cloned().or_else(|| fs::read_to_string(&go_mod).ok());
+        let module = match content {
+            Some(content) => parse_go_mod(&content).map(|path| GoModule { root: dir.to_path_buf(), path }),
+            None => match dir.parent().filter(|parent| !parent.as_os_str().is_empty()) {
+                Some(parent) => self.resolve(parent),
+                None => None,
+            },
+        };
+        self.modules.insert(dir.to_path_buf(), module.clone());
+        module
+    }
+}
+
+/// 为声明了包的文件添加包节点以及 包 -> 顶层声明 的包含边。
+///
+/// 同一个包的文件在同一目录下，包节点的路径为目录，ID由目录和包名计算，
+/// 合并多个文件的图时同一个包只保留一个节点（位置为第一个文件中的包声明）。
+///
+/// Go 的包名不唯一（每个命令都是 `main`），包节点的限定名是导入路径，文件属于 `module` 时
+/// 同时记录为 `import_path` 属性，否则为目录；包含关系为 包 -> 文件 -> 顶层声明。
+/// 不读取磁盘，模块由调用方查找后传入
+pub fn link_packages(graph: &mut SymbolGraph, root: &Node, code: &str, file_path: &PathBuf, module: Option<&GoModule>) {
+    let language = match graph.nodes().find(|n| &n.file_path == file_path) {
+        Some(node) => node.language,
+        None => return,
+    };
+    let (name, declaration) = match package_declaration(root, code, language) {
+        Some(package) => package,
+        None => return,
+    };
+    let dir = file_path.parent().map(|dir| dir.to_path_buf()).unwrap_or_default();
+    let id = stable_id(&dir, SymbolKind::Package, &name, 0, None);
+    let top_level = graph.nodes()
+        .filter(|n| &n.file_path == file_path)
+        .filter(|n| !matches!(n.kind, SymbolKind::Package | SymbolKind::File | SymbolKind::Import | SymbolKind::Unresolved | SymbolKind::TypeParameter))
+        .filter(|n| graph.incoming_edges(&n.id, Some(SymbolEdgeKind::Contains)).is_empty())
+        .map(|n| n.id)
+        .collect::<Vec<_>>();
+    let mut qualified_name = name.clone();
+    let mut attributes = BTreeMap::new();
+    if language == LanguageId::Go {
+        let import_path = module.and_then(|module| module.import_path(&dir));
+        qualified_name = import_path.clone().unwrap_or_else(|| dir.display().to_string());
+        if let Some(import_path) = import_path {
+            attributes.insert("import_path".to_string(), json!(import_path));
+        }
+    }
+    graph.add_node(SymbolNode {
+        id,
+        kind: SymbolKind::Package,
+        name,
+        qualified_name,
+        language,
+        file_path: dir,
+        span: Span::from(declaration.range()),
+        declaration_span: Span::from(declaration.range()),
+        doc: None,
+        attributes,
+    });
+    let container = match language {
+        LanguageId::Go => {
+            let file_id = add_file_node(graph, file_path, language);
+            let _ = graph.add_edge(SymbolEdge::new(id, file_id, SymbolEdgeKind::Contains));
+            file_id
+        }
+        _ => id,
+    };
+    for node_id in top_level {
+        let _ = graph.add_edge(SymbolEdge::new(container, node_id, SymbolEdgeKind::Contains));
+    }
+}
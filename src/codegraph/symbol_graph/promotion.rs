@@ -0,0 +1,129 @@
+use std::collections::{HashMap, HashSet};
+use std::path::PathBuf;
+
+use serde_json::json;
+use uuid::Uuid;
+
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::symbol_graph::types::{SymbolEdge, SymbolEdgeKind, SymbolKind, SymbolNode};
+use crate::codegraph::treesitter::language_id::LanguageId;
+
+/// 为 Go 结构体添加提升方法边（Promotes）：外层结构体 -> 嵌入字段（包括多层嵌入）提供的方法。
+///
+/// 嵌入类型在同一个包（同一目录下的 Go 文件）内按名称查找，同名时优先同一文件，
+/// 其他包的类型（`geo.Point`）不解析。按嵌入深度由浅到深处理：外层自身的字段和方法
+/// 遮蔽同名的提升方法；同一深度有多个嵌入字段提供同名的字段或方法时存在歧义，
+/// 不产生提升边，更深处的同名方法也不再提升。
+///
+/// 已有的提升边不会重复添加，合并多个文件的图之后可以再次调用以连接跨文件的嵌入。
+pub fn link_promotions(graph: &mut SymbolGraph) {
+    let mut types: HashMap<(Option<PathBuf>, String), Vec<Uuid>> = HashMap::new();
+    for node in graph.nodes() {
+        if matches!(node.kind, SymbolKind::Struct | SymbolKind::Interface) && node.language == LanguageId::Go {
+            types.entry((package_dir(node), node.name.clone())).or_default().push(node.id);
+        }
+    }
+    let structs = graph.nodes()
+        .filter(|n| n.kind == SymbolKind::Struct && n.language == LanguageId::Go)
+        .map(|n| n.id)
+        .collect::<Vec<_>>();
+
+    let mut edges = vec![];
+    for struct_id in structs {
+        for (method_id, via) in promoted_methods(graph, &types, &struct_id) {
+            let exists = graph.outgoing_edges(&struct_id, Some(SymbolEdgeKind::Promotes)).iter()
+                .any(|edge| edge.target == method_id);
+            if !exists {
+                let mut edge = SymbolEdge::new(struct_id, method_id, SymbolEdgeKind::Promotes);
+                edge.metadata = Some(json!({"via": via}));
+                edges.push(edge);
+            }
+        }
+    }
+    for edge in edges {
+        let _ = graph.add_edge(edge);
+    }
+}
+
+/// 结构体通过嵌入得到的方法，以及经过的嵌入字段路径（例如 `Pixel.Point`）
+fn promoted_methods(
+    graph: &SymbolGraph,
+    types: &HashMap<(Option<PathBuf>, String), Vec<Uuid>>,
+    struct_id: &Uuid,
+) -> Vec<(Uuid, String)> {
+    let mut seen = members(graph, struct_id).into_iter()
+        .map(|(name, _)| name)
+        .collect::<HashSet<_>>();
+    let mut expanded = HashSet::from([*struct_id]);
+    let mut level = embedded_types(graph, types, struct_id);
+    let mut promoted = vec![];
+    while !level.is_empty() {
+        // 名称 -> 提供该名称的 (方法, 嵌入路径)，按源码顺序
+        let mut names = vec![];
+        let mut providers: HashMap<String, Vec<(Option<Uuid>, String)>> = HashMap::new();
+        for (via, type_id) in &level {
+            for (name, method_id) in members(graph, type_id) {
+                if !providers.contains_key(&name) {
+                    names.push(name.clone());
+                }
+                providers.entry(name).or_default().push((method_id, via.clone()));
+            }
+        }
+        for name in names {
+            if !seen.insert(name.clone()) {
+                continue;
+            }
+            if let [(Some(method_id), via)] = providers[&name].as_slice() {
+                promoted.push((*method_id, via.clone()));
+            }
+        }
+
+        let mut next = vec![];
+        for (via, type_id) in &level {
+            if expanded.insert(*type_id) {
+                for (field, embedded_id) in embedded_types(graph, types, type_id) {
+                    next.push((format!("{}.{}", via, field), embedded_id));
+                }
+            }
+        }
+        level = next;
+    }
+    promoted
+}
+
+/// 类型自身声明的字段和方法，字段没有方法ID
+fn members(graph: &SymbolGraph, type_id: &Uuid) -> Vec<(String, Option<Uuid>)> {
+    let fields = graph.children_of(type_id).into_iter()
+        .filter(|n| n.kind == SymbolKind::Field)
+        .map(|n| (n.name.clone(), None));
+    let methods = graph.methods_of(type_id).into_iter()
+        .map(|n| (n.name.clone(), Some(n.id)));
+    fields.chain(methods).collect()
+}
+
+/// 类型的嵌入字段：(字段名, 嵌入的类型)，只包含同一包内能找到的类型
+fn embedded_types(
+    graph: &SymbolGraph,
+    types: &HashMap<(Option<PathBuf>, String), Vec<Uuid>>,
+    type_id: &Uuid,
+) -> Vec<(String, Uuid)> {
+    let owner = match graph.get_node(type_id) {
+        Some(owner) => owner,
+        None => return vec![],
+    };
+    graph.children_of(type_id).into_iter()
+        .filter(|n| n.kind == SymbolKind::Field && n.attributes.get("embedded") == Some(&json!(true)))
+        .filter(|n| !n.attributes.get("type").and_then(|t| t.as_str()).map_or(false, |t| t.contains('.')))
+        .filter_map(|field| {
+            let candidates = types.get(&(package_dir(owner), field.name.clone()))?;
+            let same_file = candidates.iter()
+                .find(|id| graph.get_node(id).map_or(false, |n| n.file_path == owner.file_path));
+            same_file.or(candidates.first()).map(|id| (field.name.clone(), *id))
+        })
+        .collect()
+}
+
+/// Go 包按目录划分
+pub(crate) fn package_dir(node: &SymbolNode) -> Option<PathBuf> {
+    node.file_path.parent().map(|dir| dir.to_path_buf())
+}
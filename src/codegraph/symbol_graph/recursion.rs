@@ -0,0 +1,64 @@
+use petgraph::algo::tarjan_scc;
+use petgraph::visit::EdgeFiltered;
+use uuid::Uuid;
+
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::symbol_graph::types::SymbolEdgeKind;
+
+impl SymbolGraph {
+    /// 调用图中的递归：多于一个函数的强连通分量（互相递归），以及调用自身的函数（直接递归）。
+    /// 每个环中的节点和环之间都按节点插入顺序排列
+    pub fn recursive_cycles(&self) -> Vec<Vec<Uuid>> {
+        let calls = EdgeFiltered::from_fn(&self.graph, |edge| edge.weight().kind == SymbolEdgeKind::Calls);
+        let mut cycles = tarjan_scc(&calls).into_iter()
+            .filter(|component| {
+                component.len() > 1
+                    || self.graph.edges_connecting(component[0], component[0]).any(|e| e.weight().kind == SymbolEdgeKind::Calls)
+            })
+            .map(|mut component| {
+                component.sort();
+                component
+            })
+            .collect::<Vec<_>>();
+        cycles.sort();
+        cycles.into_iter()
+            .map(|component| component.into_iter().map(|index| self.graph[index].id).collect())
+            .collect()
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use std::path::PathBuf;
+
+    use crate::codegraph::symbol_graph::builder::parse_code;
+    use crate::codegraph::symbol_graph::graph::SymbolGraph;
+
+    const RECURSION_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/recursion.go");
+    const MAIN_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/main.go");
+
+    fn cycle_names(graph: &SymbolGraph) -> Vec<Vec<String>> {
+        graph.recursive_cycles().iter()
+            .map(|cycle| cycle.iter().map(|id| graph.get_node(id).unwrap().qualified_name.clone()).collect())
+            .collect()
+    }
+
+    #[test]
+    fn recursive_cycles_test() {
+        let graph = parse_code(RECURSION_GO_CODE, &PathBuf::from("/recursion.go")).unwrap();
+        assert_eq!(cycle_names(&graph), vec![
+            vec!["factorial".to_string()],
+            vec!["isEven".to_string(), "isOdd".to_string()],
+            vec!["stepA".to_string(), "stepB".to_string(), "stepC".to_string()],
+        ]);
+        // countdown 调用了环中的函数，但自身不在环中
+        let countdown = graph.find_nodes_by_name("countdown")[0].id;
+        assert!(graph.recursive_cycles().iter().all(|cycle| !cycle.contains(&countdown)));
+    }
+
+    #[test]
+    fn no_recursion_test() {
+        let graph = parse_code(MAIN_GO_CODE, &PathBuf::from("/main.go")).unwrap();
+        assert!(graph.recursive_cycles().is_empty());
+    }
+}
@@ -0,0 +1,419 @@
+use std::collections::HashMap;
+use std::path::PathBuf;
+
+use serde::{Deserialize, Serialize};
+use serde_json::json;
+use tree_sitter::Node;
+use uuid::Uuid;
+
+use crate::codegraph::symbol_graph::builder::add_file_node;
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::symbol_graph::span::Span;
+use crate::codegraph::symbol_graph::types::{SymbolEdge, SymbolEdgeKind, SymbolKind, SymbolNode};
+
+/// 引用的种类
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash, Serialize, Deserialize)]
+pub enum ReferenceKind {
+    /// 类型出现在签名、字段、字面量等位置，或者变量的类型由初始化表达式推断得到
+    Type,
+    /// 函数或方法调用
+    Call,
+}
+
+/// 对符号的一次引用
+#[derive(Debug, Clone, PartialEq)]
+pub struct Reference {
+    /// 引用所在的最内层声明，包级别的引用为文件节点
+    pub source: Uuid,
+    pub target: Uuid,
+    pub kind: ReferenceKind,
+    /// 引用出现的位置，旧版本导出的图中可能没有
+    pub span: Option<Span>,
+    /// 类型没有写在源码中，而是由初始化表达式推断得到，例如 `p := NewPoint(1, 2)`
+    pub inferred: bool,
+}
+
+fn reference_from_edge(edge: &SymbolEdge) -> Option<Reference> {
+    let kind = match edge.kind {
+        SymbolEdgeKind::Calls => ReferenceKind::Call,
+        SymbolEdgeKind::References => ReferenceKind::Type,
+        _ => return None,
+    };
+    let inferred = edge.metadata.as_ref()
+        .and_then(|m| m.get("inferred"))
+        .and_then(|v| v.as_bool())
+        .unwrap_or(false);
+    Some(Reference {
+        source: edge.source,
+        target: edge.target,
+        kind,
+        span: edge.site(),
+        inferred,
+    })
+}
+
+impl SymbolGraph {
+    /// 指向符号的所有引用（类型引用和调用），按位置排序
+    pub fn references_to(&self, symbol_id: &Uuid) -> Vec<Reference> {
+        let mut references = self.incoming_edges(symbol_id, None).into_iter()
+            .filter_map(reference_from_edge)
+            .collect::<Vec<_>>();
+        references.sort_by_key(|r| r.span.map(|s| (s.start_byte, s.end_byte)));
+        references
+    }
+
+    /// 位置上的引用所指向的定义，多个引用都包含该位置时取范围最小的一个
+    pub fn definition_of(&self, file_path: &PathBuf, span: &Span) -> Option<&SymbolNode> {
+        self.edges()
+            .filter_map(reference_from_edge)
+            .filter(|r| r.span.map_or(false, |s| s.contains(span)))
+            .filter(|r| self.get_node(&r.source).map_or(false, |n| &n.file_path == file_path))
+            .min_by_key(|r| r.span.map(|s| s.len()))
+            .and_then(|r| self.get_node(&r.target))
+    }
+}
+
+/// 根据语法树为文件添加类型引用边（References），解析规则：
+///
+/// 1. 只在同一文件内按名称查找类型声明（结构体、接口、枚举、类型别名），找不到时不产生引用；
+/// 2. 声明在函数等符号内部的类型只在该符号内部可见，多个同名类型都可见时取作用域最内层的一个，
+///    同一作用域中同名时取源码中靠前的一个；
+/// 3. 声明自身的名称以及带限定符的类型（`pkg.T`）不算引用；
+/// 4. Go 中没有写类型的变量（`p := NewPoint(1, 2)`、`var p = NewPoint(1, 2)`）按被调函数的返回类型
+///    记一次推断引用，位置为变量名；
+/// 5. 引用的源为包含引用位置的最内层声明，包级别的引用以文件节点为源。
+///
+/// 调用引用来自调用边（Calls），不在这里生成。
+pub fn link_type_references(graph: &mut SymbolGraph, root: &Node, code: &str, file_path: &PathBuf) {
+    let language = match graph.nodes().find(|n| &n.file_path == file_path) {
+        Some(node) => node.language.clone(),
+        None => return,
+    };
+    let references = {
+        let mut linker = ReferenceLinker::new(graph, root, code, file_path);
+        linker.walk(root);
+        linker.references
+    };
+    for (source, target, span, inferred) in references {
+        let source = match source {
+            Some(source) => source,
+            None => add_file_node(graph, file_path, language),
+        };
+        let mut edge = SymbolEdge::new(source, target, SymbolEdgeKind::References);
+        edge.metadata = Some(if inferred {
+            json!({"span": span, "inferred": true})
+        } else {
+            json!({"span": span})
+        });
+        let _ = graph.add_edge(edge);
+    }
+}
+
+struct ReferenceLinker<'a, 'tree> {
+    graph: &'a SymbolGraph,
+    code: &'a str,
+    /// 文件中可以包含引用的声明，按 (起始位置, 结束位置倒序) 排序
+    declarations: Vec<&'a SymbolNode>,
+    /// 类型名 -> 同名类型节点，按源码顺序
+    types: HashMap<&'a str, Vec<&'a SymbolNode>>,
+    /// Go 顶层函数名 -> 返回值语法节点
+    results: HashMap<&'a str, Node<'tree>>,
+    /// (源, 目标, 位置, 是否推断)
+    references: Vec<(Option<Uuid>, Uuid, Span, bool)>,
+}
+
+impl<'a, 'tree> ReferenceLinker<'a, 'tree> {
+    fn new(graph: &'a SymbolGraph, root: &Node<'tree>, code: &'a str, file_path: &PathBuf) -> Self {
+        let mut declarations = graph.nodes()
+            .filter(|n| &n.file_path == file_path)
+            .filter(|n| !matches!(n.kind, SymbolKind::Package | SymbolKind::File | SymbolKind::Import | SymbolKind::Unresolved))
+            // 函数中的局部变量不单独作为引用的来源，引用仍然记在函数上
+            .filter(|n| n.kind != SymbolKind::Variable || !graph.parent_of(&n.id).map_or(false, |p| matches!(p.kind, SymbolKind::Function | SymbolKind::Method)))
+            .collect::<Vec<_>>();
+        declarations.sort_by_key(|n| (n.span.start_byte, std::cmp::Reverse(n.span.end_byte)));
+
+        let mut types: HashMap<&'a str, Vec<&'a SymbolNode>> = HashMap::new();
+        for node in declarations.iter() {
+            if matches!(node.kind, SymbolKind::Struct | SymbolKind::Interface | SymbolKind::Enum | SymbolKind::Union | SymbolKind::TypeAlias) {
+                types.entry(node.name.as_str()).or_default().push(node);
+            }
+        }
+
+        let mut results = HashMap::new();
+        for i in 0..root.child_count() {
+            let child = root.child(i).unwrap();
+            if child.kind() != "function_declaration" {
+                continue;
+            }
+            if let (Some(name), Some(result)) = (child.child_by_field_name("name"), child.child_by_field_name("result")) {
+                results.insert(&code[name.byte_range()], result);
+            }
+        }
+
+        Self { graph, code, declarations, types, results, references: vec![] }
+    }
+
+    /// 先序遍历语法树，用显式的栈避免深层嵌套的输入耗尽调用栈
+    fn walk(&mut self, root: &Node<'tree>) {
+        let mut stack = vec![*root];
+        while let Some(node) = stack.pop() {
+            self.visit(&node);
+            for i in (0..node.child_count()).rev() {
+                stack.push(node.child(i).unwrap());
+            }
+        }
+    }
+
+    fn visit(&mut self, node: &Node<'tree>) {
+        match node.kind() {
+            "type_identifier" => {
+                if !is_declaration_name(node) && !is_qualified(node) {
+                    let name = &self.code[node.byte_range()];
+                    let span = Span::from(node.range());
+                    if let Some(target) = self.resolve_type(name, &span) {
+                        let source = self.enclosing(&span).map(|n| n.id);
+                        self.references.push((source, target, span, false));
+                    }
+                }
+            }
+            "short_var_declaration" => {
+                if let (Some(left), Some(right)) = (node.child_by_field_name("left"), node.child_by_field_name("right")) {
+                    let names = named_children(&left);
+                    self.link_inferred(&names, &named_children(&right));
+                }
+            }
+            "var_spec" => {
+                if node.child_by_field_name("type").is_none() {
+                    if let Some(value) = node.child_by_field_name("value") {
+                        let mut cursor = node.walk();
+                        let names = node.children_by_field_name("name", &mut cursor).collect::<Vec<_>>();
+                        self.link_inferred(&names, &named_children(&value));
+                    }
+                }
+            }
+            _ => {}
+        }
+    }
+
+    /// 没有写类型的变量按被调函数的返回类型记推断引用
+    fn link_inferred(&mut self, names: &[Node<'tree>], values: &[Node<'tree>]) {
+        let result_types = if values.len() == names.len() {
+            values.iter().map(|value| self.call_result_types(value).into_iter().next()).collect::<Vec<_>>()
+        } else if values.len() == 1 {
+            // a, err := f()
+            self.call_result_types(&values[0]).into_iter().map(Some).collect()
+        } else {
+            return;
+        };
+        for (name, type_node) in names.iter().zip(result_types) {
+            let type_name = match type_node.and_then(|t| type_name_node(&t)) {
+                Some(t) => &self.code[t.byte_range()],
+                None => continue,
+            };
+            if &self.code[name.byte_range()] == "_" {
+                continue;
+            }
+            let span = Span::from(name.range());
+            if let Some(target) = self.resolve_type(type_name, &span) {
+                let source = self.enclosing(&span).map(|n| n.id);
+                self.references.push((source, target, span, true));
+            }
+        }
+    }
+
+    /// 调用表达式对应的顶层函数的各个返回值类型
+    fn call_result_types(&self, value: &Node<'tree>) -> Vec<Node<'tree>> {
+        if value.kind() != "call_expression" {
+            return vec![];
+        }
+        let function = match value.child_by_field_name("function") {
+            Some(function) if function.kind() == "identifier" => function,
+            _ => return vec![],
+        };
+        let result = match self.results.get(&self.code[function.byte_range()]) {
+            Some(result) => *result,
+            None => return vec![],
+        };
+        if result.kind() != "parameter_list" {
+            return vec![result];
+        }
+        let mut types = vec![];
+        for param in named_children(&result) {
+            if let Some(type_) = param.child_by_field_name("type") {
+                let mut cursor = param.walk();
+                let names = param.children_by_field_name("name", &mut cursor).count();
+                types.extend(std::iter::repeat(type_).take(names.max(1)));
+            }
+        }
+        types
+    }
+
+    /// 包含位置的最内层声明
+    fn enclosing(&self, span: &Span) -> Option<&'a SymbolNode> {
+        let end = self.declarations.partition_point(|n| n.span.start_byte <= span.start_byte);
+        self.declarations[..end].iter().rev()
+            .find(|n| n.span.contains(span))
+            .copied()
+    }
+
+    /// 按作用域规则解析类型名
+    fn resolve_type(&self, name: &str, span: &Span) -> Option<Uuid> {
+        let candidates = self.types.get(name)?;
+        // 引用位置所在的声明链，由内向外
+        let mut scopes = vec![];
+        let mut current = self.enclosing(span);
+        while let Some(node) = current {
+            scopes.push(node.id);
+            current = self.graph.parent_of(&node.id);
+        }
+        let mut best: Option<(usize, Uuid)> = None;
+        for candidate in candidates {
+            // 作用域深度：顶层为 0，越内层越大
+            let depth = match self.graph.parent_of(&candidate.id) {
+                None => 0,
+                Some(parent) => match scopes.iter().position(|id| *id == parent.id) {
+                    Some(pos) => scopes.len() - pos,
+                    None => continue,
+                },
+            };
+            if best.map_or(true, |(best_depth, _)| depth > best_depth) {
+                best = Some((depth, candidate.id));
+            }
+        }
+        best.map(|(_, id)| id)
+    }
+}
+
+fn named_children<'tree>(node: &Node<'tree>) -> Vec<Node<'tree>> {
+    (0..node.named_child_count()).filter_map(|i| node.named_child(i)).collect()
+}
+
+/// 类型名本身就是声明的名称，例如 `type Point struct` 中的 `Point`
+fn is_declaration_name(node: &Node) -> bool {
+    node.parent()
+        .and_then(|parent| parent.child_by_field_name("name"))
+        .map_or(false, |name| name == *node)
+}
+
+/// 带包名或命名空间的类型，例如 `fmt.Stringer`
+fn is_qualified(node: &Node) -> bool {
+    node.parent().map_or(false, |parent| matches!(
+        parent.kind(),
+        "qualified_type" | "nested_type_identifier" | "scoped_type_identifier"
+    ))
+}
+
+/// 返回值类型中的类型名：`T`、`*T`、`T[int]`
+fn type_name_node<'tree>(type_: &Node<'tree>) -> Option<Node<'tree>> {
+    let mut node = *type_;
+    loop {
+        node = match node.kind() {
+            "type_identifier" => return Some(node),
+            "pointer_type" => node.named_child(0)?,
+            "generic_type" => node.child_by_field_name("type")?,
+            _ => return None,
+        };
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use std::path::PathBuf;
+
+    use crate::codegraph::symbol_graph::builder::parse_code;
+    use crate::codegraph::symbol_graph::graph::SymbolGraph;
+    use crate::codegraph::symbol_graph::references::ReferenceKind;
+    use crate::codegraph::symbol_graph::span::Span;
+    use crate::codegraph::symbol_graph::types::SymbolKind;
+
+    const MAIN_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/main.go");
+    const SCOPES_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/scopes.go");
+
+    /// (引用所在符号, 引用处的源码, 种类, 是否推断)
+    fn references(graph: &SymbolGraph, code: &str, qualified_name: &str) -> Vec<(String, String, ReferenceKind, bool)> {
+        let nodes = graph.find_nodes_by_qualified_name(qualified_name);
+        assert_eq!(nodes.len(), 1, "node {}", qualified_name);
+        graph.references_to(&nodes[0].id).iter()
+            .map(|r| {
+                let span = r.span.unwrap();
+                (
+                    graph.get_node(&r.source).unwrap().qualified_name.clone(),
+                    code[span.start_byte..span.end_byte].to_string(),
+                    r.kind,
+                    r.inferred,
+                )
+            })
+            .collect()
+    }
+
+    /// `context` 首次出现处开头 `len` 个字节的位置
+    fn span_of(code: &str, context: &str, len: usize) -> Span {
+        let start = code.find(context).unwrap();
+        Span { start_byte: start, end_byte: start + len, ..Span::default() }
+    }
+
+    #[test]
+    fn references_to_point_test() {
+        let graph = parse_code(MAIN_GO_CODE, &PathBuf::from("/main.go")).unwrap();
+        assert_eq!(references(&graph, MAIN_GO_CODE, "Point"), vec![
+            ("NewPoint".to_string(), "Point".to_string(), ReferenceKind::Type, false),
+            ("NewPoint".to_string(), "Point".to_string(), ReferenceKind::Type, false),
+            ("(*Point).Move".to_string(), "Point".to_string(), ReferenceKind::Type, false),
+            ("main".to_string(), "p".to_string(), ReferenceKind::Type, true),
+        ]);
+        // 第一处是返回值类型，第二处是复合字面量
+        let refs = graph.references_to(&graph.find_nodes_by_name("Point")[0].id);
+        assert_eq!(refs[0].span.unwrap().start_line, 11);
+        assert_eq!(refs[1].span.unwrap().start_line, 12);
+
+        assert_eq!(references(&graph, MAIN_GO_CODE, "NewPoint"), vec![
+            ("main".to_string(), "NewPoint(1, 2)".to_string(), ReferenceKind::Call, false),
+        ]);
+        assert_eq!(references(&graph, MAIN_GO_CODE, "(*Point).Move"), vec![
+            ("main".to_string(), "p.Move(3, 4)".to_string(), ReferenceKind::Call, false),
+        ]);
+    }
+
+    #[test]
+    fn definition_of_test() {
+        let path = PathBuf::from("/main.go");
+        let graph = parse_code(MAIN_GO_CODE, &path).unwrap();
+
+        let definition = graph.definition_of(&path, &span_of(MAIN_GO_CODE, "NewPoint(1", 8)).unwrap();
+        assert_eq!(definition.kind, SymbolKind::Function);
+        assert_eq!(definition.name, "NewPoint");
+
+        let definition = graph.definition_of(&path, &span_of(MAIN_GO_CODE, "Point{X", 5)).unwrap();
+        assert_eq!(definition.kind, SymbolKind::Struct);
+        let definition = graph.definition_of(&path, &span_of(MAIN_GO_CODE, "Move(3", 4)).unwrap();
+        assert_eq!(definition.qualified_name, "(*Point).Move");
+
+        // 声明自身的名称不是引用
+        assert!(graph.definition_of(&path, &span_of(MAIN_GO_CODE, "Point struct", 5)).is_none());
+        assert!(graph.definition_of(&PathBuf::from("/other.go"), &span_of(MAIN_GO_CODE, "NewPoint(1", 8)).is_none());
+    }
+
+    #[test]
+    fn shadowed_type_test() {
+        let path = PathBuf::from("/scopes.go");
+        let graph = parse_code(SCOPES_GO_CODE, &path).unwrap();
+        let configs = graph.find_nodes_by_name("Config");
+        assert_eq!(configs.len(), 2);
+
+        assert_eq!(references(&graph, SCOPES_GO_CODE, "Config"), vec![
+            ("Settings.Main".to_string(), "Config".to_string(), ReferenceKind::Type, false),
+            ("load".to_string(), "Config".to_string(), ReferenceKind::Type, false),
+            ("load".to_string(), "Config".to_string(), ReferenceKind::Type, false),
+            ("defaults".to_string(), "c".to_string(), ReferenceKind::Type, true),
+        ]);
+        assert_eq!(references(&graph, SCOPES_GO_CODE, "override.Config"), vec![
+            ("override".to_string(), "Config".to_string(), ReferenceKind::Type, false),
+            ("override".to_string(), "Config".to_string(), ReferenceKind::Type, false),
+        ]);
+
+        let local = graph.definition_of(&path, &span_of(SCOPES_GO_CODE, "Config{Name: \"local\"}", 6)).unwrap();
+        assert_eq!(local.qualified_name, "override.Config");
+        let global = graph.definition_of(&path, &span_of(SCOPES_GO_CODE, "Config{Name: \"default\"}", 6)).unwrap();
+        assert_eq!(global.qualified_name, "Config");
+    }
+}
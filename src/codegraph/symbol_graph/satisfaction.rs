@@ -0,0 +1,245 @@
+use std::collections::{BTreeSet, HashMap, HashSet};
+use std::path::PathBuf;
+
+use serde_json::json;
+use uuid::Uuid;
+
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::symbol_graph::promotion::package_dir;
+use crate::codegraph::symbol_graph::types::{ReceiverKind, SymbolEdge, SymbolEdgeKind, SymbolKind, SymbolNode};
+use crate::codegraph::treesitter::language_id::LanguageId;
+
+/// 方法名和去掉名称的签名
+type MethodKey = (String, String);
+
+/// 为 Go 结构体添加接口满足边（Satisfies）：类型 -> 方法集包含接口全部方法的接口。
+///
+/// 只在同一个包（同一目录下的 Go 文件）内比较，方法按名称和签名匹配。
+/// `T` 的方法集只包含值接收者的方法，`*T` 的方法集包含全部方法；通过嵌入提升的方法中，
+/// 指针接收者的方法只有在第一层嵌入字段是指针（`*S`）时才属于 `T` 的方法集。
+/// 边的元数据 `receiver` 为 `value` 表示 `T` 满足接口，为 `pointer` 表示只有 `*T` 满足。
+///
+/// 没有方法的接口、嵌入了其他包或无法找到的接口（包括类型约束）的接口不参与比较。
+/// 需要遍历包内所有类型和接口，开销较大，由调用方决定是否执行
+pub fn link_interface_satisfaction(graph: &mut SymbolGraph) {
+    let index = MethodIndex::new(graph);
+    let interfaces = index.interfaces(graph, None);
+    let mut edges = vec![];
+    for node in graph.nodes().filter(|n| n.kind == SymbolKind::Struct && n.language == LanguageId::Go) {
+        let method_sets = index.concrete_method_sets(graph, node);
+        for (interface, methods) in &interfaces {
+            let Some(receiver) = satisfies(&method_sets, node, interface, methods) else { continue };
+            let exists = graph.outgoing_edges(&node.id, Some(SymbolEdgeKind::Satisfies)).iter()
+                .any(|edge| edge.target == interface.id);
+            if !exists {
+                let mut edge = SymbolEdge::new(node.id, interface.id, SymbolEdgeKind::Satisfies);
+                edge.metadata = Some(json!({"receiver": receiver.as_str()}));
+                edges.push(edge);
+            }
+        }
+    }
+    for edge in edges {
+        let _ = graph.add_edge(edge);
+    }
+}
+
+impl SymbolGraph {
+    /// 满足接口的 Go 结构体（`T` 或 `*T` 满足均计入），按插入顺序。
+    /// 与 `link_interface_satisfaction` 规则相同，但每次调用时重新计算，不需要事先添加 Satisfies 边
+    pub fn implementations(&self, interface_id: &Uuid) -> Vec<&SymbolNode> {
+        let Some(interface) = self.get_node(interface_id).filter(|n| n.kind == SymbolKind::Interface) else {
+            return vec![];
+        };
+        let index = MethodIndex::new(self);
+        let Some(methods) = index.interface_method_set(self, interface, &mut HashSet::new()).filter(|m| !m.is_empty()) else {
+            return vec![];
+        };
+        self.nodes()
+            .filter(|n| n.kind == SymbolKind::Struct && n.language == LanguageId::Go)
+            .filter(|n| satisfies(&index.concrete_method_sets(self, n), n, interface, &methods).is_some())
+            .collect()
+    }
+
+    /// 结构体满足的 Go 接口，与 `implementations` 互逆
+    pub fn interfaces(&self, type_id: &Uuid) -> Vec<&SymbolNode> {
+        let Some(node) = self.get_node(type_id).filter(|n| n.kind == SymbolKind::Struct && n.language == LanguageId::Go) else {
+            return vec![];
+        };
+        let index = MethodIndex::new(self);
+        let method_sets = index.concrete_method_sets(self, node);
+        index.interfaces(self, package_dir(node).as_ref()).into_iter()
+            .filter(|(interface, methods)| satisfies(&method_sets, node, interface, methods).is_some())
+            .map(|(interface, _)| interface)
+            .collect()
+    }
+
+    /// 实现接口方法的具体方法：对满足声明该方法的接口的每个结构体，取名称和签名相同的方法
+    /// （自身的方法优先，其次是嵌入提升的方法），按结构体的插入顺序
+    pub fn implementations_of_method(&self, method_id: &Uuid) -> Vec<&SymbolNode> {
+        let Some(method) = self.get_node(method_id).filter(|n| n.kind == SymbolKind::Method) else {
+            return vec![];
+        };
+        let Some(interface) = self.parent_of(method_id).filter(|n| n.kind == SymbolKind::Interface) else {
+            return vec![];
+        };
+        let index = MethodIndex::new(self);
+        let key = method_key(method);
+        self.implementations(&interface.id).into_iter()
+            .filter_map(|node| index.concrete_method(self, node, &key))
+            .collect()
+    }
+}
+
+/// 按包和名称索引 Go 接口和方法
+struct MethodIndex {
+    interfaces_by_name: HashMap<(Option<PathBuf>, String), Vec<Uuid>>,
+    /// (包目录, 接收者类型名) -> 方法
+    methods_by_receiver: HashMap<(Option<PathBuf>, String), Vec<(MethodKey, ReceiverKind, Uuid)>>,
+}
+
+impl MethodIndex {
+    fn new(graph: &SymbolGraph) -> Self {
+        let mut interfaces_by_name: HashMap<(Option<PathBuf>, String), Vec<Uuid>> = HashMap::new();
+        let mut methods_by_receiver: HashMap<(Option<PathBuf>, String), Vec<(MethodKey, ReceiverKind, Uuid)>> = HashMap::new();
+        for node in graph.nodes().filter(|n| n.language == LanguageId::Go) {
+            match node.kind {
+                SymbolKind::Interface => {
+                    interfaces_by_name.entry((package_dir(node), node.name.clone())).or_default().push(node.id);
+                }
+                SymbolKind::Method => {
+                    if let Some((type_name, receiver)) = receiver_of(&node.qualified_name) {
+                        methods_by_receiver.entry((package_dir(node), type_name))
+                            .or_default()
+                            .push((method_key(node), receiver, node.id));
+                    }
+                }
+                _ => {}
+            }
+        }
+        Self { interfaces_by_name, methods_by_receiver }
+    }
+
+    /// 参与比较的接口及其方法集，`dir` 不为 None 时只取该包中的接口
+    fn interfaces<'a>(&self, graph: &'a SymbolGraph, dir: Option<&PathBuf>) -> Vec<(&'a SymbolNode, BTreeSet<MethodKey>)> {
+        let mut interfaces = vec![];
+        for node in graph.nodes().filter(|n| n.kind == SymbolKind::Interface && n.language == LanguageId::Go) {
+            if dir.map_or(false, |dir| package_dir(node).as_ref() != Some(dir)) {
+                continue;
+            }
+            match self.interface_method_set(graph, node, &mut HashSet::new()) {
+                Some(methods) if !methods.is_empty() => interfaces.push((node, methods)),
+                _ => {}
+            }
+        }
+        interfaces
+    }
+
+    /// 接口自身声明的方法加上嵌入接口的方法，存在无法解析的嵌入时返回 None
+    fn interface_method_set(&self, graph: &SymbolGraph, interface: &SymbolNode, visiting: &mut HashSet<Uuid>) -> Option<BTreeSet<MethodKey>> {
+        let mut methods = BTreeSet::new();
+        if !visiting.insert(interface.id) {
+            return Some(methods);
+        }
+        for child in graph.children_of(&interface.id) {
+            if child.kind == SymbolKind::Method {
+                methods.insert(method_key(child));
+            }
+        }
+        let embeds = interface.attributes.get("embeds")
+            .and_then(|e| e.as_array())
+            .cloned()
+            .unwrap_or_default();
+        for embed in embeds {
+            let name = embed.as_str()?;
+            let candidates = self.interfaces_by_name.get(&(package_dir(interface), name.to_string()))?;
+            let embedded = candidates.iter()
+                .filter_map(|id| graph.get_node(id))
+                .find(|n| n.file_path == interface.file_path)
+                .or_else(|| graph.get_node(&candidates[0]))?;
+            methods.extend(self.interface_method_set(graph, embedded, visiting)?);
+        }
+        Some(methods)
+    }
+
+    /// `T` 和 `*T` 的方法集
+    fn concrete_method_sets(&self, graph: &SymbolGraph, node: &SymbolNode) -> (BTreeSet<MethodKey>, BTreeSet<MethodKey>) {
+        let mut value_set = BTreeSet::new();
+        let mut pointer_set = BTreeSet::new();
+        if let Some(methods) = self.methods_by_receiver.get(&(package_dir(node), node.name.clone())) {
+            for (key, receiver, _) in methods {
+                if *receiver == ReceiverKind::Value {
+                    value_set.insert(key.clone());
+                }
+                pointer_set.insert(key.clone());
+            }
+        }
+        for edge in graph.outgoing_edges(&node.id, Some(SymbolEdgeKind::Promotes)) {
+            let method = match graph.get_node(&edge.target) {
+                Some(method) => method,
+                None => continue,
+            };
+            let key = method_key(method);
+            let value_receiver = receiver_of(&method.qualified_name)
+                .map_or(true, |(_, receiver)| receiver == ReceiverKind::Value);
+            let first_field = edge.metadata.as_ref()
+                .and_then(|m| m["via"].as_str())
+                .and_then(|via| via.split('.').next())
+                .unwrap_or_default();
+            let pointer_embedded = graph.children_of(&node.id).iter()
+                .filter(|n| n.kind == SymbolKind::Field && n.name == first_field)
+                .any(|n| n.attributes.get("type").and_then(|t| t.as_str()).map_or(false, |t| t.starts_with('*')));
+            if value_receiver || pointer_embedded {
+                value_set.insert(key.clone());
+            }
+            pointer_set.insert(key);
+        }
+        (value_set, pointer_set)
+    }
+
+    /// 结构体上与 `key` 对应的具体方法：自身的方法优先，其次是提升的方法
+    fn concrete_method<'a>(&self, graph: &'a SymbolGraph, node: &SymbolNode, key: &MethodKey) -> Option<&'a SymbolNode> {
+        let own = self.methods_by_receiver.get(&(package_dir(node), node.name.clone()))
+            .and_then(|methods| methods.iter().find(|(k, _, _)| k == key))
+            .and_then(|(_, _, id)| graph.get_node(id));
+        own.or_else(|| {
+            graph.outgoing_edges(&node.id, Some(SymbolEdgeKind::Promotes)).into_iter()
+                .filter_map(|edge| graph.get_node(&edge.target))
+                .find(|method| method_key(method) == *key)
+        })
+    }
+}
+
+/// 结构体 `node` 是否满足同一包中的接口，返回满足接口的接收者形式
+fn satisfies(
+    (value_set, pointer_set): &(BTreeSet<MethodKey>, BTreeSet<MethodKey>),
+    node: &SymbolNode,
+    interface: &SymbolNode,
+    methods: &BTreeSet<MethodKey>,
+) -> Option<ReceiverKind> {
+    if package_dir(interface) != package_dir(node) {
+        return None;
+    }
+    if methods.is_subset(value_set) {
+        Some(ReceiverKind::Value)
+    } else if methods.is_subset(pointer_set) {
+        Some(ReceiverKind::Pointer)
+    } else {
+        None
+    }
+}
+
+/// 从方法限定名 `(*T).M` / `(T).M` 中取出接收者类型名和接收者形式
+fn receiver_of(qualified_name: &str) -> Option<(String, ReceiverKind)> {
+    let receiver = qualified_name.strip_prefix('(')?.split(')').next()?;
+    match receiver.strip_prefix('*') {
+        Some(type_name) => Some((type_name.to_string(), ReceiverKind::Pointer)),
+        None => Some((receiver.to_string(), ReceiverKind::Value)),
+    }
+}
+
+fn method_key(node: &SymbolNode) -> MethodKey {
+    let signature = node.attributes.get("signature")
+        .and_then(|s| s.as_str())
+        .unwrap_or_default();
+    (node.name.clone(), signature.to_string())
+}
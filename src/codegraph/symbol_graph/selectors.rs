@@ -0,0 +1,258 @@
+use std::collections::{BTreeMap, HashMap};
+use std::path::PathBuf;
+
+use serde_json::json;
+use tree_sitter::Node;
+use uuid::Uuid;
+
+use crate::codegraph::symbol_graph::builder::add_file_node;
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::symbol_graph::normalize::{unresolved_id, IdentifierNormalizer};
+use crate::codegraph::symbol_graph::span::Span;
+use crate::codegraph::symbol_graph::types::{ImportKind, SymbolEdge, SymbolEdgeKind, SymbolKind, SymbolNode};
+use crate::codegraph::treesitter::language_id::LanguageId;
+
+/// 没有别名的导入在文件中绑定的包名：路径的最后一段，跳过主版本后缀（`tools/v2` 绑定为 `tools`）。
+/// 包名与最后一段不同的包（例如 `go-isatty`）无法只从路径得知
+fn default_package_name(path: &str) -> &str {
+    let mut segments = path.rsplit('/');
+    let last = segments.next().unwrap_or(path);
+    let is_major_version = last.len() > 1 && last.starts_with('v') && last[1..].chars().all(|c| c.is_ascii_digit());
+    match segments.next() {
+        Some(parent) if is_major_version => parent,
+        _ => last,
+    }
+}
+
+/// Go 中以导入的包名开头的选择器：`fmt.Println(...)`、`os.Args`、`http.Request`。
+///
+/// 调用已经由 link_calls 指向限定名为选择器路径的占位节点，这里在占位节点上记录 `import_path`；
+/// 其他用法（取值、类型）添加占位节点以及 所在声明 -> 占位节点 的引用边。
+/// 有别名的导入按别名匹配，`.` 和 `_` 导入不绑定名称；
+/// 选择器的左侧是同名的局部变量或参数时不是包选择器
+pub fn link_package_selectors(graph: &mut SymbolGraph, root: &Node, code: &str, file_path: &PathBuf, normalizer: &dyn IdentifierNormalizer) {
+    let mut packages: HashMap<&str, &str> = HashMap::new();
+    for node in graph.nodes() {
+        if node.kind != SymbolKind::Import || &node.file_path != file_path || node.language != LanguageId::Go {
+            continue;
+        }
+        let Some(path) = node.import_path() else { continue };
+        let name = match node.import_kind() {
+            Some(ImportKind::Alias) => node.import_alias().unwrap_or_default(),
+            Some(ImportKind::Plain) => default_package_name(path),
+            _ => continue,
+        };
+        packages.entry(name).or_insert(path);
+    }
+    if packages.is_empty() {
+        return;
+    }
+
+    let mut selectors = vec![];
+    let mut stack = vec![*root];
+    while let Some(node) = stack.pop() {
+        let operands = match node.kind() {
+            "selector_expression" => Some(("operand", "field")),
+            "qualified_type" => Some(("package", "name")),
+            _ => None,
+        };
+        if let Some((operand, field)) = operands {
+            if let (Some(operand), Some(field)) = (node.child_by_field_name(operand), node.child_by_field_name(field)) {
+                let package = &code[operand.byte_range()];
+                if matches!(operand.kind(), "identifier" | "package_identifier") && !is_local(&operand, package, code) {
+                    if let Some(path) = packages.get(package) {
+                        let is_call = node.parent().map_or(false, |p| p.kind() == "call_expression" && p.child_by_field_name("function") == Some(node));
+                        selectors.push((package, &code[field.byte_range()], path.to_string(), Span::from(node.range()), is_call));
+                    }
+                }
+            }
+        }
+        for i in (0..node.child_count()).rev() {
+            stack.push(node.child(i).unwrap());
+        }
+    }
+
+    let mut declarations = graph.nodes()
+        .filter(|n| &n.file_path == file_path)
+        .filter(|n| !matches!(n.kind, SymbolKind::Package | SymbolKind::File | SymbolKind::Import | SymbolKind::Unresolved))
+        .filter(|n| n.kind != SymbolKind::Variable || !graph.parent_of(&n.id).map_or(false, |p| matches!(p.kind, SymbolKind::Function | SymbolKind::Method)))
+        .map(|n| (n.span, n.id))
+        .collect::<Vec<_>>();
+    declarations.sort_by_key(|(span, _)| (span.start_byte, std::cmp::Reverse(span.end_byte)));
+
+    for (package, name, path, span, is_call) in selectors {
+        let qualified_name = format!("{}.{}", package, name);
+        let id = unresolved_id(file_path, LanguageId::Go, &qualified_name, normalizer);
+        let exists = graph.get_node(&id).is_some();
+        if !exists {
+            graph.add_node(SymbolNode {
+                id,
+                kind: SymbolKind::Unresolved,
+                name: name.to_string(),
+                qualified_name,
+                language: LanguageId::Go,
+                file_path: file_path.clone(),
+                span,
+                declaration_span: span,
+                doc: None,
+                attributes: BTreeMap::new(),
+            });
+        }
+        if let Some(&index) = graph.symbol_to_node.get(&id) {
+            graph.graph[index].attributes.insert("import_path".to_string(), json!(path));
+        }
+        if is_call && exists {
+            continue;
+        }
+        let source = declarations.iter().rev()
+            .find(|(declaration, _)| declaration.start_byte <= span.start_byte && declaration.contains(&span))
+            .map(|(_, id)| *id);
+        let source = match source {
+            Some(source) => source,
+            None => add_file_node(graph, file_path, LanguageId::Go),
+        };
+        let mut edge = SymbolEdge::new(source, id, SymbolEdgeKind::References);
+        edge.metadata = Some(json!({"span": span}));
+        let _ = graph.add_edge(edge);
+    }
+}
+
+/// `name` 在 `usage` 处是否指向局部声明：外层语法节点中位于使用之前的
+/// 短变量声明、`var`/`const` 声明、参数和接收者、`range` 和类型 switch 绑定的变量
+fn is_local(usage: &Node, name: &str, code: &str) -> bool {
+    let mut child = *usage;
+    while let Some(parent) = child.parent() {
+        if parent.kind() == "source_file" {
+            break;
+        }
+        if parent.kind() == "type_switch_statement" {
+            if let Some(alias) = parent.child_by_field_name("alias") {
+                if alias.end_byte() <= usage.start_byte() && identifiers(&alias, code).contains(&name) {
+                    return true;
+                }
+            }
+        }
+        for i in 0..parent.child_count() {
+            let sibling = parent.child(i).unwrap();
+            if sibling.id() == child.id() {
+                break;
+            }
+            if declared_names(&sibling, code).contains(&name) {
+                return true;
+            }
+        }
+        child = parent;
+    }
+    false
+}
+
+/// 语法节点直接声明的名称
+fn declared_names<'a>(node: &Node, code: &'a str) -> Vec<&'a str> {
+    let mut names = vec![];
+    let mut stack = vec![*node];
+    while let Some(node) = stack.pop() {
+        match node.kind() {
+            "short_var_declaration" => names.extend(node.child_by_field_name("left").map_or(vec![], |left| identifiers(&left, code))),
+            "range_clause" => {
+                if (0..node.child_count()).any(|i| node.child(i).unwrap().kind() == ":=") {
+                    names.extend(node.child_by_field_name("left").map_or(vec![], |left| identifiers(&left, code)));
+                }
+            }
+            "for_clause" => stack.extend(node.child_by_field_name("initializer")),
+            "var_declaration" | "const_declaration" | "var_spec_list" | "parameter_list" => {
+                stack.extend((0..node.named_child_count()).filter_map(|i| node.named_child(i)));
+            }
+            "var_spec" | "const_spec" | "parameter_declaration" | "variadic_parameter_declaration" => {
+                let mut cursor = node.walk();
+                names.extend(node.children_by_field_name("name", &mut cursor).map(|n| &code[n.byte_range()]));
+            }
+            _ => {}
+        }
+    }
+    names
+}
+
+/// 表达式列表中的标识符：`a, b := ...` 的左侧
+fn identifiers<'a>(list: &Node, code: &'a str) -> Vec<&'a str> {
+    (0..list.named_child_count())
+        .filter_map(|i| list.named_child(i))
+        .filter(|n| n.kind() == "identifier")
+        .map(|n| &code[n.byte_range()])
+        .collect()
+}
+
+impl SymbolGraph {
+    /// 包选择器的占位节点所指的导入，见 [`link_package_selectors`]
+    pub fn import_of(&self, symbol_id: &Uuid) -> Option<&SymbolNode> {
+        let node = self.get_node(symbol_id)?;
+        let path = node.attributes.get("import_path")?.as_str()?;
+        self.nodes().find(|n| n.kind == SymbolKind::Import && n.file_path == node.file_path && n.import_path() == Some(path))
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use std::path::PathBuf;
+
+    use crate::codegraph::symbol_graph::builder::parse_code;
+    use crate::codegraph::symbol_graph::graph::SymbolGraph;
+    use crate::codegraph::symbol_graph::selectors::default_package_name;
+    use crate::codegraph::symbol_graph::types::{SymbolEdgeKind, SymbolKind};
+
+    const MAIN_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/main.go");
+    const IMPORTS_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/imports.go");
+
+    fn import_path(graph: &SymbolGraph, qualified_name: &str) -> Option<String> {
+        let nodes = graph.find_nodes_by_qualified_name(qualified_name);
+        assert_eq!(nodes.len(), 1, "{}", qualified_name);
+        assert_eq!(nodes[0].kind, SymbolKind::Unresolved);
+        graph.import_of(&nodes[0].id).map(|n| n.import_path().unwrap().to_string())
+    }
+
+    #[test]
+    fn package_call_test() {
+        let graph = parse_code(MAIN_GO_CODE, &PathBuf::from("/main.go")).unwrap();
+        let println = graph.find_nodes_by_qualified_name("fmt.Println")[0];
+        let import = graph.import_of(&println.id).unwrap();
+        assert_eq!(import.kind, SymbolKind::Import);
+        assert_eq!(import.import_path(), Some("fmt"));
+        // 调用仍然只有调用边
+        let main = graph.find_nodes_by_name("main")[0];
+        assert_eq!(graph.incoming_edges(&println.id, None).iter().map(|e| (e.source, e.kind)).collect::<Vec<_>>(),
+                   vec![(main.id, SymbolEdgeKind::Calls)]);
+    }
+
+    #[test]
+    fn aliased_and_value_selectors_test() {
+        let graph = parse_code(IMPORTS_GO_CODE, &PathBuf::from("/imports.go")).unwrap();
+        assert_eq!(import_path(&graph, "str.ToUpper").as_deref(), Some("strings"));
+        assert_eq!(import_path(&graph, "json.Valid").as_deref(), Some("encoding/json"));
+        assert_eq!(import_path(&graph, "tools.Run").as_deref(), Some("github.com/acme/tools/v2"));
+        // 取值的选择器记为引用
+        assert_eq!(import_path(&graph, "os.Args").as_deref(), Some("os"));
+        let args = graph.find_nodes_by_qualified_name("os.Args")[0];
+        let main = graph.find_nodes_by_name("main")[0];
+        assert_eq!(graph.incoming_edges(&args.id, Some(SymbolEdgeKind::References))[0].source, main.id);
+    }
+
+    #[test]
+    fn local_shadows_package_test() {
+        let code = "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\ntype printer struct{}\n\n\
+                    func (printer) Println() {}\n\n\
+                    func run(os printer) {\n\tfmt := printer{}\n\tfmt.Println()\n\tos.Println()\n\tfor _, fmt := range []printer{} {\n\t\t_ = fmt.Println\n\t}\n}\n";
+        let graph = parse_code(code, &PathBuf::from("/shadow.go")).unwrap();
+        // fmt 和 os 都是局部变量或参数，不指向导入
+        for name in ["fmt.Println", "os.Println"] {
+            assert!(graph.find_nodes_by_qualified_name(name).iter().all(|n| graph.import_of(&n.id).is_none()), "{}", name);
+        }
+    }
+
+    #[test]
+    fn default_package_name_test() {
+        assert_eq!(default_package_name("fmt"), "fmt");
+        assert_eq!(default_package_name("net/http"), "http");
+        assert_eq!(default_package_name("github.com/acme/tools/v2"), "tools");
+        assert_eq!(default_package_name("example.com/v2"), "example.com");
+        assert_eq!(default_package_name("github.com/acme/dev"), "dev");
+    }
+}
@@ -0,0 +1,289 @@
+use std::collections::{BTreeMap, HashMap};
+use std::path::PathBuf;
+
+use serde_json::json;
+use tree_sitter::Node;
+use uuid::Uuid;
+
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::symbol_graph::normalize::{unresolved_id, IdentifierNormalizer};
+use crate::codegraph::symbol_graph::span::Span;
+use crate::codegraph::symbol_graph::types::{stable_id, SymbolEdge, SymbolEdgeKind, SymbolKind, SymbolNode};
+use crate::codegraph::treesitter::language_id::LanguageId;
+
+/// Go 预声明的类型
+const GO_BUILTIN_TYPES: &[&str] = &[
+    "any", "bool", "byte", "complex64", "complex128", "error", "float32", "float64",
+    "int", "int8", "int16", "int32", "int64", "rune", "string",
+    "uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+];
+
+/// 内置类型节点，不存在时创建。路径为空，合并多个文件的图时每种内置类型只有一个节点
+pub fn add_builtin_node(graph: &mut SymbolGraph, name: &str, language: LanguageId) -> Uuid {
+    let file_path = PathBuf::new();
+    let id = stable_id(&file_path, SymbolKind::Builtin, name, 0, None);
+    graph.add_node(SymbolNode {
+        id,
+        kind: SymbolKind::Builtin,
+        name: name.to_string(),
+        qualified_name: name.to_string(),
+        language,
+        file_path,
+        span: Span::default(),
+        declaration_span: Span::default(),
+        doc: None,
+        attributes: BTreeMap::new(),
+    });
+    id
+}
+
+/// 为 Go 函数和方法添加签名中的类型边：函数 -> 参数类型（ParamType），函数 -> 返回值类型（ReturnType）。
+///
+/// 类型名依次按函数自身的类型参数、接收者类型的类型参数、文件中的顶层类型和内置类型解析；
+/// 都找不到时指向占位节点，由 `resolve_package_references` 在包内其他文件中查找。
+/// 带包名的类型（`time.Duration`）和接收者不产生边。
+/// 每个参数名一条边（`dx, dy int` 两条），边上记录参数名和类型在源码中的位置
+pub fn link_signature_types(graph: &mut SymbolGraph, root: &Node, code: &str, file_path: &PathBuf, normalizer: &dyn IdentifierNormalizer) {
+    let functions = graph.nodes()
+        .filter(|n| &n.file_path == file_path && n.language == LanguageId::Go)
+        .filter(|n| matches!(n.kind, SymbolKind::Function | SymbolKind::Method))
+        .map(|n| (n.span.start_byte, n.id))
+        .collect::<HashMap<_, _>>();
+    if functions.is_empty() {
+        return;
+    }
+    let mut types: HashMap<String, Uuid> = HashMap::new();
+    for node in graph.nodes().filter(|n| &n.file_path == file_path) {
+        let top_level = graph.parent_of(&node.id).map_or(true, |p| matches!(p.kind, SymbolKind::Package | SymbolKind::File));
+        if top_level && matches!(node.kind, SymbolKind::Struct | SymbolKind::Interface | SymbolKind::TypeAlias) {
+            types.entry(node.name.clone()).or_insert(node.id);
+        }
+    }
+
+    let mut edges = vec![];
+    for i in 0..root.child_count() {
+        let declaration = root.child(i).unwrap();
+        if !matches!(declaration.kind(), "function_declaration" | "method_declaration") {
+            continue;
+        }
+        let function_id = match functions.get(&declaration.start_byte()) {
+            Some(id) => *id,
+            None => continue,
+        };
+        let scope = type_parameter_scope(graph, &function_id, &declaration, code);
+        let resolve = |graph: &mut SymbolGraph, name: &Node| -> Uuid {
+            let text = &code[name.byte_range()];
+            if let Some(id) = scope.get(text).or_else(|| types.get(text)) {
+                return *id;
+            }
+            if GO_BUILTIN_TYPES.contains(&text) {
+                return add_builtin_node(graph, text, LanguageId::Go);
+            }
+            add_unresolved_node(graph, text, file_path, Span::from(&name.range()), normalizer)
+        };
+
+        if let Some(parameters) = declaration.child_by_field_name("parameters") {
+            for parameter in named_children(&parameters) {
+                let type_ = match parameter.child_by_field_name("type") {
+                    Some(type_) => type_,
+                    None => continue,
+                };
+                let mut cursor = parameter.walk();
+                let names = parameter.children_by_field_name("name", &mut cursor)
+                    .map(|name| code[name.byte_range()].to_string())
+                    .collect::<Vec<_>>();
+                for type_name in type_names(&type_) {
+                    let target = resolve(graph, &type_name);
+                    let span = Span::from(&type_name.range());
+                    if names.is_empty() {
+                        edges.push(signature_edge(function_id, target, SymbolEdgeKind::ParamType, json!({"span": span})));
+                    }
+                    for name in &names {
+                        edges.push(signature_edge(function_id, target, SymbolEdgeKind::ParamType, json!({"param": name, "span": span})));
+                    }
+                }
+            }
+        }
+        if let Some(result) = declaration.child_by_field_name("result") {
+            for type_name in type_names(&result) {
+                let target = resolve(graph, &type_name);
+                let span = Span::from(&type_name.range());
+                edges.push(signature_edge(function_id, target, SymbolEdgeKind::ReturnType, json!({"span": span})));
+            }
+        }
+    }
+    for edge in edges {
+        let _ = graph.add_edge(edge);
+    }
+}
+
+fn signature_edge(source: Uuid, target: Uuid, kind: SymbolEdgeKind, metadata: serde_json::Value) -> SymbolEdge {
+    let mut edge = SymbolEdge::new(source, target, kind);
+    edge.metadata = Some(metadata);
+    edge
+}
+
+/// 签名中可见的类型参数：函数自身的，以及方法接收者 `(s *Stack[E])` 按位置对应到类型声明的类型参数
+fn type_parameter_scope(graph: &SymbolGraph, function_id: &Uuid, declaration: &Node, code: &str) -> HashMap<String, Uuid> {
+    let mut scope = HashMap::new();
+    for param in graph.children_of(function_id).into_iter().filter(|n| n.kind == SymbolKind::TypeParameter) {
+        scope.insert(param.name.clone(), param.id);
+    }
+    let receiver_type = match graph.outgoing_edges(function_id, Some(SymbolEdgeKind::MethodOf)).first() {
+        Some(edge) => edge.target,
+        None => return scope,
+    };
+    let type_arguments = declaration.child_by_field_name("receiver")
+        .and_then(|receiver| named_children(&receiver).into_iter().next())
+        .and_then(|parameter| parameter.child_by_field_name("type"))
+        .and_then(|type_| generic_arguments(&type_));
+    if let Some(type_arguments) = type_arguments {
+        let params = graph.children_of(&receiver_type).into_iter()
+            .filter(|n| n.kind == SymbolKind::TypeParameter)
+            .collect::<Vec<_>>();
+        for (name, param) in type_names(&type_arguments).into_iter().zip(params) {
+            scope.entry(code[name.byte_range()].to_string()).or_insert(param.id);
+        }
+    }
+    scope
+}
+
+/// 接收者类型 `Stack[T]`、`*Stack[T]` 的类型实参列表
+fn generic_arguments<'tree>(type_: &Node<'tree>) -> Option<Node<'tree>> {
+    let mut node = *type_;
+    loop {
+        node = match node.kind() {
+            "pointer_type" => node.named_child(0)?,
+            "generic_type" => return node.child_by_field_name("type_arguments"),
+            _ => return None,
+        };
+    }
+}
+
+/// 类型表达式中出现的类型名，按源码顺序：`map[string][]*Point` 中的 `string` 和 `Point`。
+/// 带包名的类型不算
+fn type_names<'tree>(type_: &Node<'tree>) -> Vec<Node<'tree>> {
+    let mut names = vec![];
+    let mut stack = vec![*type_];
+    while let Some(node) = stack.pop() {
+        match node.kind() {
+            "type_identifier" => names.push(node),
+            "qualified_type" => {}
+            _ => {
+                for i in (0..node.named_child_count()).rev() {
+                    stack.push(node.named_child(i).unwrap());
+                }
+            }
+        }
+    }
+    names
+}
+
+fn named_children<'tree>(node: &Node<'tree>) -> Vec<Node<'tree>> {
+    (0..node.named_child_count()).filter_map(|i| node.named_child(i)).collect()
+}
+
+/// 文件中的占位节点，与未解析的调用共用同名节点
+fn add_unresolved_node(graph: &mut SymbolGraph, name: &str, file_path: &PathBuf, span: Span, normalizer: &dyn IdentifierNormalizer) -> Uuid {
+    let id = unresolved_id(file_path, LanguageId::Go, name, normalizer);
+    graph.add_node(SymbolNode {
+        id,
+        kind: SymbolKind::Unresolved,
+        name: name.to_string(),
+        qualified_name: name.to_string(),
+        language: LanguageId::Go,
+        file_path: file_path.clone(),
+        span,
+        declaration_span: span,
+        doc: None,
+        attributes: BTreeMap::new(),
+    });
+    id
+}
+
+#[cfg(test)]
+mod tests {
+    use std::fs;
+    use std::path::PathBuf;
+
+    use crate::codegraph::symbol_graph::builder::parse_code;
+    use crate::codegraph::symbol_graph::dir::{parse_dir, ParseOptions};
+    use crate::codegraph::symbol_graph::graph::SymbolGraph;
+    use crate::codegraph::symbol_graph::types::{SymbolEdgeKind, SymbolKind};
+
+    const MAIN_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/main.go");
+    const GENERICS_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/generics.go");
+    const CALLS_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/calls.go");
+
+    /// (参数名, 类型限定名, 类型节点类型)，按插入顺序
+    fn signature_types(graph: &SymbolGraph, qualified_name: &str, kind: SymbolEdgeKind) -> Vec<(String, String, SymbolKind)> {
+        let function = graph.find_nodes_by_qualified_name(qualified_name);
+        assert_eq!(function.len(), 1, "function {}", qualified_name);
+        graph.outgoing_edges(&function[0].id, Some(kind)).iter()
+            .map(|edge| {
+                let target = graph.get_node(&edge.target).unwrap();
+                let param = edge.metadata.as_ref().and_then(|m| m["param"].as_str()).unwrap_or_default();
+                (param.to_string(), target.qualified_name.clone(), target.kind)
+            })
+            .collect()
+    }
+
+    fn t(param: &str, type_name: &str, kind: SymbolKind) -> (String, String, SymbolKind) {
+        (param.to_string(), type_name.to_string(), kind)
+    }
+
+    #[test]
+    fn params_and_results_test() {
+        let graph = parse_code(MAIN_GO_CODE, &PathBuf::from("/main.go")).unwrap();
+        assert_eq!(signature_types(&graph, "NewPoint", SymbolEdgeKind::ReturnType), vec![t("", "Point", SymbolKind::Struct)]);
+        assert_eq!(signature_types(&graph, "NewPoint", SymbolEdgeKind::ParamType), vec![
+            t("x", "int", SymbolKind::Builtin),
+            t("y", "int", SymbolKind::Builtin),
+        ]);
+        assert_eq!(signature_types(&graph, "(*Point).Move", SymbolEdgeKind::ParamType).len(), 2);
+        // 接收者不是参数
+        assert!(signature_types(&graph, "(*Point).Move", SymbolEdgeKind::ReturnType).is_empty());
+        assert!(signature_types(&graph, "main", SymbolEdgeKind::ParamType).is_empty());
+        // 内置类型只有一个节点
+        assert_eq!(graph.nodes_of_kind(SymbolKind::Builtin).len(), 1);
+
+        // 影响分析：签名中使用 Point 的函数
+        let point = graph.find_nodes_by_qualified_name("Point")[0];
+        let users = graph.incoming_edges(&point.id, Some(SymbolEdgeKind::ReturnType)).iter()
+            .map(|e| graph.get_node(&e.source).unwrap().qualified_name.clone())
+            .collect::<Vec<_>>();
+        assert_eq!(users, vec!["NewPoint"]);
+    }
+
+    #[test]
+    fn generic_signatures_test() {
+        let graph = parse_code(GENERICS_GO_CODE, &PathBuf::from("/generics.go")).unwrap();
+        assert_eq!(signature_types(&graph, "Map", SymbolEdgeKind::ParamType), vec![
+            t("xs", "Map.T", SymbolKind::TypeParameter),
+            t("f", "Map.T", SymbolKind::TypeParameter),
+            t("f", "Map.U", SymbolKind::TypeParameter),
+        ]);
+        assert_eq!(signature_types(&graph, "Map", SymbolEdgeKind::ReturnType), vec![t("", "Map.U", SymbolKind::TypeParameter)]);
+        // 方法签名中的 T 是接收者类型的类型参数
+        assert_eq!(signature_types(&graph, "(*Stack).Push", SymbolEdgeKind::ParamType), vec![t("v", "Stack.T", SymbolKind::TypeParameter)]);
+        assert_eq!(signature_types(&graph, "(*Stack).Pop", SymbolEdgeKind::ReturnType), vec![t("", "Stack.T", SymbolKind::TypeParameter)]);
+    }
+
+    #[test]
+    fn unresolved_and_package_types_test() {
+        let graph = parse_code(CALLS_GO_CODE, &PathBuf::from("/calls.go")).unwrap();
+        assert_eq!(signature_types(&graph, "run", SymbolEdgeKind::ParamType), vec![
+            t("other", "Counter", SymbolKind::Struct),
+            t("unknown", "Thing", SymbolKind::Unresolved),
+        ]);
+
+        // 同一个包的其他文件中声明的类型
+        let dir = tempfile::tempdir().unwrap();
+        fs::write(dir.path().join("calls.go"), CALLS_GO_CODE).unwrap();
+        fs::write(dir.path().join("thing.go"), "package main\n\ntype Thing interface {\n\tDo()\n}\n").unwrap();
+        let (graph, errors) = parse_dir(dir.path(), &ParseOptions::default()).unwrap();
+        assert!(errors.is_empty(), "{:?}", errors);
+        assert_eq!(signature_types(&graph, "run", SymbolEdgeKind::ParamType)[1], t("unknown", "Thing", SymbolKind::Interface));
+        assert!(graph.find_nodes_by_name("Thing").iter().all(|n| n.kind != SymbolKind::Unresolved));
+    }
+}
@@ -0,0 +1,137 @@
+use serde::{Deserialize, Serialize};
+use tree_sitter::{Point, Range};
+
+/// 列号的计算方式
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize, Deserialize)]
+pub enum ColumnEncoding {
+    /// UTF-8 字节列（tree-sitter 的默认值）
+    Utf8,
+    /// UTF-16 码元列，LSP 客户端使用
+    Utf16,
+}
+
+impl Default for ColumnEncoding {
+    fn default() -> Self {
+        ColumnEncoding::Utf8
+    }
+}
+
+/// 源码位置，字节偏移以及从0开始的行列号
+#[derive(Debug, Clone, Copy, PartialEq, Eq, PartialOrd, Ord, Hash, Default, Serialize, Deserialize)]
+pub struct Span {
+    pub start_byte: usize,
+    pub end_byte: usize,
+    pub start_line: usize,
+    pub start_column: usize,
+    pub end_line: usize,
+    pub end_column: usize,
+}
+
+impl From<&Range> for Span {
+    fn from(range: &Range) -> Self {
+        Self {
+            start_byte: range.start_byte,
+            end_byte: range.end_byte,
+            start_line: range.start_point.row,
+            start_column: range.start_point.column,
+            end_line: range.end_point.row,
+            end_column: range.end_point.column,
+        }
+    }
+}
+
+impl From<Range> for Span {
+    fn from(range: Range) -> Self {
+        Span::from(&range)
+    }
+}
+
+impl From<&Span> for Range {
+    fn from(span: &Span) -> Self {
+        Range {
+            start_byte: span.start_byte,
+            end_byte: span.end_byte,
+            start_point: Point { row: span.start_line, column: span.start_column },
+            end_point: Point { row: span.end_line, column: span.end_column },
+        }
+    }
+}
+
+impl Span {
+    /// 是否包含另一个位置
+    pub fn contains(&self, other: &Span) -> bool {
+        self.start_byte <= other.start_byte && other.end_byte <= self.end_byte
+    }
+
+    /// 是否包含字节偏移
+    pub fn contains_byte(&self, byte: usize) -> bool {
+        self.start_byte <= byte && byte < self.end_byte
+    }
+
+    pub fn len(&self) -> usize {
+        self.end_byte.saturating_sub(self.start_byte)
+    }
+
+    /// 按指定方式重新计算列号，`code` 必须是生成该位置的源码
+    pub fn with_encoding(&self, code: &str, encoding: ColumnEncoding) -> Span {
+        match encoding {
+            ColumnEncoding::Utf8 => *self,
+            ColumnEncoding::Utf16 => Span {
+                start_column: utf16_column(code, self.start_byte, self.start_column),
+                end_column: utf16_column(code, self.end_byte, self.end_column),
+                ..*self
+            },
+        }
+    }
+}
+
+/// 把字节列转换为 UTF-16 列：行首到该位置之间的文本按 UTF-16 编码的长度
+fn utf16_column(code: &str, byte: usize, byte_column: usize) -> usize {
+    let line_start = byte.saturating_sub(byte_column);
+    match code.get(line_start..byte) {
+        Some(prefix) => prefix.encode_utf16().count(),
+        None => byte_column,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use std::path::PathBuf;
+
+    use crate::codegraph::symbol_graph::builder::parse_code;
+    use crate::codegraph::symbol_graph::span::{ColumnEncoding, Span};
+
+    const MAIN_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/main.go");
+
+    #[test]
+    fn function_span_test() {
+        let graph = parse_code(MAIN_GO_CODE, &PathBuf::from("/main.go")).unwrap();
+        let new_point = graph.find_nodes_by_name("NewPoint");
+        assert_eq!(new_point.len(), 1);
+        assert_eq!(new_point[0].span, Span {
+            start_byte: 70,
+            end_byte: 133,
+            start_line: 11,
+            start_column: 0,
+            end_line: 13,
+            end_column: 1,
+        });
+        assert_eq!(&MAIN_GO_CODE[70..83], "func NewPoint");
+        assert_eq!(new_point[0].span.with_encoding(MAIN_GO_CODE, ColumnEncoding::Utf16), new_point[0].span);
+    }
+
+    #[test]
+    fn multibyte_columns_test() {
+        let code = "package main\n\ntype T struct { 名前 string; Größe int }\n";
+        let graph = parse_code(code, &PathBuf::from("/t.go")).unwrap();
+        let field = graph.find_nodes_by_name("Größe");
+        assert_eq!(field.len(), 1);
+        let span = field[0].span;
+        assert_eq!(&code[span.start_byte..span.end_byte], "Größe int");
+        assert_eq!((span.start_line, span.start_column, span.end_column), (2, 31, 42));
+
+        let utf16 = span.with_encoding(code, ColumnEncoding::Utf16);
+        assert_eq!((utf16.start_line, utf16.start_column, utf16.end_column), (2, 27, 36));
+        assert_eq!((utf16.start_byte, utf16.end_byte), (span.start_byte, span.end_byte));
+    }
+}
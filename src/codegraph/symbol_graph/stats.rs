@@ -0,0 +1,125 @@
+use std::collections::{BTreeMap, HashSet};
+use std::path::PathBuf;
+
+use serde::{Deserialize, Serialize};
+use uuid::Uuid;
+
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::symbol_graph::types::SymbolKind;
+
+/// 函数体最长的函数或方法
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct FunctionSize {
+    pub id: Uuid,
+    pub qualified_name: String,
+    pub file_path: PathBuf,
+    /// 声明头部之后到声明结束的字节数
+    pub body_bytes: usize,
+    /// 整个声明所占的行数
+    pub lines: usize,
+}
+
+/// 符号图的统计信息，可以序列化为 JSON 上报监控
+#[derive(Debug, Clone, PartialEq, Default, Serialize, Deserialize)]
+pub struct GraphStats {
+    pub nodes: usize,
+    pub edges: usize,
+    /// 种类名称 -> 节点数，没有节点的种类不出现
+    pub nodes_by_kind: BTreeMap<String, usize>,
+    /// 种类名称 -> 边数，没有边的种类不出现
+    pub edges_by_kind: BTreeMap<String, usize>,
+    /// 有声明的不同文件数，不包括包节点所在的目录
+    pub files: usize,
+    pub packages: usize,
+    /// 空图或没有函数时为 None，函数体一样长时取先加入的
+    pub largest_function: Option<FunctionSize>,
+    /// 语法错误数（见 `SymbolGraph::syntax_errors`）
+    pub parse_errors: usize,
+}
+
+impl SymbolGraph {
+    /// 统计节点、边、文件和包的数量，节点和边各遍历一次
+    pub fn stats(&self) -> GraphStats {
+        let mut stats = GraphStats {
+            nodes: self.node_count(),
+            parse_errors: self.syntax_errors.len(),
+            ..Default::default()
+        };
+        let mut files: HashSet<&PathBuf> = HashSet::new();
+        for node in self.nodes() {
+            *stats.nodes_by_kind.entry(node.kind.to_string()).or_insert(0) += 1;
+            // 包节点的路径是目录，内置类型和占位节点不是文件中的声明
+            if !matches!(node.kind, SymbolKind::Package | SymbolKind::Builtin | SymbolKind::Unresolved) {
+                files.insert(&node.file_path);
+            }
+            if node.kind == SymbolKind::Package {
+                stats.packages += 1;
+            }
+            if matches!(node.kind, SymbolKind::Function | SymbolKind::Method) {
+                let body_bytes = node.span.end_byte.saturating_sub(node.declaration_span.end_byte);
+                if stats.largest_function.as_ref().map_or(true, |largest| body_bytes > largest.body_bytes) {
+                    stats.largest_function = Some(FunctionSize {
+                        id: node.id,
+                        qualified_name: node.qualified_name.clone(),
+                        file_path: node.file_path.clone(),
+                        body_bytes,
+                        lines: node.span.end_line - node.span.start_line + 1,
+                    });
+                }
+            }
+        }
+        stats.files = files.len();
+        for edge in self.edges() {
+            *stats.edges_by_kind.entry(edge.kind.to_string()).or_insert(0) += 1;
+            stats.edges += 1;
+        }
+        stats
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use std::path::PathBuf;
+
+    use crate::codegraph::symbol_graph::builder::parse_code;
+    use crate::codegraph::symbol_graph::graph::SymbolGraph;
+    use crate::codegraph::symbol_graph::stats::GraphStats;
+
+    const MAIN_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/main.go");
+    const SHAPE_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/shape.go");
+
+    #[test]
+    fn fixtures_stats_test() {
+        let mut graph = parse_code(MAIN_GO_CODE, &PathBuf::from("/main.go")).unwrap();
+        graph.merge(&parse_code(SHAPE_GO_CODE, &PathBuf::from("/shape.go")).unwrap());
+        let stats = graph.stats();
+
+        let count = |kind: &str| stats.nodes_by_kind.get(kind).copied().unwrap_or(0);
+        assert_eq!(count("Struct"), 3);
+        assert_eq!(count("Function"), 2);
+        assert_eq!(count("Method"), 3);
+        assert_eq!(count("Field"), 5);
+        assert_eq!(count("Interface"), 0);
+        assert_eq!(stats.nodes_by_kind.values().sum::<usize>(), graph.node_count());
+        assert_eq!(stats.edges, graph.edges().count());
+        assert_eq!(stats.edges_by_kind.values().sum::<usize>(), stats.edges);
+        assert_eq!(stats.edges_by_kind["MethodOf"], 3);
+        // 同一目录中的两个文件属于同一个包
+        assert_eq!((stats.files, stats.packages, stats.parse_errors), (2, 1, 0));
+
+        let largest = stats.largest_function.as_ref().unwrap();
+        assert_eq!(largest.qualified_name, "main");
+        assert_eq!(largest.file_path, PathBuf::from("/main.go"));
+        assert_eq!(largest.lines, 5);
+
+        let json = serde_json::to_string(&stats).unwrap();
+        assert_eq!(serde_json::from_str::<GraphStats>(&json).unwrap(), stats);
+    }
+
+    #[test]
+    fn empty_graph_stats_test() {
+        let stats = SymbolGraph::new().stats();
+        assert_eq!(stats, GraphStats::default());
+        assert!(stats.largest_function.is_none());
+    }
+}
@@ -0,0 +1,213 @@
+use std::collections::{HashMap, HashSet};
+use std::path::PathBuf;
+
+use serde_json::json;
+use uuid::Uuid;
+
+use crate::codegraph::symbol_graph::builder::{link_source, ParseContext};
+use crate::codegraph::symbol_graph::complexity::record_complexity;
+use crate::codegraph::symbol_graph::diff::record_body_hashes;
+use crate::codegraph::symbol_graph::docs::attach_doc_comments;
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::symbol_graph::incremental::top_level_nodes;
+use crate::codegraph::symbol_graph::types::{SymbolEdge, SymbolKind, SymbolNode};
+use crate::codegraph::treesitter::parsers::{get_ast_parser_by_filename, ParserError};
+
+/// 流式解析一个文件：每个顶层语法节点提取完后立即通过 `emit` 交出其中的声明节点，
+/// 不等整个文件解析完成。需要看到整个文件才能确定的内容最后一次性交给 `flush`：
+/// 包节点、文件节点、占位节点以及所有的边（调用、引用等可能指向后面的声明）。
+///
+/// 交出的节点ID与 `parse_code` 的结果相同，`emit` 和 `flush` 收到的节点合起来就是
+/// `parse_code` 的全部节点。任何一个回调返回错误时停止解析并返回该错误
+pub fn parse_stream<E, F>(code: &str, path: &PathBuf, emit: E, flush: F) -> Result<(), ParserError>
+where
+    E: FnMut(SymbolNode) -> Result<(), ParserError>,
+    F: FnOnce(Vec<SymbolNode>, Vec<SymbolEdge>) -> Result<(), ParserError>,
+{
+    parse_stream_with_context(code, path, &ParseContext::default(), emit, flush)
+}
+
+/// 与 `parse_stream` 相同，使用调用方给出的上下文；节点ID与同一上下文下
+/// `parse_code_with_context` 的结果相同，可以和 `parse_dir` 的结果合并
+pub fn parse_stream_with_context<E, F>(code: &str, path: &PathBuf, context: &ParseContext, mut emit: E, flush: F) -> Result<(), ParserError>
+where
+    E: FnMut(SymbolNode) -> Result<(), ParserError>,
+    F: FnOnce(Vec<SymbolNode>, Vec<SymbolEdge>) -> Result<(), ParserError>,
+{
+    let (mut parser, _language_id) = get_ast_parser_by_filename(path)?;
+    let tree = parser.parse_tree(code, None)
+        .ok_or_else(|| ParserError { message: format!("Failed to parse {}", path.display()) })?;
+    let root = tree.root_node();
+
+    let mut symbols = vec![];
+    let mut emitted: HashSet<Uuid> = HashSet::new();
+    // 单个顶层节点内的同名序号从0开始，按整个文件重新编号
+    let mut occurrences: HashMap<(SymbolKind, String, Option<String>), usize> = HashMap::new();
+    for node in top_level_nodes(&root) {
+        let unit_symbols = parser.parse_top_level(&[node], code, path);
+        if unit_symbols.is_empty() {
+            continue;
+        }
+        let mut unit = SymbolGraph::from_symbols_with_context(&unit_symbols, context);
+        attach_doc_comments(&mut unit, code, path);
+        record_body_hashes(&mut unit, code, path);
+        record_complexity(&mut unit, &node, code, path);
+        symbols.extend(unit_symbols);
+
+        for mut symbol in unit.graph.node_weights().cloned() {
+            if matches!(symbol.kind, SymbolKind::Package | SymbolKind::File | SymbolKind::Unresolved) {
+                continue;
+            }
+            if symbol.kind != SymbolKind::TypeParameter {
+                reindex(&mut symbol, &mut occurrences);
+            }
+            if emitted.insert(symbol.id) {
+                emit(symbol)?;
+            }
+        }
+    }
+
+    let mut graph = SymbolGraph::from_symbols_with_context(&symbols, context);
+    drop(symbols);
+    link_source(&mut graph, &root, code, path, context);
+    let remaining = graph.nodes().filter(|n| !emitted.contains(&n.id)).cloned().collect();
+    let edges = graph.edges().cloned().collect();
+    flush(remaining, edges)
+}
+
+fn reindex(symbol: &mut SymbolNode, occurrences: &mut HashMap<(SymbolKind, String, Option<String>), usize>) {
+    let signature = symbol.attributes.get("signature").and_then(|s| s.as_str()).map(|s| s.to_string());
+    let occurrence = occurrences.entry((symbol.kind, symbol.qualified_name.clone(), signature)).or_insert(0);
+    if *occurrence > 0 {
+        symbol.attributes.insert("index".to_string(), json!(*occurrence));
+    } else {
+        symbol.attributes.remove("index");
+    }
+    *occurrence += 1;
+    symbol.id = symbol.stable_id();
+}
+
+#[cfg(test)]
+mod tests {
+    use std::collections::BTreeSet;
+    use std::path::PathBuf;
+    use std::sync::Arc;
+
+    use crate::codegraph::symbol_graph::builder::{parse_code, parse_code_with_context, ParseContext};
+    use crate::codegraph::symbol_graph::normalize::CaseInsensitiveNormalizer;
+    use crate::codegraph::symbol_graph::packages::GoModule;
+    use crate::codegraph::symbol_graph::stream::{parse_stream, parse_stream_with_context};
+    use crate::codegraph::symbol_graph::types::SymbolKind;
+    use crate::codegraph::treesitter::language_id::LanguageId;
+    use crate::codegraph::treesitter::parsers::ParserError;
+
+    const MAIN_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/main.go");
+
+    fn large_go_file(functions: usize) -> String {
+        let mut code = String::from("package main\n\ntype Counter struct {\n\tn int\n}\n\nfunc (c *Counter) Inc() {\n\tc.n++\n}\n");
+        for i in 0..functions {
+            code.push_str(&format!("\n// f{i} increments twice\nfunc f{i}(c *Counter) int {{\n\tc.Inc()\n\tc.Inc()\n\treturn c.n + {i}\n}}\n"));
+        }
+        code
+    }
+
+    #[test]
+    fn stream_large_file_test() {
+        let functions = 2000;
+        let code = large_go_file(functions);
+        let path = PathBuf::from("/large.go");
+        let mut emitted = 0;
+        let mut functions_seen = 0;
+        let mut flushed_edges = 0;
+        parse_stream(&code, &path, |node| {
+            emitted += 1;
+            if node.kind == SymbolKind::Function {
+                // 按源码顺序逐个交出
+                assert_eq!(node.name, format!("f{}", functions_seen));
+                assert_eq!(node.doc, Some(format!("f{} increments twice", functions_seen)));
+                functions_seen += 1;
+            }
+            Ok(())
+        }, |_nodes, edges| {
+            // 所有声明都已经交出
+            assert_eq!(functions_seen, functions);
+            flushed_edges = edges.len();
+            Ok(())
+        }).unwrap();
+        // Counter、Counter.n、(*Counter).Inc 和每个函数
+        assert_eq!(emitted, functions + 3);
+        assert_eq!(flushed_edges, parse_code(&code, &path).unwrap().edge_count());
+    }
+
+    #[test]
+    fn stream_matches_full_parse_test() {
+        let path = PathBuf::from("/main.go");
+        let code = format!("{}\nfunc helper() {{}}\n\nfunc helper() {{}}\n", MAIN_GO_CODE);
+        let full = parse_code(&code, &path).unwrap();
+        let mut nodes = vec![];
+        let mut edges = vec![];
+        parse_stream(&code, &path, |node| {
+            nodes.push(node);
+            Ok(())
+        }, |rest, all_edges| {
+            nodes.extend(rest);
+            edges = all_edges;
+            Ok(())
+        }).unwrap();
+
+        let ids = nodes.iter().map(|n| n.id).collect::<BTreeSet<_>>();
+        assert_eq!(ids.len(), nodes.len());
+        assert_eq!(ids, full.nodes().map(|n| n.id).collect::<BTreeSet<_>>());
+        for node in &nodes {
+            assert_eq!(node.stable_id(), node.id, "{}", node.qualified_name);
+        }
+        let edge_set = |edges: Vec<(uuid::Uuid, uuid::Uuid, String)>| edges.into_iter().collect::<BTreeSet<_>>();
+        assert_eq!(
+            edge_set(edges.iter().map(|e| (e.source, e.target, e.kind.to_string())).collect()),
+            edge_set(full.edges().map(|e| (e.source, e.target, e.kind.to_string())).collect()),
+        );
+    }
+
+    #[test]
+    fn stream_with_context_test() {
+        let path = PathBuf::from("/main.go");
+        let code = "package main\n\nfunc main() {\n\tHelper()\n\tHELPER()\n}\n";
+        let context = ParseContext {
+            go_module: Some(GoModule { root: PathBuf::from("/"), path: "example.com/shapes".to_string() }),
+            normalizer: Some(Arc::new(CaseInsensitiveNormalizer { languages: vec![LanguageId::Go] })),
+        };
+        let full = parse_code_with_context(code, &path, &context).unwrap();
+        let mut nodes = vec![];
+        parse_stream_with_context(code, &path, &context, |node| {
+            nodes.push(node);
+            Ok(())
+        }, |rest, _edges| {
+            nodes.extend(rest);
+            Ok(())
+        }).unwrap();
+
+        // 两次调用指向同一个占位节点，包节点的限定名是导入路径
+        assert_eq!(nodes.iter().filter(|n| n.kind == SymbolKind::Unresolved).count(), 1);
+        let package = nodes.iter().find(|n| n.kind == SymbolKind::Package).unwrap();
+        assert_eq!(package.qualified_name, "example.com/shapes");
+        assert_eq!(
+            nodes.iter().map(|n| n.id).collect::<BTreeSet<_>>(),
+            full.nodes().map(|n| n.id).collect::<BTreeSet<_>>(),
+        );
+    }
+
+    #[test]
+    fn emit_error_stops_stream_test() {
+        let code = large_go_file(100);
+        let mut emitted = 0;
+        let result = parse_stream(&code, &PathBuf::from("/large.go"), |_node| {
+            emitted += 1;
+            if emitted == 10 {
+                return Err(ParserError { message: "consumer closed".to_string() });
+            }
+            Ok(())
+        }, |_nodes, _edges| panic!("flush after emit error"));
+        assert_eq!(result.unwrap_err().message, "consumer closed");
+        assert_eq!(emitted, 10);
+    }
+}
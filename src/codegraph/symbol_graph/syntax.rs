@@ -0,0 +1,124 @@
+use std::path::PathBuf;
+
+use serde::{Deserialize, Serialize};
+use tree_sitter::Node;
+
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::symbol_graph::span::Span;
+
+/// 错误信息中引用的源码最多保留的字符数
+const MAX_SNIPPET_CHARS: usize = 32;
+
+/// 语法树中的一处错误：ERROR 节点（无法解析的源码）或 MISSING 节点（解析器补上的缺失记号）
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+pub struct SyntaxError {
+    pub file_path: PathBuf,
+    /// MISSING 节点的范围长度为 0，位于缺失记号应该出现的位置
+    pub span: Span,
+    pub message: String,
+}
+
+/// 按源码顺序收集语法树中的错误，没有错误的子树不展开；ERROR 节点内部不再单独报告
+pub fn collect_syntax_errors(root: &Node, code: &str, file_path: &PathBuf) -> Vec<SyntaxError> {
+    let mut errors = vec![];
+    let mut stack = vec![*root];
+    while let Some(node) = stack.pop() {
+        if node.is_error() {
+            errors.push(SyntaxError {
+                file_path: file_path.clone(),
+                span: Span::from(node.range()),
+                message: format!("Unexpected `{}`", snippet(&code[node.byte_range()])),
+            });
+            continue;
+        }
+        if node.is_missing() {
+            errors.push(SyntaxError {
+                file_path: file_path.clone(),
+                span: Span::from(node.range()),
+                message: format!("Missing `{}`", node.kind()),
+            });
+            continue;
+        }
+        if !node.has_error() {
+            continue;
+        }
+        for i in (0..node.child_count()).rev() {
+            stack.push(node.child(i).unwrap());
+        }
+    }
+    errors
+}
+
+/// 用该文件的语法错误替换图中原有的记录
+pub fn record_syntax_errors(graph: &mut SymbolGraph, root: &Node, code: &str, file_path: &PathBuf) {
+    graph.syntax_errors.retain(|e| &e.file_path != file_path);
+    graph.syntax_errors.extend(collect_syntax_errors(root, code, file_path));
+}
+
+/// 第一行去掉首尾空白，过长时截断
+fn snippet(text: &str) -> String {
+    let line = text.trim().lines().next().unwrap_or("").trim_end();
+    match line.char_indices().nth(MAX_SNIPPET_CHARS) {
+        Some((end, _)) => format!("{}...", &line[..end]),
+        None => line.to_string(),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use std::fs;
+    use std::path::PathBuf;
+
+    use crate::codegraph::symbol_graph::builder::parse_code;
+    use crate::codegraph::symbol_graph::dir::{parse_dir, ParseOptions};
+    use crate::codegraph::symbol_graph::graph::SymbolGraph;
+    use crate::codegraph::symbol_graph::types::SymbolKind;
+
+    const MAIN_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/main.go");
+    const UNBALANCED_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/unbalanced.go");
+    const SPLIT_GEOMETRY_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/split_geometry.go");
+    const SPLIT_RESIZE_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/split_resize.go");
+
+    #[test]
+    fn unbalanced_brace_test() {
+        let path = PathBuf::from("/unbalanced.go");
+        let graph = parse_code(UNBALANCED_GO_CODE, &path).unwrap();
+        // 错误之前的声明仍然完整
+        let point = graph.find_nodes_by_qualified_name("Point");
+        assert_eq!(point.len(), 1);
+        assert_eq!(point[0].kind, SymbolKind::Struct);
+        assert_eq!(graph.find_nodes_by_qualified_name("Point.X").len(), 1);
+        assert_eq!(graph.find_nodes_by_qualified_name("Origin")[0].kind, SymbolKind::Function);
+
+        let errors = graph.syntax_errors();
+        assert!(!errors.is_empty());
+        let stray_line = UNBALANCED_GO_CODE.lines().position(|line| line == "}}").unwrap();
+        assert!(errors.iter().any(|e| e.span.start_line == stray_line), "{:?}", errors);
+        assert!(errors.iter().all(|e| e.file_path == path && !e.message.is_empty()));
+        assert_eq!(graph.stats().parse_errors, errors.len());
+
+        // 错误随图一起序列化
+        let restored = SymbolGraph::from_json(&graph.to_json().unwrap()).unwrap();
+        assert_eq!(restored.syntax_errors(), errors);
+    }
+
+    #[test]
+    fn parse_dir_keeps_errors_test() {
+        let dir = tempfile::tempdir().unwrap();
+        fs::write(dir.path().join("unbalanced.go"), UNBALANCED_GO_CODE).unwrap();
+        fs::write(dir.path().join("split_geometry.go"), SPLIT_GEOMETRY_GO_CODE).unwrap();
+        fs::write(dir.path().join("split_resize.go"), SPLIT_RESIZE_GO_CODE).unwrap();
+        let (graph, _) = parse_dir(dir.path(), &ParseOptions::default()).unwrap();
+        // 包内引用解析重建图后错误仍然保留
+        let single = parse_code(UNBALANCED_GO_CODE, &dir.path().join("unbalanced.go")).unwrap();
+        assert!(!single.syntax_errors().is_empty());
+        assert_eq!(graph.syntax_errors(), single.syntax_errors());
+    }
+
+    #[test]
+    fn no_errors_test() {
+        let graph = parse_code(MAIN_GO_CODE, &PathBuf::from("/main.go")).unwrap();
+        assert!(graph.syntax_errors().is_empty());
+        assert!(!graph.to_json().unwrap().contains("syntax_errors"));
+    }
+}
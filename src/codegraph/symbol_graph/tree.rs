@@ -0,0 +1,148 @@
+use std::collections::HashMap;
+use std::path::PathBuf;
+use std::sync::atomic::{AtomicUsize, Ordering};
+
+use tree_sitter::{Node, Tree};
+
+use crate::codegraph::symbol_graph::builder::{parse_source, read_source, ParseContext};
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::treesitter::language_id::LanguageId;
+use crate::codegraph::treesitter::parsers::ParserError;
+
+/// 尚未释放的语法树数量
+static OPEN_TREES: AtomicUsize = AtomicUsize::new(0);
+
+/// 构建符号图时使用的 tree-sitter 语法树和对应的源码，供需要自定义查询的调用方使用。
+///
+/// 语法树占用解析器分配的 C 内存，`close` 立即释放，未关闭时在 drop 时释放。
+/// 关闭后 `tree` 和 `root_node` 返回 None，调用方不应再使用之前取得的节点
+/// （节点借用了 `SyntaxTree`，编译器会拒绝在借用期间调用 `close`）；重复关闭没有影响
+pub struct SyntaxTree {
+    tree: Option<Tree>,
+    code: String,
+    language: LanguageId,
+}
+
+impl SyntaxTree {
+    fn new(tree: Tree, code: String, language: LanguageId) -> Self {
+        OPEN_TREES.fetch_add(1, Ordering::Relaxed);
+        Self { tree: Some(tree), code, language }
+    }
+
+    pub fn tree(&self) -> Option<&Tree> {
+        self.tree.as_ref()
+    }
+
+    pub fn root_node(&self) -> Option<Node<'_>> {
+        self.tree.as_ref().map(|tree| tree.root_node())
+    }
+
+    /// 解析的源码，节点的字节范围对应其中的位置
+    pub fn code(&self) -> &str {
+        &self.code
+    }
+
+    pub fn language(&self) -> LanguageId {
+        self.language
+    }
+
+    pub fn is_closed(&self) -> bool {
+        self.tree.is_none()
+    }
+
+    /// 释放语法树，已经关闭时什么也不做
+    pub fn close(&mut self) {
+        if self.tree.take().is_some() {
+            OPEN_TREES.fetch_sub(1, Ordering::Relaxed);
+        }
+    }
+}
+
+impl Drop for SyntaxTree {
+    fn drop(&mut self) {
+        self.close();
+    }
+}
+
+/// 尚未关闭（或 drop）的 `SyntaxTree` 数量，用于检查调用方是否泄漏语法树
+pub fn open_syntax_trees() -> usize {
+    OPEN_TREES.load(Ordering::Relaxed)
+}
+
+/// 与 `parse_code` 相同，同时返回构建时使用的语法树；解析器不生成语法树时为 None
+pub fn parse_code_with_tree(code: &str, path: &PathBuf) -> Result<(SymbolGraph, Option<SyntaxTree>), ParserError> {
+    with_tree(code, path, &ParseContext::default())
+}
+
+/// 与 `parse_file_with_overlay` 相同，同时返回语法树
+pub fn parse_file_with_tree(path: &PathBuf, overlay: &HashMap<PathBuf, String>) -> Result<(SymbolGraph, Option<SyntaxTree>), ParserError> {
+    let code = read_source(path, overlay)?;
+    with_tree(&code, path, &ParseContext::for_file(path, overlay))
+}
+
+fn with_tree(code: &str, path: &PathBuf, context: &ParseContext) -> Result<(SymbolGraph, Option<SyntaxTree>), ParserError> {
+    let (graph, tree, language) = parse_source(code, path, context)?;
+    Ok((graph, tree.map(|tree| SyntaxTree::new(tree, code.to_string(), language))))
+}
+
+#[cfg(test)]
+mod tests {
+    use std::collections::HashMap;
+    use std::path::PathBuf;
+
+    use tree_sitter::Node;
+
+    use crate::codegraph::symbol_graph::builder::parse_code;
+    use crate::codegraph::symbol_graph::tree::{open_syntax_trees, parse_file_with_tree};
+    use crate::codegraph::treesitter::language_id::LanguageId;
+
+    fn count_kind(node: &Node, kind: &str) -> usize {
+        let mut count = 0;
+        let mut stack = vec![*node];
+        while let Some(node) = stack.pop() {
+            if node.kind() == kind {
+                count += 1;
+            }
+            for i in 0..node.child_count() {
+                stack.push(node.child(i).unwrap());
+            }
+        }
+        count
+    }
+
+    /// 计数器是全局的，所有检查放在同一个测试中
+    #[test]
+    fn tree_lifecycle_test() {
+        let path = PathBuf::from(env!("CARGO_MANIFEST_DIR")).join("src/codegraph/treesitter/parsers/tests/cases/go/main.go");
+        let before = open_syntax_trees();
+        let (graph, tree) = parse_file_with_tree(&path, &HashMap::new()).unwrap();
+        let mut tree = tree.unwrap();
+        assert_eq!(open_syntax_trees(), before + 1);
+        assert_eq!(tree.language(), LanguageId::Go);
+
+        // 与不保留语法树时得到的图相同
+        let code = std::fs::read_to_string(&path).unwrap();
+        assert_eq!(tree.code(), code);
+        assert_eq!(graph.to_json().unwrap(), parse_code(&code, &path).unwrap().to_json().unwrap());
+
+        let root = tree.root_node().unwrap();
+        assert_eq!(root.kind(), "source_file");
+        assert_eq!(count_kind(&root, "function_declaration"), 2);
+        assert_eq!(count_kind(&root, "method_declaration"), 1);
+        assert_eq!(count_kind(&root, "type_declaration"), 1);
+
+        tree.close();
+        assert!(tree.is_closed() && tree.root_node().is_none() && tree.tree().is_none());
+        assert_eq!(open_syntax_trees(), before);
+        // 重复关闭和随后的 drop 不会再次释放
+        tree.close();
+        drop(tree);
+        assert_eq!(open_syntax_trees(), before);
+
+        // 未关闭的语法树在 drop 时释放
+        let (_, tree) = parse_file_with_tree(&path, &HashMap::new()).unwrap();
+        assert_eq!(open_syntax_trees(), before + 1);
+        drop(tree);
+        assert_eq!(open_syntax_trees(), before);
+    }
+}
@@ -0,0 +1,363 @@
+use std::collections::BTreeMap;
+use std::fmt;
+use std::path::PathBuf;
+
+use serde::{Deserialize, Serialize};
+use uuid::Uuid;
+
+use crate::codegraph::symbol_graph::span::Span;
+use crate::codegraph::treesitter::language_id::LanguageId;
+
+/// 符号节点类型
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash, Serialize, Deserialize)]
+pub enum SymbolKind {
+    Struct,
+    Interface,
+    Enum,
+    /// C `union`
+    Union,
+    TypeAlias,
+    /// Rust `impl` 块，方法挂在实现的类型上
+    Impl,
+    Field,
+    Function,
+    Method,
+    /// C 全局变量；Go 的包级和局部变量、常量（带 `constant` 属性）
+    Variable,
+    /// C `#define` 宏，函数式宏带 `function_like` 属性
+    Macro,
+    /// Go 泛型声明的类型参数，例如 `func Map[T any]` 中的 `T`
+    TypeParameter,
+    /// Java 包，包含包中的顶层声明
+    Package,
+    /// C++ 命名空间，包含其中的声明；同名命名空间的多个定义各有一个节点
+    Namespace,
+    /// Kotlin `object` 声明和伴生对象（单例）
+    Object,
+    /// 语言内置类型，例如 Go 的 `int`、`error`，每种只有一个节点
+    Builtin,
+    /// 源文件，作为文件级关系（例如导入）的起点
+    File,
+    /// 一条导入，名称为源码中的导入路径
+    Import,
+    /// 无法在当前范围内解析的引用目标
+    Unresolved,
+}
+
+impl fmt::Display for SymbolKind {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "{:?}", self)
+    }
+}
+
+/// 符号节点
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct SymbolNode {
+    pub id: Uuid,
+    pub kind: SymbolKind,
+    pub name: String,
+    /// 限定名，例如 `(*Point).Move`、`Shape.area`
+    pub qualified_name: String,
+    pub language: LanguageId,
+    pub file_path: PathBuf,
+    /// 整个声明的位置
+    pub span: Span,
+    /// 声明头部（签名）的位置
+    pub declaration_span: Span,
+    /// 紧邻声明上方的文档注释，去掉了注释符号
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub doc: Option<String>,
+    /// 语言相关的附加属性，例如 Python 装饰器
+    pub attributes: BTreeMap<String, serde_json::Value>,
+}
+
+/// 符号边类型
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash, Serialize, Deserialize)]
+pub enum SymbolEdgeKind {
+    Contains,      // 父符号包含子符号
+    MethodOf,      // 方法 -> 接收者类型
+    Calls,         // 调用者 -> 被调用者
+    Extends,       // 子类/子接口 -> 父类/父接口
+    Implements,    // 类 -> 实现的接口
+    Imports,       // 文件 -> 导入
+    References,    // 使用类型的符号 -> 类型
+    Promotes,      // 外层结构体 -> 通过嵌入字段提升的方法
+    Satisfies,     // 类型 -> 方法集满足的接口
+    ConstrainedBy, // 类型参数 -> 约束类型
+    HasType,       // 变量 -> 变量的类型
+    ParamType,     // 函数/方法 -> 参数的类型
+    ReturnType,    // 函数/方法 -> 返回值的类型
+    AccessesField, // 函数/方法 -> 读写的结构体字段
+}
+
+impl fmt::Display for SymbolEdgeKind {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "{:?}", self)
+    }
+}
+
+/// 方法接收者形式
+#[derive(Debug, Clone, Copy, PartialEq, Eq, PartialOrd, Ord, Hash, Serialize, Deserialize)]
+pub enum ReceiverKind {
+    Value,
+    Pointer,
+}
+
+impl ReceiverKind {
+    pub fn as_str(&self) -> &'static str {
+        match self {
+            ReceiverKind::Value => "value",
+            ReceiverKind::Pointer => "pointer",
+        }
+    }
+
+    pub fn from_str(s: &str) -> Option<Self> {
+        match s {
+            "value" => Some(ReceiverKind::Value),
+            "pointer" => Some(ReceiverKind::Pointer),
+            _ => None,
+        }
+    }
+}
+
+/// 字段访问方式：`p.X = 1` 只写，`p.X += 1` 和 `p.X++` 读写，其余为只读
+#[derive(Debug, Clone, Copy, PartialEq, Eq, PartialOrd, Ord, Hash, Serialize, Deserialize)]
+pub enum FieldAccess {
+    Read,
+    Write,
+    ReadWrite,
+}
+
+impl FieldAccess {
+    pub fn as_str(&self) -> &'static str {
+        match self {
+            FieldAccess::Read => "read",
+            FieldAccess::Write => "write",
+            FieldAccess::ReadWrite => "read_write",
+        }
+    }
+
+    pub fn from_str(s: &str) -> Option<Self> {
+        match s {
+            "read" => Some(FieldAccess::Read),
+            "write" => Some(FieldAccess::Write),
+            "read_write" => Some(FieldAccess::ReadWrite),
+            _ => None,
+        }
+    }
+
+    pub fn is_read(&self) -> bool {
+        matches!(self, FieldAccess::Read | FieldAccess::ReadWrite)
+    }
+
+    pub fn is_write(&self) -> bool {
+        matches!(self, FieldAccess::Write | FieldAccess::ReadWrite)
+    }
+
+    /// 同一函数中对同一字段的多次访问合并
+    pub fn merge(self, other: FieldAccess) -> FieldAccess {
+        if self == other { self } else { FieldAccess::ReadWrite }
+    }
+}
+
+/// 导入的绑定方式
+#[derive(Debug, Clone, Copy, PartialEq, Eq, PartialOrd, Ord, Hash, Serialize, Deserialize)]
+pub enum ImportKind {
+    /// `import "fmt"`
+    Plain,
+    /// `import f "fmt"`
+    Alias,
+    /// `import . "fmt"`
+    Dot,
+    /// `import _ "fmt"`
+    Blank,
+}
+
+impl ImportKind {
+    pub fn from_alias(alias: Option<&str>) -> Self {
+        match alias {
+            None => ImportKind::Plain,
+            Some(".") => ImportKind::Dot,
+            Some("_") => ImportKind::Blank,
+            Some(_) => ImportKind::Alias,
+        }
+    }
+
+    pub fn as_str(&self) -> &'static str {
+        match self {
+            ImportKind::Plain => "plain",
+            ImportKind::Alias => "alias",
+            ImportKind::Dot => "dot",
+            ImportKind::Blank => "blank",
+        }
+    }
+
+    pub fn from_str(s: &str) -> Option<Self> {
+        match s {
+            "plain" => Some(ImportKind::Plain),
+            "alias" => Some(ImportKind::Alias),
+            "dot" => Some(ImportKind::Dot),
+            "blank" => Some(ImportKind::Blank),
+            _ => None,
+        }
+    }
+}
+
+/// 内容寻址的稳定ID：对 `文件路径\0类型\0限定名\0同名序号\0签名` 取 md5。
+///
+/// 不包含位置和解析顺序，同一份源码在不同机器上、在无关代码变化后都得到相同的ID；
+/// 函数签名（去掉名称后的参数和返回值类型）变化时ID随之变化。同名序号只区分
+/// 同一文件中类型、限定名和签名都相同的声明（例如遮蔽或重复声明），按源码顺序从0开始
+pub fn stable_id(file_path: &PathBuf, kind: SymbolKind, qualified_name: &str, index: usize, signature: Option<&str>) -> Uuid {
+    let digest = md5::compute(format!(
+        "{}\u{0}{}\u{0}{}\u{0}{}\u{0}{}",
+        file_path.display(), kind, qualified_name, index, signature.unwrap_or_default()
+    ));
+    Uuid::from_bytes(digest.0)
+}
+
+impl SymbolNode {
+    /// 由节点内容计算的稳定ID，见 [`stable_id`]。构建符号图时节点的 `id` 就是它
+    pub fn stable_id(&self) -> Uuid {
+        let index = self.attributes.get("index").and_then(|v| v.as_u64()).unwrap_or(0) as usize;
+        let signature = self.attributes.get("signature").and_then(|v| v.as_str());
+        stable_id(&self.file_path, self.kind, &self.qualified_name, index, signature)
+    }
+
+    /// Import 节点的导入路径
+    pub fn import_path(&self) -> Option<&str> {
+        self.attributes.get("path").and_then(|v| v.as_str())
+    }
+
+    /// Import 节点的绑定方式
+    pub fn import_kind(&self) -> Option<ImportKind> {
+        self.attributes.get("import_kind")
+            .and_then(|v| v.as_str())
+            .and_then(ImportKind::from_str)
+    }
+
+    /// Import 节点的别名（包括 `.` 和 `_`）
+    pub fn import_alias(&self) -> Option<&str> {
+        self.attributes.get("alias").and_then(|v| v.as_str())
+    }
+
+    /// 节点来自生成的文件（目录解析时标记）
+    pub fn is_generated(&self) -> bool {
+        self.attributes.get("generated").and_then(|v| v.as_bool()).unwrap_or(false)
+    }
+
+    /// 符号是否导出，语言或符号种类没有可见性规则时为 None
+    pub fn is_exported(&self) -> Option<bool> {
+        self.attributes.get("exported").and_then(|v| v.as_bool())
+    }
+}
+
+/// 符号边
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+pub struct SymbolEdge {
+    pub source: Uuid,
+    pub target: Uuid,
+    pub kind: SymbolEdgeKind,
+    pub metadata: Option<serde_json::Value>,
+}
+
+impl SymbolEdge {
+    pub fn new(source: Uuid, target: Uuid, kind: SymbolEdgeKind) -> Self {
+        Self { source, target, kind, metadata: None }
+    }
+
+    /// MethodOf 边记录的接收者形式
+    pub fn receiver_kind(&self) -> Option<ReceiverKind> {
+        self.metadata.as_ref()
+            .and_then(|m| m.get("receiver"))
+            .and_then(|r| r.as_str())
+            .and_then(ReceiverKind::from_str)
+    }
+
+    /// AccessesField 边记录的访问方式
+    pub fn field_access(&self) -> Option<FieldAccess> {
+        self.metadata.as_ref()
+            .and_then(|m| m.get("access"))
+            .and_then(|a| a.as_str())
+            .and_then(FieldAccess::from_str)
+    }
+
+    /// Calls、References 和 AccessesField 边记录的引用位置
+    pub fn site(&self) -> Option<Span> {
+        self.metadata.as_ref()
+            .and_then(|m| m.get("span"))
+            .and_then(|s| serde_json::from_value(s.clone()).ok())
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use std::path::PathBuf;
+
+    use uuid::Uuid;
+
+    use crate::codegraph::symbol_graph::builder::parse_code;
+    use crate::codegraph::symbol_graph::graph::SymbolGraph;
+
+    const MAIN_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/main.go");
+
+    fn stable_id_of(graph: &SymbolGraph, qualified_name: &str) -> Uuid {
+        let nodes = graph.find_nodes_by_qualified_name(qualified_name);
+        assert_eq!(nodes.len(), 1, "node {}", qualified_name);
+        nodes[0].stable_id()
+    }
+
+    #[test]
+    fn stable_id_matches_node_id_test() {
+        let graph = parse_code(MAIN_GO_CODE, &PathBuf::from("/main.go")).unwrap();
+        for node in graph.nodes() {
+            assert_eq!(node.stable_id(), node.id, "{}", node.qualified_name);
+        }
+    }
+
+    #[test]
+    fn stable_id_survives_unrelated_edit_test() {
+        let path = PathBuf::from("/main.go");
+        let before = parse_code(MAIN_GO_CODE, &path).unwrap();
+        let code = MAIN_GO_CODE.replacen("func NewPoint", "\nfunc NewPoint", 1);
+        let after = parse_code(&code, &path).unwrap();
+
+        for name in ["Point", "Point.X", "NewPoint", "(*Point).Move", "main", "fmt"] {
+            assert_eq!(stable_id_of(&after, name), stable_id_of(&before, name), "{}", name);
+        }
+        let moved = after.find_nodes_by_qualified_name("NewPoint")[0];
+        assert_eq!(moved.span.start_line, before.find_nodes_by_qualified_name("NewPoint")[0].span.start_line + 1);
+    }
+
+    #[test]
+    fn stable_id_follows_signature_test() {
+        let path = PathBuf::from("/main.go");
+        let before = parse_code(MAIN_GO_CODE, &path).unwrap();
+        let code = MAIN_GO_CODE.replacen("func NewPoint(x int, y int) Point", "func NewPoint(x int, y int) *Point", 1);
+        let after = parse_code(&code, &path).unwrap();
+
+        assert_ne!(stable_id_of(&after, "NewPoint"), stable_id_of(&before, "NewPoint"));
+        // 参数改名不影响签名
+        let renamed = MAIN_GO_CODE.replacen("func NewPoint(x int, y int)", "func NewPoint(a int, b int)", 1);
+        let renamed = parse_code(&renamed, &path).unwrap();
+        assert_eq!(stable_id_of(&renamed, "NewPoint"), stable_id_of(&before, "NewPoint"));
+        // 其他文件中的同名符号ID不同
+        let other = parse_code(MAIN_GO_CODE, &PathBuf::from("/other/main.go")).unwrap();
+        assert_ne!(stable_id_of(&other, "NewPoint"), stable_id_of(&before, "NewPoint"));
+    }
+
+    #[test]
+    fn stable_id_disambiguates_duplicates_test() {
+        let code = "package main\n\nfunc helper() {}\n\nfunc helper() {}\n\nfunc helper(n int) {}\n";
+        let graph = parse_code(code, &PathBuf::from("/dup.go")).unwrap();
+        let helpers = graph.find_nodes_by_qualified_name("helper");
+        assert_eq!(helpers.len(), 3);
+        assert_eq!(helpers[0].attributes.get("index"), None);
+        assert_eq!(helpers[1].attributes["index"], 1);
+        // 签名不同的声明各自从0编号
+        assert_eq!(helpers[2].attributes.get("index"), None);
+        let ids = helpers.iter().map(|n| n.id).collect::<std::collections::HashSet<_>>();
+        assert_eq!(ids.len(), 3);
+        assert!(helpers.iter().all(|n| n.stable_id() == n.id));
+    }
+}
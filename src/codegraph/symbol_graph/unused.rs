@@ -0,0 +1,128 @@
+use std::collections::HashSet;
+
+use uuid::Uuid;
+
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::symbol_graph::types::{SymbolEdgeKind, SymbolKind, SymbolNode};
+
+/// 未使用符号检查选项
+#[derive(Debug, Clone)]
+pub struct UnreferencedOptions {
+    /// 入口函数名，不会被报告
+    pub entrypoints: Vec<String>,
+    /// 同时报告导出的符号（`exported` 属性，例如 Go 中首字母大写的名称、C 中非 static 的函数），默认只报告私有的符号
+    pub include_exported: bool,
+}
+
+impl Default for UnreferencedOptions {
+    fn default() -> Self {
+        Self {
+            entrypoints: vec!["main".to_string(), "init".to_string()],
+            include_exported: false,
+        }
+    }
+}
+
+impl SymbolGraph {
+    /// 没有被调用也没有被引用的函数和方法，按插入顺序返回。自身递归调用不算引用。
+    ///
+    /// 以下方法可能通过接口或 trait 间接调用，不会被报告：
+    /// 接口中声明的方法；Rust `impl Trait for Type` 中的方法；
+    /// 所属类型（或通过嵌入提升到的类型）满足某个接口（Satisfies 边）且接口中有同名方法的 Go 方法。
+    /// Satisfies 边需要事先用 `link_interface_satisfaction` 计算，否则这类方法会被当作未使用
+    pub fn unreferenced_symbols(&self, options: &UnreferencedOptions) -> Vec<&SymbolNode> {
+        let dispatched = self.interface_dispatched_methods();
+        self.nodes()
+            .filter(|n| matches!(n.kind, SymbolKind::Function | SymbolKind::Method))
+            .filter(|n| !options.entrypoints.contains(&n.name))
+            .filter(|n| options.include_exported || n.is_exported() != Some(true))
+            .filter(|n| !dispatched.contains(&n.id))
+            .filter(|n| {
+                !self.incoming_edges(&n.id, None).iter()
+                    .any(|edge| matches!(edge.kind, SymbolEdgeKind::Calls | SymbolEdgeKind::References) && edge.source != n.id)
+            })
+            .collect()
+    }
+
+    /// 可能通过接口或 trait 调用的方法
+    fn interface_dispatched_methods(&self) -> HashSet<Uuid> {
+        let mut dispatched = HashSet::new();
+        for node in self.nodes() {
+            match node.kind {
+                SymbolKind::Method => {
+                    let parent = self.parent_of(&node.id);
+                    let via_trait = parent.map_or(false, |p| {
+                        p.kind == SymbolKind::Interface || (p.kind == SymbolKind::Impl && p.attributes.contains_key("trait"))
+                    });
+                    if via_trait {
+                        dispatched.insert(node.id);
+                    }
+                }
+                SymbolKind::Struct => {
+                    let interface_methods = self.outgoing_edges(&node.id, Some(SymbolEdgeKind::Satisfies)).iter()
+                        .flat_map(|edge| self.children_of(&edge.target))
+                        .filter(|n| n.kind == SymbolKind::Method)
+                        .map(|n| n.name.clone())
+                        .collect::<HashSet<_>>();
+                    if interface_methods.is_empty() {
+                        continue;
+                    }
+                    let promoted = self.outgoing_edges(&node.id, Some(SymbolEdgeKind::Promotes)).iter()
+                        .filter_map(|edge| self.get_node(&edge.target))
+                        .collect::<Vec<_>>();
+                    for method in self.methods_of(&node.id).into_iter().chain(promoted) {
+                        if interface_methods.contains(&method.name) {
+                            dispatched.insert(method.id);
+                        }
+                    }
+                }
+                _ => {}
+            }
+        }
+        dispatched
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use std::path::PathBuf;
+
+    use crate::codegraph::symbol_graph::builder::parse_code;
+    use crate::codegraph::symbol_graph::satisfaction::link_interface_satisfaction;
+    use crate::codegraph::symbol_graph::unused::UnreferencedOptions;
+
+    const UNUSED_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/unused.go");
+
+    fn unreferenced(code: &str, options: &UnreferencedOptions, satisfaction: bool) -> Vec<String> {
+        let mut graph = parse_code(code, &PathBuf::from("/unused.go")).unwrap();
+        if satisfaction {
+            link_interface_satisfaction(&mut graph);
+        }
+        graph.unreferenced_symbols(options).iter()
+            .map(|n| n.qualified_name.clone())
+            .collect()
+    }
+
+    #[test]
+    fn unreferenced_symbols_test() {
+        assert_eq!(unreferenced(UNUSED_GO_CODE, &UnreferencedOptions::default(), true), vec!["unusedHelper"]);
+    }
+
+    #[test]
+    fn interface_dispatch_requires_satisfaction_test() {
+        // 没有 Satisfies 边时无法知道 greet 会通过接口调用
+        assert_eq!(
+            unreferenced(UNUSED_GO_CODE, &UnreferencedOptions::default(), false),
+            vec!["(english).greet", "unusedHelper"]
+        );
+    }
+
+    #[test]
+    fn exported_and_entrypoints_test() {
+        let options = UnreferencedOptions { include_exported: true, entrypoints: vec!["main".to_string()] };
+        assert_eq!(
+            unreferenced(UNUSED_GO_CODE, &options, true),
+            vec!["unusedHelper", "(*Registry).Register", "init"]
+        );
+    }
+}
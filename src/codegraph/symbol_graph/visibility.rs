@@ -0,0 +1,150 @@
+use std::collections::HashMap;
+use std::sync::{Arc, OnceLock};
+
+use parking_lot::RwLock;
+use serde_json::json;
+
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::symbol_graph::types::{SymbolKind, SymbolNode};
+use crate::codegraph::treesitter::language_id::LanguageId;
+use crate::codegraph::treesitter::parsers::cpp::ANONYMOUS_NAMESPACE;
+
+/// 一种语言的可见性规则：声明是否在包（或编译单元）之外可见
+pub trait VisibilityRule: Send + Sync {
+    /// 返回 None 表示该种类的符号没有可见性，例如类型参数。`parent` 是包含该符号的节点
+    fn is_exported(&self, node: &SymbolNode, parent: Option<&SymbolNode>) -> Option<bool>;
+}
+
+/// Go：名称首字母大写的标识符是导出的，函数中的局部变量不导出
+pub struct GoVisibility;
+
+impl VisibilityRule for GoVisibility {
+    fn is_exported(&self, node: &SymbolNode, parent: Option<&SymbolNode>) -> Option<bool> {
+        match node.kind {
+            SymbolKind::Struct | SymbolKind::Interface | SymbolKind::TypeAlias | SymbolKind::Field
+            | SymbolKind::Function | SymbolKind::Method | SymbolKind::Variable => {}
+            _ => return None,
+        }
+        if parent.map_or(false, |p| matches!(p.kind, SymbolKind::Function | SymbolKind::Method)) {
+            return Some(false);
+        }
+        Some(node.name.chars().next().map_or(false, |c| c.is_uppercase()))
+    }
+}
+
+/// C：`static` 函数只在本文件中可见，其余函数都是外部链接
+pub struct CVisibility;
+
+impl VisibilityRule for CVisibility {
+    fn is_exported(&self, node: &SymbolNode, _parent: Option<&SymbolNode>) -> Option<bool> {
+        match node.kind {
+            SymbolKind::Function => Some(!node.attributes.get("file_local").and_then(|v| v.as_bool()).unwrap_or(false)),
+            _ => None,
+        }
+    }
+}
+
+/// C++：类成员按访问说明符，只有 `public` 成员在类外可见；
+/// 命名空间中的声明除匿名命名空间中的以外都是外部可见的
+pub struct CppVisibility;
+
+impl VisibilityRule for CppVisibility {
+    fn is_exported(&self, node: &SymbolNode, parent: Option<&SymbolNode>) -> Option<bool> {
+        match node.kind {
+            SymbolKind::Field | SymbolKind::Method => {
+                node.attributes.get("access").and_then(|v| v.as_str()).map(|access| access == "public")
+            }
+            SymbolKind::Struct | SymbolKind::Union | SymbolKind::Enum | SymbolKind::Function | SymbolKind::Namespace => {
+                match parent {
+                    Some(parent) if parent.kind == SymbolKind::Namespace => Some(parent.name != ANONYMOUS_NAMESPACE),
+                    Some(_) => None,
+                    None => Some(node.name != ANONYMOUS_NAMESPACE),
+                }
+            }
+            _ => None,
+        }
+    }
+}
+
+/// 语言 -> 可见性规则，首次使用时注册内置规则
+fn rules() -> &'static RwLock<HashMap<LanguageId, Arc<dyn VisibilityRule>>> {
+    static RULES: OnceLock<RwLock<HashMap<LanguageId, Arc<dyn VisibilityRule>>>> = OnceLock::new();
+    RULES.get_or_init(|| {
+        let mut rules: HashMap<LanguageId, Arc<dyn VisibilityRule>> = HashMap::new();
+        rules.insert(LanguageId::Go, Arc::new(GoVisibility));
+        rules.insert(LanguageId::C, Arc::new(CVisibility));
+        rules.insert(LanguageId::Cpp, Arc::new(CppVisibility));
+        RwLock::new(rules)
+    })
+}
+
+/// 注册（或替换）一种语言的可见性规则，之后构建的符号图使用新规则
+pub fn register_visibility_rule<R: VisibilityRule + 'static>(language: LanguageId, rule: R) {
+    rules().write().insert(language, Arc::new(rule));
+}
+
+/// 按节点语言的规则记录 `exported` 属性，没有规则的语言和种类不记录
+pub fn attach_visibility(graph: &mut SymbolGraph) {
+    let rules = rules().read();
+    let mut exported = vec![];
+    for index in graph.graph.node_indices() {
+        let node = &graph.graph[index];
+        let Some(rule) = rules.get(&node.language) else { continue };
+        if let Some(value) = rule.is_exported(node, graph.parent_of(&node.id)) {
+            exported.push((index, value));
+        }
+    }
+    for (index, value) in exported {
+        graph.graph[index].attributes.insert("exported".to_string(), json!(value));
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use std::path::PathBuf;
+
+    use crate::codegraph::symbol_graph::builder::parse_code;
+    use crate::codegraph::symbol_graph::graph::SymbolGraph;
+
+    const MAIN_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/main.go");
+    const SHAPE_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/shape.go");
+    const VARIABLES_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/variables.go");
+
+    fn exported(graph: &SymbolGraph, qualified_name: &str) -> Option<bool> {
+        let nodes = graph.find_nodes_by_qualified_name(qualified_name);
+        assert_eq!(nodes.len(), 1, "{}", qualified_name);
+        nodes[0].is_exported()
+    }
+
+    #[test]
+    fn go_visibility_test() {
+        let main = parse_code(MAIN_GO_CODE, &PathBuf::from("/main.go")).unwrap();
+        for name in ["Point", "Point.X", "Point.Y", "NewPoint", "(*Point).Move"] {
+            assert_eq!(exported(&main, name), Some(true), "{}", name);
+        }
+        assert_eq!(exported(&main, "main"), Some(false));
+        assert_eq!(exported(&main, "main.p"), Some(false));
+        // 导入和包节点没有可见性
+        assert_eq!(exported(&main, "fmt"), None);
+
+        let shape = parse_code(SHAPE_GO_CODE, &PathBuf::from("/shape.go")).unwrap();
+        assert_eq!(exported(&shape, "Rectangle"), Some(true));
+        assert_eq!(exported(&shape, "Rectangle.width"), Some(false));
+        assert_eq!(exported(&shape, "Rectangle.height"), Some(false));
+        assert_eq!(exported(&shape, "(Rectangle).Area"), Some(true));
+
+        let variables = parse_code(VARIABLES_GO_CODE, &PathBuf::from("/variables.go")).unwrap();
+        assert_eq!(exported(&variables, "Cell.Row"), Some(true));
+        assert_eq!(exported(&variables, "origin"), Some(false));
+        assert_eq!(exported(&variables, "maxDepth"), Some(false));
+        assert_eq!(exported(&variables, "shadow.c"), Some(false));
+    }
+
+    #[test]
+    fn c_visibility_test() {
+        let code = "static int helper(void) { return 1; }\n\nint api(void) { return helper(); }\n";
+        let graph = parse_code(code, &PathBuf::from("/lib.c")).unwrap();
+        assert_eq!(exported(&graph, "helper"), Some(false));
+        assert_eq!(exported(&graph, "api"), Some(true));
+    }
+}
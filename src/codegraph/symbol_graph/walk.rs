@@ -0,0 +1,158 @@
+use std::collections::{HashSet, VecDeque};
+
+use uuid::Uuid;
+
+use crate::codegraph::symbol_graph::graph::SymbolGraph;
+use crate::codegraph::symbol_graph::types::{SymbolEdge, SymbolEdgeKind, SymbolNode};
+
+/// 遍历顺序
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Default)]
+pub enum WalkOrder {
+    #[default]
+    DepthFirst,
+    BreadthFirst,
+}
+
+/// 访问节点后的动作
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum WalkAction {
+    /// 继续沿该节点的出边遍历
+    Continue,
+    /// 不再沿该节点的出边遍历，其他分支照常进行
+    SkipChildren,
+    /// 结束遍历
+    Stop,
+}
+
+/// 遍历时沿哪些边前进
+#[derive(Debug, Clone, PartialEq, Eq, Default)]
+pub enum EdgeFilter {
+    #[default]
+    All,
+    /// 只沿调用边前进
+    Calls,
+    Kinds(Vec<SymbolEdgeKind>),
+}
+
+impl EdgeFilter {
+    fn follows(&self, kind: SymbolEdgeKind) -> bool {
+        match self {
+            EdgeFilter::All => true,
+            EdgeFilter::Calls => kind == SymbolEdgeKind::Calls,
+            EdgeFilter::Kinds(kinds) => kinds.contains(&kind),
+        }
+    }
+}
+
+/// 遍历选项
+#[derive(Debug, Clone, Default)]
+pub struct WalkOptions {
+    pub order: WalkOrder,
+    pub edges: EdgeFilter,
+}
+
+impl SymbolGraph {
+    /// 从 `start` 出发沿出边遍历，`visit` 收到节点和到达它的边（起点没有边）。
+    /// 每个节点只访问一次，递归调用等环路不会重复进入；同一节点的出边按插入顺序展开。
+    /// 起点不存在时不访问任何节点
+    pub fn walk<F>(&self, start: &Uuid, options: &WalkOptions, mut visit: F)
+    where
+        F: FnMut(&SymbolNode, Option<&SymbolEdge>) -> WalkAction,
+    {
+        let start = match self.get_node(start) {
+            Some(node) => node,
+            None => return,
+        };
+        let mut visited: HashSet<Uuid> = HashSet::new();
+        // 深度优先时作为栈从尾部取，广度优先时作为队列从头部取
+        let mut pending: VecDeque<(&SymbolNode, Option<&SymbolEdge>)> = VecDeque::from([(start, None)]);
+        while let Some((node, edge)) = match options.order {
+            WalkOrder::DepthFirst => pending.pop_back(),
+            WalkOrder::BreadthFirst => pending.pop_front(),
+        } {
+            if !visited.insert(node.id) {
+                continue;
+            }
+            match visit(node, edge) {
+                WalkAction::Stop => return,
+                WalkAction::SkipChildren => continue,
+                WalkAction::Continue => {}
+            }
+            let mut next = self.outgoing_edges(&node.id, None).into_iter()
+                .filter(|e| options.edges.follows(e.kind) && !visited.contains(&e.target))
+                .filter_map(|e| self.get_node(&e.target).map(|target| (target, Some(e))))
+                .collect::<Vec<_>>();
+            if options.order == WalkOrder::DepthFirst {
+                // 栈顶是第一条出边
+                next.reverse();
+            }
+            pending.extend(next);
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use std::path::PathBuf;
+
+    use crate::codegraph::symbol_graph::builder::parse_code;
+    use crate::codegraph::symbol_graph::graph::SymbolGraph;
+    use crate::codegraph::symbol_graph::types::SymbolEdgeKind;
+    use crate::codegraph::symbol_graph::walk::{EdgeFilter, WalkAction, WalkOptions, WalkOrder};
+
+    const RECURSION_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/recursion.go");
+    const CALLS_GO_CODE: &str = include_str!("../treesitter/parsers/tests/cases/go/calls.go");
+
+    fn visited(graph: &SymbolGraph, start: &str, options: &WalkOptions, skip: &str) -> Vec<String> {
+        let start = graph.find_nodes_by_qualified_name(start)[0].id;
+        let mut names = vec![];
+        graph.walk(&start, options, |node, _edge| {
+            names.push(node.qualified_name.clone());
+            if node.qualified_name == skip { WalkAction::SkipChildren } else { WalkAction::Continue }
+        });
+        names
+    }
+
+    #[test]
+    fn recursive_calls_terminate_test() {
+        let graph = parse_code(RECURSION_GO_CODE, &PathBuf::from("/recursion.go")).unwrap();
+        let calls = WalkOptions { order: WalkOrder::DepthFirst, edges: EdgeFilter::Calls };
+        assert_eq!(visited(&graph, "countdown", &calls, ""), vec!["countdown", "factorial", "stepA", "stepB", "stepC"]);
+        assert_eq!(visited(&graph, "isOdd", &calls, ""), vec!["isOdd", "isEven"]);
+        assert_eq!(visited(&graph, "factorial", &calls, ""), vec!["factorial"]);
+    }
+
+    #[test]
+    fn breadth_first_and_skip_test() {
+        let graph = parse_code(RECURSION_GO_CODE, &PathBuf::from("/recursion.go")).unwrap();
+        let bfs = WalkOptions { order: WalkOrder::BreadthFirst, edges: EdgeFilter::Calls };
+        assert_eq!(visited(&graph, "stepC", &bfs, ""), vec!["stepC", "stepA", "stepB"]);
+        // 跳过 stepA 的出边，但 factorial 分支照常遍历
+        let dfs = WalkOptions { order: WalkOrder::DepthFirst, edges: EdgeFilter::Calls };
+        assert_eq!(visited(&graph, "countdown", &dfs, "stepA"), vec!["countdown", "factorial", "stepA"]);
+    }
+
+    #[test]
+    fn edge_filters_and_stop_test() {
+        let graph = parse_code(CALLS_GO_CODE, &PathBuf::from("/calls.go")).unwrap();
+        let all = visited(&graph, "run", &WalkOptions::default(), "");
+        // 沿类型引用到达 Counter，再沿包含边到达字段
+        assert!(all.contains(&"Counter".to_string()));
+        assert!(all.contains(&"Counter.n".to_string()));
+
+        // 只沿调用边时不会经过类型和字段
+        let calls = visited(&graph, "run", &WalkOptions { order: WalkOrder::DepthFirst, edges: EdgeFilter::Calls }, "");
+        assert!(calls.contains(&"(*Counter).Add".to_string()));
+        assert!(!calls.contains(&"Counter.n".to_string()));
+        let references = WalkOptions { order: WalkOrder::BreadthFirst, edges: EdgeFilter::Kinds(vec![SymbolEdgeKind::References]) };
+        assert_eq!(visited(&graph, "NewCounter", &references, ""), vec!["NewCounter", "Counter"]);
+
+        let run = graph.find_nodes_by_qualified_name("run")[0].id;
+        let mut count = 0;
+        graph.walk(&run, &WalkOptions::default(), |_node, _edge| {
+            count += 1;
+            if count == 3 { WalkAction::Stop } else { WalkAction::Continue }
+        });
+        assert_eq!(count, 3);
+    }
+}
@@ -247,6 +247,8 @@ pub trait AstSymbolInstance: Debug + Send + Sync + Any {
 
     fn fields_mut(&mut self) -> &mut AstSymbolFields;
 
+    fn as_any(&self) -> &dyn Any;
+
     fn as_any_mut(&mut self) -> &mut dyn Any;
 
     fn symbol_info_struct(&self) -> SymbolInformation {
@@ -393,11 +395,43 @@ pub type AstSymbolInstanceArc = Arc<RwLock<Box<dyn AstSymbolInstance>>>;
 /*
 StructDeclaration
 */
+/// Kind of a type declaration
+#[derive(Eq, Hash, PartialEq, Debug, Serialize, Deserialize, Clone, Copy)]
+pub enum StructKind {
+    Struct,
+    Interface,
+    Enum,
+    /// Rust `impl` block, named after the implementing type; `implemented_types` holds the trait
+    Impl,
+    /// C `union`
+    Union,
+    /// C++ `namespace`; anonymous namespaces are named `(anonymous namespace)`
+    Namespace,
+    /// Kotlin `object` or companion object; unnamed companions are named `Companion`
+    Object,
+}
+
+impl Default for StructKind {
+    fn default() -> Self {
+        StructKind::Struct
+    }
+}
+
 #[derive(DynPartialEq, PartialEq, Debug, Serialize, Deserialize, Clone)]
 pub struct StructDeclaration {
     pub ast_fields: AstSymbolFields,
     pub template_types: Vec<TypeDef>,
     pub inherited_types: Vec<TypeDef>,
+    /// Interfaces from the implements clause
+    #[serde(default)]
+    pub implemented_types: Vec<TypeDef>,
+    #[serde(default)]
+    pub decorators: Vec<String>,
+    #[serde(default)]
+    pub kind: StructKind,
+    /// Members the compiler generates for the declaration, e.g. `componentN` and `copy` of a Kotlin `data class`
+    #[serde(default)]
+    pub generated_members: Vec<String>,
 }
 
 impl Default for StructDeclaration {
@@ -406,6 +440,10 @@ impl Default for StructDeclaration {
             ast_fields: AstSymbolFields::default(),
             template_types: vec![],
             inherited_types: vec![],
+            implemented_types: vec![],
+            decorators: vec![],
+            kind: StructKind::Struct,
+            generated_members: vec![],
         }
     }
 }
@@ -422,6 +460,8 @@ impl AstSymbolInstance for StructDeclaration {
         &mut self.ast_fields
     }
 
+    fn as_any(&self) -> &dyn Any { self }
+
     fn as_any_mut(&mut self) -> &mut dyn Any { self }
 
     fn types(&self) -> Vec<TypeDef> {
@@ -430,6 +470,10 @@ impl AstSymbolInstance for StructDeclaration {
             types.push(t.clone());
             types.extend(t.get_nested_types());
         }
+        for t in self.implemented_types.iter() {
+            types.push(t.clone());
+            types.extend(t.get_nested_types());
+        }
         for t in self.template_types.iter() {
             types.push(t.clone());
             types.extend(t.get_nested_types());
@@ -447,6 +491,14 @@ impl AstSymbolInstance for StructDeclaration {
                 idx += 1;
             })
         }
+        for t in self.implemented_types.iter_mut() {
+            t.guid = guids[idx].clone();
+            idx += 1;
+            t.mutate_nested_types(|t| {
+                t.guid = guids[idx].clone();
+                idx += 1;
+            })
+        }
         for t in self.template_types.iter_mut() {
             t.guid = guids[idx].clone();
             idx += 1;
@@ -467,6 +519,14 @@ impl AstSymbolInstance for StructDeclaration {
                 idx += 1;
             })
         }
+        for t in self.implemented_types.iter_mut() {
+            t.inference_info_guid = guids[idx].clone();
+            idx += 1;
+            t.mutate_nested_types(|t| {
+                t.inference_info_guid = guids[idx].clone();
+                idx += 1;
+            })
+        }
         for t in self.template_types.iter_mut() {
             t.inference_info_guid = guids[idx].clone();
             idx += 1;
@@ -484,6 +544,12 @@ impl AstSymbolInstance for StructDeclaration {
                 t.inference_info = None
             })
         }
+        for t in self.implemented_types.iter_mut() {
+            t.inference_info = None;
+            t.mutate_nested_types(|t| {
+                t.inference_info = None
+            })
+        }
         for t in self.template_types.iter_mut() {
             t.inference_info = None;
             t.mutate_nested_types(|t| {
@@ -533,6 +599,8 @@ impl AstSymbolInstance for TypeAlias {
         &mut self.ast_fields
     }
 
+    fn as_any(&self) -> &dyn Any { self }
+
     fn as_any_mut(&mut self) -> &mut dyn Any { self }
 
     fn types(&self) -> Vec<TypeDef> {
@@ -596,6 +664,12 @@ ClassFieldDeclaration
 pub struct ClassFieldDeclaration {
     pub ast_fields: AstSymbolFields,
     pub type_: TypeDef,
+    /// Go embedded field, named after its type
+    #[serde(default)]
+    pub embedded: bool,
+    /// Access of a C++ member: `public`, `protected` or `private`
+    #[serde(default)]
+    pub access: Option<String>,
 }
 
 impl Default for ClassFieldDeclaration {
@@ -603,6 +677,8 @@ impl Default for ClassFieldDeclaration {
         Self {
             ast_fields: AstSymbolFields::default(),
             type_: TypeDef::default(),
+            embedded: false,
+            access: None,
         }
     }
 }
@@ -618,6 +694,8 @@ impl AstSymbolInstance for ClassFieldDeclaration {
         &mut self.ast_fields
     }
 
+    fn as_any(&self) -> &dyn Any { self }
+
     fn as_any_mut(&mut self) -> &mut dyn Any { self }
 
     fn types(&self) -> Vec<TypeDef> {
@@ -683,6 +761,9 @@ pub struct ImportDeclaration {
     pub alias: Option<String>,
     pub import_type: ImportType,
     pub filepath_ref: Option<PathBuf>,
+    /// Import path as written in the source, without the quotes
+    #[serde(default)]
+    pub path: Option<String>,
 }
 
 impl Default for ImportDeclaration {
@@ -693,6 +774,7 @@ impl Default for ImportDeclaration {
             alias: None,
             import_type: ImportType::Unknown,
             filepath_ref: None,
+            path: None,
         }
     }
 }
@@ -708,6 +790,8 @@ impl AstSymbolInstance for ImportDeclaration {
         &mut self.ast_fields
     }
 
+    fn as_any(&self) -> &dyn Any { self }
+
     fn as_any_mut(&mut self) -> &mut dyn Any { self }
 
     fn types(&self) -> Vec<TypeDef> {
@@ -735,10 +819,30 @@ impl AstSymbolInstance for ImportDeclaration {
 /*
 VariableDefinition
 */
+/// Kind of a variable definition
+#[derive(Eq, Hash, PartialEq, Debug, Serialize, Deserialize, Clone, Copy)]
+pub enum VariableKind {
+    Variable,
+    /// C `#define NAME value`
+    Macro,
+    /// C `#define NAME(args) body`, used like a function call in the source
+    FunctionMacro,
+    /// Go `const`
+    Constant,
+}
+
+impl Default for VariableKind {
+    fn default() -> Self {
+        VariableKind::Variable
+    }
+}
+
 #[derive(DynPartialEq, PartialEq, Debug, Serialize, Deserialize, Clone)]
 pub struct VariableDefinition {
     pub ast_fields: AstSymbolFields,
     pub type_: TypeDef,
+    #[serde(default)]
+    pub kind: VariableKind,
 }
 
 impl Default for VariableDefinition {
@@ -746,6 +850,7 @@ impl Default for VariableDefinition {
         Self {
             ast_fields: AstSymbolFields::default(),
             type_: TypeDef::default(),
+            kind: VariableKind::Variable,
         }
     }
 }
@@ -761,6 +866,8 @@ impl AstSymbolInstance for VariableDefinition {
         &mut self.ast_fields
     }
 
+    fn as_any(&self) -> &dyn Any { self }
+
     fn as_any_mut(&mut self) -> &mut dyn Any { self }
 
     fn types(&self) -> Vec<TypeDef> {
@@ -833,12 +940,29 @@ impl Default for FunctionArg {
     }
 }
 
+#[derive(Eq, Hash, PartialEq, Debug, Serialize, Deserialize, Clone)]
+pub struct FunctionReceiver {
+    pub name: Option<String>,
+    pub type_: TypeDef,
+    pub is_pointer: bool,
+}
+
 #[derive(DynPartialEq, PartialEq, Debug, Serialize, Deserialize, Clone)]
 pub struct FunctionDeclaration {
     pub ast_fields: AstSymbolFields,
     pub template_types: Vec<TypeDef>,
     pub args: Vec<FunctionArg>,
     pub return_type: Option<TypeDef>,
+    #[serde(default)]
+    pub decorators: Vec<String>,
+    #[serde(default)]
+    pub receiver: Option<FunctionReceiver>,
+    /// C `static` function, visible only in its own file
+    #[serde(default)]
+    pub file_local: bool,
+    /// Access of a C++ member function; out-of-line definitions take it from the in-class declaration
+    #[serde(default)]
+    pub access: Option<String>,
 }
 
 impl Default for FunctionDeclaration {
@@ -848,6 +972,10 @@ impl Default for FunctionDeclaration {
             template_types: vec![],
             args: vec![],
             return_type: None,
+            decorators: vec![],
+            receiver: None,
+            file_local: false,
+            access: None,
         }
     }
 }
@@ -863,6 +991,8 @@ impl AstSymbolInstance for FunctionDeclaration {
         &mut self.ast_fields
     }
 
+    fn as_any(&self) -> &dyn Any { self }
+
     fn as_any_mut(&mut self) -> &mut dyn Any { self }
 
     fn is_type(&self) -> bool {
@@ -980,6 +1110,8 @@ impl AstSymbolInstance for CommentDefinition {
         &mut self.ast_fields
     }
 
+    fn as_any(&self) -> &dyn Any { self }
+
     fn as_any_mut(&mut self) -> &mut dyn Any { self }
 
     fn is_type(&self) -> bool {
@@ -1033,6 +1165,8 @@ impl AstSymbolInstance for FunctionCall {
         &mut self.ast_fields
     }
 
+    fn as_any(&self) -> &dyn Any { self }
+
     fn as_any_mut(&mut self) -> &mut dyn Any { self }
 
     fn is_type(&self) -> bool {
@@ -1142,6 +1276,8 @@ impl AstSymbolInstance for VariableUsage {
         &mut self.ast_fields
     }
 
+    fn as_any(&self) -> &dyn Any { self }
+
     fn as_any_mut(&mut self) -> &mut dyn Any { self }
 
     fn is_type(&self) -> bool {
@@ -3,7 +3,7 @@ use std::fmt;
 use serde::{Deserialize, Serialize};
 use tree_sitter::Language;
 
-#[derive(Debug, Clone, Copy, Serialize, Deserialize, PartialEq)]
+#[derive(Debug, Clone, Copy, Serialize, Deserialize, PartialEq, Eq, Hash)]
 pub enum LanguageId {
     Apex,
     Bash,
@@ -101,6 +101,7 @@ impl From<&str> for LanguageId {
             // "erlang" => Self::Erlang,
             "go" => Self::Go,
             "html" => Self::Html,
+            "kotlin" => Self::Kotlin,
             "java" => Self::Java,
             "javascript" => Self::JavaScript,
             // "json" => Self::Json,
@@ -133,6 +134,7 @@ impl From<Language> for LanguageId {
     fn from(value: Language) -> Self {
         match value {
             lang if lang == tree_sitter_cpp::LANGUAGE.into() => Self::Cpp,
+            lang if lang == tree_sitter_c::LANGUAGE.into() => Self::C,
             lang if lang == tree_sitter_python::LANGUAGE.into() => Self::Python,
             lang if lang == tree_sitter_java::LANGUAGE.into() => Self::Java,
             lang if lang == tree_sitter_javascript::LANGUAGE.into() => Self::JavaScript,
@@ -140,6 +142,7 @@ impl From<Language> for LanguageId {
             lang if lang == tree_sitter_typescript::LANGUAGE_TYPESCRIPT.into() => Self::TypeScript,
             lang if lang == tree_sitter_typescript::LANGUAGE_TSX.into() => Self::TypeScriptReact,
             lang if lang == tree_sitter_go::LANGUAGE.into() => Self::Go,
+            lang if lang == tree_sitter_kotlin_sg::LANGUAGE.into() => Self::Kotlin,
             _ => Self::Unknown,
         }
     }
@@ -3,6 +3,7 @@ use std::path::PathBuf;
 use std::error::Error;
 
 use tracing::error;
+use tree_sitter::{Node, Tree};
 
 use crate::codegraph::treesitter::ast_instance_structs::AstSymbolInstanceArc;
 use crate::codegraph::treesitter::language_id::LanguageId;
@@ -15,9 +16,12 @@ mod tests;
 mod utils;
 mod java;
 pub(crate) mod cpp;
+pub(crate) mod c;
 pub(crate) mod ts;
 mod js;
 pub(crate) mod go;
+pub(crate) mod kotlin;
+pub mod registry;
 
 
 #[derive(Debug, PartialEq, Eq)]
@@ -35,6 +39,44 @@ impl Error for ParserError {}
 
 pub trait AstLanguageParser: Send {
     fn parse(&mut self, code: &str, path: &PathBuf) -> Vec<AstSymbolInstanceArc>;
+
+    /// Parses `code` into a syntax tree; passing the previous tree with the edits already
+    /// applied as `old_tree` reparses incrementally
+    fn parse_tree(&mut self, code: &str, old_tree: Option<&Tree>) -> Option<Tree>;
+
+    /// Same as `parse_tree`, but gives up and returns None once `should_abort` returns true.
+    /// The default implementation cannot be interrupted and never calls `should_abort`
+    fn parse_tree_until(&mut self, code: &str, old_tree: Option<&Tree>, _should_abort: &dyn Fn() -> bool) -> Option<Tree> {
+        self.parse_tree(code, old_tree)
+    }
+
+    /// Extracts symbols from the given top-level nodes only; the result matches the
+    /// corresponding part of a parse over the whole tree
+    fn parse_top_level(&mut self, nodes: &[Node], code: &str, path: &PathBuf) -> Vec<AstSymbolInstanceArc>;
+}
+
+/// Syntax rules for cyclomatic complexity. Complexity is 1 plus the number of decision points in
+/// a function body: every node in `decisions`, and every `binary_expression` whose operator is
+/// one of `boolean_operators`
+#[derive(Debug)]
+pub struct ComplexityRule {
+    /// Node kinds of function declarations, starting where the graph's function nodes start;
+    /// nested declarations are counted separately
+    pub functions: &'static [&'static str],
+    /// Node kinds that add one each: branches, loops and case clauses
+    pub decisions: &'static [&'static str],
+    /// Node kind of binary expressions, with the operator in the `operator` field
+    pub binary_expression: &'static str,
+    /// Short-circuiting boolean operators
+    pub boolean_operators: &'static [&'static str],
+}
+
+/// Complexity rule provided by the language's parser; languages without one get no complexity
+pub(crate) fn complexity_rule(language_id: LanguageId) -> Option<&'static ComplexityRule> {
+    match language_id {
+        LanguageId::Go => Some(&go::GO_COMPLEXITY_RULE),
+        _ => None,
+    }
 }
 
 fn internal_error<E: Display>(err: E) -> ParserError {
@@ -63,6 +105,10 @@ pub(crate) fn get_ast_parser(language_id: LanguageId) -> Result<Box<dyn AstLangu
             let parser = cpp::CppParser::new()?;
             Ok(Box::new(parser))
         }
+        LanguageId::C => {
+            let parser = c::CParser::new()?;
+            Ok(Box::new(parser))
+        }
         LanguageId::TypeScript => {
             let parser = ts::TSParser::new()?;
             Ok(Box::new(parser))
@@ -72,13 +118,17 @@ pub(crate) fn get_ast_parser(language_id: LanguageId) -> Result<Box<dyn AstLangu
             Ok(Box::new(parser))
         }
         LanguageId::TypeScriptReact => {
-            let parser = ts::TSParser::new()?; //quick fix untill we have a dedicated parser for TypeScriptReact
+            let parser = ts::TSParser::new_tsx()?;
             Ok(Box::new(parser))
         }
         LanguageId::Go => {
             let parser = go::GoParser::new()?;
             Ok(Box::new(parser))
         }
+        LanguageId::Kotlin => {
+            let parser = kotlin::KotlinParser::new()?;
+            Ok(Box::new(parser))
+        }
         other => Err(ParserError {
             message: "Unsupported language id: ".to_string() + &other.to_string()
         }),
@@ -86,31 +136,35 @@ pub(crate) fn get_ast_parser(language_id: LanguageId) -> Result<Box<dyn AstLangu
 }
 
 
+/// File extensions handled by the built-in parsers
+/// (`.h` may be a C or C++ header and is parsed as C++)
+pub(crate) const BUILTIN_EXTENSIONS: [(LanguageId, &[&str]); 10] = [
+    (LanguageId::Cpp, &["cpp", "cc", "cxx", "c++", "h", "hpp", "hxx", "hh", "inl", "inc", "tpp", "tpl"]),
+    (LanguageId::C, &["c"]),
+    (LanguageId::Python, &["py", "py3", "pyx"]),
+    (LanguageId::Java, &["java"]),
+    (LanguageId::JavaScript, &["js", "jsx"]),
+    (LanguageId::Rust, &["rs"]),
+    (LanguageId::TypeScript, &["ts"]),
+    (LanguageId::TypeScriptReact, &["tsx"]),
+    (LanguageId::Go, &["go"]),
+    (LanguageId::Kotlin, &["kt", "kts"]),
+];
+
+/// Looks up a parser by file extension, including parsers added with `registry::register_parser`.
+/// Registered languages without a matching `LanguageId` report `LanguageId::Unknown`
 pub fn get_ast_parser_by_filename(filename: &PathBuf) -> Result<(Box<dyn AstLanguageParser + 'static>, LanguageId), ParserError> {
     let suffix = filename.extension().and_then(|e| e.to_str()).unwrap_or("").to_lowercase();
-    let maybe_language_id = get_language_id_by_filename(filename);
-    match maybe_language_id {
-        Some(language_id) => {
-            let parser = get_ast_parser(language_id)?;
-            Ok((parser, language_id))
-        }
+    match registry::parser_for(filename) {
+        Some(result) => result,
         None => Err(ParserError { message: format!("not supported {}", suffix) }),
     }
 }
 
 pub fn get_language_id_by_filename(filename: &PathBuf) -> Option<LanguageId> {
     let suffix = filename.extension().and_then(|e| e.to_str()).unwrap_or("").to_lowercase();
-    match suffix.as_str() {
-        "cpp" | "cc" | "cxx" | "c++" | "c" | "h" | "hpp" | "hxx" | "hh" => Some(LanguageId::Cpp),
-        "inl" | "inc" | "tpp" | "tpl" => Some(LanguageId::Cpp),
-        "py" | "py3" | "pyx" => Some(LanguageId::Python),
-        "java" => Some(LanguageId::Java),
-        "js" | "jsx" => Some(LanguageId::JavaScript),
-        "rs" => Some(LanguageId::Rust),
-        "ts" => Some(LanguageId::TypeScript),
-        "tsx" => Some(LanguageId::TypeScriptReact),
-        "go" => Some(LanguageId::Go),
-        _ => None
-    }
+    BUILTIN_EXTENSIONS.iter()
+        .find(|(_, extensions)| extensions.contains(&suffix.as_str()))
+        .map(|(language_id, _)| *language_id)
 }
 
@@ -0,0 +1,545 @@
+use std::collections::{HashMap, VecDeque};
+use std::path::PathBuf;
+use std::sync::Arc;
+use parking_lot::RwLock;
+
+use tree_sitter::{Node, Parser, Tree, Range};
+use similar::DiffableStr;
+
+use crate::codegraph::treesitter::ast_instance_structs::{AstSymbolFields, AstSymbolInstanceArc, ClassFieldDeclaration, CommentDefinition, FunctionArg, FunctionCall, FunctionDeclaration, StructDeclaration, StructKind, TypeAlias, TypeDef, VariableDefinition, VariableKind};
+use crate::codegraph::treesitter::language_id::LanguageId;
+use crate::codegraph::treesitter::parsers::{AstLanguageParser, internal_error, ParserError};
+use crate::codegraph::treesitter::parsers::utils::{CandidateInfo, get_guid, parse_tree_until};
+
+pub(crate) struct CParser {
+    pub parser: Parser,
+}
+
+/// Name of a (possibly nested) declarator: `*name`, `name[4]`, `name = 1`, `(*name)(int)`
+fn declarator_name(declarator: &Node, code: &str) -> Option<String> {
+    let mut node = declarator.clone();
+    loop {
+        match node.kind() {
+            "identifier" | "field_identifier" | "type_identifier" => {
+                return Some(code.slice(node.byte_range()).to_string());
+            }
+            "parenthesized_declarator" => node = node.named_child(0)?,
+            _ => node = node.child_by_field_name("declarator")?,
+        }
+    }
+}
+
+/// Number of pointer levels applied to the declared name: `**argv` -> 2
+fn pointer_depth(declarator: &Node) -> usize {
+    let mut depth = 0;
+    let mut node = Some(declarator.clone());
+    while let Some(current) = node {
+        if current.kind() == "pointer_declarator" {
+            depth += 1;
+        }
+        node = match current.kind() {
+            "parenthesized_declarator" => current.named_child(0),
+            _ => current.child_by_field_name("declarator"),
+        };
+    }
+    depth
+}
+
+/// The function declarator of a function definition or prototype, looking through
+/// pointer return types (`char *name(void)`). Function pointers (`int (*cb)(int)`)
+/// declare variables, not functions, and return None
+fn function_declarator<'a>(declarator: &Node<'a>) -> Option<Node<'a>> {
+    let mut node = declarator.clone();
+    loop {
+        match node.kind() {
+            "pointer_declarator" | "attributed_declarator" => node = node.child_by_field_name("declarator")?,
+            "function_declarator" => {
+                let inner = node.child_by_field_name("declarator")?;
+                return if inner.kind() == "identifier" { Some(node) } else { None };
+            }
+            _ => return None,
+        }
+    }
+}
+
+fn has_storage_class(node: &Node, code: &str, storage_class: &str) -> bool {
+    (0..node.child_count())
+        .filter_map(|i| node.child(i))
+        .any(|child| child.kind() == "storage_class_specifier" && code.slice(child.byte_range()) == storage_class)
+}
+
+fn in_function(node: &Node) -> bool {
+    let mut parent = node.parent();
+    while let Some(current) = parent {
+        if current.kind() == "function_definition" {
+            return true;
+        }
+        parent = current.parent();
+    }
+    false
+}
+
+fn parse_type(type_node: &Node, code: &str) -> Option<TypeDef> {
+    let kind = type_node.kind();
+    match kind {
+        "primitive_type" | "sized_type_specifier" | "type_identifier" => Some(TypeDef {
+            name: Some(code.slice(type_node.byte_range()).to_string()),
+            is_pod: kind != "type_identifier",
+            ..Default::default()
+        }),
+        // struct point, union value, enum color: the tag names the type
+        "struct_specifier" | "union_specifier" | "enum_specifier" => {
+            let name = type_node.child_by_field_name("name")?;
+            Some(TypeDef {
+                name: Some(code.slice(name.byte_range()).to_string()),
+                ..Default::default()
+            })
+        }
+        _ => None,
+    }
+}
+
+/// Type of a declared name, with one `*` per pointer level: `char *name` -> `char*`
+fn declared_type(type_node: &Node, declarator: Option<&Node>, code: &str) -> Option<TypeDef> {
+    let mut type_ = parse_type(type_node, code)?;
+    let depth = declarator.map_or(0, |d| pointer_depth(d));
+    if depth > 0 {
+        type_.name = type_.name.map(|name| format!("{}{}", name, "*".repeat(depth)));
+        type_.is_pod = false;
+    }
+    Some(type_)
+}
+
+impl CParser {
+    pub fn new() -> Result<CParser, ParserError> {
+        let mut parser = Parser::new();
+        parser
+            .set_language(&tree_sitter_c::LANGUAGE.into())
+            .map_err(internal_error)?;
+        Ok(CParser { parser })
+    }
+
+    fn new_fields(&self, info: &CandidateInfo, range: Range) -> AstSymbolFields {
+        let mut fields = AstSymbolFields::default();
+        fields.language = info.ast_fields.language;
+        fields.file_path = info.ast_fields.file_path.clone();
+        fields.is_error = info.ast_fields.is_error;
+        fields.full_range = range;
+        fields.declaration_range = range;
+        fields.definition_range = range;
+        fields.parent_guid = Some(info.parent_guid.clone());
+        fields.guid = get_guid();
+        fields
+    }
+
+    /// struct/union/enum with a body. `name` overrides the tag, used for `typedef struct { ... } Name;`
+    fn parse_struct_specifier<'a>(&mut self, info: &CandidateInfo<'a>, node: &Node<'a>, range: Range, name: Option<String>, code: &str, candidates: &mut VecDeque<CandidateInfo<'a>>) -> Vec<AstSymbolInstanceArc> {
+        let mut symbols: Vec<AstSymbolInstanceArc> = Default::default();
+        let mut decl = StructDeclaration::default();
+        decl.ast_fields = self.new_fields(info, range);
+        decl.kind = match node.kind() {
+            "union_specifier" => StructKind::Union,
+            "enum_specifier" => StructKind::Enum,
+            _ => StructKind::Struct,
+        };
+
+        let tag = node.child_by_field_name("name");
+        decl.ast_fields.name = match (name, tag) {
+            (Some(name), _) => name,
+            (None, Some(tag)) => code.slice(tag.byte_range()).to_string(),
+            (None, None) => format!("anon-{}", decl.ast_fields.guid),
+        };
+
+        if let Some(body) = node.child_by_field_name("body") {
+            // Declaration range covers everything up to the opening brace
+            decl.ast_fields.declaration_range = Range {
+                start_byte: range.start_byte,
+                end_byte: body.start_byte(),
+                start_point: range.start_point,
+                end_point: body.start_position(),
+            };
+            decl.ast_fields.definition_range = body.range();
+            for i in 0..body.child_count() {
+                let child = body.child(i).unwrap();
+                candidates.push_back(CandidateInfo {
+                    ast_fields: decl.ast_fields.clone(),
+                    node: child,
+                    parent_guid: decl.ast_fields.guid.clone(),
+                });
+            }
+        }
+
+        symbols.push(Arc::new(RwLock::new(Box::new(decl))));
+        symbols
+    }
+
+    fn parse_field_declaration<'a>(&mut self, info: &CandidateInfo<'a>, code: &str, candidates: &mut VecDeque<CandidateInfo<'a>>) -> Vec<AstSymbolInstanceArc> {
+        let mut symbols: Vec<AstSymbolInstanceArc> = Default::default();
+        let type_node = info.node.child_by_field_name("type");
+        // Nested definitions: struct outer { struct inner { int x; } in; }
+        if let Some(type_node) = type_node.filter(|t| t.child_by_field_name("body").is_some()) {
+            candidates.push_back(CandidateInfo {
+                ast_fields: info.ast_fields.clone(),
+                node: type_node,
+                parent_guid: info.parent_guid.clone(),
+            });
+        }
+
+        let mut cursor = info.node.walk();
+        for declarator in info.node.children_by_field_name("declarator", &mut cursor) {
+            let name = match declarator_name(&declarator, code) {
+                Some(name) => name,
+                None => continue,
+            };
+            let mut decl = ClassFieldDeclaration::default();
+            decl.ast_fields = self.new_fields(info, info.node.range());
+            decl.ast_fields.name = name;
+            if let Some(type_) = type_node.and_then(|t| declared_type(&t, Some(&declarator), code)) {
+                decl.type_ = type_;
+            }
+            symbols.push(Arc::new(RwLock::new(Box::new(decl))));
+        }
+        symbols
+    }
+
+    fn parse_enumerator<'a>(&mut self, info: &CandidateInfo<'a>, code: &str) -> Vec<AstSymbolInstanceArc> {
+        let mut symbols: Vec<AstSymbolInstanceArc> = Default::default();
+        if let Some(name) = info.node.child_by_field_name("name") {
+            let mut decl = ClassFieldDeclaration::default();
+            decl.ast_fields = self.new_fields(info, info.node.range());
+            decl.ast_fields.name = code.slice(name.byte_range()).to_string();
+            symbols.push(Arc::new(RwLock::new(Box::new(decl))));
+        }
+        symbols
+    }
+
+    fn parse_function_definition<'a>(&mut self, info: &CandidateInfo<'a>, code: &str, candidates: &mut VecDeque<CandidateInfo<'a>>) -> Vec<AstSymbolInstanceArc> {
+        let mut symbols: Vec<AstSymbolInstanceArc> = Default::default();
+        let declarator = match info.node.child_by_field_name("declarator") {
+            Some(declarator) => declarator,
+            None => return symbols,
+        };
+        let function = match function_declarator(&declarator) {
+            Some(function) => function,
+            None => return symbols,
+        };
+
+        let mut decl = FunctionDeclaration::default();
+        decl.ast_fields = self.new_fields(info, info.node.range());
+        decl.ast_fields.name = function.child_by_field_name("declarator")
+            .map(|name| code.slice(name.byte_range()).to_string())
+            .unwrap_or_default();
+        decl.file_local = has_storage_class(&info.node, code, "static");
+        // Declaration range covers the return type, name and parameters
+        decl.ast_fields.declaration_range = Range {
+            start_byte: info.node.start_byte(),
+            end_byte: function.end_byte(),
+            start_point: info.node.start_position(),
+            end_point: function.end_position(),
+        };
+
+        if let Some(type_node) = info.node.child_by_field_name("type") {
+            // `void f()` returns nothing
+            decl.return_type = declared_type(&type_node, Some(&declarator), code)
+                .filter(|t| t.name.as_deref() != Some("void"));
+        }
+        if let Some(parameters) = function.child_by_field_name("parameters") {
+            decl.args = self.parse_parameters(&parameters, code);
+        }
+
+        if let Some(body) = info.node.child_by_field_name("body") {
+            decl.ast_fields.definition_range = body.range();
+            candidates.push_back(CandidateInfo {
+                ast_fields: decl.ast_fields.clone(),
+                node: body,
+                parent_guid: decl.ast_fields.guid.clone(),
+            });
+        }
+
+        symbols.push(Arc::new(RwLock::new(Box::new(decl))));
+        symbols
+    }
+
+    fn parse_parameters(&self, parameters: &Node, code: &str) -> Vec<FunctionArg> {
+        let mut args = vec![];
+        for i in 0..parameters.named_child_count() {
+            let parameter = parameters.named_child(i).unwrap();
+            match parameter.kind() {
+                "parameter_declaration" => {
+                    let declarator = parameter.child_by_field_name("declarator");
+                    let type_ = parameter.child_by_field_name("type")
+                        .and_then(|t| declared_type(&t, declarator.as_ref(), code));
+                    // `int f(void)` has no parameters
+                    if declarator.is_none() && type_.as_ref().and_then(|t| t.name.as_deref()) == Some("void") {
+                        continue;
+                    }
+                    args.push(FunctionArg {
+                        name: declarator.and_then(|d| declarator_name(&d, code)).unwrap_or_default(),
+                        type_,
+                    });
+                }
+                "variadic_parameter" => {
+                    args.push(FunctionArg { name: "...".to_string(), type_: None });
+                }
+                _ => {}
+            }
+        }
+        args
+    }
+
+    /// Top-level `declaration`: global variables. Prototypes and locals only contribute
+    /// the calls in their initializers
+    fn parse_declaration<'a>(&mut self, info: &CandidateInfo<'a>, code: &str, candidates: &mut VecDeque<CandidateInfo<'a>>) -> Vec<AstSymbolInstanceArc> {
+        let mut symbols: Vec<AstSymbolInstanceArc> = Default::default();
+        let type_node = info.node.child_by_field_name("type");
+        // struct point { int x; } origin;
+        if let Some(type_node) = type_node.filter(|t| t.child_by_field_name("body").is_some()) {
+            candidates.push_back(CandidateInfo {
+                ast_fields: info.ast_fields.clone(),
+                node: type_node,
+                parent_guid: info.parent_guid.clone(),
+            });
+        }
+
+        let global = !in_function(&info.node);
+        let mut cursor = info.node.walk();
+        for declarator in info.node.children_by_field_name("declarator", &mut cursor) {
+            if let Some(value) = declarator.child_by_field_name("value") {
+                candidates.push_back(CandidateInfo {
+                    ast_fields: info.ast_fields.clone(),
+                    node: value,
+                    parent_guid: info.parent_guid.clone(),
+                });
+            }
+            if !global || function_declarator(&declarator).is_some() {
+                continue;
+            }
+            let name = match declarator_name(&declarator, code) {
+                Some(name) => name,
+                None => continue,
+            };
+            let mut decl = VariableDefinition::default();
+            decl.ast_fields = self.new_fields(info, info.node.range());
+            decl.ast_fields.name = name;
+            if let Some(type_) = type_node.and_then(|t| declared_type(&t, Some(&declarator), code)) {
+                decl.type_ = type_;
+            }
+            symbols.push(Arc::new(RwLock::new(Box::new(decl))));
+        }
+        symbols
+    }
+
+    /// `typedef struct { ... } Point;` defines the struct under the typedef name,
+    /// `typedef struct point { ... } Point;` defines `point` plus an alias `Point`,
+    /// any other typedef is an alias of the named type
+    fn parse_type_definition<'a>(&mut self, info: &CandidateInfo<'a>, code: &str, candidates: &mut VecDeque<CandidateInfo<'a>>) -> Vec<AstSymbolInstanceArc> {
+        let mut symbols: Vec<AstSymbolInstanceArc> = Default::default();
+        let type_node = match info.node.child_by_field_name("type") {
+            Some(type_node) => type_node,
+            None => return symbols,
+        };
+        let mut cursor = info.node.walk();
+        let mut names = info.node.children_by_field_name("declarator", &mut cursor)
+            .filter_map(|d| declarator_name(&d, code).map(|name| (name, d)))
+            .collect::<Vec<_>>()
+            .into_iter();
+
+        if type_node.child_by_field_name("body").is_some() {
+            match type_node.child_by_field_name("name") {
+                Some(_) => {
+                    let tag_range = type_node.range();
+                    symbols.extend(self.parse_struct_specifier(info, &type_node, tag_range, None, code, candidates));
+                }
+                None => {
+                    let name = names.next().map(|(name, _)| name);
+                    symbols.extend(self.parse_struct_specifier(info, &type_node, info.node.range(), name, code, candidates));
+                }
+            }
+        }
+
+        for (name, declarator) in names {
+            let target = declared_type(&type_node, Some(&declarator), code);
+            // typedef struct point point;
+            if target.as_ref().and_then(|t| t.name.as_ref()) == Some(&name) {
+                continue;
+            }
+            let mut decl = TypeAlias::default();
+            decl.ast_fields = self.new_fields(info, info.node.range());
+            decl.ast_fields.name = name;
+            decl.types = target.into_iter().collect();
+            symbols.push(Arc::new(RwLock::new(Box::new(decl))));
+        }
+        symbols
+    }
+
+    /// `#define NAME value` and `#define NAME(args) body`
+    fn parse_macro<'a>(&mut self, info: &CandidateInfo<'a>, code: &str) -> Vec<AstSymbolInstanceArc> {
+        let mut symbols: Vec<AstSymbolInstanceArc> = Default::default();
+        if let Some(name) = info.node.child_by_field_name("name") {
+            let mut decl = VariableDefinition::default();
+            decl.ast_fields = self.new_fields(info, info.node.range());
+            decl.ast_fields.name = code.slice(name.byte_range()).to_string();
+            decl.kind = if info.node.kind() == "preproc_function_def" {
+                VariableKind::FunctionMacro
+            } else {
+                VariableKind::Macro
+            };
+            symbols.push(Arc::new(RwLock::new(Box::new(decl))));
+        }
+        symbols
+    }
+
+    fn parse_call_expression<'a>(&mut self, info: &CandidateInfo<'a>, code: &str, candidates: &mut VecDeque<CandidateInfo<'a>>) -> Vec<AstSymbolInstanceArc> {
+        let mut symbols: Vec<AstSymbolInstanceArc> = Default::default();
+        let mut decl = FunctionCall::default();
+        decl.ast_fields = self.new_fields(info, info.node.range());
+
+        if let Some(function_node) = info.node.child_by_field_name("function") {
+            match function_node.kind() {
+                // plain call or function-like macro: foo(x)
+                "identifier" => {
+                    decl.ast_fields.name = code.slice(function_node.byte_range()).to_string();
+                }
+                // call through a function pointer member: ops->run(x), ops.run(x)
+                "field_expression" => {
+                    if let Some(field_node) = function_node.child_by_field_name("field") {
+                        decl.ast_fields.name = code.slice(field_node.byte_range()).to_string();
+                    }
+                    if let Some(argument) = function_node.child_by_field_name("argument") {
+                        decl.ast_fields.namespace = code.slice(argument.byte_range()).to_string();
+                        candidates.push_back(CandidateInfo {
+                            ast_fields: info.ast_fields.clone(),
+                            node: argument,
+                            parent_guid: info.parent_guid.clone(),
+                        });
+                    }
+                }
+                // (*fn)(x) and other callee expressions
+                _ => {
+                    candidates.push_back(CandidateInfo {
+                        ast_fields: info.ast_fields.clone(),
+                        node: function_node,
+                        parent_guid: info.parent_guid.clone(),
+                    });
+                }
+            }
+        }
+
+        if let Some(arguments) = info.node.child_by_field_name("arguments") {
+            for i in 0..arguments.child_count() {
+                candidates.push_back(CandidateInfo {
+                    ast_fields: info.ast_fields.clone(),
+                    node: arguments.child(i).unwrap(),
+                    parent_guid: info.parent_guid.clone(),
+                });
+            }
+        }
+
+        if !decl.ast_fields.name.is_empty() {
+            symbols.push(Arc::new(RwLock::new(Box::new(decl))));
+        }
+        symbols
+    }
+
+    fn parse_usages_<'a>(&mut self, info: &CandidateInfo<'a>, code: &str, candidates: &mut VecDeque<CandidateInfo<'a>>) -> Vec<AstSymbolInstanceArc> {
+        let mut symbols: Vec<AstSymbolInstanceArc> = vec![];
+        match info.node.kind() {
+            "function_definition" => {
+                symbols.extend(self.parse_function_definition(info, code, candidates));
+            }
+            "declaration" => {
+                symbols.extend(self.parse_declaration(info, code, candidates));
+            }
+            "type_definition" => {
+                symbols.extend(self.parse_type_definition(info, code, candidates));
+            }
+            "struct_specifier" | "union_specifier" | "enum_specifier" => {
+                if info.node.child_by_field_name("body").is_some() {
+                    let range = info.node.range();
+                    symbols.extend(self.parse_struct_specifier(info, &info.node, range, None, code, candidates));
+                }
+            }
+            "field_declaration" => {
+                symbols.extend(self.parse_field_declaration(info, code, candidates));
+            }
+            "enumerator" => {
+                symbols.extend(self.parse_enumerator(info, code));
+            }
+            "preproc_def" | "preproc_function_def" => {
+                symbols.extend(self.parse_macro(info, code));
+            }
+            "call_expression" => {
+                symbols.extend(self.parse_call_expression(info, code, candidates));
+            }
+            "comment" => {
+                let mut def = CommentDefinition::default();
+                def.ast_fields = self.new_fields(info, info.node.range());
+                def.ast_fields.is_error = false;
+                symbols.push(Arc::new(RwLock::new(Box::new(def))));
+            }
+            _ => {
+                // translation_unit, #ifdef blocks, statements and expressions
+                for i in 0..info.node.child_count() {
+                    let child = info.node.child(i).unwrap();
+                    candidates.push_back(CandidateInfo {
+                        ast_fields: info.ast_fields.clone(),
+                        node: child,
+                        parent_guid: info.parent_guid.clone(),
+                    });
+                }
+            }
+        }
+        symbols
+    }
+
+    fn parse_(&mut self, parent: &Node, code: &str, path: &PathBuf) -> Vec<AstSymbolInstanceArc> {
+        let mut symbols: Vec<AstSymbolInstanceArc> = Default::default();
+        let mut ast_fields = AstSymbolFields::default();
+        ast_fields.file_path = path.clone();
+        ast_fields.is_error = false;
+        ast_fields.language = LanguageId::C;
+
+        let mut candidates = VecDeque::from(vec![CandidateInfo {
+            ast_fields,
+            node: parent.clone(),
+            parent_guid: get_guid(),
+        }]);
+        while let Some(candidate) = candidates.pop_front() {
+            let symbols_l = self.parse_usages_(&candidate, code, &mut candidates);
+            symbols.extend(symbols_l);
+        }
+
+        // Build parent-child relationships
+        let guid_to_symbol_map = symbols.iter()
+            .map(|s| (s.clone().read().guid().clone(), s.clone())).collect::<HashMap<_, _>>();
+        for symbol in symbols.iter_mut() {
+            let guid = symbol.read().guid().clone();
+            if let Some(parent_guid) = symbol.read().parent_guid() {
+                if let Some(parent) = guid_to_symbol_map.get(parent_guid) {
+                    parent.write().fields_mut().childs_guid.push(guid);
+                }
+            }
+        }
+
+        symbols
+    }
+}
+
+impl AstLanguageParser for CParser {
+    fn parse(&mut self, code: &str, path: &PathBuf) -> Vec<AstSymbolInstanceArc> {
+        let tree = self.parser.parse(code, None).unwrap();
+        self.parse_(&tree.root_node(), code, path)
+    }
+
+    fn parse_tree(&mut self, code: &str, old_tree: Option<&Tree>) -> Option<Tree> {
+        self.parser.parse(code, old_tree)
+    }
+
+    fn parse_tree_until(&mut self, code: &str, old_tree: Option<&Tree>, should_abort: &dyn Fn() -> bool) -> Option<Tree> {
+        parse_tree_until(&mut self.parser, code, old_tree, should_abort)
+    }
+
+    fn parse_top_level(&mut self, nodes: &[Node], code: &str, path: &PathBuf) -> Vec<AstSymbolInstanceArc> {
+        nodes.iter().flat_map(|node| self.parse_(node, code, path)).collect()
+    }
+}
@@ -6,13 +6,13 @@ use itertools::Itertools;
 use parking_lot::RwLock;
 
 use similar::DiffableStr;
-use tree_sitter::{Node, Parser, Range};
+use tree_sitter::{Node, Parser, Tree, Range};
 use uuid::Uuid;
 
-use crate::codegraph::treesitter::ast_instance_structs::{AstSymbolFields, AstSymbolInstanceArc, ClassFieldDeclaration, CommentDefinition, FunctionArg, FunctionCall, FunctionDeclaration, ImportDeclaration, ImportType, StructDeclaration, TypeDef, VariableDefinition, VariableUsage};
+use crate::codegraph::treesitter::ast_instance_structs::{AstSymbolFields, AstSymbolInstanceArc, ClassFieldDeclaration, CommentDefinition, FunctionArg, FunctionCall, FunctionDeclaration, ImportDeclaration, ImportType, StructDeclaration, StructKind, TypeDef, VariableDefinition, VariableUsage};
 use crate::codegraph::treesitter::language_id::LanguageId;
 use crate::codegraph::treesitter::parsers::{AstLanguageParser, internal_error, ParserError};
-use crate::codegraph::treesitter::parsers::utils::{CandidateInfo, get_guid};
+use crate::codegraph::treesitter::parsers::utils::{CandidateInfo, get_guid, parse_tree_until};
 use crate::codegraph::treesitter::skeletonizer::SkeletonFormatter;
 use crate::codegraph::treesitter::ast_instance_structs::SymbolInformation;
 use crate::codegraph::treesitter::structs::SymbolType;
@@ -98,6 +98,103 @@ pub fn parse_type(parent: &Node, code: &str) -> Option<TypeDef> {
     None
 }
 
+/// Name given to anonymous namespaces; their declarations are only visible in this file
+pub(crate) const ANONYMOUS_NAMESPACE: &str = "(anonymous namespace)";
+
+/// Template parameter names: `T` and `N` in `template <typename T, int N>`
+fn template_parameters(parameters: &Node, code: &str) -> Vec<TypeDef> {
+    let mut types = vec![];
+    for i in 0..parameters.named_child_count() {
+        let param = parameters.named_child(i).unwrap();
+        let name = match param.kind() {
+            "type_parameter_declaration" | "variadic_type_parameter_declaration" => {
+                (0..param.named_child_count()).filter_map(|j| param.named_child(j)).filter(|n| n.kind() == "type_identifier").last()
+            }
+            "optional_type_parameter_declaration" => param.child_by_field_name("name"),
+            "parameter_declaration" | "optional_parameter_declaration" => {
+                param.child_by_field_name("declarator").filter(|d| d.kind() == "identifier")
+            }
+            // template <typename> class C
+            "template_template_parameter_declaration" => {
+                (0..param.named_child_count()).filter_map(|j| param.named_child(j))
+                    .filter(|n| n.kind() == "type_parameter_declaration")
+                    .last()
+                    .and_then(|n| (0..n.named_child_count()).filter_map(|j| n.named_child(j)).find(|n| n.kind() == "type_identifier"))
+            }
+            _ => None,
+        };
+        if let Some(name) = name {
+            types.push(TypeDef {
+                name: Some(code.slice(name.byte_range()).to_string()),
+                ..TypeDef::default()
+            });
+        }
+    }
+    types
+}
+
+/// Access of a class member: the closest preceding access specifier, or the default when
+/// there is none (`private` for `class`, `public` for `struct` and `union`). None for non-members
+fn member_access(node: &Node, code: &str) -> Option<String> {
+    let mut member = *node;
+    if let Some(parent) = member.parent().filter(|p| p.kind() == "template_declaration") {
+        member = parent;
+    }
+    let body = member.parent().filter(|p| p.kind() == "field_declaration_list")?;
+    let mut sibling = member.prev_sibling();
+    while let Some(node) = sibling {
+        if node.kind() == "access_specifier" {
+            return Some(code.slice(node.byte_range()).trim().to_string());
+        }
+        sibling = node.prev_sibling();
+    }
+    match body.parent()?.kind() {
+        "class_specifier" => Some("private".to_string()),
+        _ => Some("public".to_string()),
+    }
+}
+
+/// Access of an out-of-line member function (`double Circle::area() const { ... }`), taken from
+/// its declaration in the class. Only classes in the same file are searched
+fn declared_access(node: &Node, code: &str, scope: &str, name: &str) -> Option<String> {
+    let class_name = scope.rsplit("::").next()?;
+    let mut root = *node;
+    while let Some(parent) = root.parent() {
+        root = parent;
+    }
+    let mut stack = vec![root];
+    while let Some(current) = stack.pop() {
+        if matches!(current.kind(), "class_specifier" | "struct_specifier" | "union_specifier")
+            && current.child_by_field_name("name").map_or(false, |n| code.slice(n.byte_range()) == class_name) {
+            if let Some(body) = current.child_by_field_name("body") {
+                for i in 0..body.named_child_count() {
+                    let member = body.named_child(i).unwrap();
+                    if matches!(member.kind(), "field_declaration" | "declaration") && declares_function(&member, code, name) {
+                        return member_access(&member, code);
+                    }
+                }
+            }
+        }
+        for i in 0..current.named_child_count() {
+            stack.push(current.named_child(i).unwrap());
+        }
+    }
+    None
+}
+
+/// Whether a member declaration declares a function called `name`, e.g. `double area() const;`
+fn declares_function(member: &Node, code: &str, name: &str) -> bool {
+    let mut declarator = member.child_by_field_name("declarator");
+    while let Some(node) = declarator {
+        if node.kind() == "function_declarator" {
+            return node.child_by_field_name("declarator").map_or(false, |n| code.slice(n.byte_range()) == name);
+        }
+        // Reference declarators have no `declarator` field
+        declarator = node.child_by_field_name("declarator").or_else(|| node.named_child(0));
+    }
+    false
+}
+
 impl CppParser {
     pub fn new() -> Result<CppParser, ParserError> {
         let mut parser = Parser::new();
@@ -160,10 +257,8 @@ impl CppParser {
                         let child = parameters.child(i).unwrap();
                         symbols.extend(self.find_error_usages(&child, code, &info.ast_fields.file_path,
                                                               &decl.ast_fields.guid));
-                        if let Some(arg) = parse_type(&child, code) {
-                            decl.template_types.push(arg);
-                        }
                     }
+                    decl.template_types = template_parameters(&parameters, code);
                 }
             }
         }
@@ -202,6 +297,48 @@ impl CppParser {
         symbols
     }
 
+    /// Namespaces are recorded as `StructKind::Namespace` struct declarations and become the
+    /// parent of the declarations inside them
+    fn parse_namespace_definition<'a>(&mut self, info: &CandidateInfo<'a>, code: &str, candidates: &mut VecDeque<CandidateInfo<'a>>) -> Vec<AstSymbolInstanceArc> {
+        let mut symbols: Vec<AstSymbolInstanceArc> = vec![];
+        let mut decl = StructDeclaration::default();
+        decl.kind = StructKind::Namespace;
+        decl.ast_fields.language = info.ast_fields.language;
+        decl.ast_fields.file_path = info.ast_fields.file_path.clone();
+        decl.ast_fields.is_error = info.ast_fields.is_error;
+        decl.ast_fields.full_range = info.node.range();
+        decl.ast_fields.declaration_range = info.node.range();
+        decl.ast_fields.definition_range = info.node.range();
+        decl.ast_fields.parent_guid = Some(info.parent_guid.clone());
+        decl.ast_fields.guid = get_guid();
+
+        symbols.extend(self.find_error_usages(&info.node, code, &info.ast_fields.file_path, &decl.ast_fields.guid));
+
+        match info.node.child_by_field_name("name") {
+            Some(name) => {
+                decl.ast_fields.name = code.slice(name.byte_range()).to_string();
+                decl.ast_fields.declaration_range = Range {
+                    start_byte: decl.ast_fields.full_range.start_byte,
+                    end_byte: name.end_byte(),
+                    start_point: decl.ast_fields.full_range.start_point,
+                    end_point: name.end_position()
+                };
+            }
+            None => decl.ast_fields.name = ANONYMOUS_NAMESPACE.to_string(),
+        }
+        if let Some(body) = info.node.child_by_field_name("body") {
+            decl.ast_fields.definition_range = body.range();
+            candidates.push_back(CandidateInfo {
+                ast_fields: decl.ast_fields.clone(),
+                node: body,
+                parent_guid: decl.ast_fields.guid.clone(),
+            })
+        }
+
+        symbols.push(Arc::new(RwLock::new(Box::new(decl))));
+        symbols
+    }
+
     fn parse_variable_definition<'a>(&mut self, info: &CandidateInfo<'a>, code: &str, candidates: &mut VecDeque<CandidateInfo<'a>>) -> Vec<AstSymbolInstanceArc> {
         let mut symbols: Vec<AstSymbolInstanceArc> = vec![];
         let mut type_ = TypeDef::default();
@@ -308,6 +445,7 @@ impl CppParser {
             decl.ast_fields.parent_guid = Some(info.parent_guid.clone());
             decl.ast_fields.guid = get_guid();
             decl.ast_fields.name = name_l;
+            decl.access = member_access(&info.node, code);
 
             let local_dtype = dtype.clone();
             if let Some(default_value) = default_value_mb {
@@ -369,7 +507,7 @@ impl CppParser {
             let text = code.slice(parent.byte_range());
         let kind = parent.kind();
         match kind {
-            "identifier" | "field_identifier" => {
+            "identifier" | "field_identifier" | "namespace_identifier" | "destructor_name" | "operator_name" => {
                 name = code.slice(parent.byte_range()).to_string();
             }
             "template_function" | "template_type" => {
@@ -492,19 +630,16 @@ impl CppParser {
             }
             template_parent_node = parent.parent();
         }
+        let mut in_template = false;
         if let Some(template_parent) = template_parent_node {
             if template_parent.kind() == "template_declaration" {
+                in_template = true;
                 if let Some(parameters) = template_parent.child_by_field_name("parameters") {
                     for i in 0..parameters.child_count() {
                         let child = parameters.child(i).unwrap();
-                        let (_, types_l, _, _) =
-                            self.parse_declaration(&child, code, &decl.ast_fields.file_path,
-                                                   &decl.ast_fields.guid, decl.ast_fields.is_error,
-                                                   candidates);
-
-                        decl.template_types.extend(types_l);
                         symbols.extend(self.find_error_usages(&child, code, &decl.ast_fields.file_path, &decl.ast_fields.guid));
                     }
+                    decl.template_types = template_parameters(&parameters, code);
                 }
             }
         }
@@ -520,7 +655,10 @@ impl CppParser {
                 symbols.extend(symbols_l);
                 decl.ast_fields.name = name_l;
                 decl.ast_fields.namespace = namespace_l;
-                decl.template_types = types_l;
+                // Only template parameters are recorded; specialization arguments are not parameters
+                if !in_template {
+                    decl.template_types = types_l;
+                }
             }
             if let Some(parameters) = declarator.child_by_field_name("parameters") {
                 symbols.extend(self.find_error_usages(&parameters, code, &decl.ast_fields.file_path,
@@ -556,6 +694,11 @@ impl CppParser {
             decl.return_type = parse_type(&return_type, code);
         }
 
+        decl.access = member_access(&info.node, code);
+        if decl.access.is_none() && !decl.ast_fields.namespace.is_empty() {
+            decl.access = declared_access(&info.node, code, &decl.ast_fields.namespace, &decl.ast_fields.name);
+        }
+
         if let Some(body_node) = info.node.child_by_field_name("body") {
             decl.ast_fields.definition_range = body_node.range();
             candidates.push_back(CandidateInfo {
@@ -717,6 +860,9 @@ impl CppParser {
             "enum_specifier" | "class_specifier" | "struct_specifier" => {
                 symbols.extend(self.parse_struct_declaration(info, code, candidates));
             }
+            "namespace_definition" => {
+                symbols.extend(self.parse_namespace_definition(info, code, candidates));
+            }
             "declaration" => {
                 symbols.extend(self.parse_variable_definition(info, code, candidates));
             }
@@ -900,6 +1046,18 @@ impl AstLanguageParser for CppParser {
         let symbols = self.parse_(&tree.root_node(), code, path);
         symbols
     }
+
+    fn parse_tree(&mut self, code: &str, old_tree: Option<&Tree>) -> Option<Tree> {
+        self.parser.parse(code, old_tree)
+    }
+
+    fn parse_tree_until(&mut self, code: &str, old_tree: Option<&Tree>, should_abort: &dyn Fn() -> bool) -> Option<Tree> {
+        parse_tree_until(&mut self.parser, code, old_tree, should_abort)
+    }
+
+    fn parse_top_level(&mut self, nodes: &[Node], code: &str, path: &PathBuf) -> Vec<AstSymbolInstanceArc> {
+        nodes.iter().flat_map(|node| self.parse_(node, code, path)).collect()
+    }
 }
 
 pub struct CppSkeletonFormatter;
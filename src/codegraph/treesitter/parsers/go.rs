@@ -3,19 +3,32 @@ use std::path::PathBuf;
 use std::sync::Arc;
 use parking_lot::RwLock;
 
-use tree_sitter::{Node, Parser, Range};
+use tree_sitter::{Node, Parser, Tree, Range};
 use uuid::Uuid;
 use similar::DiffableStr;
 use tracing::debug;
 
-use crate::codegraph::treesitter::ast_instance_structs::{AstSymbolFields, AstSymbolInstanceArc, ClassFieldDeclaration, CommentDefinition, FunctionArg, FunctionDeclaration, ImportDeclaration, ImportType, StructDeclaration, TypeDef, FunctionCall};
+use crate::codegraph::treesitter::ast_instance_structs::{AstSymbolFields, AstSymbolInstanceArc, ClassFieldDeclaration, CommentDefinition, FunctionArg, FunctionDeclaration, FunctionReceiver, ImportDeclaration, ImportType, StructDeclaration, StructKind, TypeDef, FunctionCall, VariableDefinition, VariableKind};
 use crate::codegraph::treesitter::language_id::LanguageId;
-use crate::codegraph::treesitter::parsers::{AstLanguageParser, internal_error, ParserError};
-use crate::codegraph::treesitter::parsers::utils::{CandidateInfo, get_children_guids, get_guid};
+use crate::codegraph::treesitter::parsers::{AstLanguageParser, ComplexityRule, internal_error, ParserError};
+use crate::codegraph::treesitter::parsers::utils::{CandidateInfo, get_children_guids, get_guid, parse_tree_until};
 use crate::codegraph::treesitter::skeletonizer::SkeletonFormatter;
 use crate::codegraph::treesitter::ast_instance_structs::SymbolInformation;
 use crate::codegraph::treesitter::structs::SymbolType;
 
+/// Nesting limit for type expressions, deeper types are kept as text
+const MAX_TYPE_DEPTH: usize = 64;
+
+/// Decision points as counted by gocyclo: `if`, `for` (including `range`), each non-default
+/// `case` of expression, type and select switches, and `&&`/`||`. `else` and `default` add nothing;
+/// function literals count towards the enclosing function
+pub(crate) const GO_COMPLEXITY_RULE: ComplexityRule = ComplexityRule {
+    functions: &["function_declaration", "method_declaration"],
+    decisions: &["if_statement", "for_statement", "expression_case", "type_case", "communication_case"],
+    binary_expression: "binary_expression",
+    boolean_operators: &["&&", "||"],
+};
+
 pub(crate) struct GoParser {
     pub parser: Parser,
 }
@@ -64,6 +77,9 @@ impl GoParser {
         // Find the name from the parent type_declaration
         if let Some(parent) = info.node.parent() {
             if parent.kind() == "type_spec" {
+                if let Some(type_parameters) = parent.child_by_field_name("type_parameters") {
+                    decl.template_types = self.parse_type_parameters(&type_parameters, code);
+                }
                 if let Some(name_node) = parent.child_by_field_name("name") {
                     decl.ast_fields.name = code.slice(name_node.byte_range()).to_string();
                     // Declaration range should be just the struct name
@@ -112,6 +128,86 @@ impl GoParser {
         symbols
     }
 
+    pub fn parse_interface_declaration<'a>(&mut self, info: &CandidateInfo<'a>, code: &str) -> Vec<AstSymbolInstanceArc> {
+        let mut symbols: Vec<AstSymbolInstanceArc> = Default::default();
+        let mut decl = StructDeclaration::default();
+        decl.kind = StructKind::Interface;
+        decl.ast_fields.language = info.ast_fields.language;
+
+        let type_spec = match info.node.parent() {
+            Some(parent) if parent.kind() == "type_spec" => parent,
+            _ => {
+                debug!("anonymous interface: {}", code.slice(info.node.byte_range()).to_string());
+                return symbols;
+            }
+        };
+        decl.ast_fields.full_range = type_spec.parent()
+            .filter(|parent| parent.kind() == "type_declaration")
+            .map(|parent| parent.range())
+            .unwrap_or(type_spec.range());
+        decl.ast_fields.file_path = info.ast_fields.file_path.clone();
+        decl.ast_fields.parent_guid = Some(info.parent_guid.clone());
+        decl.ast_fields.guid = get_guid();
+        decl.ast_fields.is_error = info.ast_fields.is_error;
+        decl.ast_fields.declaration_range = decl.ast_fields.full_range.clone();
+        if let Some(name_node) = type_spec.child_by_field_name("name") {
+            decl.ast_fields.name = code.slice(name_node.byte_range()).to_string();
+            decl.ast_fields.declaration_range = name_node.range();
+        }
+        if let Some(type_parameters) = type_spec.child_by_field_name("type_parameters") {
+            decl.template_types = self.parse_type_parameters(&type_parameters, code);
+        }
+
+        for i in 0..info.node.named_child_count() {
+            let child = info.node.named_child(i).unwrap();
+            match child.kind() {
+                "method_elem" | "method_spec" => {
+                    symbols.extend(self.parse_method_elem(&child, &decl.ast_fields, code));
+                }
+                // Embedded interfaces: interface { io.Reader; Shaper }
+                "type_elem" | "type_identifier" | "qualified_type" => {
+                    let type_nodes = if child.kind() == "type_elem" {
+                        (0..child.named_child_count()).filter_map(|j| child.named_child(j)).collect::<Vec<_>>()
+                    } else {
+                        vec![child]
+                    };
+                    for type_node in type_nodes {
+                        if matches!(type_node.kind(), "type_identifier" | "qualified_type") {
+                            decl.inherited_types.push(self.parse_type_or_text(&type_node, code));
+                        }
+                    }
+                }
+                _ => {}
+            }
+        }
+
+        decl.ast_fields.definition_range = info.node.range();
+        decl.ast_fields.childs_guid = get_children_guids(&decl.ast_fields.guid, &symbols);
+        symbols.push(Arc::new(RwLock::new(Box::new(decl))));
+        symbols
+    }
+
+    fn parse_method_elem(&mut self, node: &Node, parent: &AstSymbolFields, code: &str) -> Option<AstSymbolInstanceArc> {
+        let name_node = node.child_by_field_name("name")?;
+        let mut decl = FunctionDeclaration::default();
+        decl.ast_fields.language = parent.language;
+        decl.ast_fields.full_range = node.range();
+        decl.ast_fields.declaration_range = node.range();
+        decl.ast_fields.definition_range = node.range();
+        decl.ast_fields.file_path = parent.file_path.clone();
+        decl.ast_fields.parent_guid = Some(parent.guid.clone());
+        decl.ast_fields.guid = get_guid();
+        decl.ast_fields.is_error = parent.is_error;
+        decl.ast_fields.name = code.slice(name_node.byte_range()).to_string();
+        if let Some(parameters_node) = node.child_by_field_name("parameters") {
+            decl.args = self.parse_parameters(&parameters_node, code);
+        }
+        if let Some(result_node) = node.child_by_field_name("result") {
+            decl.return_type = self.parse_result(&result_node, code);
+        }
+        Some(Arc::new(RwLock::new(Box::new(decl))))
+    }
+
     fn parse_field_declaration<'a>(&mut self, info: &CandidateInfo<'a>, code: &str) -> Vec<AstSymbolInstanceArc> {
         let mut symbols: Vec<AstSymbolInstanceArc> = vec![];
         
@@ -146,12 +242,103 @@ impl GoParser {
 
                 let _field_name = decl.ast_fields.name.clone();
                 symbols.push(Arc::new(RwLock::new(Box::new(decl))));
+            } else if info.node.child_by_field_name("name").is_none() {
+                symbols.extend(self.parse_embedded_field(info, code));
             }
         }
 
         symbols
     }
 
+    /// `var p Point`, `const n = 3`, `p := NewPoint(1, 2)`: one variable per declared name.
+    /// Without a written type the value's type is inferred like for call receivers;
+    /// the values are parsed afterwards with the enclosing declaration as parent
+    fn parse_variable_declaration<'a>(&mut self, info: &CandidateInfo<'a>, code: &str, candidates: &mut VecDeque<CandidateInfo<'a>>) -> Vec<AstSymbolInstanceArc> {
+        let mut symbols: Vec<AstSymbolInstanceArc> = vec![];
+        let (names, type_node, value) = if info.node.kind() == "short_var_declaration" {
+            let names = info.node.child_by_field_name("left")
+                .map(|left| (0..left.named_child_count()).filter_map(|i| left.named_child(i)).collect::<Vec<_>>())
+                .unwrap_or_default();
+            (names, None, info.node.child_by_field_name("right"))
+        } else {
+            let mut cursor = info.node.walk();
+            let names = info.node.children_by_field_name("name", &mut cursor).collect::<Vec<_>>();
+            (names, info.node.child_by_field_name("type"), info.node.child_by_field_name("value"))
+        };
+        let values = value
+            .map(|value| (0..value.named_child_count()).filter_map(|i| value.named_child(i)).collect::<Vec<_>>())
+            .unwrap_or_default();
+
+        for (idx, name) in names.iter().enumerate() {
+            let name_text = code.slice(name.byte_range()).to_string();
+            if name.kind() != "identifier" || name_text == "_" {
+                continue;
+            }
+            let mut decl = VariableDefinition::default();
+            decl.ast_fields.language = info.ast_fields.language;
+            decl.ast_fields.full_range = info.node.range();
+            decl.ast_fields.declaration_range = name.range();
+            decl.ast_fields.definition_range = info.node.range();
+            decl.ast_fields.file_path = info.ast_fields.file_path.clone();
+            decl.ast_fields.parent_guid = Some(info.parent_guid.clone());
+            decl.ast_fields.guid = get_guid();
+            decl.ast_fields.name = name_text;
+            decl.ast_fields.is_error = info.ast_fields.is_error;
+            if info.node.kind() == "const_spec" {
+                decl.kind = VariableKind::Constant;
+            }
+            if let Some(type_node) = type_node {
+                decl.type_ = self.parse_type_or_text(&type_node, code);
+            } else if values.len() == names.len() {
+                decl.type_ = self.infer_expression_type(&values[idx], code);
+            }
+            symbols.push(Arc::new(RwLock::new(Box::new(decl))));
+        }
+
+        for i in 0..info.node.child_count() {
+            candidates.push_back(CandidateInfo {
+                ast_fields: info.ast_fields.clone(),
+                node: info.node.child(i).unwrap(),
+                parent_guid: info.parent_guid.clone(),
+            });
+        }
+        symbols
+    }
+
+    /// Embedded field: `Point`, `*Point`, `geo.Point` or `List[T]`. The field is named after
+    /// the type without its package qualifier and type arguments
+    fn parse_embedded_field<'a>(&mut self, info: &CandidateInfo<'a>, code: &str) -> Option<AstSymbolInstanceArc> {
+        let type_node = info.node.child_by_field_name("type")?;
+        let name_node = match type_node.kind() {
+            "type_identifier" => type_node,
+            "qualified_type" => type_node.child_by_field_name("name")?,
+            "generic_type" => type_node.child_by_field_name("type")?,
+            _ => return None,
+        };
+        let mut type_ = self.parse_type_or_text(&type_node, code);
+        let is_pointer = info.node.child(0).map_or(false, |child| child.kind() == "*");
+        if is_pointer {
+            type_ = TypeDef {
+                name: Some(format!("*{}", type_.name.clone().unwrap_or_default())),
+                nested_types: vec![type_],
+                ..Default::default()
+            };
+        }
+
+        let mut decl = ClassFieldDeclaration::default();
+        decl.ast_fields.language = info.ast_fields.language;
+        decl.ast_fields.full_range = info.node.range();
+        decl.ast_fields.declaration_range = type_node.range();
+        decl.ast_fields.file_path = info.ast_fields.file_path.clone();
+        decl.ast_fields.parent_guid = Some(info.parent_guid.clone());
+        decl.ast_fields.guid = get_guid();
+        decl.ast_fields.name = code.slice(name_node.byte_range()).to_string();
+        decl.ast_fields.is_error = info.ast_fields.is_error;
+        decl.type_ = type_;
+        decl.embedded = true;
+        Some(Arc::new(RwLock::new(Box::new(decl))))
+    }
+
     fn parse_function_declaration<'a>(&mut self, info: &CandidateInfo<'a>, code: &str, candidates: &mut VecDeque<CandidateInfo<'a>>) -> Vec<AstSymbolInstanceArc> {
         let mut symbols: Vec<AstSymbolInstanceArc> = Default::default();
         let mut decl = FunctionDeclaration::default();
@@ -171,6 +358,11 @@ impl GoParser {
             decl.ast_fields.name = code.slice(name_node.byte_range()).to_string();
         }
 
+        // Parse type parameters: func Map[T any, U comparable]
+        if let Some(type_parameters) = info.node.child_by_field_name("type_parameters") {
+            decl.template_types = self.parse_type_parameters(&type_parameters, code);
+        }
+
         // Parse parameters
         if let Some(parameters_node) = info.node.child_by_field_name("parameters") {
             decl.args = self.parse_parameters(&parameters_node, code);
@@ -185,7 +377,7 @@ impl GoParser {
 
         // Parse return type
         if let Some(result_node) = info.node.child_by_field_name("result") {
-            decl.return_type = self.parse_type(&result_node, code);
+            decl.return_type = self.parse_result(&result_node, code);
             // Declaration range should extend to include the return type
             decl.ast_fields.declaration_range = Range {
                 start_byte: decl.ast_fields.full_range.start_byte,
@@ -234,6 +426,7 @@ impl GoParser {
 
         // Parse receiver
         if let Some(receiver_node) = info.node.child_by_field_name("receiver") {
+            decl.receiver = self.parse_receiver(&receiver_node, code);
             candidates.push_back(CandidateInfo {
                 ast_fields: info.ast_fields.clone(),
                 node: receiver_node,
@@ -255,7 +448,7 @@ impl GoParser {
 
         // Parse return type
         if let Some(result_node) = info.node.child_by_field_name("result") {
-            decl.return_type = self.parse_type(&result_node, code);
+            decl.return_type = self.parse_result(&result_node, code);
             // Declaration range should extend to include the return type
             decl.ast_fields.declaration_range = Range {
                 start_byte: decl.ast_fields.full_range.start_byte,
@@ -283,40 +476,137 @@ impl GoParser {
         symbols
     }
 
+    fn parse_receiver(&self, parent: &Node, code: &str) -> Option<FunctionReceiver> {
+        for i in 0..parent.child_count() {
+            let child = parent.child(i).unwrap();
+            if child.kind() != "parameter_declaration" {
+                continue;
+            }
+            // Receiver name is optional: func (Point) Name()
+            let name = child.child_by_field_name("name")
+                .map(|name_node| code.slice(name_node.byte_range()).to_string());
+            let mut type_node = child.child_by_field_name("type")?;
+            let mut is_pointer = false;
+            if type_node.kind() == "pointer_type" {
+                is_pointer = true;
+                type_node = type_node.named_child(0)?;
+            }
+            // Receiver of a generic type: func (s *Stack[T]) Push(v T)
+            if type_node.kind() == "generic_type" {
+                type_node = type_node.child_by_field_name("type")?;
+            }
+            let type_ = self.parse_type(&type_node, code).unwrap_or(TypeDef {
+                name: Some(code.slice(type_node.byte_range()).to_string()),
+                ..Default::default()
+            });
+            return Some(FunctionReceiver { name, type_, is_pointer });
+        }
+        None
+    }
+
     fn parse_parameters(&self, parent: &Node, code: &str) -> Vec<FunctionArg> {
         let mut args: Vec<FunctionArg> = vec![];
         
         for i in 0..parent.child_count() {
             let child = parent.child(i).unwrap();
-            if child.kind() == "parameter_declaration" {
-                // Parse parameter names
-                if let Some(names_node) = child.child_by_field_name("name") {
-                    for j in 0..names_node.child_count() {
-                        let name_child = names_node.child(j).unwrap();
-                        if name_child.kind() == "identifier" {
-                            let mut arg = FunctionArg {
-                                name: code.slice(name_child.byte_range()).to_string(),
-                                type_: None,
-                            };
-                            
-                            // Parse parameter type
-                            if let Some(type_node) = child.child_by_field_name("type") {
-                                if let Some(type_) = self.parse_type(&type_node, code) {
-                                    arg.type_ = Some(type_);
-                                }
-                            }
-                            
-                            args.push(arg);
-                        }
+            if child.kind() != "parameter_declaration" && child.kind() != "variadic_parameter_declaration" {
+                continue;
+            }
+            let type_ = child.child_by_field_name("type").map(|type_node| {
+                let type_ = self.parse_type_or_text(&type_node, code);
+                if child.kind() == "variadic_parameter_declaration" {
+                    TypeDef {
+                        name: Some(format!("...{}", type_.name.clone().unwrap_or_default())),
+                        nested_types: vec![type_],
+                        ..Default::default()
                     }
+                } else {
+                    type_
                 }
+            });
+            // Several names may share one type: func(x, y int)
+            let mut cursor = child.walk();
+            let names = child.children_by_field_name("name", &mut cursor).collect::<Vec<_>>();
+            if names.is_empty() {
+                // Unnamed parameter: func(int)
+                args.push(FunctionArg { name: String::new(), type_ });
+            }
+            for name_node in names {
+                args.push(FunctionArg {
+                    name: code.slice(name_node.byte_range()).to_string(),
+                    type_: type_.clone(),
+                });
             }
         }
         
         args
     }
 
+    /// Type parameters, one per name, with the constraint types as nested types:
+    /// `[K comparable, V int | string]` gives `K(comparable)` and `V(int, string)`
+    fn parse_type_parameters(&self, parent: &Node, code: &str) -> Vec<TypeDef> {
+        let mut params = vec![];
+        for i in 0..parent.named_child_count() {
+            let child = parent.named_child(i).unwrap();
+            if child.kind() != "type_parameter_declaration" {
+                continue;
+            }
+            let constraints = child.child_by_field_name("type")
+                .map(|constraint| self.parse_constraint(&constraint, code))
+                .unwrap_or_default();
+            let mut cursor = child.walk();
+            for name_node in child.children_by_field_name("name", &mut cursor) {
+                params.push(TypeDef {
+                    name: Some(code.slice(name_node.byte_range()).to_string()),
+                    nested_types: constraints.clone(),
+                    ..Default::default()
+                });
+            }
+        }
+        params
+    }
+
+    /// Terms of a type constraint; a union `~int | string` gives one type per term
+    fn parse_constraint(&self, constraint: &Node, code: &str) -> Vec<TypeDef> {
+        match constraint.kind() {
+            "type_constraint" | "type_elem" | "union_type" => {
+                (0..constraint.named_child_count())
+                    .filter_map(|i| constraint.named_child(i))
+                    .flat_map(|term| self.parse_constraint(&term, code))
+                    .collect()
+            }
+            _ => vec![self.parse_type_or_text(constraint, code)],
+        }
+    }
+
+    /// Result type; multiple results are named after their types: `(int, error)`
+    fn parse_result(&self, result_node: &Node, code: &str) -> Option<TypeDef> {
+        if result_node.kind() != "parameter_list" {
+            return Some(self.parse_type_or_text(result_node, code));
+        }
+        let types = self.parse_parameters(result_node, code).into_iter()
+            .map(|arg| arg.type_.unwrap_or_default())
+            .collect::<Vec<_>>();
+        let names = types.iter()
+            .map(|t| t.name.clone().unwrap_or_default())
+            .collect::<Vec<_>>();
+        Some(TypeDef {
+            name: Some(format!("({})", names.join(", "))),
+            nested_types: types,
+            ..Default::default()
+        })
+    }
+
     fn parse_type(&self, parent: &Node, code: &str) -> Option<TypeDef> {
+        self.parse_nested_type(parent, code, 0)
+    }
+
+    /// Types nested deeper than MAX_TYPE_DEPTH (e.g. `[][][]...int` in malformed input)
+    /// are not parsed, callers fall back to the type's text
+    fn parse_nested_type(&self, parent: &Node, code: &str, depth: usize) -> Option<TypeDef> {
+        if depth > MAX_TYPE_DEPTH {
+            return None;
+        }
         let kind = parent.kind();
         let text = code.slice(parent.byte_range()).to_string();
         
@@ -344,8 +634,8 @@ impl GoParser {
                 });
             }
             "pointer_type" => {
-                if let Some(child) = parent.child(0) {
-                    if let Some(child_type) = self.parse_type(&child, code) {
+                if let Some(child) = parent.named_child(0) {
+                    if let Some(child_type) = self.parse_nested_type(&child, code, depth + 1) {
                         let child_name = child_type.name.clone();
                         return Some(TypeDef {
                             name: Some(format!("*{}", child_name.unwrap_or_default())),
@@ -361,7 +651,7 @@ impl GoParser {
             }
             "slice_type" => {
                 if let Some(child) = parent.child(0) {
-                    if let Some(child_type) = self.parse_type(&child, code) {
+                    if let Some(child_type) = self.parse_nested_type(&child, code, depth + 1) {
                         let child_name = child_type.name.clone();
                         return Some(TypeDef {
                             name: Some(format!("[]{}", child_name.unwrap_or_default())),
@@ -377,7 +667,7 @@ impl GoParser {
             }
             "array_type" => {
                 if let Some(element_node) = parent.child_by_field_name("element") {
-                    if let Some(element_type) = self.parse_type(&element_node, code) {
+                    if let Some(element_type) = self.parse_nested_type(&element_node, code, depth + 1) {
                         let element_name = element_type.name.clone();
                         return Some(TypeDef {
                             name: Some(format!("[]{}", element_name.unwrap_or_default())),
@@ -416,7 +706,7 @@ impl GoParser {
             "map_type" => {
                 if let Some(key_node) = parent.child_by_field_name("key") {
                     if let Some(value_node) = parent.child_by_field_name("value") {
-                        if let (Some(key_type), Some(value_type)) = (self.parse_type(&key_node, code), self.parse_type(&value_node, code)) {
+                        if let (Some(key_type), Some(value_type)) = (self.parse_nested_type(&key_node, code, depth + 1), self.parse_nested_type(&value_node, code, depth + 1)) {
                             let mut nested_types = vec![key_type];
                             nested_types.extend(vec![value_type]);
                             return Some(TypeDef {
@@ -434,7 +724,7 @@ impl GoParser {
             }
             "channel_type" => {
                 if let Some(child) = parent.child(0) {
-                    if let Some(child_type) = self.parse_type(&child, code) {
+                    if let Some(child_type) = self.parse_nested_type(&child, code, depth + 1) {
                         let child_name = child_type.name.clone();
                         return Some(TypeDef {
                             name: Some(format!("chan {}", child_name.unwrap_or_default())),
@@ -477,93 +767,62 @@ impl GoParser {
                 for i in 0..import_node.child_count() {
                     let child = import_node.child(i).unwrap();
                     if child.kind() == "import_spec" {
-                        let mut decl = ImportDeclaration::default();
-                        decl.ast_fields.language = info.ast_fields.language;
-                        // Use the full import declaration range, not just the import spec
-                        decl.ast_fields.full_range = info.node.range();
-                        decl.ast_fields.file_path = info.ast_fields.file_path.clone();
-                        decl.ast_fields.parent_guid = Some(info.parent_guid.clone());
-                        decl.ast_fields.guid = get_guid();
-                        decl.ast_fields.is_error = info.ast_fields.is_error;
-
-                        // Parse import path
-                        if let Some(path_node) = child.child_by_field_name("path") {
-                            let path_text = code.slice(path_node.byte_range()).to_string();
-                            // Remove quotes
-                            let path_text = path_text.trim_matches('"');
-                            decl.path_components = path_text.split('/').map(|s| s.to_string()).collect();
-                            // Don't set the name for import declarations - keep it empty
-                            // decl.ast_fields.name = decl.path_components.last().unwrap_or(&"".to_string()).clone();
-                        }
-
-                        // Parse import name/alias
-                        if let Some(name_node) = child.child_by_field_name("name") {
-                            if name_node.kind() == "dot" {
-                                decl.ast_fields.name = ".".to_string();
-                            } else if name_node.kind() == "blank_identifier" {
-                                decl.ast_fields.name = "_".to_string();
-                            } else {
-                                decl.ast_fields.name = code.slice(name_node.byte_range()).to_string();
-                            }
-                        }
-
-                        // Determine import type
-                        if let Some(first) = decl.path_components.first() {
-                            if first.starts_with(".") {
-                                decl.import_type = ImportType::UserModule;
-                            } else {
-                                decl.import_type = ImportType::System;
-                            }
-                        }
-
+                        // Each spec in a group gets its own range
+                        let decl = self.parse_import_spec(info, &child, child.range(), code);
                         symbols.push(Arc::new(RwLock::new(Box::new(decl))));
                     }
                 }
             } else if import_node.kind() == "import_spec" {
-                let mut decl = ImportDeclaration::default();
-                decl.ast_fields.language = info.ast_fields.language;
                 // Use the full import declaration range
-                decl.ast_fields.full_range = info.node.range();
-                decl.ast_fields.file_path = info.ast_fields.file_path.clone();
-                decl.ast_fields.parent_guid = Some(info.parent_guid.clone());
-                decl.ast_fields.guid = get_guid();
-                decl.ast_fields.is_error = info.ast_fields.is_error;
+                let decl = self.parse_import_spec(info, &import_node, info.node.range(), code);
+                symbols.push(Arc::new(RwLock::new(Box::new(decl))));
+            }
+        }
 
-                // Parse import path
-                if let Some(path_node) = import_node.child_by_field_name("path") {
-                    let path_text = code.slice(path_node.byte_range()).to_string();
-                    // Remove quotes
-                    let path_text = path_text.trim_matches('"');
-                    decl.path_components = path_text.split('/').map(|s| s.to_string()).collect();
-                    // Don't set the name for import declarations - keep it empty
-                    // decl.ast_fields.name = decl.path_components.last().unwrap_or(&"".to_string()).clone();
-                }
-
-                // Parse import name/alias
-                if let Some(name_node) = import_node.child_by_field_name("name") {
-                    if name_node.kind() == "dot" {
-                        decl.ast_fields.name = ".".to_string();
-                    } else if name_node.kind() == "blank_identifier" {
-                        decl.ast_fields.name = "_".to_string();
-                    } else {
-                        decl.ast_fields.name = code.slice(name_node.byte_range()).to_string();
-                    }
-                }
+        symbols
+    }
 
-                // Determine import type
-                if let Some(first) = decl.path_components.first() {
-                    if first.starts_with(".") {
-                        decl.import_type = ImportType::UserModule;
-                    } else {
-                        decl.import_type = ImportType::System;
-                    }
-                }
+    fn parse_import_spec<'a>(&mut self, info: &CandidateInfo<'a>, spec: &Node, range: Range, code: &str) -> ImportDeclaration {
+        let mut decl = ImportDeclaration::default();
+        decl.ast_fields.language = info.ast_fields.language;
+        decl.ast_fields.full_range = range;
+        decl.ast_fields.file_path = info.ast_fields.file_path.clone();
+        decl.ast_fields.parent_guid = Some(info.parent_guid.clone());
+        decl.ast_fields.guid = get_guid();
+        decl.ast_fields.is_error = info.ast_fields.is_error;
 
-                symbols.push(Arc::new(RwLock::new(Box::new(decl))));
+        // Parse import path, both "interpreted" and `raw` string literals
+        if let Some(path_node) = spec.child_by_field_name("path") {
+            let path_text = code.slice(path_node.byte_range()).to_string();
+            let path_text = path_text.strip_prefix(|c: char| c == '"' || c == '`').unwrap_or(&path_text);
+            let path_text = path_text.strip_suffix(|c: char| c == '"' || c == '`').unwrap_or(path_text);
+            decl.path_components = path_text.split('/').map(|s| s.to_string()).collect();
+            decl.path = Some(path_text.to_string());
+            // Don't set the name for import declarations - keep it empty
+            // decl.ast_fields.name = decl.path_components.last().unwrap_or(&"".to_string()).clone();
+        }
+
+        // Parse import name/alias
+        if let Some(name_node) = spec.child_by_field_name("name") {
+            if name_node.kind() == "dot" {
+                decl.ast_fields.name = ".".to_string();
+            } else if name_node.kind() == "blank_identifier" {
+                decl.ast_fields.name = "_".to_string();
+            } else {
+                decl.ast_fields.name = code.slice(name_node.byte_range()).to_string();
             }
+            decl.alias = Some(decl.ast_fields.name.clone());
         }
 
-        symbols
+        // Determine import type
+        if let Some(first) = decl.path_components.first() {
+            if first.starts_with(".") {
+                decl.import_type = ImportType::UserModule;
+            } else {
+                decl.import_type = ImportType::System;
+            }
+        }
+        decl
     }
 
     fn parse_usages_<'a>(&mut self, info: &CandidateInfo<'a>, code: &str, candidates: &mut VecDeque<CandidateInfo<'a>>) -> Vec<AstSymbolInstanceArc> {
@@ -574,6 +833,9 @@ impl GoParser {
             "struct_type" => {
                 symbols.extend(self.parse_struct_declaration(info, code, candidates));
             }
+            "interface_type" => {
+                symbols.extend(self.parse_interface_declaration(info, code));
+            }
             "function_declaration" => {
                 symbols.extend(self.parse_function_declaration(info, code, candidates));
             }
@@ -621,6 +883,22 @@ impl GoParser {
             "call_expression" => {
                 symbols.extend(self.parse_call_expression(info, code, candidates));
             }
+            "ERROR" => {
+                // Symbols recovered from a syntax error are kept but marked
+                let mut ast_fields = info.ast_fields.clone();
+                ast_fields.is_error = true;
+                for i in 0..info.node.child_count() {
+                    let child = info.node.child(i).unwrap();
+                    candidates.push_back(CandidateInfo {
+                        ast_fields: ast_fields.clone(),
+                        node: child,
+                        parent_guid: info.parent_guid.clone(),
+                    });
+                }
+            }
+            "var_spec" | "const_spec" | "short_var_declaration" => {
+                symbols.extend(self.parse_variable_declaration(info, code, candidates));
+            }
             _ => {
                 // Recursively process child nodes, but don't parse every identifier
                 for i in 0..info.node.child_count() {
@@ -683,10 +961,18 @@ impl GoParser {
         decl.ast_fields.is_error = info.ast_fields.is_error;
 
         // Extract function name
-        if let Some(function_node) = info.node.child_by_field_name("function") {
+        if let Some(mut function_node) = info.node.child_by_field_name("function") {
+            // explicit instantiation of a generic function: Map[int, string](xs)
+            while let Some(generic_node) = match function_node.kind() {
+                "type_instantiation_expression" | "generic_type" => function_node.child_by_field_name("type"),
+                "index_expression" => function_node.child_by_field_name("operand"),
+                _ => None,
+            } {
+                function_node = generic_node;
+            }
             match function_node.kind() {
                 // simple function call: foo()
-                "identifier" => {
+                "identifier" | "type_identifier" => {
                     decl.ast_fields.name = code.slice(function_node.byte_range()).to_string();
                 }
                 // method or selector call: pkg.Func() or obj.Method()
@@ -695,6 +981,8 @@ impl GoParser {
                         decl.ast_fields.name = code.slice(field_node.byte_range()).to_string();
                     }
                     if let Some(expr_node) = function_node.child_by_field_name("operand") {
+                        decl.ast_fields.namespace = code.slice(expr_node.byte_range()).to_string();
+                        decl.ast_fields.linked_decl_type = self.infer_operand_type(&expr_node, code);
                         candidates.push_back(CandidateInfo {
                             ast_fields: decl.ast_fields.clone(),
                             node: expr_node,
@@ -731,10 +1019,163 @@ impl GoParser {
     }
 }
 
+impl GoParser {
+    /// Infers the type of the operand in a selector call, e.g. `p` in `p.Move()`
+    fn infer_operand_type(&self, operand: &Node, code: &str) -> Option<TypeDef> {
+        if operand.kind() != "identifier" {
+            return None;
+        }
+        let name = code.slice(operand.byte_range());
+        let mut parent_mb = operand.parent();
+        while let Some(parent) = parent_mb {
+            match parent.kind() {
+                "function_declaration" | "method_declaration" => {
+                    return self.find_local_type(&parent, name, operand.start_byte(), code);
+                }
+                // closures can capture variables of the enclosing function
+                "func_literal" => {
+                    if let Some(type_) = self.find_local_type(&parent, name, operand.start_byte(), code) {
+                        return Some(type_);
+                    }
+                }
+                _ => {}
+            }
+            parent_mb = parent.parent();
+        }
+        None
+    }
+
+    /// Looks up the variable in the enclosing function's receiver, parameters and body,
+    /// taking the closest declaration before the usage
+    fn find_local_type(&self, function: &Node, name: &str, before: usize, code: &str) -> Option<TypeDef> {
+        let mut found: Option<(usize, TypeDef)> = None;
+        for field in ["receiver", "parameters"] {
+            if let Some(params) = function.child_by_field_name(field) {
+                for i in 0..params.child_count() {
+                    let param = params.child(i).unwrap();
+                    if param.kind() != "parameter_declaration" {
+                        continue;
+                    }
+                    let mut cursor = param.walk();
+                    let is_match = param.children_by_field_name("name", &mut cursor)
+                        .any(|n| code.slice(n.byte_range()) == name);
+                    if let (true, Some(type_node)) = (is_match, param.child_by_field_name("type")) {
+                        found = Some((param.start_byte(), self.parse_type_or_text(&type_node, code)));
+                    }
+                }
+            }
+        }
+
+        let body = function.child_by_field_name("body")?;
+        let mut stack = vec![body];
+        while let Some(node) = stack.pop() {
+            if node.start_byte() >= before {
+                continue;
+            }
+            let mut declared: Option<TypeDef> = None;
+            match node.kind() {
+                // nested closures have their own scope
+                "func_literal" => continue,
+                "short_var_declaration" => {
+                    if let (Some(left), Some(right)) = (node.child_by_field_name("left"), node.child_by_field_name("right")) {
+                        let lefts = (0..left.named_child_count()).filter_map(|i| left.named_child(i)).collect::<Vec<_>>();
+                        let rights = (0..right.named_child_count()).filter_map(|i| right.named_child(i)).collect::<Vec<_>>();
+                        if lefts.len() == rights.len() {
+                            if let Some(idx) = lefts.iter().position(|n| code.slice(n.byte_range()) == name) {
+                                declared = Some(self.infer_expression_type(&rights[idx], code));
+                            }
+                        }
+                    }
+                }
+                "var_spec" => {
+                    let mut cursor = node.walk();
+                    let names = node.children_by_field_name("name", &mut cursor).collect::<Vec<_>>();
+                    if let Some(idx) = names.iter().position(|n| code.slice(n.byte_range()) == name) {
+                        if let Some(type_node) = node.child_by_field_name("type") {
+                            declared = Some(self.parse_type_or_text(&type_node, code));
+                        } else if let Some(value) = node.child_by_field_name("value") {
+                            let values = (0..value.named_child_count()).filter_map(|i| value.named_child(i)).collect::<Vec<_>>();
+                            if values.len() == names.len() {
+                                declared = Some(self.infer_expression_type(&values[idx], code));
+                            }
+                        }
+                    }
+                }
+                _ => {}
+            }
+            if let Some(type_) = declared {
+                if found.as_ref().map_or(true, |(start, _)| *start < node.start_byte()) {
+                    found = Some((node.start_byte(), type_));
+                }
+            }
+            for i in 0..node.child_count() {
+                stack.push(node.child(i).unwrap());
+            }
+        }
+        found.map(|(_, type_)| type_)
+    }
+
+    /// Static type of an expression; when it can't be determined directly,
+    /// the expression text is left in inference_info
+    fn infer_expression_type(&self, value: &Node, code: &str) -> TypeDef {
+        match value.kind() {
+            "composite_literal" => {
+                if let Some(type_node) = value.child_by_field_name("type") {
+                    return self.parse_type_or_text(&type_node, code);
+                }
+            }
+            "unary_expression" => {
+                if let Some(operand) = value.child_by_field_name("operand") {
+                    if operand.kind() == "composite_literal" {
+                        return self.infer_expression_type(&operand, code);
+                    }
+                }
+            }
+            "call_expression" => {
+                let function = value.child_by_field_name("function");
+                let arguments = value.child_by_field_name("arguments");
+                if let (Some(function), Some(arguments)) = (function, arguments) {
+                    if code.slice(function.byte_range()) == "new" {
+                        if let Some(type_node) = arguments.named_child(0) {
+                            return self.parse_type_or_text(&type_node, code);
+                        }
+                    }
+                }
+            }
+            _ => {}
+        }
+        TypeDef {
+            inference_info: Some(code.slice(value.byte_range()).to_string()),
+            ..Default::default()
+        }
+    }
+
+    fn parse_type_or_text(&self, type_node: &Node, code: &str) -> TypeDef {
+        self.parse_type(type_node, code).unwrap_or(TypeDef {
+            name: Some(code.slice(type_node.byte_range()).to_string()),
+            ..Default::default()
+        })
+    }
+}
+
 impl AstLanguageParser for GoParser {
     fn parse(&mut self, code: &str, path: &PathBuf) -> Vec<AstSymbolInstanceArc> {
-        let tree = self.parser.parse(code, None).unwrap();
-        self.parse_(&tree.root_node(), code, path)
+        match self.parser.parse(code, None) {
+            Some(tree) => self.parse_(&tree.root_node(), code, path),
+            None => vec![],
+        }
+    }
+
+    fn parse_tree(&mut self, code: &str, old_tree: Option<&Tree>) -> Option<Tree> {
+        self.parser.parse(code, old_tree)
+    }
+
+    fn parse_tree_until(&mut self, code: &str, old_tree: Option<&Tree>, should_abort: &dyn Fn() -> bool) -> Option<Tree> {
+        parse_tree_until(&mut self.parser, code, old_tree, should_abort)
+    }
+
+    fn parse_top_level(&mut self, nodes: &[Node], code: &str, path: &PathBuf) -> Vec<AstSymbolInstanceArc> {
+        nodes.iter().flat_map(|node| self.parse_(node, code, path)).collect()
     }
 }
 
@@ -8,13 +8,13 @@ use itertools::Itertools;
 
 use parking_lot::RwLock;
 use similar::DiffableStr;
-use tree_sitter::{Node, Parser, Range};
+use tree_sitter::{Node, Parser, Tree, Range};
 use uuid::Uuid;
 
-use crate::codegraph::treesitter::ast_instance_structs::{AstSymbolFields, AstSymbolInstanceArc, ClassFieldDeclaration, CommentDefinition, FunctionArg, FunctionCall, FunctionDeclaration, ImportDeclaration, ImportType, StructDeclaration, TypeDef, VariableDefinition, VariableUsage};
+use crate::codegraph::treesitter::ast_instance_structs::{AstSymbolFields, AstSymbolInstanceArc, ClassFieldDeclaration, CommentDefinition, FunctionArg, FunctionCall, FunctionDeclaration, ImportDeclaration, ImportType, StructDeclaration, StructKind, TypeDef, VariableDefinition, VariableUsage};
 use crate::codegraph::treesitter::language_id::LanguageId;
 use crate::codegraph::treesitter::parsers::{AstLanguageParser, internal_error, ParserError};
-use crate::codegraph::treesitter::parsers::utils::{CandidateInfo, get_guid};
+use crate::codegraph::treesitter::parsers::utils::{CandidateInfo, get_guid, parse_tree_until};
 
 pub(crate) struct JavaParser {
     pub parser: Parser,
@@ -184,6 +184,24 @@ pub fn parse_type(parent: &Node, code: &str) -> Option<TypeDef> {
     None
 }
 
+/// Annotations in the declaration's modifiers without the leading `@`, e.g. `Override`, `SuppressWarnings("unchecked")`
+fn parse_annotations(parent: &Node, code: &str) -> Vec<String> {
+    let mut annotations = vec![];
+    for i in 0..parent.child_count() {
+        let child = parent.child(i).unwrap();
+        if child.kind() != "modifiers" {
+            continue;
+        }
+        for j in 0..child.child_count() {
+            let modifier = child.child(j).unwrap();
+            if modifier.kind() == "marker_annotation" || modifier.kind() == "annotation" {
+                annotations.push(code.slice(modifier.byte_range()).trim_start_matches('@').to_string());
+            }
+        }
+    }
+    annotations
+}
+
 fn parse_function_arg(parent: &Node, code: &str) -> FunctionArg {
     let mut arg = FunctionArg::default();
     if let Some(name) = parent.child_by_field_name("name") {
@@ -247,6 +265,12 @@ impl JavaParser {
         if let Some(name_node) = info.node.child_by_field_name("name") {
             decl.ast_fields.name = code.slice(name_node.byte_range()).to_string();
         }
+        decl.kind = match info.node.kind() {
+            "interface_declaration" | "annotation_type_declaration" => StructKind::Interface,
+            "enum_declaration" => StructKind::Enum,
+            _ => StructKind::Struct,
+        };
+        decl.decorators = parse_annotations(&info.node, code);
 
         if let Some(node) = info.node.child_by_field_name("superclass") {
             symbols.extend(self.find_error_usages(&node, code, &info.ast_fields.file_path, &decl.ast_fields.guid));
@@ -267,7 +291,7 @@ impl JavaParser {
                         for i in 0..child.child_count() {
                             let child = child.child(i).unwrap();
                             if let Some(dtype) = parse_type(&child, code) {
-                                decl.inherited_types.push(dtype);
+                                decl.implemented_types.push(dtype);
                             }
                         }
                     }
@@ -275,6 +299,25 @@ impl JavaParser {
                 }
             }
         }
+        // `interface Drawable extends Shape, Named` has no field name
+        for i in 0..info.node.child_count() {
+            let child = info.node.child(i).unwrap();
+            if child.kind() != "extends_interfaces" {
+                continue;
+            }
+            symbols.extend(self.find_error_usages(&child, code, &info.ast_fields.file_path, &decl.ast_fields.guid));
+            for j in 0..child.child_count() {
+                let type_list = child.child(j).unwrap();
+                if type_list.kind() != "type_list" {
+                    continue;
+                }
+                for k in 0..type_list.child_count() {
+                    if let Some(dtype) = parse_type(&type_list.child(k).unwrap(), code) {
+                        decl.inherited_types.push(dtype);
+                    }
+                }
+            }
+        }
         if let Some(_) = info.node.child_by_field_name("type_parameters") {}
 
 
@@ -650,6 +693,7 @@ impl JavaParser {
         if let Some(name_node) = info.node.child_by_field_name("name") {
             decl.ast_fields.name = code.slice(name_node.byte_range()).to_string();
         }
+        decl.decorators = parse_annotations(&info.node, code);
 
         if let Some(parameters_node) = info.node.child_by_field_name("parameters") {
             symbols.extend(self.find_error_usages(&parameters_node, code, &info.ast_fields.file_path, &decl.ast_fields.guid));
@@ -665,7 +709,10 @@ impl JavaParser {
             for idx in 0..params_len {
                 let child = parameters_node.child(idx).unwrap();
                 symbols.extend(self.find_error_usages(&child, code, &info.ast_fields.file_path, &decl.ast_fields.guid));
-                function_args.push(parse_function_arg(&child, code));
+                // skip the parentheses and commas
+                if child.kind() == "formal_parameter" || child.kind() == "spread_parameter" {
+                    function_args.push(parse_function_arg(&child, code));
+                }
             }
             decl.args = function_args;
         }
@@ -798,4 +845,16 @@ impl AstLanguageParser for JavaParser {
         let symbols = self.parse_(&tree.root_node(), code, path);
         symbols
     }
+
+    fn parse_tree(&mut self, code: &str, old_tree: Option<&Tree>) -> Option<Tree> {
+        self.parser.parse(code, old_tree)
+    }
+
+    fn parse_tree_until(&mut self, code: &str, old_tree: Option<&Tree>, should_abort: &dyn Fn() -> bool) -> Option<Tree> {
+        parse_tree_until(&mut self.parser, code, old_tree, should_abort)
+    }
+
+    fn parse_top_level(&mut self, nodes: &[Node], code: &str, path: &PathBuf) -> Vec<AstSymbolInstanceArc> {
+        nodes.iter().flat_map(|node| self.parse_(node, code, path)).collect()
+    }
 }
@@ -5,13 +5,13 @@ use std::sync::Arc;
 use parking_lot::RwLock;
 
 use similar::DiffableStr;
-use tree_sitter::{Node, Parser, Range};
+use tree_sitter::{Node, Parser, Tree, Range};
 use uuid::Uuid;
 
 use crate::codegraph::treesitter::ast_instance_structs::{AstSymbolFields, AstSymbolInstanceArc, ClassFieldDeclaration, CommentDefinition, FunctionArg, FunctionCall, FunctionDeclaration, ImportDeclaration, ImportType, StructDeclaration, TypeDef, VariableDefinition, VariableUsage};
 use crate::codegraph::treesitter::language_id::LanguageId;
 use crate::codegraph::treesitter::parsers::{AstLanguageParser, internal_error, ParserError};
-use crate::codegraph::treesitter::parsers::utils::{CandidateInfo, get_guid};
+use crate::codegraph::treesitter::parsers::utils::{CandidateInfo, get_guid, parse_tree_until};
 
 pub(crate) struct JSParser {
     pub parser: Parser,
@@ -792,6 +792,18 @@ impl AstLanguageParser for JSParser {
         let symbols = self.parse_(&tree.root_node(), code, path);
         symbols
     }
+
+    fn parse_tree(&mut self, code: &str, old_tree: Option<&Tree>) -> Option<Tree> {
+        self.parser.parse(code, old_tree)
+    }
+
+    fn parse_tree_until(&mut self, code: &str, old_tree: Option<&Tree>, should_abort: &dyn Fn() -> bool) -> Option<Tree> {
+        parse_tree_until(&mut self.parser, code, old_tree, should_abort)
+    }
+
+    fn parse_top_level(&mut self, nodes: &[Node], code: &str, path: &PathBuf) -> Vec<AstSymbolInstanceArc> {
+        nodes.iter().flat_map(|node| self.parse_(node, code, path)).collect()
+    }
 }
 
 
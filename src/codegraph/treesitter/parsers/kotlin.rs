@@ -0,0 +1,657 @@
+use std::collections::{HashMap, VecDeque};
+use std::path::PathBuf;
+use std::sync::Arc;
+
+#[cfg(test)]
+use itertools::Itertools;
+
+use parking_lot::RwLock;
+use similar::DiffableStr;
+use tree_sitter::{Node, Parser, Range, Tree};
+
+use crate::codegraph::treesitter::ast_instance_structs::{AstSymbolFields, AstSymbolInstanceArc, ClassFieldDeclaration, CommentDefinition, FunctionArg, FunctionCall, FunctionDeclaration, FunctionReceiver, ImportDeclaration, ImportType, StructDeclaration, StructKind, TypeDef, VariableDefinition, VariableUsage};
+use crate::codegraph::treesitter::language_id::LanguageId;
+use crate::codegraph::treesitter::parsers::{AstLanguageParser, internal_error, ParserError};
+use crate::codegraph::treesitter::parsers::utils::{CandidateInfo, get_guid, parse_tree_until};
+
+pub(crate) struct KotlinParser {
+    pub parser: Parser,
+}
+
+static SYSTEM_MODULES: [&str; 3] = [
+    "kotlin", "java", "javax",
+];
+
+/// Members the compiler generates for a `data class` with the given number of primary constructor
+/// properties: `component1()..componentN()`, `copy`, `equals`, `hashCode` and `toString`
+pub(crate) fn data_class_members(properties: usize) -> Vec<String> {
+    (1..=properties).map(|i| format!("component{}", i))
+        .chain(["copy", "equals", "hashCode", "toString"].map(|m| m.to_string()))
+        .collect()
+}
+
+fn children<'a>(parent: &Node<'a>) -> Vec<Node<'a>> {
+    (0..parent.child_count()).filter_map(|i| parent.child(i)).collect()
+}
+
+fn child_of_kind<'a>(parent: &Node<'a>, kinds: &[&str]) -> Option<Node<'a>> {
+    children(parent).into_iter().find(|c| kinds.contains(&c.kind()))
+}
+
+/// The type following `:` among the children, e.g. the type of a parameter or the return type of a function
+fn type_after_colon<'a>(nodes: &[Node<'a>]) -> Option<Node<'a>> {
+    nodes.iter()
+        .skip_while(|c| c.kind() != ":")
+        .skip(1)
+        .find(|c| c.is_named())
+        .copied()
+}
+
+pub fn parse_type(parent: &Node, code: &str) -> Option<TypeDef> {
+    let text = code.slice(parent.byte_range()).to_string();
+    match parent.kind() {
+        "type_identifier" | "simple_identifier" => {
+            Some(TypeDef {
+                name: Some(text),
+                ..Default::default()
+            })
+        }
+        // `List<String>`, `Map.Entry<K, V>`: the name is the last segment
+        "user_type" => {
+            let mut decl = TypeDef::default();
+            let mut segments = vec![];
+            for child in children(parent) {
+                match child.kind() {
+                    "type_identifier" => segments.push(code.slice(child.byte_range()).to_string()),
+                    "type_arguments" => {
+                        decl.nested_types.clear();
+                        for projection in children(&child) {
+                            let type_ = match projection.kind() {
+                                "type_projection" => child_of_kind(&projection, &["user_type", "nullable_type", "function_type", "parenthesized_type"]),
+                                _ => None,
+                            };
+                            if let Some(dtype) = type_.and_then(|t| parse_type(&t, code)) {
+                                decl.nested_types.push(dtype);
+                            }
+                        }
+                    }
+                    _ => {}
+                }
+            }
+            decl.name = segments.pop();
+            decl.namespace = segments.join(".");
+            Some(decl)
+        }
+        // `String?` keeps the `?` in the name so that signatures show nullability
+        "nullable_type" => {
+            let inner = children(parent).into_iter().find(|c| c.is_named())?;
+            let mut decl = parse_type(&inner, code)?;
+            decl.name = decl.name.map(|name| name + "?");
+            Some(decl)
+        }
+        "parenthesized_type" => {
+            let inner = children(parent).into_iter().find(|c| c.is_named())?;
+            parse_type(&inner, code)
+        }
+        "function_type" | "non_nullable_type" => {
+            Some(TypeDef {
+                name: Some(text),
+                ..Default::default()
+            })
+        }
+        _ => None,
+    }
+}
+
+/// Annotations in the declaration's modifiers without the leading `@`
+fn parse_annotations(modifiers: Option<Node>, code: &str) -> Vec<String> {
+    let Some(modifiers) = modifiers else { return vec![] };
+    children(&modifiers).into_iter()
+        .filter(|m| m.kind() == "annotation")
+        .map(|m| code.slice(m.byte_range()).trim_start_matches('@').to_string())
+        .collect()
+}
+
+/// The `class_modifier` keywords of a declaration, e.g. `data`, `sealed`
+fn class_modifiers<'a>(modifiers: Option<Node>, code: &'a str) -> Vec<&'a str> {
+    let Some(modifiers) = modifiers else { return vec![] };
+    children(&modifiers).into_iter()
+        .filter(|m| m.kind() == "class_modifier")
+        .map(|m| code.slice(m.byte_range()))
+        .collect()
+}
+
+/// `name: Type` in a function or class parameter list
+fn parse_parameter(parent: &Node, code: &str) -> FunctionArg {
+    let nodes = children(parent);
+    let mut arg = FunctionArg::default();
+    if let Some(name) = nodes.iter().find(|c| c.kind() == "simple_identifier") {
+        arg.name = code.slice(name.byte_range()).to_string();
+    }
+    arg.type_ = type_after_colon(&nodes).and_then(|t| parse_type(&t, code));
+    arg
+}
+
+impl KotlinParser {
+    pub fn new() -> Result<KotlinParser, ParserError> {
+        let mut parser = Parser::new();
+        parser
+            .set_language(&tree_sitter_kotlin_sg::LANGUAGE.into())
+            .map_err(internal_error)?;
+        Ok(KotlinParser { parser })
+    }
+
+    fn push_children<'a>(&self, info: &CandidateInfo<'a>, node: &Node<'a>, candidates: &mut VecDeque<CandidateInfo<'a>>) {
+        for child in children(node) {
+            candidates.push_back(CandidateInfo {
+                ast_fields: info.ast_fields.clone(),
+                node: child,
+                parent_guid: info.parent_guid.clone(),
+            });
+        }
+    }
+
+    /// `class`, `interface`, `enum class`, `object` and `companion object` declarations
+    pub fn parse_struct_declaration<'a>(
+        &mut self,
+        info: &CandidateInfo<'a>,
+        code: &str,
+        candidates: &mut VecDeque<CandidateInfo<'a>>,
+    ) -> Vec<AstSymbolInstanceArc> {
+        let mut symbols: Vec<AstSymbolInstanceArc> = Default::default();
+        let mut decl = StructDeclaration::default();
+
+        decl.ast_fields.language = info.ast_fields.language;
+        decl.ast_fields.full_range = info.node.range();
+        decl.ast_fields.declaration_range = info.node.range();
+        decl.ast_fields.definition_range = info.node.range();
+        decl.ast_fields.file_path = info.ast_fields.file_path.clone();
+        decl.ast_fields.parent_guid = Some(info.parent_guid.clone());
+        decl.ast_fields.guid = get_guid();
+        decl.ast_fields.is_error = info.ast_fields.is_error;
+
+        let nodes = children(&info.node);
+        let modifiers = nodes.iter().find(|c| c.kind() == "modifiers").copied();
+        decl.decorators = parse_annotations(modifiers, code);
+        decl.ast_fields.name = match nodes.iter().find(|c| c.kind() == "type_identifier") {
+            Some(name) => code.slice(name.byte_range()).to_string(),
+            // `companion object { ... }` without a name
+            None if info.node.kind() == "companion_object" => "Companion".to_string(),
+            None => "".to_string(),
+        };
+        decl.kind = if matches!(info.node.kind(), "object_declaration" | "companion_object") {
+            StructKind::Object
+        } else if nodes.iter().any(|c| c.kind() == "interface") {
+            StructKind::Interface
+        } else if nodes.iter().any(|c| c.kind() == "enum") {
+            StructKind::Enum
+        } else {
+            StructKind::Struct
+        };
+
+        if let Some(type_parameters) = nodes.iter().find(|c| c.kind() == "type_parameters") {
+            for parameter in children(type_parameters).into_iter().filter(|c| c.kind() == "type_parameter") {
+                if let Some(name) = child_of_kind(&parameter, &["type_identifier"]) {
+                    decl.template_types.push(TypeDef {
+                        name: Some(code.slice(name.byte_range()).to_string()),
+                        ..Default::default()
+                    });
+                }
+            }
+        }
+
+        // A supertype with constructor arguments (`: Shape()`) is a class, one without is taken to be an interface
+        if let Some(specifiers) = nodes.iter().find(|c| c.kind() == "delegation_specifiers") {
+            for specifier in children(specifiers).into_iter().filter(|c| c.kind() == "delegation_specifier") {
+                let Some(inner) = children(&specifier).into_iter().find(|c| c.is_named()) else { continue };
+                match inner.kind() {
+                    "constructor_invocation" => {
+                        if let Some(dtype) = child_of_kind(&inner, &["user_type"]).and_then(|t| parse_type(&t, code)) {
+                            decl.inherited_types.push(dtype);
+                        }
+                    }
+                    "explicit_delegation" => {
+                        if let Some(dtype) = child_of_kind(&inner, &["user_type"]).and_then(|t| parse_type(&t, code)) {
+                            decl.implemented_types.push(dtype);
+                        }
+                    }
+                    _ => {
+                        if let Some(dtype) = parse_type(&inner, code) {
+                            decl.implemented_types.push(dtype);
+                        }
+                    }
+                }
+            }
+        }
+
+        // Primary constructor parameters declared with `val` or `var` are properties of the class
+        let mut properties = 0;
+        if let Some(constructor) = nodes.iter().find(|c| c.kind() == "primary_constructor") {
+            for parameters in children(constructor).into_iter().filter(|c| c.kind() == "class_parameters") {
+                for parameter in children(&parameters).into_iter().filter(|c| c.kind() == "class_parameter") {
+                    let parameter_nodes = children(&parameter);
+                    if !parameter_nodes.iter().any(|c| matches!(c.kind(), "val" | "var" | "binding_pattern_kind")) {
+                        continue;
+                    }
+                    let arg = parse_parameter(&parameter, code);
+                    let mut field = ClassFieldDeclaration::default();
+                    field.ast_fields.language = info.ast_fields.language;
+                    field.ast_fields.full_range = parameter.range();
+                    field.ast_fields.declaration_range = parameter.range();
+                    field.ast_fields.file_path = info.ast_fields.file_path.clone();
+                    field.ast_fields.parent_guid = Some(decl.ast_fields.guid.clone());
+                    field.ast_fields.guid = get_guid();
+                    field.ast_fields.is_error = info.ast_fields.is_error;
+                    field.ast_fields.name = arg.name;
+                    field.type_ = arg.type_.unwrap_or_default();
+                    symbols.push(Arc::new(RwLock::new(Box::new(field))));
+                    properties += 1;
+
+                    // default values may contain calls
+                    if let Some(value) = parameter_nodes.iter().skip_while(|c| c.kind() != "=").nth(1) {
+                        candidates.push_back(CandidateInfo {
+                            ast_fields: info.ast_fields.clone(),
+                            node: *value,
+                            parent_guid: decl.ast_fields.guid.clone(),
+                        });
+                    }
+                }
+            }
+        }
+        if class_modifiers(modifiers, code).contains(&"data") {
+            decl.generated_members = data_class_members(properties);
+        }
+
+        if let Some(body) = nodes.iter().find(|c| matches!(c.kind(), "class_body" | "enum_class_body")) {
+            decl.ast_fields.definition_range = body.range();
+            decl.ast_fields.declaration_range = Range {
+                start_byte: decl.ast_fields.full_range.start_byte,
+                end_byte: decl.ast_fields.definition_range.start_byte,
+                start_point: decl.ast_fields.full_range.start_point,
+                end_point: decl.ast_fields.definition_range.start_point,
+            };
+            candidates.push_back(CandidateInfo {
+                ast_fields: decl.ast_fields.clone(),
+                node: *body,
+                parent_guid: decl.ast_fields.guid.clone(),
+            })
+        }
+
+        symbols.push(Arc::new(RwLock::new(Box::new(decl))));
+        symbols
+    }
+
+    /// `val`/`var` properties: fields inside a class body, variables elsewhere
+    fn parse_property_declaration<'a>(&mut self, info: &CandidateInfo<'a>, code: &str, candidates: &mut VecDeque<CandidateInfo<'a>>) -> Vec<AstSymbolInstanceArc> {
+        let mut symbols: Vec<AstSymbolInstanceArc> = vec![];
+        let nodes = children(&info.node);
+        let in_class = info.node.parent().map_or(false, |p| matches!(p.kind(), "class_body" | "enum_class_body"));
+        let value = nodes.iter().skip_while(|c| c.kind() != "=").nth(1).copied();
+
+        let mut declarations = vec![];
+        for child in nodes.iter() {
+            match child.kind() {
+                "variable_declaration" => declarations.push(*child),
+                "multi_variable_declaration" => {
+                    declarations.extend(children(child).into_iter().filter(|c| c.kind() == "variable_declaration"));
+                }
+                _ => {}
+            }
+        }
+        for declaration in declarations {
+            let arg = parse_parameter(&declaration, code);
+            let mut type_ = arg.type_.unwrap_or_default();
+            if let Some(value) = value {
+                type_.inference_info = Some(code.slice(value.byte_range()).to_string());
+            }
+            if in_class {
+                let mut decl = ClassFieldDeclaration::default();
+                decl.ast_fields.language = info.ast_fields.language;
+                decl.ast_fields.full_range = info.node.range();
+                decl.ast_fields.declaration_range = info.node.range();
+                decl.ast_fields.file_path = info.ast_fields.file_path.clone();
+                decl.ast_fields.parent_guid = Some(info.parent_guid.clone());
+                decl.ast_fields.guid = get_guid();
+                decl.ast_fields.is_error = info.ast_fields.is_error;
+                decl.ast_fields.name = arg.name;
+                decl.type_ = type_;
+                symbols.push(Arc::new(RwLock::new(Box::new(decl))));
+            } else {
+                let mut decl = VariableDefinition::default();
+                decl.ast_fields.language = info.ast_fields.language;
+                decl.ast_fields.full_range = info.node.range();
+                decl.ast_fields.file_path = info.ast_fields.file_path.clone();
+                decl.ast_fields.parent_guid = Some(info.parent_guid.clone());
+                decl.ast_fields.guid = get_guid();
+                decl.ast_fields.is_error = info.ast_fields.is_error;
+                decl.ast_fields.name = arg.name;
+                decl.type_ = type_;
+                symbols.push(Arc::new(RwLock::new(Box::new(decl))));
+            }
+        }
+        if let Some(value) = value {
+            candidates.push_back(CandidateInfo {
+                ast_fields: info.ast_fields.clone(),
+                node: value,
+                parent_guid: info.parent_guid.clone(),
+            });
+        }
+        symbols
+    }
+
+    fn parse_enum_entry<'a>(&mut self, info: &CandidateInfo<'a>, code: &str, candidates: &mut VecDeque<CandidateInfo<'a>>) -> Vec<AstSymbolInstanceArc> {
+        let mut decl = ClassFieldDeclaration::default();
+        decl.ast_fields.language = info.ast_fields.language;
+        decl.ast_fields.full_range = info.node.range();
+        decl.ast_fields.declaration_range = info.node.range();
+        decl.ast_fields.file_path = info.ast_fields.file_path.clone();
+        decl.ast_fields.parent_guid = Some(info.parent_guid.clone());
+        decl.ast_fields.guid = get_guid();
+        decl.ast_fields.is_error = info.ast_fields.is_error;
+        if let Some(name) = child_of_kind(&info.node, &["simple_identifier"]) {
+            decl.ast_fields.name = code.slice(name.byte_range()).to_string();
+        }
+        if let Some(arguments) = child_of_kind(&info.node, &["value_arguments"]) {
+            decl.type_.inference_info = Some(code.slice(arguments.byte_range()).to_string());
+            self.push_children(info, &arguments, candidates);
+        }
+        vec![Arc::new(RwLock::new(Box::new(decl)))]
+    }
+
+    /// `fun name(...)` and extension functions `fun Receiver.name(...)`; the receiver type keeps
+    /// its name without `?`, so `fun String?.orEmpty()` extends `String`
+    pub fn parse_function_declaration<'a>(&mut self, info: &CandidateInfo<'a>, code: &str, candidates: &mut VecDeque<CandidateInfo<'a>>) -> Vec<AstSymbolInstanceArc> {
+        let mut decl = FunctionDeclaration::default();
+        decl.ast_fields.language = info.ast_fields.language;
+        decl.ast_fields.full_range = info.node.range();
+        decl.ast_fields.declaration_range = info.node.range();
+        decl.ast_fields.definition_range = info.node.range();
+        decl.ast_fields.file_path = info.ast_fields.file_path.clone();
+        decl.ast_fields.parent_guid = Some(info.parent_guid.clone());
+        decl.ast_fields.is_error = info.ast_fields.is_error;
+        decl.ast_fields.guid = get_guid();
+
+        let nodes = children(&info.node);
+        decl.decorators = parse_annotations(nodes.iter().find(|c| c.kind() == "modifiers").copied(), code);
+        if let Some(name) = nodes.iter().find(|c| c.kind() == "simple_identifier") {
+            decl.ast_fields.name = code.slice(name.byte_range()).to_string();
+        }
+        if let Some(type_parameters) = nodes.iter().find(|c| c.kind() == "type_parameters") {
+            for parameter in children(type_parameters).into_iter().filter(|c| c.kind() == "type_parameter") {
+                if let Some(name) = child_of_kind(&parameter, &["type_identifier"]) {
+                    decl.template_types.push(TypeDef {
+                        name: Some(code.slice(name.byte_range()).to_string()),
+                        ..Default::default()
+                    });
+                }
+            }
+        }
+
+        // the receiver type comes right before the `.` that precedes the name
+        let dot = nodes.iter().position(|c| c.kind() == ".");
+        let receiver = dot.and_then(|dot| nodes[..dot].iter().rev().find(|c| c.is_named()));
+        if let Some(type_) = receiver.and_then(|r| parse_type(r, code)) {
+            let name = type_.name.clone().map(|name| name.trim_end_matches('?').to_string());
+            decl.receiver = Some(FunctionReceiver {
+                name: None,
+                type_: TypeDef { name, ..type_ },
+                is_pointer: false,
+            });
+        }
+
+        if let Some(parameters) = nodes.iter().position(|c| c.kind() == "function_value_parameters") {
+            let parameters_node = nodes[parameters];
+            decl.ast_fields.declaration_range = Range {
+                start_byte: decl.ast_fields.full_range.start_byte,
+                end_byte: parameters_node.end_byte(),
+                start_point: decl.ast_fields.full_range.start_point,
+                end_point: parameters_node.end_position(),
+            };
+            let parameter_nodes = children(&parameters_node);
+            for (idx, child) in parameter_nodes.iter().enumerate() {
+                match child.kind() {
+                    "parameter" => decl.args.push(parse_parameter(child, code)),
+                    // default arguments: `scale: Double = 1.0`
+                    "=" => {
+                        if let Some(value) = parameter_nodes.get(idx + 1) {
+                            candidates.push_back(CandidateInfo {
+                                ast_fields: info.ast_fields.clone(),
+                                node: *value,
+                                parent_guid: decl.ast_fields.guid.clone(),
+                            });
+                        }
+                    }
+                    _ => {}
+                }
+            }
+            decl.return_type = type_after_colon(&nodes[parameters + 1..])
+                .filter(|t| t.kind() != "function_body" && t.kind() != "type_constraints")
+                .and_then(|t| parse_type(&t, code));
+        }
+
+        if let Some(body_node) = nodes.iter().find(|c| c.kind() == "function_body") {
+            decl.ast_fields.definition_range = body_node.range();
+            decl.ast_fields.declaration_range = Range {
+                start_byte: decl.ast_fields.full_range.start_byte,
+                end_byte: decl.ast_fields.definition_range.start_byte,
+                start_point: decl.ast_fields.full_range.start_point,
+                end_point: decl.ast_fields.definition_range.start_point,
+            };
+            candidates.push_back(CandidateInfo {
+                ast_fields: decl.ast_fields.clone(),
+                node: *body_node,
+                parent_guid: decl.ast_fields.guid.clone(),
+            });
+        } else {
+            decl.ast_fields.declaration_range = decl.ast_fields.full_range;
+        }
+
+        vec![Arc::new(RwLock::new(Box::new(decl)))]
+    }
+
+    /// `foo(x)`, `obj.foo(x)` and trailing lambdas `list.map { ... }`
+    pub fn parse_call_expression<'a>(&mut self, info: &CandidateInfo<'a>, code: &str, candidates: &mut VecDeque<CandidateInfo<'a>>) -> Vec<AstSymbolInstanceArc> {
+        let mut decl = FunctionCall::default();
+        decl.ast_fields.language = info.ast_fields.language;
+        decl.ast_fields.full_range = info.node.range();
+        decl.ast_fields.file_path = info.ast_fields.file_path.clone();
+        decl.ast_fields.parent_guid = Some(info.parent_guid.clone());
+        decl.ast_fields.guid = get_guid();
+        decl.ast_fields.is_error = info.ast_fields.is_error;
+        if let Some(caller_guid) = info.ast_fields.caller_guid.clone() {
+            decl.ast_fields.guid = caller_guid;
+        }
+        decl.ast_fields.caller_guid = Some(get_guid());
+
+        let nodes = children(&info.node);
+        if let Some(callee) = nodes.first() {
+            match callee.kind() {
+                "simple_identifier" => {
+                    decl.ast_fields.name = code.slice(callee.byte_range()).to_string();
+                }
+                "navigation_expression" => {
+                    let parts = children(callee);
+                    let name = parts.last()
+                        .filter(|s| s.kind() == "navigation_suffix")
+                        .and_then(|s| child_of_kind(s, &["simple_identifier"]));
+                    if let Some(name) = name {
+                        decl.ast_fields.name = code.slice(name.byte_range()).to_string();
+                    }
+                    if let Some(object) = parts.first() {
+                        decl.ast_fields.namespace = code.slice(object.byte_range()).to_string();
+                        candidates.push_back(CandidateInfo {
+                            ast_fields: decl.ast_fields.clone(),
+                            node: *object,
+                            parent_guid: info.parent_guid.clone(),
+                        });
+                    }
+                }
+                _ => {
+                    candidates.push_back(CandidateInfo {
+                        ast_fields: info.ast_fields.clone(),
+                        node: *callee,
+                        parent_guid: info.parent_guid.clone(),
+                    });
+                }
+            }
+        }
+        if let Some(suffix) = nodes.iter().find(|c| c.kind() == "call_suffix") {
+            let mut new_ast_fields = info.ast_fields.clone();
+            new_ast_fields.caller_guid = None;
+            for child in children(suffix) {
+                candidates.push_back(CandidateInfo {
+                    ast_fields: new_ast_fields.clone(),
+                    node: child,
+                    parent_guid: info.parent_guid.clone(),
+                });
+            }
+        }
+
+        vec![Arc::new(RwLock::new(Box::new(decl)))]
+    }
+
+    fn parse_import<'a>(&mut self, info: &CandidateInfo<'a>, code: &str) -> Vec<AstSymbolInstanceArc> {
+        let mut def = ImportDeclaration::default();
+        def.ast_fields.language = info.ast_fields.language;
+        def.ast_fields.full_range = info.node.range();
+        def.ast_fields.file_path = info.ast_fields.file_path.clone();
+        def.ast_fields.parent_guid = Some(info.parent_guid.clone());
+        def.ast_fields.guid = get_guid();
+        if let Some(identifier) = child_of_kind(&info.node, &["identifier"]) {
+            let path = code.slice(identifier.byte_range()).to_string();
+            def.path_components = path.split(".").map(|x| x.to_string()).collect();
+            if let Some(first) = def.path_components.first() {
+                if SYSTEM_MODULES.contains(&first.as_str()) {
+                    def.import_type = ImportType::System;
+                }
+            }
+            def.path = Some(path);
+        }
+        if let Some(alias) = child_of_kind(&info.node, &["import_alias"]) {
+            def.alias = child_of_kind(&alias, &["type_identifier", "simple_identifier"])
+                .map(|name| code.slice(name.byte_range()).to_string());
+        }
+        vec![Arc::new(RwLock::new(Box::new(def)))]
+    }
+
+    fn parse_usages_<'a>(&mut self, info: &CandidateInfo<'a>, code: &str, candidates: &mut VecDeque<CandidateInfo<'a>>) -> Vec<AstSymbolInstanceArc> {
+        let mut symbols: Vec<AstSymbolInstanceArc> = vec![];
+        match info.node.kind() {
+            "class_declaration" | "object_declaration" | "companion_object" => {
+                symbols.extend(self.parse_struct_declaration(info, code, candidates));
+            }
+            "function_declaration" => {
+                symbols.extend(self.parse_function_declaration(info, code, candidates));
+            }
+            "property_declaration" => {
+                symbols.extend(self.parse_property_declaration(info, code, candidates));
+            }
+            "enum_entry" => {
+                symbols.extend(self.parse_enum_entry(info, code, candidates));
+            }
+            "call_expression" => {
+                symbols.extend(self.parse_call_expression(info, code, candidates));
+            }
+            "simple_identifier" => {
+                let mut usage = VariableUsage::default();
+                usage.ast_fields.name = code.slice(info.node.byte_range()).to_string();
+                usage.ast_fields.language = info.ast_fields.language;
+                usage.ast_fields.full_range = info.node.range();
+                usage.ast_fields.file_path = info.ast_fields.file_path.clone();
+                usage.ast_fields.parent_guid = Some(info.parent_guid.clone());
+                usage.ast_fields.guid = get_guid();
+                usage.ast_fields.is_error = info.ast_fields.is_error;
+                if let Some(caller_guid) = info.ast_fields.caller_guid.clone() {
+                    usage.ast_fields.guid = caller_guid;
+                }
+                symbols.push(Arc::new(RwLock::new(Box::new(usage))));
+            }
+            "line_comment" | "multiline_comment" => {
+                let mut def = CommentDefinition::default();
+                def.ast_fields.language = info.ast_fields.language;
+                def.ast_fields.full_range = info.node.range();
+                def.ast_fields.file_path = info.ast_fields.file_path.clone();
+                def.ast_fields.parent_guid = Some(info.parent_guid.clone());
+                def.ast_fields.guid = get_guid();
+                def.ast_fields.is_error = info.ast_fields.is_error;
+                symbols.push(Arc::new(RwLock::new(Box::new(def))));
+            }
+            "import_header" => {
+                symbols.extend(self.parse_import(info, code));
+            }
+            "ERROR" => {
+                let mut ast = info.ast_fields.clone();
+                ast.is_error = true;
+                for child in children(&info.node) {
+                    candidates.push_back(CandidateInfo {
+                        ast_fields: ast.clone(),
+                        node: child,
+                        parent_guid: info.parent_guid.clone(),
+                    });
+                }
+            }
+            "package_header" | "type_identifier" => {}
+            _ => self.push_children(info, &info.node, candidates),
+        }
+        symbols
+    }
+
+    fn parse_(&mut self, parent: &Node, code: &str, path: &PathBuf) -> Vec<AstSymbolInstanceArc> {
+        let mut symbols: Vec<AstSymbolInstanceArc> = Default::default();
+        let mut ast_fields = AstSymbolFields::default();
+        ast_fields.file_path = path.clone();
+        ast_fields.is_error = false;
+        ast_fields.language = LanguageId::Kotlin;
+
+        let mut candidates = VecDeque::from(vec![CandidateInfo {
+            ast_fields,
+            node: parent.clone(),
+            parent_guid: get_guid(),
+        }]);
+        while let Some(candidate) = candidates.pop_front() {
+            let symbols_l = self.parse_usages_(&candidate, code, &mut candidates);
+            symbols.extend(symbols_l);
+        }
+        let guid_to_symbol_map = symbols.iter()
+            .map(|s| (s.clone().read().guid().clone(), s.clone())).collect::<HashMap<_, _>>();
+        for symbol in symbols.iter_mut() {
+            let guid = symbol.read().guid().clone();
+            if let Some(parent_guid) = symbol.read().parent_guid() {
+                if let Some(parent) = guid_to_symbol_map.get(parent_guid) {
+                    parent.write().fields_mut().childs_guid.push(guid);
+                }
+            }
+        }
+
+        #[cfg(test)]
+        for symbol in symbols.iter_mut() {
+            let mut sym = symbol.write();
+            sym.fields_mut().childs_guid = sym.fields_mut().childs_guid.iter()
+                .sorted_by_key(|x| {
+                    guid_to_symbol_map.get(*x).unwrap().read().full_range().start_byte
+                }).map(|x| x.clone()).collect();
+        }
+
+        symbols
+    }
+}
+
+impl AstLanguageParser for KotlinParser {
+    fn parse(&mut self, code: &str, path: &PathBuf) -> Vec<AstSymbolInstanceArc> {
+        let tree = self.parser.parse(code, None).unwrap();
+        let symbols = self.parse_(&tree.root_node(), code, path);
+        symbols
+    }
+
+    fn parse_tree(&mut self, code: &str, old_tree: Option<&Tree>) -> Option<Tree> {
+        self.parser.parse(code, old_tree)
+    }
+
+    fn parse_tree_until(&mut self, code: &str, old_tree: Option<&Tree>, should_abort: &dyn Fn() -> bool) -> Option<Tree> {
+        parse_tree_until(&mut self.parser, code, old_tree, should_abort)
+    }
+
+    fn parse_top_level(&mut self, nodes: &[Node], code: &str, path: &PathBuf) -> Vec<AstSymbolInstanceArc> {
+        nodes.iter().flat_map(|node| self.parse_(node, code, path)).collect()
+    }
+}
@@ -7,13 +7,13 @@ use std::sync::Arc;
 use itertools::Itertools;
 use parking_lot::RwLock;
 use similar::DiffableStr;
-use tree_sitter::{Node, Parser, Point, Range};
+use tree_sitter::{Node, Parser, Tree, Point, Range};
 use uuid::Uuid;
 
 use crate::codegraph::treesitter::ast_instance_structs::{AstSymbolFields, AstSymbolInstanceArc, ClassFieldDeclaration, CommentDefinition, FunctionArg, FunctionCall, FunctionDeclaration, ImportDeclaration, ImportType, StructDeclaration, SymbolInformation, TypeDef, VariableDefinition, VariableUsage};
 use crate::codegraph::treesitter::language_id::LanguageId;
 use crate::codegraph::treesitter::parsers::{AstLanguageParser, internal_error, ParserError};
-use crate::codegraph::treesitter::parsers::utils::{CandidateInfo, get_children_guids, get_guid};
+use crate::codegraph::treesitter::parsers::utils::{CandidateInfo, get_children_guids, get_guid, parse_tree_until};
 use crate::codegraph::treesitter::skeletonizer::SkeletonFormatter;
 use crate::codegraph::treesitter::structs::SymbolType;
 
@@ -44,6 +44,47 @@ static PYTHON_MODULES: [&str; 203] = [
 ];
 
 
+fn parse_decorators(node: &Node, code: &str) -> Vec<String> {
+    let mut decorators = vec![];
+    if let Some(parent_node) = node.parent() {
+        if parent_node.kind() == "decorated_definition" {
+            for i in 0..parent_node.child_count() {
+                let child = parent_node.child(i).unwrap();
+                if child.kind() == "decorator" {
+                    if let Some(expr) = child.named_child(0) {
+                        decorators.push(code.slice(expr.byte_range()).to_string());
+                    }
+                }
+            }
+        }
+    }
+    decorators
+}
+
+fn is_main_guard(node: &Node, code: &str) -> bool {
+    if node.kind() != "if_statement" || node.parent().map(|x| x.kind()) != Some("module") {
+        return false;
+    }
+    if let Some(condition) = node.child_by_field_name("condition") {
+        let text = code.slice(condition.byte_range()).replace(" ", "").replace("'", "\"");
+        return text == "__name__==\"__main__\"" || text == "\"__main__\"==__name__";
+    }
+    false
+}
+
+/// Assignments and definitions inside an `if __name__ == "__main__":` block are not declarations
+fn is_inside_main_guard(node: &Node, code: &str) -> bool {
+    let mut parent_mb = node.parent();
+    while let Some(parent) = parent_mb {
+        if is_main_guard(&parent, code) {
+            return true;
+        }
+        parent_mb = parent.parent();
+    }
+    false
+}
+
+
 pub(crate) struct PythonParser {
     pub parser: Parser,
 }
@@ -233,6 +274,7 @@ impl PythonParser {
                 decl.ast_fields.full_range = parent_node.range();
             }
         }
+        decl.decorators = parse_decorators(&info.node, code);
 
         if let Some(name_node) = info.node.child_by_field_name("name") {
             decl.ast_fields.name = code.slice(name_node.byte_range()).to_string();
@@ -274,6 +316,7 @@ impl PythonParser {
     }
 
     fn parse_assignment<'a>(&mut self, info: &CandidateInfo<'a>, code: &str, candidates: &mut VecDeque<CandidateInfo<'a>>) -> Vec<AstSymbolInstanceArc> {
+        let in_main_guard = is_inside_main_guard(&info.node, code);
         let mut is_class_field = false;
         {
             let mut parent_mb = info.node.parent();
@@ -325,6 +368,9 @@ impl PythonParser {
                 let kind = left.kind();
                 match kind {
                     "identifier" => {
+                        if in_main_guard {
+                            continue;
+                        }
                         let mut fields = AstSymbolFields::default();
                         fields.language = info.ast_fields.language;
                         fields.full_range = info.node.range();
@@ -405,6 +451,15 @@ impl PythonParser {
         let _text = code.slice(info.node.byte_range());
         // TODO lambda https://github.com/tree-sitter/tree-sitter-python/blob/master/grammar.js#L830
         match kind {
+            "class_definition" | "function_definition" if is_inside_main_guard(&info.node, code) => {
+                if let Some(body) = info.node.child_by_field_name("body") {
+                    candidates.push_back(CandidateInfo {
+                        ast_fields: info.ast_fields.clone(),
+                        node: body,
+                        parent_guid: info.parent_guid.clone(),
+                    });
+                }
+            }
             "class_definition" => {
                 symbols.extend(self.parse_struct_declaration(info, code, candidates));
             }
@@ -412,6 +467,16 @@ impl PythonParser {
                 symbols.extend(self.parse_function_declaration(info, code, candidates));
             }
             "decorated_definition" => {
+                for i in 0..info.node.child_count() {
+                    let child = info.node.child(i).unwrap();
+                    if let ("decorator", Some(expr)) = (child.kind(), child.named_child(0)) {
+                        candidates.push_back(CandidateInfo {
+                            ast_fields: info.ast_fields.clone(),
+                            node: expr,
+                            parent_guid: info.parent_guid.clone(),
+                        });
+                    }
+                }
                 if let Some(definition) = info.node.child_by_field_name("definition") {
                     candidates.push_back(CandidateInfo {
                         ast_fields: info.ast_fields.clone(),
@@ -637,6 +702,7 @@ impl PythonParser {
                 decl.ast_fields.full_range = parent_node.range();
             }
         }
+        decl.decorators = parse_decorators(&info.node, code);
         symbols.extend(self.find_error_usages(&info.node, code, &info.ast_fields.file_path, &decl.ast_fields.guid));
 
         let mut decl_end_byte: usize = info.node.end_byte();
@@ -946,4 +1012,16 @@ impl AstLanguageParser for PythonParser {
         let symbols = self.parse_(&tree.root_node(), code, path);
         symbols
     }
+
+    fn parse_tree(&mut self, code: &str, old_tree: Option<&Tree>) -> Option<Tree> {
+        self.parser.parse(code, old_tree)
+    }
+
+    fn parse_tree_until(&mut self, code: &str, old_tree: Option<&Tree>, should_abort: &dyn Fn() -> bool) -> Option<Tree> {
+        parse_tree_until(&mut self.parser, code, old_tree, should_abort)
+    }
+
+    fn parse_top_level(&mut self, nodes: &[Node], code: &str, path: &PathBuf) -> Vec<AstSymbolInstanceArc> {
+        nodes.iter().flat_map(|node| self.parse_(node, code, path)).collect()
+    }
 }
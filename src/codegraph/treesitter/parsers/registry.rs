@@ -0,0 +1,217 @@
+use std::collections::HashMap;
+use std::path::Path;
+use std::sync::{Arc, OnceLock};
+
+use parking_lot::RwLock;
+
+use crate::codegraph::treesitter::language_id::LanguageId;
+use crate::codegraph::treesitter::parsers::{get_ast_parser, AstLanguageParser, ParserError, BUILTIN_EXTENSIONS};
+
+/// Creates a fresh parser for each parsed file; may be called from several threads at once
+pub type ParserFactory = Arc<dyn Fn() -> Result<Box<dyn AstLanguageParser>, ParserError> + Send + Sync>;
+
+struct RegisteredParser {
+    language_id: LanguageId,
+    factory: ParserFactory,
+}
+
+#[derive(Default)]
+struct ParserRegistry {
+    /// Language name -> parser
+    languages: HashMap<String, RegisteredParser>,
+    /// Extension (lowercase, without the dot) -> language name
+    extensions: HashMap<String, String>,
+}
+
+impl ParserRegistry {
+    /// Fails without changing anything when the language or any of the extensions is taken
+    fn insert(&mut self, language: &str, language_id: LanguageId, extensions: &[&str], factory: ParserFactory) -> Result<(), ParserError> {
+        if self.languages.contains_key(language) {
+            return Err(ParserError { message: format!("Parser for {} is already registered", language) });
+        }
+        let extensions = extensions.iter().map(|e| normalize_extension(e)).collect::<Vec<_>>();
+        for extension in &extensions {
+            if extension.is_empty() {
+                return Err(ParserError { message: format!("Empty file extension for {}", language) });
+            }
+            if let Some(owner) = self.extensions.get(extension) {
+                return Err(ParserError { message: format!("Extension .{} is already registered for {}", extension, owner) });
+            }
+        }
+        for extension in extensions {
+            self.extensions.insert(extension, language.to_string());
+        }
+        self.languages.insert(language.to_string(), RegisteredParser { language_id, factory });
+        Ok(())
+    }
+}
+
+fn normalize_extension(extension: &str) -> String {
+    extension.trim_start_matches('.').to_lowercase()
+}
+
+/// The global registry. Built-in parsers are registered on first use under the display name
+/// of their `LanguageId` (`go`, `python`, ...)
+fn registry() -> &'static RwLock<ParserRegistry> {
+    static REGISTRY: OnceLock<RwLock<ParserRegistry>> = OnceLock::new();
+    REGISTRY.get_or_init(|| {
+        let mut registry = ParserRegistry::default();
+        for (language_id, extensions) in BUILTIN_EXTENSIONS {
+            let factory: ParserFactory = Arc::new(move || get_ast_parser(language_id));
+            registry.insert(&language_id.to_string(), language_id, extensions, factory)
+                .expect("builtin parsers use distinct extensions");
+        }
+        RwLock::new(registry)
+    })
+}
+
+/// Registers a language parser. `extensions` are case-insensitive, with or without a leading dot.
+/// Fails if the language or an extension is already registered, built-in parsers included.
+/// Safe to call from several threads. The language maps to its `LanguageId` when there is one,
+/// otherwise to `LanguageId::Unknown`
+pub fn register_parser<F>(language: &str, extensions: &[&str], factory: F) -> Result<(), ParserError>
+where
+    F: Fn() -> Result<Box<dyn AstLanguageParser>, ParserError> + Send + Sync + 'static,
+{
+    registry().write().insert(language, LanguageId::from(language), extensions, Arc::new(factory))
+}
+
+/// Registered language name for the path's extension
+pub fn language_for(path: &Path) -> Option<String> {
+    let extension = path.extension().and_then(|e| e.to_str())?;
+    registry().read().extensions.get(&normalize_extension(extension)).cloned()
+}
+
+/// Creates a parser for a registered language
+pub fn parser_for_language(language: &str) -> Result<(Box<dyn AstLanguageParser>, LanguageId), ParserError> {
+    let (language_id, factory) = {
+        let registry = registry().read();
+        let registered = registry.languages.get(language)
+            .ok_or_else(|| ParserError { message: format!("No parser registered for {}", language) })?;
+        (registered.language_id, registered.factory.clone())
+    };
+    // Call the factory outside the lock so it can query the registry itself
+    Ok((factory()?, language_id))
+}
+
+/// Creates a parser for the path's extension, or None when no parser handles it
+pub fn parser_for(path: &Path) -> Option<Result<(Box<dyn AstLanguageParser>, LanguageId), ParserError>> {
+    language_for(path).map(|language| parser_for_language(&language))
+}
+
+#[cfg(test)]
+mod tests {
+    use std::path::{Path, PathBuf};
+    use std::sync::Arc;
+    use std::thread;
+
+    use parking_lot::RwLock;
+    use tree_sitter::{Node, Point, Range, Tree};
+
+    use crate::codegraph::symbol_graph::{parse_dir, ParseOptions, SymbolKind};
+    use crate::codegraph::treesitter::ast_instance_structs::{AstSymbolInstanceArc, FunctionDeclaration};
+    use crate::codegraph::treesitter::language_id::LanguageId;
+    use crate::codegraph::treesitter::parsers::AstLanguageParser;
+    use crate::codegraph::treesitter::parsers::registry::{language_for, parser_for, parser_for_language, register_parser};
+    use crate::codegraph::treesitter::parsers::utils::get_guid;
+
+    /// Each `def name` line declares a function
+    struct FooParser;
+
+    impl AstLanguageParser for FooParser {
+        fn parse(&mut self, code: &str, path: &PathBuf) -> Vec<AstSymbolInstanceArc> {
+            let mut symbols: Vec<AstSymbolInstanceArc> = vec![];
+            let mut offset = 0;
+            for (row, line) in code.split_inclusive('\n').enumerate() {
+                if let Some(name) = line.trim_end().strip_prefix("def ") {
+                    let mut decl = FunctionDeclaration::default();
+                    let range = Range {
+                        start_byte: offset,
+                        end_byte: offset + line.trim_end().len(),
+                        start_point: Point { row, column: 0 },
+                        end_point: Point { row, column: line.trim_end().len() },
+                    };
+                    decl.ast_fields.name = name.to_string();
+                    decl.ast_fields.file_path = path.clone();
+                    decl.ast_fields.guid = get_guid();
+                    decl.ast_fields.full_range = range;
+                    decl.ast_fields.declaration_range = range;
+                    decl.ast_fields.definition_range = range;
+                    symbols.push(Arc::new(RwLock::new(Box::new(decl))));
+                }
+                offset += line.len();
+            }
+            symbols
+        }
+
+        fn parse_tree(&mut self, _code: &str, _old_tree: Option<&Tree>) -> Option<Tree> {
+            None
+        }
+
+        fn parse_top_level(&mut self, _nodes: &[Node], _code: &str, _path: &PathBuf) -> Vec<AstSymbolInstanceArc> {
+            vec![]
+        }
+    }
+
+    #[test]
+    fn parse_dir_routes_to_registered_parser_test() {
+        register_parser("foo", &[".foo"], || Ok(Box::new(FooParser) as Box<dyn AstLanguageParser>)).unwrap();
+        assert_eq!(language_for(Path::new("/a/b.FOO")), Some("foo".to_string()));
+        let (_, language_id) = parser_for_language("foo").unwrap();
+        assert_eq!(language_id, LanguageId::Unknown);
+
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::write(dir.path().join("greeting.foo"), "def greet\nnot a declaration\ndef wave\n").unwrap();
+        std::fs::write(dir.path().join("main.go"), "package main\n\nfunc main() {}\n").unwrap();
+        let (graph, errors) = parse_dir(dir.path(), &ParseOptions::default()).unwrap();
+        assert!(errors.is_empty(), "{:?}", errors);
+
+        let foo_functions = graph.nodes()
+            .filter(|n| n.file_path == dir.path().join("greeting.foo"))
+            .map(|n| (n.name.clone(), n.kind, n.span.start_line))
+            .collect::<Vec<_>>();
+        assert_eq!(foo_functions, vec![
+            ("greet".to_string(), SymbolKind::Function, 0),
+            ("wave".to_string(), SymbolKind::Function, 2),
+        ]);
+        assert_eq!(graph.find_nodes_by_name("main")[0].language, LanguageId::Go);
+    }
+
+    #[test]
+    fn duplicate_registration_test() {
+        let factory = || Ok(Box::new(FooParser) as Box<dyn AstLanguageParser>);
+        // Extensions and language names of the built-in parsers
+        assert!(register_parser("golang", &["go"], factory).is_err());
+        assert!(register_parser("python", &["py4"], factory).is_err());
+
+        register_parser("dup-a", &["dup"], factory).unwrap();
+        assert!(register_parser("dup-b", &["DUP"], factory).is_err());
+        // A failed registration leaves no extensions behind
+        assert!(register_parser("dup-c", &["dupc", ".dup"], factory).is_err());
+        assert_eq!(language_for(Path::new("x.dupc")), None);
+        assert!(parser_for(Path::new("x.dupc")).is_none());
+        assert!(parser_for_language("dup-c").is_err());
+    }
+
+    #[test]
+    fn concurrent_registration_test() {
+        let results = thread::scope(|scope| {
+            let handles = (0..8)
+                .map(|i| scope.spawn(move || {
+                    let language = format!("concurrent-{}", i);
+                    let own = format!("conc{}", i);
+                    let own_ok = register_parser(&language, &[own.as_str()], || Ok(Box::new(FooParser) as Box<dyn AstLanguageParser>)).is_ok();
+                    let shared_ok = register_parser(&format!("{}-shared", language), &["shared-conc"], || Ok(Box::new(FooParser) as Box<dyn AstLanguageParser>)).is_ok();
+                    (own_ok, shared_ok)
+                }))
+                .collect::<Vec<_>>();
+            handles.into_iter().map(|h| h.join().unwrap()).collect::<Vec<_>>()
+        });
+        assert!(results.iter().all(|(own_ok, _)| *own_ok));
+        assert_eq!(results.iter().filter(|(_, shared_ok)| *shared_ok).count(), 1);
+        for i in 0..8 {
+            assert_eq!(language_for(Path::new(&format!("x.conc{}", i))), Some(format!("concurrent-{}", i)));
+        }
+        assert!(parser_for(Path::new("a.go")).unwrap().is_ok());
+    }
+}
@@ -4,13 +4,13 @@ use std::sync::Arc;
 use parking_lot::RwLock;
 
 use similar::DiffableStr;
-use tree_sitter::{Node, Parser, Point, Range};
+use tree_sitter::{Node, Parser, Point, Range, Tree};
 use uuid::Uuid;
 
-use crate::codegraph::treesitter::ast_instance_structs::{AstSymbolInstance, AstSymbolInstanceArc, ClassFieldDeclaration, CommentDefinition, FunctionArg, FunctionCall, FunctionDeclaration, ImportDeclaration, ImportType, StructDeclaration, TypeAlias, TypeDef, VariableDefinition, VariableUsage};
+use crate::codegraph::treesitter::ast_instance_structs::{AstSymbolInstance, AstSymbolInstanceArc, ClassFieldDeclaration, CommentDefinition, FunctionArg, FunctionCall, FunctionDeclaration, ImportDeclaration, ImportType, StructDeclaration, StructKind, TypeAlias, TypeDef, VariableDefinition, VariableUsage};
 use crate::codegraph::treesitter::language_id::LanguageId;
 use crate::codegraph::treesitter::parsers::{AstLanguageParser, internal_error, ParserError};
-use crate::codegraph::treesitter::parsers::utils::{get_children_guids, get_guid};
+use crate::codegraph::treesitter::parsers::utils::{get_children_guids, get_guid, parse_tree_until};
 use crate::codegraph::treesitter::skeletonizer::SkeletonFormatter;
 use std::collections::{HashMap, VecDeque};
 use crate::codegraph::treesitter::ast_instance_structs::SymbolInformation;
@@ -225,12 +225,27 @@ impl RustParser {
                 end_point: name_node.end_position(),
             }
         }
+        decl.kind = match parent.kind() {
+            "enum_item" => StructKind::Enum,
+            "trait_item" => StructKind::Interface,
+            "impl_item" => StructKind::Impl,
+            _ => StructKind::Struct,
+        };
+        // Supertraits: trait Shape: Named + Debug
+        if let Some(bounds_node) = parent.child_by_field_name("bounds") {
+            for idx in 0..bounds_node.named_child_count() {
+                if let Some(bound) = RustParser::parse_type(&bounds_node.named_child(idx).unwrap(), code) {
+                    decl.inherited_types.push(bound);
+                }
+            }
+        }
         if let Some(type_node) = parent.child_by_field_name("type") {
             symbols.extend(self.find_error_usages(&type_node, code, path, &decl.ast_fields.guid));
             if let Some(trait_node) = parent.child_by_field_name("trait") {
                 symbols.extend(self.find_error_usages(&trait_node, code, path, &decl.ast_fields.guid));
                 if let Some(trait_name) = RustParser::parse_type(&trait_node, code) {
-                    decl.template_types.push(trait_name);
+                    decl.template_types.push(trait_name.clone());
+                    decl.implemented_types.push(trait_name);
                 }
             }
             if let Some(type_name) = RustParser::parse_type(&type_node, code) {
@@ -600,6 +615,8 @@ impl RustParser {
             "ERROR" => {
                 symbols.extend(self.parse_error_usages(&parent, code, path, parent_guid));
             }
+            // The token tree of an unexpanded macro is not an expression
+            "macro_invocation" => {}
             _ => {}
         }
         symbols
@@ -934,72 +951,80 @@ impl RustParser {
         let mut symbols: Vec<AstSymbolInstanceArc> = vec![];
         for i in 0..parent.child_count() {
             let child = parent.child(i).unwrap();
-            let kind = child.kind();
-            let _text = code.slice(child.byte_range()).to_string();
-            match kind {
-                "use_declaration" => {
-                    symbols.extend(self.parse_use_declaration(&child, code, path, parent_guid, is_error));
-                }
-                "type_item" => {
-                    let name_node = child.child_by_field_name("name").unwrap();
-                    let mut type_alias = TypeAlias::default();
-                    type_alias.ast_fields.name = code.slice(name_node.byte_range()).to_string();
-                    type_alias.ast_fields.language = LanguageId::Rust;
-                    type_alias.ast_fields.full_range = child.range();
-                    type_alias.ast_fields.file_path = path.clone();
-                    type_alias.ast_fields.parent_guid = Some(parent_guid.clone());
-                    type_alias.ast_fields.guid = get_guid();
-                    type_alias.ast_fields.is_error = is_error;
+            symbols.extend(self.parse_block_item(&child, code, path, parent_guid, is_error));
+        }
+        symbols
+    }
 
-                    let type_node = child.child_by_field_name("type").unwrap();
-                    if let Some(dtype) = RustParser::parse_type(&type_node, code) {
-                        type_alias.types.push(dtype);
-                    }
-                    symbols.push(Arc::new(RwLock::new(Box::new(type_alias))));
-                }
-                "block" => {
-                    let v = self.parse_block(&child, code, path, parent_guid, is_error);
-                    symbols.extend(v);
-                }
-                "let_declaration" | "const_item" | "static_item" => {
-                    let symbols_ = self.parse_variable_definition(&child, code, path, parent_guid, is_error);
-                    symbols.extend(symbols_);
-                }
-                "expression_statement" => {
-                    let child = child.child(0).unwrap();
-                    let v = self.parse_expression_statement(&child, code, path, parent_guid, is_error);
-                    symbols.extend(v);
-                }
-                // return without keyword
-                "identifier" => {
-                    symbols.extend(self.parse_usages(&child, code, path, parent_guid, is_error));
-                }
-                // return without keyword
-                "call_expression" => {
-                    let symbols_ = self.parse_call_expression(&child, code, path, parent_guid, is_error);
-                    symbols.extend(symbols_);
-                }
-                "enum_item" | "struct_item" | "trait_item" | "impl_item" | "union_item" => {
-                    symbols.extend(self.parse_struct_declaration(&child, code, path, parent_guid, is_error));
-                }
-                "function_item" | "function_signature_item" => {
-                    symbols.extend(self.parse_function_declaration(&child, code, path, parent_guid, is_error));
-                }
-                "line_comment" | "block_comment" => {
-                    let mut def = CommentDefinition::default();
-                    def.ast_fields.language = LanguageId::Rust;
-                    def.ast_fields.full_range = child.range();
-                    def.ast_fields.file_path = path.clone();
-                    def.ast_fields.guid = get_guid();
-                    def.ast_fields.parent_guid = Some(parent_guid.clone());
-                    def.ast_fields.is_error = is_error;
-                    symbols.push(Arc::new(RwLock::new(Box::new(def))));
-                }
+    fn parse_block_item(&mut self, child: &Node, code: &str, path: &PathBuf, parent_guid: &Uuid, is_error: bool) -> Vec<AstSymbolInstanceArc> {
+        let mut symbols: Vec<AstSymbolInstanceArc> = vec![];
+        let kind = child.kind();
+        let _text = code.slice(child.byte_range()).to_string();
+        match kind {
+            "use_declaration" => {
+                symbols.extend(self.parse_use_declaration(&child, code, path, parent_guid, is_error));
+            }
+            "type_item" => {
+                let name_node = child.child_by_field_name("name").unwrap();
+                let mut type_alias = TypeAlias::default();
+                type_alias.ast_fields.name = code.slice(name_node.byte_range()).to_string();
+                type_alias.ast_fields.language = LanguageId::Rust;
+                type_alias.ast_fields.full_range = child.range();
+                type_alias.ast_fields.file_path = path.clone();
+                type_alias.ast_fields.parent_guid = Some(parent_guid.clone());
+                type_alias.ast_fields.guid = get_guid();
+                type_alias.ast_fields.is_error = is_error;
 
-                &_ => {
-                    let usages = self.parse_usages(&child, code, path, parent_guid, is_error);
-                    symbols.extend(usages);
+                let type_node = child.child_by_field_name("type").unwrap();
+                if let Some(dtype) = RustParser::parse_type(&type_node, code) {
+                    type_alias.types.push(dtype);
                 }
+                symbols.push(Arc::new(RwLock::new(Box::new(type_alias))));
+            }
+            "block" => {
+                let v = self.parse_block(&child, code, path, parent_guid, is_error);
+                symbols.extend(v);
+            }
+            "let_declaration" | "const_item" | "static_item" => {
+                let symbols_ = self.parse_variable_definition(&child, code, path, parent_guid, is_error);
+                symbols.extend(symbols_);
+            }
+            "expression_statement" => {
+                let child = child.child(0).unwrap();
+                let v = self.parse_expression_statement(&child, code, path, parent_guid, is_error);
+                symbols.extend(v);
+            }
+            // return without keyword
+            "identifier" => {
+                symbols.extend(self.parse_usages(&child, code, path, parent_guid, is_error));
+            }
+            // return without keyword
+            "call_expression" => {
+                let symbols_ = self.parse_call_expression(&child, code, path, parent_guid, is_error);
+                symbols.extend(symbols_);
+            }
+            "enum_item" | "struct_item" | "trait_item" | "impl_item" | "union_item" => {
+                symbols.extend(self.parse_struct_declaration(&child, code, path, parent_guid, is_error));
+            }
+            "function_item" | "function_signature_item" => {
+                symbols.extend(self.parse_function_declaration(&child, code, path, parent_guid, is_error));
+            }
+            // Macro arguments and macro_rules! bodies are token trees, they are not expanded
+            "macro_invocation" | "macro_definition" => {}
+            "line_comment" | "block_comment" => {
+                let mut def = CommentDefinition::default();
+                def.ast_fields.language = LanguageId::Rust;
+                def.ast_fields.full_range = child.range();
+                def.ast_fields.file_path = path.clone();
+                def.ast_fields.guid = get_guid();
+                def.ast_fields.parent_guid = Some(parent_guid.clone());
+                def.ast_fields.is_error = is_error;
+                symbols.push(Arc::new(RwLock::new(Box::new(def))));
+            }
+
+            &_ => {
+                let usages = self.parse_usages(&child, code, path, parent_guid, is_error);
+                symbols.extend(usages);
             }
         }
         symbols
@@ -1013,6 +1038,19 @@ impl AstLanguageParser for RustParser {
         let symbols = self.parse_block(&tree.root_node(), code, path, &parent_guid, false);
         symbols
     }
+
+    fn parse_tree(&mut self, code: &str, old_tree: Option<&Tree>) -> Option<Tree> {
+        self.parser.parse(code, old_tree)
+    }
+
+    fn parse_tree_until(&mut self, code: &str, old_tree: Option<&Tree>, should_abort: &dyn Fn() -> bool) -> Option<Tree> {
+        parse_tree_until(&mut self.parser, code, old_tree, should_abort)
+    }
+
+    fn parse_top_level(&mut self, nodes: &[Node], code: &str, path: &PathBuf) -> Vec<AstSymbolInstanceArc> {
+        let parent_guid = get_guid();
+        nodes.iter().flat_map(|node| self.parse_block_item(node, code, path, &parent_guid, false)).collect()
+    }
 }
 
 pub struct RustSkeletonFormatter;
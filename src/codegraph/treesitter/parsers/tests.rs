@@ -38,9 +38,11 @@ mod rust;
 mod python;
 mod java;
 mod cpp;
+mod c;
 mod ts;
 mod js;
 mod go;
+mod kotlin;
 
 pub(crate) fn print(symbols: &Vec<AstSymbolInstanceArc>, code: &str) {
     let guid_to_symbol_map = symbols.iter()
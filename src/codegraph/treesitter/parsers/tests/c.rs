@@ -0,0 +1,91 @@
+#[cfg(test)]
+mod tests {
+    use std::path::PathBuf;
+
+    use serde_json::json;
+
+    use crate::codegraph::symbol_graph::{parse_code, SymbolEdgeKind, SymbolGraph, SymbolKind};
+    use crate::codegraph::treesitter::language_id::LanguageId;
+    use crate::codegraph::treesitter::parsers::AstLanguageParser;
+    use crate::codegraph::treesitter::parsers::c::CParser;
+
+    const SHAPES_C_CODE: &str = include_str!("cases/c/shapes.c");
+
+    fn build_graph(code: &str, path: &str) -> SymbolGraph {
+        let mut parser: Box<dyn AstLanguageParser> = Box::new(CParser::new().expect("CParser::new"));
+        let symbols = parser.parse(code, &PathBuf::from(path));
+        SymbolGraph::from_symbols(&symbols)
+    }
+
+    /// Sorted (source name, target name) pairs of one edge kind
+    fn edges_of(graph: &SymbolGraph, kind: SymbolEdgeKind) -> Vec<(String, String)> {
+        let mut edges = graph.edges_of_kind(kind)
+            .map(|edge| (
+                graph.get_node(&edge.source).unwrap().name.clone(),
+                graph.get_node(&edge.target).unwrap().name.clone(),
+            ))
+            .collect::<Vec<_>>();
+        edges.sort();
+        edges
+    }
+
+    #[test]
+    fn declaration_kinds_test() {
+        let graph = build_graph(SHAPES_C_CODE, "shapes.c");
+        let kind_of = |name: &str| graph.find_nodes_by_qualified_name(name)[0].kind;
+        // Anonymous structs take the typedef name; tagged structs get a separate alias
+        assert_eq!(kind_of("Point"), SymbolKind::Struct);
+        assert_eq!(kind_of("shape"), SymbolKind::Struct);
+        assert_eq!(kind_of("Shape"), SymbolKind::TypeAlias);
+        assert_eq!(kind_of("Value"), SymbolKind::Union);
+        assert_eq!(kind_of("Color"), SymbolKind::Enum);
+        assert_eq!(kind_of("visit_fn"), SymbolKind::TypeAlias);
+        assert_eq!(kind_of("Point.y"), SymbolKind::Field);
+        assert_eq!(kind_of("Color.BLUE"), SymbolKind::Field);
+        assert_eq!(kind_of("shape_count"), SymbolKind::Variable);
+        assert_eq!(graph.find_nodes_by_qualified_name("shapes")[0].attributes["type"], json!("Shape*"));
+        // Locals inside functions are not nodes
+        assert!(graph.find_nodes_by_name("total").is_empty());
+
+        let max_shapes = graph.find_nodes_by_qualified_name("MAX_SHAPES")[0];
+        assert_eq!(max_shapes.kind, SymbolKind::Macro);
+        assert_eq!(max_shapes.attributes.get("function_like"), None);
+        let square = graph.find_nodes_by_qualified_name("SQUARE")[0];
+        assert_eq!(square.kind, SymbolKind::Macro);
+        assert_eq!(square.attributes["function_like"], json!(true));
+    }
+
+    #[test]
+    fn static_functions_test() {
+        let graph = build_graph(SHAPES_C_CODE, "shapes.c");
+        let area = graph.find_nodes_by_qualified_name("area")[0];
+        assert_eq!(area.kind, SymbolKind::Function);
+        assert_eq!(area.attributes["file_local"], json!(true));
+        assert_eq!(area.attributes["signature"], json!("(int) int"));
+        let register = graph.find_nodes_by_qualified_name("register_shape")[0];
+        assert_eq!(register.attributes.get("file_local"), None);
+        assert_eq!(register.attributes["signature"], json!("(Shape*) int"));
+    }
+
+    #[test]
+    fn calls_and_macros_test() {
+        let graph = build_graph(SHAPES_C_CODE, "shapes.c");
+        assert_eq!(edges_of(&graph, SymbolEdgeKind::Calls), vec![
+            ("register_shape".to_string(), "area".to_string()),
+            ("register_shape".to_string(), "printf".to_string()),
+            ("visit_shapes".to_string(), "visit".to_string()),
+        ]);
+        // Uses of function-like macros are not calls to undefined functions
+        assert_eq!(edges_of(&graph, SymbolEdgeKind::References), vec![
+            ("area".to_string(), "SQUARE".to_string()),
+        ]);
+        assert!(graph.nodes().all(|n| n.kind != SymbolKind::Unresolved || n.name != "SQUARE"));
+    }
+
+    #[test]
+    fn c_files_use_c_parser_test() {
+        let graph = parse_code(SHAPES_C_CODE, &PathBuf::from("/src/shapes.c")).unwrap();
+        assert!(graph.nodes().all(|n| n.language == LanguageId::C));
+        assert_eq!(graph.find_nodes_by_qualified_name("area").len(), 1);
+    }
+}
@@ -0,0 +1,48 @@
+#include <stdio.h>
+
+#define MAX_SHAPES 16
+#define SQUARE(x) ((x) * (x))
+
+typedef struct {
+    int x;
+    int y;
+} Point;
+
+typedef struct shape {
+    const char *name;
+    Point origin;
+} Shape;
+
+typedef union {
+    int i;
+    float f;
+} Value;
+
+typedef enum { RED, GREEN, BLUE } Color;
+
+typedef int (*visit_fn)(const Shape *shape);
+
+static int shape_count = 0;
+Shape *shapes[MAX_SHAPES];
+
+/* file-local helper */
+static int area(int side) {
+    return SQUARE(side);
+}
+
+int register_shape(Shape *shape) {
+    if (shape_count >= MAX_SHAPES) {
+        return -1;
+    }
+    shapes[shape_count++] = shape;
+    printf("%s %d\n", shape->name, area(shape->origin.x));
+    return shape_count;
+}
+
+int visit_shapes(visit_fn visit) {
+    int total = 0;
+    for (int i = 0; i < shape_count; i++) {
+        total += visit(shapes[i]);
+    }
+    return total;
+}
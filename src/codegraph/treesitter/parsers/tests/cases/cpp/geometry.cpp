@@ -0,0 +1,45 @@
+#include <cmath>
+
+namespace geo {
+
+template <typename T>
+T square(T value) {
+    return value * value;
+}
+
+class Circle {
+public:
+    explicit Circle(double r);
+    double area() const;
+    double radius;
+
+protected:
+    void scale(double factor);
+
+private:
+    int id;
+};
+
+double Circle::area() const {
+    return 3.14159 * square(radius);
+}
+
+void Circle::scale(double factor) {
+    radius *= factor;
+}
+
+template <typename K, int N>
+struct Table {
+    K keys[N];
+    int size() const { return N; }
+};
+
+namespace {
+int counter() {
+    return 0;
+}
+}
+
+}  // namespace geo
+
+geo::Circle::Circle(double r) : radius(r) {}
@@ -0,0 +1,39 @@
+package main
+
+type Counter struct {
+	n int
+}
+
+func (c *Counter) Inc() {
+	c.n++
+}
+
+func (c *Counter) Add(k int) {
+	for i := 0; i < k; i++ {
+		c.Inc()
+	}
+}
+
+func NewCounter() *Counter {
+	return &Counter{}
+}
+
+func run(other *Counter, unknown Thing) {
+	var a Counter
+	a.Inc()
+	b := &Counter{n: 1}
+	b.Add(2)
+	c := NewCounter()
+	c.Inc()
+	other.Add(1)
+	unknown.Do()
+	helper()
+}
+
+func helper() {
+	d := new(Counter)
+	d.Inc()
+	defer func() {
+		d.Inc()
+	}()
+}
@@ -0,0 +1,64 @@
+package main
+
+func classify(values []int, limit int) string {
+	count := 0
+	for _, v := range values {
+		if v > limit && v%2 == 0 {
+			count++
+		} else if v < 0 || v > 100 {
+			count--
+		}
+	}
+	switch {
+	case count > 10:
+		return "many"
+	case count > 0:
+		return "some"
+	default:
+		return "none"
+	}
+}
+
+func describe(x interface{}) string {
+	switch x.(type) {
+	case int, int64:
+		return "integer"
+	case string:
+		return "string"
+	}
+	return "other"
+}
+
+func retry(attempts int, f func() error) error {
+	var err error
+	for i := 0; i < attempts; i++ {
+		err = func() error {
+			if f == nil {
+				return nil
+			}
+			return f()
+		}()
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+type worker struct {
+	jobs chan int
+	done chan bool
+}
+
+func (w *worker) drain() int {
+	total := 0
+	for {
+		select {
+		case job := <-w.jobs:
+			total += job
+		case <-w.done:
+			return total
+		default:
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+/*
+Palette holds the colors used for drawing.
+It is shared by all renderers.
+*/
+type Palette struct {
+	Background string // fill behind every shape
+	Foreground string /* stroke color */
+	// Accent highlights the selected shape
+	Accent string
+}
+
+// This note describes the file layout, not defaultPalette.
+
+func defaultPalette() Palette {
+	return Palette{}
+}
+
+// paletteSize counts the colors in a palette.
+// Empty colors are skipped.
+//go:noinline
+func paletteSize(p Palette) int {
+	return 3 // background, foreground and accent
+}
@@ -0,0 +1,62 @@
+package main
+
+type Point struct {
+	X int
+	Y int
+}
+
+func (p *Point) Move(dx int, dy int) {
+	p.X += dx
+	p.Y += dy
+}
+
+func (p Point) String() string {
+	return "point"
+}
+
+type Color struct {
+	Name string
+}
+
+func (c Color) String() string {
+	return c.Name
+}
+
+func (c *Color) Darken() {
+}
+
+// ColoredPoint embeds a single struct
+type ColoredPoint struct {
+	Point
+	Label string
+}
+
+// Pixel embeds two structs that both provide String
+type Pixel struct {
+	*Point
+	Color
+}
+
+// NamedPoint declares its own Move, shadowing the promoted one
+type NamedPoint struct {
+	Point
+	Name string
+}
+
+func (n NamedPoint) Move(dx int, dy int) {
+}
+
+// Sprite reaches Point and Color through Pixel
+type Sprite struct {
+	Pixel
+}
+
+func paint() {
+	var c ColoredPoint
+	c.Move(1, 2)
+	var px Pixel
+	px.Darken()
+	px.String()
+	var n NamedPoint
+	n.Move(1, 1)
+}
@@ -0,0 +1,10 @@
+package main
+
+// Marker embeds a struct declared in embedding.go
+type Marker struct {
+	ColoredPoint
+}
+
+func (m Marker) Label() string {
+	return "marker"
+}
@@ -0,0 +1,30 @@
+package main
+
+type Counter struct {
+	name string
+	hits int
+}
+
+func (c *Counter) Reset() {
+	c.hits = 0
+	c.name = ""
+}
+
+func (c *Counter) Hit() {
+	c.hits++
+}
+
+func rename(c *Counter, name string) string {
+	if c.hits == 0 {
+		return ""
+	}
+	old := c.name
+	c.name = name
+	return old
+}
+
+func total(cs []Counter, c Counter) int {
+	hit := c.Hit
+	hit()
+	return len(cs)
+}
@@ -0,0 +1,45 @@
+package main
+
+// Number is satisfied by the built-in numeric types
+type Number interface {
+	~int | ~float64
+}
+
+// Stack is a LIFO collection
+type Stack[T any] struct {
+	items []T
+}
+
+func (s *Stack[T]) Push(v T) {
+	s.items = append(s.items, v)
+}
+
+func (s *Stack[T]) Pop() T {
+	v := s.items[len(s.items)-1]
+	s.items = s.items[:len(s.items)-1]
+	return v
+}
+
+func Map[T any, U comparable](xs []T, f func(T) U) []U {
+	out := make([]U, 0, len(xs))
+	for _, x := range xs {
+		out = append(out, f(x))
+	}
+	return out
+}
+
+func Sum[N Number](xs []N) N {
+	var total N
+	for _, x := range xs {
+		total += x
+	}
+	return total
+}
+
+func main() {
+	s := &Stack[int]{}
+	s.Push(1)
+	names := Map[int, string]([]int{1, 2}, func(n int) string { return "n" })
+	total := Sum[int]([]int{1, 2})
+	_, _ = names, total
+}
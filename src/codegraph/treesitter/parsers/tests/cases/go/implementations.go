@@ -0,0 +1,40 @@
+package storage
+
+// Store keeps string values by key.
+type Store interface {
+	Get(key string) string
+	Put(key string, value string)
+}
+
+// MemoryStore satisfies Store with value receivers.
+type MemoryStore struct {
+	items map[string]string
+}
+
+func (m MemoryStore) Get(key string) string {
+	return m.items[key]
+}
+
+func (m MemoryStore) Put(key string, value string) {
+	m.items[key] = value
+}
+
+// FileStore satisfies Store only through *FileStore.
+type FileStore struct {
+	path string
+}
+
+func (f *FileStore) Get(key string) string {
+	return f.path + "/" + key
+}
+
+func (f *FileStore) Put(key string, value string) {
+	f.path = value
+}
+
+// ReadOnly has no Put and does not satisfy Store.
+type ReadOnly struct{}
+
+func (r ReadOnly) Get(key string) string {
+	return key
+}
@@ -0,0 +1,18 @@
+package main
+
+import "os"
+
+import (
+	"fmt"
+	str "strings"
+	. "math"
+	_ "net/http/pprof"
+	`encoding/json`
+	"github.com/acme/tools/v2"
+)
+
+func main() {
+	fmt.Println(str.ToUpper(os.Args[0]), Pi)
+	json.Valid(nil)
+	tools.Run()
+}
@@ -0,0 +1,12 @@
+// Package main keeps the log levels by hand.
+// The String method lives in level_string.go.
+
+package main
+
+// Level is a log severity
+type Level int
+
+func levelLabel(l Level) string {
+	// Code generated by hand; DO NOT EDIT.
+	return l.String()
+}
@@ -0,0 +1,25 @@
+package main
+
+type Base struct {
+	ID int
+}
+
+func (b Base) Describe() string {
+	return "base"
+}
+
+func (*Base) Reset() {
+}
+
+type Derived struct {
+	Base
+	Name string
+}
+
+func (d *Derived) Rename(name string) {
+	d.Name = name
+}
+
+func (Derived) Kind() string {
+	return "derived"
+}
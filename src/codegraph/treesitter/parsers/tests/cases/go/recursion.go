@@ -0,0 +1,44 @@
+package main
+
+// factorial recurses directly
+func factorial(n int) int {
+	if n <= 1 {
+		return 1
+	}
+	return n * factorial(n-1)
+}
+
+// isEven and isOdd are mutually recursive
+func isEven(n int) bool {
+	if n == 0 {
+		return true
+	}
+	return isOdd(n - 1)
+}
+
+func isOdd(n int) bool {
+	if n == 0 {
+		return false
+	}
+	return isEven(n - 1)
+}
+
+// stepA, stepB and stepC form a three-function cycle
+func stepA(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	return stepB(n - 1)
+}
+
+func stepB(n int) int {
+	return stepC(n)
+}
+
+func stepC(n int) int {
+	return stepA(n)
+}
+
+func countdown(n int) int {
+	return factorial(n) + stepA(n)
+}
@@ -0,0 +1,27 @@
+package main
+
+type Config struct {
+	Name string
+}
+
+type Settings struct {
+	Main Config
+}
+
+func load() Config {
+	return Config{Name: "default"}
+}
+
+func defaults() {
+	c := load()
+	_ = c
+}
+
+func override() {
+	type Config struct {
+		Name  string
+		Local bool
+	}
+	var local Config = Config{Name: "local"}
+	_ = local
+}
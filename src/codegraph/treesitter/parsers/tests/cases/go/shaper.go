@@ -0,0 +1,41 @@
+package main
+
+// Shaper is satisfied by any type with an Area method
+type Shaper interface {
+	Area() int
+}
+
+type Scaler interface {
+	Scale(factor int)
+}
+
+// ScalableShaper embeds both interfaces above
+type ScalableShaper interface {
+	Shaper
+	Scaler
+}
+
+type Namer interface {
+	Name() (string, error)
+}
+
+type Square struct {
+	side int
+}
+
+func (s Square) Area() int {
+	return s.side * s.side
+}
+
+func (s *Square) Scale(factor int) {
+	s.side *= factor
+}
+
+// Circle has an Area method with a different signature
+type Circle struct {
+	radius float64
+}
+
+func (c Circle) Area() float64 {
+	return 3 * c.radius * c.radius
+}
@@ -0,0 +1,6 @@
+package main
+
+// scale multiplies a length by a factor
+func scale(length int, factor int) int {
+	return length * factor
+}
@@ -0,0 +1,10 @@
+package main
+
+import "fmt"
+
+// resize doubles a length using scale from split_geometry.go
+func resize(length int) int {
+	doubled := scale(length, 2)
+	fmt.Println(doubled)
+	return doubled
+}
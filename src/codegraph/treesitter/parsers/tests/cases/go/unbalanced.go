@@ -0,0 +1,15 @@
+package geometry
+
+type Point struct {
+	X int
+	Y int
+}
+
+// Origin has a stray closing brace after its body.
+func Origin() Point {
+	return Point{X: 0, Y: 0}
+}}
+
+func Scale(p Point, k int) Point {
+	return Point{X: p.X * k, Y: p.Y * k}
+}
@@ -0,0 +1,38 @@
+package main
+
+import "fmt"
+
+type greeter interface {
+	greet() string
+}
+
+type english struct{}
+
+// greet is only called through the greeter interface
+func (e english) greet() string {
+	return "hello"
+}
+
+func unusedHelper() int {
+	return 42
+}
+
+type Registry struct {
+	names []string
+}
+
+func (r *Registry) Register(name string) {
+	r.names = append(r.names, name)
+}
+
+func format(g greeter) string {
+	return fmt.Sprintf("%s!", g.greet())
+}
+
+func init() {
+	fmt.Println("starting")
+}
+
+func main() {
+	fmt.Println(format(english{}))
+}
@@ -0,0 +1,29 @@
+package main
+
+type Cell struct {
+	Row int
+	Col int
+}
+
+func newCell(row, col int) *Cell {
+	return &Cell{Row: row, Col: col}
+}
+
+var origin Cell
+
+var (
+	cursor *Cell
+	label  string = "origin"
+)
+
+const maxDepth = 3
+
+func shadow() int {
+	depth := maxDepth
+	if depth > 0 {
+		depth := depth - 1
+		_ = depth
+	}
+	c := newCell(1, 2)
+	return depth + c.Row
+}
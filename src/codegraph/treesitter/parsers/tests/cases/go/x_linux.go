@@ -0,0 +1,7 @@
+//go:build !cgo
+
+package platform
+
+func platformName() string {
+	return "linux"
+}
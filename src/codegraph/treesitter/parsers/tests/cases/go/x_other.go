@@ -0,0 +1,7 @@
+//go:build !linux && !windows
+
+package platform
+
+func platformName() string {
+	return "other"
+}
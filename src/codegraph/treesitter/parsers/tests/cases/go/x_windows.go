@@ -0,0 +1,5 @@
+package platform
+
+func platformName() string {
+	return "windows"
+}
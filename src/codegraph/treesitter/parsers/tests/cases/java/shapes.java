@@ -0,0 +1,62 @@
+package com.example.shapes;
+
+import java.util.List;
+
+/** Shape is implemented by every drawable figure. */
+public interface Shape {
+    double area();
+}
+
+interface Named {
+    String name();
+}
+
+interface Drawable extends Shape, Named {
+    void draw(List<String> layers);
+}
+
+@Deprecated
+@SuppressWarnings("unused")
+class Circle implements Drawable {
+    private double radius;
+
+    public Circle(double radius) {
+        this.radius = radius;
+    }
+
+    @Override
+    public double area() {
+        return Math.PI * radius * radius;
+    }
+
+    public double scale(double factor) {
+        return radius * factor;
+    }
+
+    public double scale(int factor, boolean round) {
+        return round ? Math.round(radius * factor) : radius * factor;
+    }
+
+    @Override
+    public String name() {
+        return "circle";
+    }
+
+    @Override
+    public void draw(List<String> layers) {
+    }
+}
+
+class UnitCircle extends Circle {
+    UnitCircle() {
+        super(1);
+    }
+}
+
+enum Palette implements Named {
+    RED, GREEN;
+
+    public String label() {
+        return toString();
+    }
+}
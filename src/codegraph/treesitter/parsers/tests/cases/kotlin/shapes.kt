@@ -0,0 +1,42 @@
+package com.example.shapes
+
+import kotlin.math.sqrt
+
+interface Shape {
+    fun area(): Double
+}
+
+/** A point on the plane. */
+data class Point(val x: Double, val y: Double)
+
+open class Polygon(val points: List<Point>) : Shape {
+    override fun area(): Double = 0.0
+}
+
+class Square(val side: Double, val label: String? = null) : Polygon(listOf()) {
+    var scale: Double = 1.0
+
+    override fun area(): Double = side * side * scale
+
+    companion object {
+        fun unit(): Square = Square(1.0)
+    }
+}
+
+object Origin : Shape {
+    val point = Point(0.0, 0.0)
+
+    override fun area(): Double = 0.0
+}
+
+fun Point.distanceTo(other: Point, scale: Double = 1.0): Double {
+    val dx = x - other.x
+    val dy = y - other.y
+    return sqrt(dx * dx + dy * dy) * scale
+}
+
+fun String?.orBlank(fallback: String = ""): String = this ?: fallback
+
+fun describe(shape: Shape?, name: String? = null): String {
+    return name ?: shape.toString()
+}
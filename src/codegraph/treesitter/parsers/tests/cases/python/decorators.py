@@ -0,0 +1,37 @@
+import functools
+from dataclasses import dataclass
+
+
+def logged(func):
+    @functools.wraps(func)
+    def wrapper(*args, **kwargs):
+        print(func.__name__)
+        return func(*args, **kwargs)
+    return wrapper
+
+
+@dataclass
+class Shape:
+    name: str
+
+    @property
+    def label(self):
+        return self.name
+
+    @staticmethod
+    @logged
+    def create(name):
+        return Shape(name)
+
+
+@logged
+def area(shape):
+    def square(x):
+        return x * x
+    return square(len(shape.name))
+
+
+if __name__ == "__main__":
+    shape = Shape.create("square")
+    result = area(shape)
+    print(result)
@@ -0,0 +1,60 @@
+use std::fmt;
+
+macro_rules! square {
+    ($x:expr) => {
+        $x * $x
+    };
+}
+
+pub trait Named {
+    fn name(&self) -> String;
+}
+
+pub trait Shape: Named {
+    fn area(&self) -> f64;
+
+    fn describe(&self) -> String {
+        format!("{} with area {}", self.name(), self.area())
+    }
+}
+
+pub enum Kind {
+    Round,
+    Angular,
+}
+
+pub struct Circle {
+    radius: f64,
+}
+
+impl Circle {
+    pub fn new(radius: f64) -> Self {
+        Circle { radius }
+    }
+}
+
+impl Named for Circle {
+    fn name(&self) -> String {
+        String::from("circle")
+    }
+}
+
+impl Shape for Circle {
+    fn area(&self) -> f64 {
+        3.14 * square!(self.radius)
+    }
+}
+
+impl fmt::Display for Circle {
+    fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+        write!(f, "Circle({})", self.radius)
+    }
+}
+
+pub fn kinds() -> Vec<Kind> {
+    vec![Kind::Round, Kind::Angular]
+}
+
+thread_local! {
+    static UNIT: Circle = Circle::new(1.0);
+}
@@ -0,0 +1,53 @@
+interface Shape {
+    area(): number;
+}
+
+interface Named {
+    name: string;
+}
+
+interface NamedShape extends Shape, Named {
+    describe(): string;
+}
+
+type Point = {
+    x: number;
+    y: number;
+};
+
+type Id = string | number;
+
+enum Color {
+    Red,
+    Green = "green",
+}
+
+abstract class Base {
+    constructor(public id: Id) {}
+}
+
+class Circle extends Base implements NamedShape {
+    name = "circle";
+
+    constructor(id: Id, private radius: number) {
+        super(id);
+    }
+
+    area(): number {
+        return Math.PI * this.radius * this.radius;
+    }
+
+    describe(): string {
+        return `${this.name} ${this.area()}`;
+    }
+}
+
+function makeCircle(radius: number): Circle {
+    return new Circle(1, radius);
+}
+
+const totalArea = (shapes: Shape[]): number => {
+    return shapes.reduce((sum, s) => sum + s.area(), 0);
+};
+
+const origin: Point = { x: 0, y: 0 };
@@ -0,0 +1,26 @@
+import React from 'react';
+
+interface WidgetProps {
+    title: string;
+    count?: number;
+}
+
+const Badge = ({ count }: { count: number }) => <span className="badge">{count}</span>;
+
+export function Widget({ title, count = 0 }: WidgetProps) {
+    const onClick = () => console.log(title);
+    return (
+        <div className="widget" onClick={onClick}>
+            <h1>{title}</h1>
+            <Badge count={count} />
+        </div>
+    );
+}
+
+class Panel extends React.Component<WidgetProps> implements WidgetProps {
+    title = "panel";
+
+    render() {
+        return <section><Widget title={this.title} /></section>;
+    }
+}
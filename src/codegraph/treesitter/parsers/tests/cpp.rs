@@ -3,6 +3,9 @@ mod tests {
     use std::fs::canonicalize;
     use std::path::PathBuf;
 
+    use serde_json::json;
+
+    use crate::codegraph::symbol_graph::{SymbolEdgeKind, SymbolGraph, SymbolKind};
     use crate::codegraph::treesitter::language_id::LanguageId;
     use crate::codegraph::treesitter::parsers::AstLanguageParser;
     use crate::codegraph::treesitter::parsers::cpp::CppParser;
@@ -14,6 +17,13 @@ mod tests {
     const CIRCLE_CPP_CODE: &str = include_str!("cases/cpp/circle.cpp");
     const CIRCLE_CPP_SKELETON: &str = include_str!("cases/cpp/circle.cpp.skeleton");
     const CIRCLE_CPP_DECLS: &str = include_str!("cases/cpp/circle.cpp.decl_json");
+    const GEOMETRY_CPP_CODE: &str = include_str!("cases/cpp/geometry.cpp");
+
+    fn build_graph(code: &str, path: &str) -> SymbolGraph {
+        let mut parser: Box<dyn AstLanguageParser> = Box::new(CppParser::new().expect("CppParser::new"));
+        let symbols = parser.parse(code, &PathBuf::from(path));
+        SymbolGraph::from_symbols(&symbols)
+    }
 
     #[test]
     fn parser_test() {
@@ -38,4 +48,71 @@ mod tests {
         assert!(file.exists());
         base_declaration_formatter_test(&LanguageId::Cpp, &mut parser, &file, CIRCLE_CPP_CODE, CIRCLE_CPP_DECLS);
     }
-}
\ No newline at end of file
+
+    #[test]
+    fn namespaces_test() {
+        let graph = build_graph(GEOMETRY_CPP_CODE, "/geometry.cpp");
+        let kind_of = |name: &str| graph.find_nodes_by_qualified_name(name)[0].kind;
+        assert_eq!(kind_of("geo"), SymbolKind::Namespace);
+        assert_eq!(kind_of("geo.Circle"), SymbolKind::Struct);
+        assert_eq!(kind_of("geo.Circle.radius"), SymbolKind::Field);
+        assert_eq!(kind_of("geo.square"), SymbolKind::Function);
+        assert_eq!(kind_of("geo.Table.size"), SymbolKind::Method);
+        assert_eq!(kind_of("geo.(anonymous namespace).counter"), SymbolKind::Function);
+        let geo = graph.find_nodes_by_qualified_name("geo")[0];
+        let circle = graph.find_nodes_by_qualified_name("geo.Circle")[0];
+        assert_eq!(graph.parent_of(&circle.id).unwrap().id, geo.id);
+    }
+
+    #[test]
+    fn out_of_line_methods_test() {
+        let graph = build_graph(GEOMETRY_CPP_CODE, "/geometry.cpp");
+        let circle = graph.find_nodes_by_qualified_name("geo.Circle")[0];
+        // Out-of-line definitions inside and outside the namespace both belong to the class
+        for name in ["geo.Circle.area", "geo.Circle.scale", "geo.Circle.Circle"] {
+            let methods = graph.find_nodes_by_qualified_name(name);
+            assert_eq!(methods.len(), 1, "{}", name);
+            let method = methods[0];
+            assert_eq!(method.kind, SymbolKind::Method, "{}", name);
+            assert_eq!(method.attributes["out_of_line"], json!(true));
+            assert_eq!(graph.parent_of(&method.id).unwrap().id, circle.id, "{}", name);
+            let owners = graph.outgoing_edges(&method.id, Some(SymbolEdgeKind::MethodOf));
+            assert_eq!(owners.iter().map(|e| e.target).collect::<Vec<_>>(), vec![circle.id], "{}", name);
+        }
+        // The in-class declaration is not a separate node
+        assert_eq!(graph.find_nodes_by_name("area").len(), 1);
+    }
+
+    #[test]
+    fn template_parameters_test() {
+        let graph = build_graph(GEOMETRY_CPP_CODE, "/geometry.cpp");
+        let params = |owner: &str| {
+            let owner = graph.find_nodes_by_qualified_name(owner)[0];
+            graph.children_of(&owner.id).iter()
+                .filter(|n| n.kind == SymbolKind::TypeParameter)
+                .map(|n| n.name.clone())
+                .collect::<Vec<_>>()
+        };
+        assert_eq!(params("geo.square"), vec!["T"]);
+        assert_eq!(params("geo.Table"), vec!["K", "N"]);
+        assert!(params("geo.Circle").is_empty());
+    }
+
+    #[test]
+    fn access_specifiers_test() {
+        let graph = build_graph(GEOMETRY_CPP_CODE, "/geometry.cpp");
+        let access = |name: &str| {
+            let node = graph.find_nodes_by_qualified_name(name)[0];
+            (node.attributes.get("access").and_then(|a| a.as_str()).map(|a| a.to_string()), node.is_exported())
+        };
+        assert_eq!(access("geo.Circle.radius"), (Some("public".to_string()), Some(true)));
+        assert_eq!(access("geo.Circle.id"), (Some("private".to_string()), Some(false)));
+        // Out-of-line member functions take the access of their in-class declaration
+        assert_eq!(access("geo.Circle.area"), (Some("public".to_string()), Some(true)));
+        assert_eq!(access("geo.Circle.scale"), (Some("protected".to_string()), Some(false)));
+        // struct members are public by default
+        assert_eq!(access("geo.Table.size"), (Some("public".to_string()), Some(true)));
+        assert_eq!(access("geo.square"), (None, Some(true)));
+        assert_eq!(access("geo.(anonymous namespace).counter"), (None, Some(false)));
+    }
+}
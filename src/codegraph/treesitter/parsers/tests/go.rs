@@ -1,8 +1,10 @@
 #[cfg(test)]
 mod tests {
+    use std::collections::HashMap;
     use std::fs::canonicalize;
     use std::path::PathBuf;
 
+    use crate::codegraph::symbol_graph::{link_interface_satisfaction, parse_code, parse_dir, ImportKind, ParseOptions, ReceiverKind, SymbolEdgeKind, SymbolGraph, SymbolKind, SymbolNode};
     use crate::codegraph::treesitter::language_id::LanguageId;
     use crate::codegraph::treesitter::parsers::AstLanguageParser;
     use crate::codegraph::treesitter::parsers::go::GoParser;
@@ -16,6 +18,123 @@ mod tests {
     const SHAPE_GO_SKELETON: &str = include_str!("cases/go/shape.go.skeleton");
     const SHAPE_GO_DECLS: &str = include_str!("cases/go/shape.go.decl_json");
 
+    const RECEIVERS_GO_CODE: &str = include_str!("cases/go/receivers.go");
+    const CALLS_GO_CODE: &str = include_str!("cases/go/calls.go");
+    const IMPORTS_GO_CODE: &str = include_str!("cases/go/imports.go");
+    const SHAPER_GO_CODE: &str = include_str!("cases/go/shaper.go");
+    const EMBEDDING_GO_CODE: &str = include_str!("cases/go/embedding.go");
+    const EMBEDDING_MARKER_GO_CODE: &str = include_str!("cases/go/embedding_marker.go");
+    const GENERICS_GO_CODE: &str = include_str!("cases/go/generics.go");
+    const VARIABLES_GO_CODE: &str = include_str!("cases/go/variables.go");
+    const SCOPES_GO_CODE: &str = include_str!("cases/go/scopes.go");
+    const IMPLEMENTATIONS_GO_CODE: &str = include_str!("cases/go/implementations.go");
+
+    fn build_graph(code: &str, path: &str) -> SymbolGraph {
+        let mut parser: Box<dyn AstLanguageParser> = Box::new(GoParser::new().expect("GoParser::new"));
+        let symbols = parser.parse(code, &PathBuf::from(path));
+        SymbolGraph::from_symbols(&symbols)
+    }
+
+    /// 变量的类型：(类型限定名, 是否推断)
+    fn type_of(graph: &SymbolGraph, qualified_name: &str) -> Vec<(String, bool)> {
+        let variables = graph.find_nodes_by_qualified_name(qualified_name);
+        assert_eq!(variables.len(), 1, "variable {}", qualified_name);
+        assert_eq!(variables[0].kind, SymbolKind::Variable);
+        graph.outgoing_edges(&variables[0].id, Some(SymbolEdgeKind::HasType)).iter()
+            .map(|edge| (
+                graph.get_node(&edge.target).unwrap().qualified_name.clone(),
+                edge.metadata.as_ref().map_or(false, |m| m["inferred"] == true),
+            ))
+            .collect()
+    }
+
+    /// 函数调用的目标（限定名，去重排序）
+    fn callees(graph: &SymbolGraph, qualified_name: &str) -> Vec<String> {
+        let caller = graph.find_nodes_by_qualified_name(qualified_name);
+        assert_eq!(caller.len(), 1, "caller {}", qualified_name);
+        let mut callees = graph.callees_of(&caller[0].id).iter()
+            .map(|node| node.qualified_name.clone())
+            .collect::<Vec<_>>();
+        callees.sort();
+        callees.dedup();
+        callees
+    }
+
+    /// 文件的导入：(路径, 绑定方式, 别名)，按源码顺序
+    fn imports_of(graph: &SymbolGraph, file: &str) -> Vec<(String, ImportKind, Option<String>)> {
+        let files = graph.find_nodes_by_qualified_name(file);
+        assert_eq!(files.len(), 1);
+        assert_eq!(files[0].kind, SymbolKind::File);
+        graph.outgoing_edges(&files[0].id, Some(SymbolEdgeKind::Imports)).iter()
+            .map(|edge| graph.get_node(&edge.target).unwrap())
+            .map(|node| {
+                assert_eq!(node.kind, SymbolKind::Import);
+                (
+                    node.import_path().unwrap().to_string(),
+                    node.import_kind().unwrap(),
+                    node.import_alias().map(|alias| alias.to_string()),
+                )
+            })
+            .collect()
+    }
+
+    /// 接口满足边：(类型, 接口, 接收者形式)，按插入顺序
+    fn satisfies_edges(graph: &SymbolGraph) -> Vec<(String, String, String)> {
+        graph.edges_of_kind(SymbolEdgeKind::Satisfies)
+            .map(|edge| (
+                graph.get_node(&edge.source).unwrap().name.clone(),
+                graph.get_node(&edge.target).unwrap().name.clone(),
+                edge.metadata.as_ref().unwrap()["receiver"].as_str().unwrap().to_string(),
+            ))
+            .collect()
+    }
+
+    /// 结构体的提升方法：(方法限定名, 嵌入路径)，按源码顺序
+    fn promoted_of(graph: &SymbolGraph, struct_name: &str) -> Vec<(String, String)> {
+        let outer = graph.find_nodes_by_qualified_name(struct_name);
+        assert_eq!(outer.len(), 1, "struct {}", struct_name);
+        graph.outgoing_edges(&outer[0].id, Some(SymbolEdgeKind::Promotes)).iter()
+            .map(|edge| (
+                graph.get_node(&edge.target).unwrap().qualified_name.clone(),
+                edge.metadata.as_ref().unwrap()["via"].as_str().unwrap().to_string(),
+            ))
+            .collect()
+    }
+
+    /// 声明的类型参数名称，按源码顺序
+    fn type_parameters_of(graph: &SymbolGraph, qualified_name: &str) -> Vec<String> {
+        let owner = graph.find_nodes_by_qualified_name(qualified_name);
+        assert_eq!(owner.len(), 1, "declaration {}", qualified_name);
+        graph.children_of(&owner[0].id).iter()
+            .filter(|n| n.kind == SymbolKind::TypeParameter)
+            .map(|n| n.name.clone())
+            .collect()
+    }
+
+    /// 类型参数的约束：(约束名称, 约束节点类型)
+    fn constraints_of(graph: &SymbolGraph, qualified_name: &str) -> Vec<(String, SymbolKind)> {
+        let param = graph.find_nodes_by_qualified_name(qualified_name);
+        assert_eq!(param.len(), 1, "type parameter {}", qualified_name);
+        assert_eq!(param[0].kind, SymbolKind::TypeParameter);
+        graph.outgoing_edges(&param[0].id, Some(SymbolEdgeKind::ConstrainedBy)).iter()
+            .map(|edge| graph.get_node(&edge.target).unwrap())
+            .map(|node| (node.qualified_name.clone(), node.kind))
+            .collect()
+    }
+
+    /// (方法限定名, 接收者类型名, 接收者形式)
+    fn method_of_edges(graph: &SymbolGraph) -> Vec<(String, String, ReceiverKind)> {
+        let mut edges = graph.edges_of_kind(SymbolEdgeKind::MethodOf)
+            .map(|edge| (
+                graph.get_node(&edge.source).unwrap().qualified_name.clone(),
+                graph.get_node(&edge.target).unwrap().name.clone(),
+                edge.receiver_kind().expect("receiver kind"),
+            ))
+            .collect::<Vec<_>>();
+        edges.sort();
+        edges
+    }
+
     #[test]
     fn parser_test() {
         let code = include_str!("./cases/go/main.go");
@@ -199,4 +318,412 @@ type Point struct {
         
         print_tree(root, 0);
     }
-} 
\ No newline at end of file
+
+    #[test]
+    fn method_of_value_receiver_test() {
+        let graph = build_graph(SHAPE_GO_CODE, "/shape.go");
+        assert_eq!(method_of_edges(&graph), vec![
+            ("(Rectangle).Area".to_string(), "Rectangle".to_string(), ReceiverKind::Value),
+            ("(Shape).Area".to_string(), "Shape".to_string(), ReceiverKind::Value),
+        ]);
+        for edge in graph.edges_of_kind(SymbolEdgeKind::MethodOf) {
+            assert_eq!(graph.get_node(&edge.source).unwrap().kind, SymbolKind::Method);
+            assert_eq!(graph.get_node(&edge.target).unwrap().kind, SymbolKind::Struct);
+        }
+    }
+
+    #[test]
+    fn method_of_pointer_receiver_test() {
+        let graph = build_graph(MAIN_GO_CODE, "/main.go");
+        assert_eq!(method_of_edges(&graph), vec![
+            ("(*Point).Move".to_string(), "Point".to_string(), ReceiverKind::Pointer),
+        ]);
+        let new_point = graph.find_nodes_by_name("NewPoint");
+        assert_eq!(new_point.len(), 1);
+        assert_eq!(new_point[0].kind, SymbolKind::Function);
+    }
+
+    #[test]
+    fn method_of_anonymous_and_embedded_receiver_test() {
+        let graph = build_graph(RECEIVERS_GO_CODE, "/receivers.go");
+        assert_eq!(method_of_edges(&graph), vec![
+            ("(*Base).Reset".to_string(), "Base".to_string(), ReceiverKind::Pointer),
+            ("(*Derived).Rename".to_string(), "Derived".to_string(), ReceiverKind::Pointer),
+            ("(Base).Describe".to_string(), "Base".to_string(), ReceiverKind::Value),
+            ("(Derived).Kind".to_string(), "Derived".to_string(), ReceiverKind::Value),
+        ]);
+
+        // 嵌入 Base 不会把 Base 的方法挂到 Derived 上
+        let base = graph.find_nodes_by_name("Base")[0].id;
+        let derived = graph.find_nodes_by_name("Derived")[0].id;
+        assert_eq!(graph.methods_of(&base).len(), 2);
+        assert_eq!(graph.methods_of(&derived).len(), 2);
+    }
+
+    #[test]
+    fn call_edges_test() {
+        let graph = build_graph(MAIN_GO_CODE, "/main.go");
+        assert_eq!(callees(&graph, "main"), vec!["(*Point).Move", "NewPoint", "fmt.Println"]);
+        assert!(callees(&graph, "NewPoint").is_empty());
+        assert!(callees(&graph, "(*Point).Move").is_empty());
+        assert_eq!(graph.call_edges().count(), 3);
+        for edge in graph.call_edges() {
+            assert_eq!(graph.get_node(&edge.source).unwrap().name, "main");
+        }
+
+        let println = graph.find_nodes_by_qualified_name("fmt.Println");
+        assert_eq!(println.len(), 1);
+        assert_eq!(println[0].kind, SymbolKind::Unresolved);
+        let move_ = graph.find_nodes_by_qualified_name("(*Point).Move");
+        assert_eq!(move_[0].kind, SymbolKind::Method);
+
+        let graph = build_graph(SHAPE_GO_CODE, "/shape.go");
+        assert_eq!(graph.call_edges().count(), 0);
+    }
+
+    #[test]
+    fn call_edges_receiver_inference_test() {
+        let graph = build_graph(CALLS_GO_CODE, "/calls.go");
+        assert_eq!(callees(&graph, "(*Counter).Add"), vec!["(*Counter).Inc"]);
+        assert_eq!(callees(&graph, "run"), vec![
+            "(*Counter).Add", "(*Counter).Inc", "NewCounter", "helper", "unknown.Do",
+        ]);
+        // new(Counter) 是内置函数，闭包中的调用归属到外层函数
+        assert_eq!(callees(&graph, "helper"), vec!["(*Counter).Inc"]);
+
+        let run = graph.find_nodes_by_qualified_name("run")[0].id;
+        assert_eq!(graph.outgoing_edges(&run, Some(SymbolEdgeKind::Calls)).len(), 7);
+        assert_eq!(graph.nodes_of_kind(SymbolKind::Unresolved).len(), 1);
+    }
+
+    #[test]
+    fn import_edges_test() {
+        let graph = build_graph(IMPORTS_GO_CODE, "/imports.go");
+        assert_eq!(imports_of(&graph, "/imports.go"), vec![
+            ("os".to_string(), ImportKind::Plain, None),
+            ("fmt".to_string(), ImportKind::Plain, None),
+            ("strings".to_string(), ImportKind::Alias, Some("str".to_string())),
+            ("math".to_string(), ImportKind::Dot, Some(".".to_string())),
+            ("net/http/pprof".to_string(), ImportKind::Blank, Some("_".to_string())),
+            ("encoding/json".to_string(), ImportKind::Plain, None),
+            ("github.com/acme/tools/v2".to_string(), ImportKind::Plain, None),
+        ]);
+
+        // 分组中的每条导入有自己的位置
+        let strings = graph.find_nodes_by_name("strings");
+        assert_eq!(strings.len(), 1);
+        let span = strings[0].span;
+        assert_eq!(&IMPORTS_GO_CODE[span.start_byte..span.end_byte], "str \"strings\"");
+        let os = graph.find_nodes_by_name("os");
+        assert_eq!(&IMPORTS_GO_CODE[os[0].span.start_byte..os[0].span.end_byte], "import \"os\"");
+
+        let graph = build_graph(MAIN_GO_CODE, "/main.go");
+        assert_eq!(imports_of(&graph, "/main.go"), vec![("fmt".to_string(), ImportKind::Plain, None)]);
+    }
+
+    #[test]
+    fn promoted_methods_test() {
+        let graph = build_graph(EMBEDDING_GO_CODE, "/embedding.go");
+        let field = graph.find_nodes_by_qualified_name("Pixel.Point");
+        assert_eq!(field[0].kind, SymbolKind::Field);
+        assert_eq!(field[0].attributes["embedded"], true);
+        assert_eq!(field[0].attributes["type"], "*Point");
+
+        assert_eq!(promoted_of(&graph, "ColoredPoint"), vec![
+            ("(*Point).Move".to_string(), "Point".to_string()),
+            ("(Point).String".to_string(), "Point".to_string()),
+        ]);
+        // Point 和 Color 都提供 String，存在歧义
+        assert_eq!(promoted_of(&graph, "Pixel"), vec![
+            ("(*Point).Move".to_string(), "Point".to_string()),
+            ("(*Color).Darken".to_string(), "Color".to_string()),
+        ]);
+        // 自身声明的 Move 遮蔽提升的方法
+        assert_eq!(promoted_of(&graph, "NamedPoint"), vec![
+            ("(Point).String".to_string(), "Point".to_string()),
+        ]);
+        assert_eq!(promoted_of(&graph, "Sprite"), vec![
+            ("(*Point).Move".to_string(), "Pixel.Point".to_string()),
+            ("(*Color).Darken".to_string(), "Pixel.Color".to_string()),
+        ]);
+        assert!(promoted_of(&graph, "Point").is_empty());
+
+        assert_eq!(callees(&graph, "paint"), vec![
+            "(*Color).Darken", "(*Point).Move", "(NamedPoint).Move", "px.String",
+        ]);
+
+        // 嵌入不改变方法的接收者
+        let colored_point = graph.find_nodes_by_name("ColoredPoint")[0].id;
+        assert!(graph.methods_of(&colored_point).is_empty());
+    }
+
+    #[test]
+    fn promoted_methods_across_files_test() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::write(dir.path().join("embedding.go"), EMBEDDING_GO_CODE).unwrap();
+        std::fs::write(dir.path().join("embedding_marker.go"), EMBEDDING_MARKER_GO_CODE).unwrap();
+        let other = dir.path().join("other");
+        std::fs::create_dir(&other).unwrap();
+        std::fs::write(other.join("marker.go"), "package other\n\ntype Other struct {\n\tColoredPoint\n}\n").unwrap();
+
+        let (graph, errors) = parse_dir(dir.path(), &ParseOptions::default()).unwrap();
+        assert!(errors.is_empty());
+        // Marker 自身的 Label 方法遮蔽 ColoredPoint 的 Label 字段
+        assert_eq!(promoted_of(&graph, "Marker"), vec![
+            ("(*Point).Move".to_string(), "ColoredPoint.Point".to_string()),
+            ("(Point).String".to_string(), "ColoredPoint.Point".to_string()),
+        ]);
+        // 其他包中同名的类型不参与提升
+        assert!(promoted_of(&graph, "Other").is_empty());
+        assert_eq!(graph.edges_of_kind(SymbolEdgeKind::Promotes).count(), 2 + 2 + 1 + 2 + 2);
+    }
+
+    #[test]
+    fn interface_declaration_test() {
+        let graph = build_graph(SHAPER_GO_CODE, "/shaper.go");
+        let shaper = graph.find_nodes_by_name("Shaper");
+        assert_eq!(shaper[0].kind, SymbolKind::Interface);
+        let area = graph.find_nodes_by_qualified_name("Shaper.Area");
+        assert_eq!(area[0].kind, SymbolKind::Method);
+        assert_eq!(area[0].attributes["signature"], "() int");
+        assert_eq!(graph.find_nodes_by_qualified_name("Namer.Name")[0].attributes["signature"], "() (string, error)");
+        assert_eq!(graph.find_nodes_by_qualified_name("(*Square).Scale")[0].attributes["signature"], "(int)");
+        assert_eq!(graph.methods_of(&shaper[0].id).len(), 1);
+
+        let scalable = graph.find_nodes_by_name("ScalableShaper");
+        assert_eq!(scalable[0].attributes["embeds"], serde_json::json!(["Shaper", "Scaler"]));
+        let extends = graph.outgoing_edges(&scalable[0].id, Some(SymbolEdgeKind::Extends)).iter()
+            .map(|edge| graph.get_node(&edge.target).unwrap().name.clone())
+            .collect::<Vec<_>>();
+        assert_eq!(extends, vec!["Shaper", "Scaler"]);
+    }
+
+    #[test]
+    fn interface_satisfaction_test() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::write(dir.path().join("shape.go"), SHAPE_GO_CODE).unwrap();
+        std::fs::write(dir.path().join("shaper.go"), SHAPER_GO_CODE).unwrap();
+
+        let (graph, _) = parse_dir(dir.path(), &ParseOptions::default()).unwrap();
+        assert_eq!(graph.edges_of_kind(SymbolEdgeKind::Satisfies).count(), 0);
+
+        let options = ParseOptions { compute_interface_satisfaction: true, ..Default::default() };
+        let (graph, errors) = parse_dir(dir.path(), &options).unwrap();
+        assert!(errors.is_empty());
+        let s = |t: &str, i: &str, r: &str| (t.to_string(), i.to_string(), r.to_string());
+        assert_eq!(satisfies_edges(&graph), vec![
+            // Shape 和 Rectangle 声明在 shape.go 中
+            s("Shape", "Shaper", "value"),
+            s("Rectangle", "Shaper", "value"),
+            s("Square", "Shaper", "value"),
+            // Scale 是指针接收者的方法，只有 *Square 满足
+            s("Square", "Scaler", "pointer"),
+            s("Square", "ScalableShaper", "pointer"),
+        ]);
+    }
+
+    #[test]
+    fn interface_satisfaction_through_embedding_test() {
+        let code = format!("{}\n{}", SHAPER_GO_CODE, "type Tile struct {\n\tSquare\n}\n\ntype Board struct {\n\t*Square\n}\n");
+        let mut graph = build_graph(&code, "/tiles.go");
+        link_interface_satisfaction(&mut graph);
+        let tiles = satisfies_edges(&graph).into_iter()
+            .filter(|(t, _, _)| t == "Tile" || t == "Board")
+            .collect::<Vec<_>>();
+        let s = |t: &str, i: &str, r: &str| (t.to_string(), i.to_string(), r.to_string());
+        // 嵌入 *Square 时指针接收者的方法也属于 Board 的方法集
+        assert_eq!(tiles, vec![
+            s("Tile", "Shaper", "value"),
+            s("Tile", "Scaler", "pointer"),
+            s("Tile", "ScalableShaper", "pointer"),
+            s("Board", "Shaper", "value"),
+            s("Board", "Scaler", "value"),
+            s("Board", "ScalableShaper", "value"),
+        ]);
+    }
+
+    #[test]
+    fn implementations_test() {
+        let graph = build_graph(IMPLEMENTATIONS_GO_CODE, "/storage/implementations.go");
+        let id = |name: &str| graph.find_nodes_by_qualified_name(name)[0].id;
+        let names = |nodes: Vec<&SymbolNode>| {
+            nodes.iter().map(|n| n.qualified_name.clone()).collect::<Vec<_>>()
+        };
+        // 按需计算，不需要 Satisfies 边
+        assert_eq!(graph.edges_of_kind(SymbolEdgeKind::Satisfies).count(), 0);
+        assert_eq!(names(graph.implementations(&id("Store"))), vec!["MemoryStore", "FileStore"]);
+        assert_eq!(names(graph.interfaces(&id("MemoryStore"))), vec!["Store"]);
+        // 只有 *FileStore 满足也计入
+        assert_eq!(names(graph.interfaces(&id("FileStore"))), vec!["Store"]);
+        assert!(graph.interfaces(&id("ReadOnly")).is_empty());
+
+        assert_eq!(names(graph.implementations_of_method(&id("Store.Get"))), vec!["(MemoryStore).Get", "(*FileStore).Get"]);
+        assert_eq!(names(graph.implementations_of_method(&id("Store.Put"))), vec!["(MemoryStore).Put", "(*FileStore).Put"]);
+        // 不是接口或接口方法时为空
+        assert!(graph.implementations(&id("MemoryStore")).is_empty());
+        assert!(graph.implementations_of_method(&id("(MemoryStore).Get")).is_empty());
+    }
+
+    #[test]
+    fn generic_type_parameters_test() {
+        let graph = build_graph(GENERICS_GO_CODE, "/generics.go");
+        assert_eq!(type_parameters_of(&graph, "Stack"), vec!["T"]);
+        assert_eq!(type_parameters_of(&graph, "Map"), vec!["T", "U"]);
+        assert_eq!(type_parameters_of(&graph, "Sum"), vec!["N"]);
+        assert!(type_parameters_of(&graph, "main").is_empty());
+
+        assert_eq!(constraints_of(&graph, "Stack.T"), vec![("any".to_string(), SymbolKind::Unresolved)]);
+        assert_eq!(constraints_of(&graph, "Map.T"), vec![("any".to_string(), SymbolKind::Unresolved)]);
+        assert_eq!(constraints_of(&graph, "Map.U"), vec![("comparable".to_string(), SymbolKind::Unresolved)]);
+        // 同一文件中声明的约束接口
+        assert_eq!(constraints_of(&graph, "Sum.N"), vec![("Number".to_string(), SymbolKind::Interface)]);
+        assert_eq!(graph.find_nodes_by_qualified_name("any").len(), 1);
+
+        // 泛型类型上的方法挂在类型本身上
+        assert_eq!(method_of_edges(&graph), vec![
+            ("(*Stack).Pop".to_string(), "Stack".to_string(), ReceiverKind::Pointer),
+            ("(*Stack).Push".to_string(), "Stack".to_string(), ReceiverKind::Pointer),
+        ]);
+    }
+
+    #[test]
+    fn generic_instantiation_call_test() {
+        let graph = build_graph(GENERICS_GO_CODE, "/generics.go");
+        let main_callees = callees(&graph, "main");
+        assert!(main_callees.contains(&"Map".to_string()), "{:?}", main_callees);
+        assert!(main_callees.contains(&"Sum".to_string()), "{:?}", main_callees);
+        for callee in ["Map", "Sum"] {
+            let node = graph.find_nodes_by_qualified_name(callee);
+            assert_eq!(node.len(), 1);
+            assert_eq!(node[0].kind, SymbolKind::Function);
+        }
+    }
+
+    #[test]
+    fn variable_declarations_test() {
+        let graph = build_graph(VARIABLES_GO_CODE, "/variables.go");
+        let mut variables = graph.nodes_of_kind(SymbolKind::Variable).iter()
+            .map(|n| n.qualified_name.clone())
+            .collect::<Vec<_>>();
+        variables.sort();
+        assert_eq!(variables, vec!["cursor", "label", "maxDepth", "origin", "shadow.c", "shadow.depth", "shadow.depth"]);
+
+        let max_depth = graph.find_nodes_by_qualified_name("maxDepth")[0];
+        assert_eq!(max_depth.attributes["constant"], true);
+        assert!(graph.find_nodes_by_qualified_name("origin")[0].attributes.get("constant").is_none());
+        // 局部变量挂在函数下
+        let shadow = graph.find_nodes_by_qualified_name("shadow")[0];
+        assert!(graph.children_of(&shadow.id).iter().any(|n| n.qualified_name == "shadow.c"));
+    }
+
+    #[test]
+    fn variable_types_test() {
+        let graph = build_graph(VARIABLES_GO_CODE, "/variables.go");
+        assert_eq!(type_of(&graph, "origin"), vec![("Cell".to_string(), false)]);
+        assert_eq!(type_of(&graph, "cursor"), vec![("Cell".to_string(), false)]);
+        // 内置类型和无法推断的初始值没有类型边
+        assert!(type_of(&graph, "label").is_empty());
+        assert!(type_of(&graph, "maxDepth").is_empty());
+        assert_eq!(type_of(&graph, "shadow.c"), vec![("Cell".to_string(), true)]);
+
+        // p := NewPoint(1, 2) 取 NewPoint 的返回类型
+        let graph = build_graph(MAIN_GO_CODE, "/main.go");
+        assert_eq!(type_of(&graph, "main.p"), vec![("Point".to_string(), true)]);
+
+        // 函数内声明的同名类型优先
+        let graph = build_graph(SCOPES_GO_CODE, "/scopes.go");
+        assert_eq!(type_of(&graph, "override.local"), vec![("override.Config".to_string(), false)]);
+        assert_eq!(type_of(&graph, "defaults.c"), vec![("Config".to_string(), true)]);
+    }
+
+    #[test]
+    fn shadowed_variables_test() {
+        let graph = build_graph(VARIABLES_GO_CODE, "/variables.go");
+        let depths = graph.find_nodes_by_qualified_name("shadow.depth");
+        assert_eq!(depths.len(), 2);
+        assert_ne!(depths[0].id, depths[1].id);
+        let lines = depths.iter().map(|n| n.declaration_span.start_line).collect::<Vec<_>>();
+        assert_eq!(lines, vec![21, 23]);
+        assert!(depths[0].attributes.get("index").is_none());
+        assert_eq!(depths[1].attributes["index"], 1);
+    }
+
+    /// 语法错误的输入：解析只能返回符号图或错误，不能 panic。
+    /// 重放模糊测试的种子语料（fuzz/corpus/parse_go）以及每个测试用例被截断后的前缀
+    #[test]
+    fn malformed_input_test() {
+        let corpus = PathBuf::from(env!("CARGO_MANIFEST_DIR")).join("fuzz/corpus/parse_go");
+        let mut inputs = std::fs::read_dir(&corpus).unwrap()
+            .map(|entry| String::from_utf8_lossy(&std::fs::read(entry.unwrap().path()).unwrap()).to_string())
+            .collect::<Vec<_>>();
+        assert!(inputs.len() >= 5, "seed corpus in {}", corpus.display());
+        for code in [MAIN_GO_CODE, SHAPE_GO_CODE, CALLS_GO_CODE, GENERICS_GO_CODE, VARIABLES_GO_CODE, SCOPES_GO_CODE] {
+            let boundaries = code.char_indices().map(|(i, _)| i).step_by(7).collect::<Vec<_>>();
+            inputs.extend(boundaries.into_iter().map(|end| code[..end].to_string()));
+        }
+        let path = PathBuf::from("/fuzz.go");
+        for code in &inputs {
+            if let Ok(graph) = parse_code(code, &path) {
+                graph.to_json().unwrap();
+            }
+        }
+
+        // ERROR 节点中的声明仍然提取
+        let graph = parse_code("package main\n\nfunc ok() {}\n\nfunc broken( {\n", &path).unwrap();
+        assert_eq!(graph.find_nodes_by_name("ok").len(), 1);
+    }
+
+    #[test]
+    fn package_hierarchy_test() {
+        let dir = tempfile::tempdir().unwrap();
+        std::fs::write(dir.path().join("go.mod"), "module example.com/shapes\n\ngo 1.22\n").unwrap();
+        std::fs::write(dir.path().join("main.go"), MAIN_GO_CODE).unwrap();
+        std::fs::write(dir.path().join("shape.go"), SHAPE_GO_CODE).unwrap();
+        std::fs::create_dir(dir.path().join("geo")).unwrap();
+        std::fs::write(dir.path().join("geo/geo.go"), "package geo\n\nfunc Origin() {}\n").unwrap();
+
+        let (graph, errors) = parse_dir(dir.path(), &ParseOptions::default()).unwrap();
+        assert!(errors.is_empty(), "{:?}", errors);
+        let main = graph.nodes_of_kind(SymbolKind::Package).into_iter().filter(|n| n.name == "main").collect::<Vec<_>>();
+        // 两个文件的包节点合并为一个
+        assert_eq!(main.len(), 1);
+        assert_eq!(main[0].qualified_name, "example.com/shapes");
+        assert_eq!(main[0].attributes["import_path"], "example.com/shapes");
+        let files = graph.children_of(&main[0].id).iter().map(|n| (n.kind, n.name.clone())).collect::<Vec<_>>();
+        assert_eq!(files, vec![(SymbolKind::File, "main.go".to_string()), (SymbolKind::File, "shape.go".to_string())]);
+
+        // 包 -> 文件 -> 顶层声明
+        for (name, file) in [("NewPoint", "main.go"), ("(*Point).Move", "main.go"), ("Rectangle", "shape.go")] {
+            let node = graph.find_nodes_by_qualified_name(name)[0];
+            let parent = graph.parent_of(&node.id).unwrap();
+            assert_eq!((parent.kind, parent.name.as_str()), (SymbolKind::File, file), "{}", name);
+            assert_eq!(graph.parent_of(&parent.id).unwrap().id, main[0].id);
+        }
+        // 字段仍然属于结构体
+        let width = graph.find_nodes_by_qualified_name("Rectangle.width")[0];
+        assert_eq!(graph.parent_of(&width.id).unwrap().name, "Rectangle");
+
+        let geo = graph.nodes_of_kind(SymbolKind::Package).into_iter().find(|n| n.name == "geo").unwrap();
+        assert_eq!(geo.attributes["import_path"], "example.com/shapes/geo");
+
+        // 没有模块信息时限定名为目录
+        let graph = parse_code(MAIN_GO_CODE, &PathBuf::from("/src/app/main.go")).unwrap();
+        let packages = graph.nodes_of_kind(SymbolKind::Package);
+        assert_eq!(packages.len(), 1);
+        assert_eq!((packages[0].name.as_str(), packages[0].qualified_name.as_str()), ("main", "/src/app"));
+        assert!(packages[0].attributes.get("import_path").is_none());
+
+        // parse_code 不读取磁盘上的 go.mod
+        let graph = parse_code(MAIN_GO_CODE, &dir.path().join("main.go")).unwrap();
+        let packages = graph.nodes_of_kind(SymbolKind::Package);
+        assert_eq!(PathBuf::from(&packages[0].qualified_name), dir.path());
+        assert!(packages[0].attributes.get("import_path").is_none());
+
+        // 覆盖层中的 go.mod 优先于磁盘
+        let overlay = HashMap::from([(dir.path().join("go.mod"), "module example.com/edited\n".to_string())]);
+        let (graph, _) = parse_dir(dir.path(), &ParseOptions { overlay, ..Default::default() }).unwrap();
+        let mut import_paths = graph.nodes_of_kind(SymbolKind::Package).iter().map(|n| n.qualified_name.clone()).collect::<Vec<_>>();
+        import_paths.sort();
+        assert_eq!(import_paths, vec!["example.com/edited", "example.com/edited/geo"]);
+    }
+}
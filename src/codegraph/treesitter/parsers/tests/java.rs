@@ -3,6 +3,9 @@ mod tests {
     use std::fs::canonicalize;
     use std::path::PathBuf;
 
+    use serde_json::json;
+
+    use crate::codegraph::symbol_graph::{parse_code, SymbolEdgeKind, SymbolGraph, SymbolKind};
     use crate::codegraph::treesitter::language_id::LanguageId;
     use crate::codegraph::treesitter::parsers::AstLanguageParser;
     use crate::codegraph::treesitter::parsers::java::JavaParser;
@@ -15,6 +18,20 @@ mod tests {
     const PERSON_JAVA_SKELETON: &str = include_str!("cases/java/person.java.skeleton");
     const PERSON_JAVA_DECLS: &str = include_str!("cases/java/person.java.decl_json");
 
+    const SHAPES_JAVA_CODE: &str = include_str!("cases/java/shapes.java");
+
+    /// Sorted (source qualified name, target qualified name) pairs of one edge kind
+    fn edges_of(graph: &SymbolGraph, kind: SymbolEdgeKind) -> Vec<(String, String)> {
+        let mut edges = graph.edges_of_kind(kind)
+            .map(|edge| (
+                graph.get_node(&edge.source).unwrap().qualified_name.clone(),
+                graph.get_node(&edge.target).unwrap().qualified_name.clone(),
+            ))
+            .collect::<Vec<_>>();
+        edges.sort();
+        edges
+    }
+
     #[test]
     fn parser_test() {
         let mut parser: Box<dyn AstLanguageParser> = Box::new(JavaParser::new().expect("JavaParser::new"));
@@ -38,4 +55,68 @@ mod tests {
         assert!(file.exists());
         base_declaration_formatter_test(&LanguageId::Java, &mut parser, &file, PERSON_JAVA_CODE, PERSON_JAVA_DECLS);
     }
+
+    #[test]
+    fn declaration_kinds_test() {
+        let graph = parse_code(SHAPES_JAVA_CODE, &PathBuf::from("/shapes/shapes.java")).unwrap();
+        let kind_of = |name: &str| graph.find_nodes_by_qualified_name(name)[0].kind;
+        assert_eq!(kind_of("Shape"), SymbolKind::Interface);
+        assert_eq!(kind_of("Drawable"), SymbolKind::Interface);
+        assert_eq!(kind_of("Circle"), SymbolKind::Struct);
+        assert_eq!(kind_of("Palette"), SymbolKind::Enum);
+        assert_eq!(kind_of("Shape.area"), SymbolKind::Method);
+        assert_eq!(kind_of("Circle.Circle"), SymbolKind::Method);
+        assert_eq!(kind_of("Palette.label"), SymbolKind::Method);
+        assert_eq!(kind_of("Circle.radius"), SymbolKind::Field);
+
+        // Methods hang off their class
+        let circle = graph.find_nodes_by_qualified_name("Circle")[0];
+        let mut methods = graph.children_of(&circle.id).iter()
+            .filter(|n| n.kind == SymbolKind::Method)
+            .map(|n| n.name.clone())
+            .collect::<Vec<_>>();
+        methods.sort();
+        assert_eq!(methods, vec!["Circle", "area", "draw", "name", "scale", "scale"]);
+    }
+
+    #[test]
+    fn inheritance_test() {
+        let graph = parse_code(SHAPES_JAVA_CODE, &PathBuf::from("/shapes/shapes.java")).unwrap();
+        let pairs = |pairs: &[(&str, &str)]| pairs.iter().map(|(a, b)| (a.to_string(), b.to_string())).collect::<Vec<_>>();
+        assert_eq!(edges_of(&graph, SymbolEdgeKind::Extends), pairs(&[("Drawable", "Named"), ("Drawable", "Shape"), ("UnitCircle", "Circle")]));
+        assert_eq!(edges_of(&graph, SymbolEdgeKind::Implements), pairs(&[("Circle", "Drawable"), ("Palette", "Named")]));
+    }
+
+    #[test]
+    fn overloads_and_annotations_test() {
+        let graph = parse_code(SHAPES_JAVA_CODE, &PathBuf::from("/shapes/shapes.java")).unwrap();
+        let scales = graph.find_nodes_by_qualified_name("Circle.scale");
+        assert_eq!(scales.len(), 2);
+        assert_ne!(scales[0].id, scales[1].id);
+        assert_eq!(scales[0].attributes["signature"], json!("(double) double"));
+        assert_eq!(scales[1].attributes["signature"], json!("(int, boolean) double"));
+        assert_eq!(graph.find_nodes_by_qualified_name("Drawable.draw")[0].attributes["signature"], json!("(List) void"));
+
+        let circle = graph.find_nodes_by_qualified_name("Circle")[0];
+        assert_eq!(circle.attributes["annotations"], json!(["Deprecated", "SuppressWarnings(\"unused\")"]));
+        assert_eq!(graph.find_nodes_by_qualified_name("Circle.area")[0].attributes["annotations"], json!(["Override"]));
+        assert_eq!(scales[0].attributes.get("annotations"), None);
+    }
+
+    #[test]
+    fn package_test() {
+        let graph = parse_code(SHAPES_JAVA_CODE, &PathBuf::from("/shapes/shapes.java")).unwrap();
+        let packages = graph.nodes_of_kind(SymbolKind::Package);
+        assert_eq!(packages.len(), 1);
+        assert_eq!(packages[0].name, "com.example.shapes");
+        assert_eq!(packages[0].file_path, PathBuf::from("/shapes"));
+        let mut members = graph.children_of(&packages[0].id).iter().map(|n| n.name.clone()).collect::<Vec<_>>();
+        members.sort();
+        assert_eq!(members, vec!["Circle", "Drawable", "Named", "Palette", "Shape", "UnitCircle"]);
+        assert_eq!(graph.parent_of(&graph.find_nodes_by_qualified_name("Circle")[0].id).unwrap().id, packages[0].id);
+
+        // Files without a package declaration have no package node
+        let graph = parse_code(MAIN_JAVA_CODE, &PathBuf::from("/main.java")).unwrap();
+        assert!(graph.nodes_of_kind(SymbolKind::Package).is_empty());
+    }
 }
@@ -0,0 +1,103 @@
+#[cfg(test)]
+mod tests {
+    use std::path::PathBuf;
+
+    use serde_json::json;
+
+    use crate::codegraph::symbol_graph::{parse_code, SymbolEdgeKind, SymbolGraph, SymbolKind};
+    use crate::codegraph::treesitter::parsers::kotlin::data_class_members;
+
+    const SHAPES_KOTLIN_CODE: &str = include_str!("cases/kotlin/shapes.kt");
+
+    fn shapes_graph() -> SymbolGraph {
+        parse_code(SHAPES_KOTLIN_CODE, &PathBuf::from("/shapes.kt")).unwrap()
+    }
+
+    /// Sorted (source qualified name, target qualified name) pairs of one edge kind
+    fn edges_of(graph: &SymbolGraph, kind: SymbolEdgeKind) -> Vec<(String, String)> {
+        let mut edges = graph.edges_of_kind(kind)
+            .map(|edge| (
+                graph.get_node(&edge.source).unwrap().qualified_name.clone(),
+                graph.get_node(&edge.target).unwrap().qualified_name.clone(),
+            ))
+            .collect::<Vec<_>>();
+        edges.sort();
+        edges
+    }
+
+    #[test]
+    fn declaration_kinds_test() {
+        let graph = shapes_graph();
+        let kind_of = |name: &str| {
+            let nodes = graph.find_nodes_by_qualified_name(name);
+            assert_eq!(nodes.len(), 1, "{}", name);
+            nodes[0].kind
+        };
+        assert_eq!(kind_of("Shape"), SymbolKind::Interface);
+        assert_eq!(kind_of("Shape.area"), SymbolKind::Method);
+        assert_eq!(kind_of("Point"), SymbolKind::Struct);
+        assert_eq!(kind_of("Square"), SymbolKind::Struct);
+        assert_eq!(kind_of("Origin"), SymbolKind::Object);
+        assert_eq!(kind_of("Origin.area"), SymbolKind::Method);
+        assert_eq!(kind_of("Square.Companion"), SymbolKind::Object);
+        assert_eq!(kind_of("Square.Companion.unit"), SymbolKind::Method);
+        assert_eq!(kind_of("describe"), SymbolKind::Function);
+        // val/var parameters of the primary constructor and properties in the body are both fields
+        for name in ["Point.x", "Point.y", "Square.side", "Square.label", "Square.scale", "Origin.point"] {
+            assert_eq!(kind_of(name), SymbolKind::Field, "{}", name);
+        }
+
+        assert_eq!(edges_of(&graph, SymbolEdgeKind::Extends), vec![("Square".to_string(), "Polygon".to_string())]);
+        assert_eq!(edges_of(&graph, SymbolEdgeKind::Implements), vec![
+            ("Origin".to_string(), "Shape".to_string()),
+            ("Polygon".to_string(), "Shape".to_string()),
+        ]);
+    }
+
+    #[test]
+    fn data_class_test() {
+        let graph = shapes_graph();
+        let point = graph.find_nodes_by_qualified_name("Point")[0];
+        assert_eq!(point.attributes["generated_members"], json!(["component1", "component2", "copy", "equals", "hashCode", "toString"]));
+        assert_eq!(point.doc.as_deref(), Some("A point on the plane."));
+        // Plain classes have no generated members
+        let square = graph.find_nodes_by_qualified_name("Square")[0];
+        assert!(!square.attributes.contains_key("generated_members"));
+        assert_eq!(data_class_members(0), vec!["copy", "equals", "hashCode", "toString"]);
+    }
+
+    #[test]
+    fn extension_functions_test() {
+        let graph = shapes_graph();
+        let distance = graph.find_nodes_by_qualified_name("Point.distanceTo")[0];
+        assert_eq!(distance.kind, SymbolKind::Method);
+        assert_eq!(distance.attributes["extension"], json!(true));
+        // A receiver declared in the file links to that type
+        let point = graph.find_nodes_by_qualified_name("Point")[0];
+        let owners = graph.outgoing_edges(&distance.id, Some(SymbolEdgeKind::MethodOf));
+        assert_eq!(owners.iter().map(|e| e.target).collect::<Vec<_>>(), vec![point.id]);
+
+        // Other receivers become references to placeholders; nullable receivers link like the base type
+        let or_blank = graph.find_nodes_by_qualified_name("String.orBlank")[0];
+        assert!(graph.outgoing_edges(&or_blank.id, Some(SymbolEdgeKind::MethodOf)).is_empty());
+        let references = graph.outgoing_edges(&or_blank.id, Some(SymbolEdgeKind::References));
+        assert_eq!(references.len(), 1);
+        let string = graph.get_node(&references[0].target).unwrap();
+        assert_eq!((string.kind, string.name.as_str()), (SymbolKind::Unresolved, "String"));
+    }
+
+    #[test]
+    fn signatures_test() {
+        let graph = shapes_graph();
+        let signature = |name: &str| {
+            let node = graph.find_nodes_by_qualified_name(name)[0];
+            node.attributes["signature"].as_str().unwrap().to_string()
+        };
+        // Default arguments don't change the signature; nullable types keep the `?`
+        assert_eq!(signature("Point.distanceTo"), "(Point, Double) Double");
+        assert_eq!(signature("String.orBlank"), "(String) String");
+        assert_eq!(signature("describe"), "(Shape?, String?) String");
+        assert_eq!(signature("Square.Companion.unit"), "() Square");
+        assert_eq!(signature("Shape.area"), "() Double");
+    }
+}
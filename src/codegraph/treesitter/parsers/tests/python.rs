@@ -3,16 +3,19 @@ mod tests {
     use std::fs::canonicalize;
     use std::path::PathBuf;
 
+    use crate::codegraph::treesitter::ast_instance_structs::{FunctionDeclaration, StructDeclaration};
     use crate::codegraph::treesitter::language_id::LanguageId;
     use crate::codegraph::treesitter::parsers::AstLanguageParser;
     use crate::codegraph::treesitter::parsers::python::PythonParser;
     use crate::codegraph::treesitter::parsers::tests::{base_declaration_formatter_test, base_parser_test, base_skeletonizer_test};
+    use crate::codegraph::treesitter::structs::SymbolType;
 
     const MAIN_PY_CODE: &str = include_str!("cases/python/main.py");
     const CALCULATOR_PY_CODE: &str = include_str!("cases/python/calculator.py");
     const CALCULATOR_PY_SKELETON: &str = include_str!("cases/python/calculator.py.skeleton");
     const CALCULATOR_PY_DECLS: &str = include_str!("cases/python/calculator.py.decl_json");
     const MAIN_PY_SYMBOLS: &str = include_str!("cases/python/main.py.json");
+    const DECORATORS_PY_CODE: &str = include_str!("cases/python/decorators.py");
 
     #[test]
     fn parser_test() {
@@ -37,4 +40,79 @@ mod tests {
         assert!(file.exists());
         base_declaration_formatter_test(&LanguageId::Python, &mut parser, &file, CALCULATOR_PY_CODE, CALCULATOR_PY_DECLS);
     }
+
+    #[test]
+    fn decorators_test() {
+        let mut parser: Box<dyn AstLanguageParser> = Box::new(PythonParser::new().expect("PythonParser::new"));
+        let path = PathBuf::from("/decorators.py");
+        let symbols = parser.parse(DECORATORS_PY_CODE, &path);
+
+        let shape = symbols.iter()
+            .find(|s| s.read().symbol_type() == SymbolType::StructDeclaration && s.read().name() == "Shape")
+            .expect("Shape class");
+        let shape_guid = shape.read().guid().clone();
+        {
+            let shape = shape.read();
+            let decl = shape.as_any().downcast_ref::<StructDeclaration>().unwrap();
+            assert_eq!(decl.decorators, vec!["dataclass".to_string()]);
+        }
+
+        let decorators_of = |name: &str| -> Vec<String> {
+            let sym = symbols.iter()
+                .find(|s| s.read().symbol_type() == SymbolType::FunctionDeclaration && s.read().name() == name)
+                .unwrap_or_else(|| panic!("function {}", name));
+            let sym = sym.read();
+            sym.as_any().downcast_ref::<FunctionDeclaration>().unwrap().decorators.clone()
+        };
+        assert_eq!(decorators_of("label"), vec!["property".to_string()]);
+        assert_eq!(decorators_of("create"), vec!["staticmethod".to_string(), "logged".to_string()]);
+        assert_eq!(decorators_of("wrapper"), vec!["functools.wraps(func)".to_string()]);
+        assert_eq!(decorators_of("area"), vec!["logged".to_string()]);
+        assert!(decorators_of("logged").is_empty());
+
+        let create = symbols.iter().find(|s| s.read().name() == "create").unwrap();
+        assert_eq!(create.read().parent_guid().clone(), Some(shape_guid));
+    }
+
+    #[test]
+    fn nested_functions_test() {
+        let mut parser: Box<dyn AstLanguageParser> = Box::new(PythonParser::new().expect("PythonParser::new"));
+        let path = PathBuf::from("/decorators.py");
+        let symbols = parser.parse(DECORATORS_PY_CODE, &path);
+
+        let find_function = |name: &str| symbols.iter()
+            .find(|s| s.read().symbol_type() == SymbolType::FunctionDeclaration && s.read().name() == name)
+            .unwrap_or_else(|| panic!("function {}", name))
+            .clone();
+        for (outer, inner) in [("logged", "wrapper"), ("area", "square")] {
+            let outer = find_function(outer);
+            let inner = find_function(inner);
+            let outer_guid = outer.read().guid().clone();
+            let inner_guid = inner.read().guid().clone();
+            assert_eq!(inner.read().parent_guid().clone(), Some(outer_guid));
+            assert!(outer.read().childs_guid().contains(&inner_guid));
+        }
+    }
+
+    #[test]
+    fn main_guard_test() {
+        let mut parser: Box<dyn AstLanguageParser> = Box::new(PythonParser::new().expect("PythonParser::new"));
+        let path = PathBuf::from("/decorators.py");
+        let symbols = parser.parse(DECORATORS_PY_CODE, &path);
+
+        let variables = symbols.iter()
+            .filter(|s| s.read().symbol_type() == SymbolType::VariableDefinition)
+            .map(|s| s.read().name().to_string())
+            .collect::<Vec<_>>();
+        assert!(!variables.contains(&"shape".to_string()));
+        assert!(!variables.contains(&"result".to_string()));
+
+        // Calls are still recorded as usages
+        let calls = symbols.iter()
+            .filter(|s| s.read().symbol_type() == SymbolType::FunctionCall)
+            .map(|s| s.read().name().to_string())
+            .collect::<Vec<_>>();
+        assert!(calls.contains(&"create".to_string()));
+        assert!(calls.contains(&"area".to_string()));
+    }
 }
@@ -3,6 +3,7 @@ mod tests {
     use std::fs::canonicalize;
     use std::path::PathBuf;
 
+    use crate::codegraph::symbol_graph::{SymbolEdgeKind, SymbolGraph, SymbolKind};
     use crate::codegraph::treesitter::language_id::LanguageId;
     use crate::codegraph::treesitter::parsers::AstLanguageParser;
     use crate::codegraph::treesitter::parsers::rust::RustParser;
@@ -15,6 +16,26 @@ mod tests {
     const POINT_RS_DECLS: &str = include_str!("cases/rust/point.rs.decl_json");
     const POINT_RS_SKELETON: &str = include_str!("cases/rust/point.rs.skeleton");
 
+    const SHAPES_RS_CODE: &str = include_str!("cases/rust/shapes.rs");
+
+    fn build_graph(code: &str, path: &str) -> SymbolGraph {
+        let mut parser: Box<dyn AstLanguageParser> = Box::new(RustParser::new().expect("RustParser::new"));
+        let symbols = parser.parse(code, &PathBuf::from(path));
+        SymbolGraph::from_symbols(&symbols)
+    }
+
+    /// Sorted (source name, target name) pairs of one edge kind
+    fn edges_of(graph: &SymbolGraph, kind: SymbolEdgeKind) -> Vec<(String, String)> {
+        let mut edges = graph.edges_of_kind(kind)
+            .map(|edge| (
+                graph.get_node(&edge.source).unwrap().name.clone(),
+                graph.get_node(&edge.target).unwrap().name.clone(),
+            ))
+            .collect::<Vec<_>>();
+        edges.sort();
+        edges
+    }
+
     #[test]
     fn parser_test() {
         let mut parser: Box<dyn AstLanguageParser> = Box::new(RustParser::new().expect("RustParser::new"));
@@ -38,4 +59,71 @@ mod tests {
         assert!(file.exists());
         base_declaration_formatter_test(&LanguageId::Rust, &mut parser, &file, POINT_RS_CODE, POINT_RS_DECLS);
     }
+
+    #[test]
+    fn declaration_kinds_test() {
+        let graph = build_graph(SHAPES_RS_CODE, "shapes.rs");
+        let kind_of = |name: &str| graph.find_nodes_by_qualified_name(name)[0].kind;
+        assert_eq!(kind_of("Circle"), SymbolKind::Struct);
+        assert_eq!(kind_of("Kind"), SymbolKind::Enum);
+        assert_eq!(kind_of("Shape"), SymbolKind::Interface);
+        assert_eq!(kind_of("kinds"), SymbolKind::Function);
+        assert_eq!(kind_of("Shape.area"), SymbolKind::Method);
+
+        let mut impls = graph.nodes()
+            .filter(|n| n.kind == SymbolKind::Impl)
+            .map(|n| n.qualified_name.clone())
+            .collect::<Vec<_>>();
+        impls.sort();
+        assert_eq!(impls, vec!["impl Circle", "impl Display for Circle", "impl Named for Circle", "impl Shape for Circle"]);
+        // The type name refers only to the struct itself
+        assert_eq!(graph.find_nodes_by_qualified_name("Circle").len(), 1);
+    }
+
+    #[test]
+    fn impl_methods_test() {
+        let graph = build_graph(SHAPES_RS_CODE, "shapes.rs");
+        let circle = graph.find_nodes_by_qualified_name("Circle")[0].id;
+        let mut methods = graph.methods_of(&circle).iter()
+            .map(|n| n.qualified_name.clone())
+            .collect::<Vec<_>>();
+        methods.sort();
+        assert_eq!(methods, vec!["<Circle as Display>.fmt", "<Circle as Named>.name", "<Circle as Shape>.area", "Circle.new"]);
+
+        // Methods are still contained in the impl block
+        let new = graph.find_nodes_by_qualified_name("Circle.new")[0];
+        let parent = graph.parent_of(&new.id).unwrap();
+        assert_eq!(parent.kind, SymbolKind::Impl);
+        assert_eq!(parent.qualified_name, "impl Circle");
+
+        let shape = graph.find_nodes_by_qualified_name("Shape")[0].id;
+        let mut trait_methods = graph.methods_of(&shape).iter()
+            .map(|n| n.name.clone())
+            .collect::<Vec<_>>();
+        trait_methods.sort();
+        assert_eq!(trait_methods, vec!["area", "describe"]);
+    }
+
+    #[test]
+    fn trait_impl_edges_test() {
+        let graph = build_graph(SHAPES_RS_CODE, "shapes.rs");
+        // The standard library's Display is not in the graph, so there is no edge
+        assert_eq!(edges_of(&graph, SymbolEdgeKind::Implements), vec![
+            ("Circle".to_string(), "Named".to_string()),
+            ("Circle".to_string(), "Shape".to_string()),
+        ]);
+        assert_eq!(edges_of(&graph, SymbolEdgeKind::Extends), vec![
+            ("Shape".to_string(), "Named".to_string()),
+        ]);
+    }
+
+    #[test]
+    fn macros_test() {
+        let graph = build_graph(SHAPES_RS_CODE, "shapes.rs");
+        // macro_rules! definitions and macro calls declare nothing; later declarations parse as usual
+        assert!(graph.find_nodes_by_name("square").is_empty());
+        assert!(graph.find_nodes_by_name("UNIT").is_empty());
+        assert_eq!(graph.find_nodes_by_qualified_name("<Circle as Shape>.area").len(), 1);
+        assert_eq!(graph.find_nodes_by_qualified_name("kinds").len(), 1);
+    }
 }
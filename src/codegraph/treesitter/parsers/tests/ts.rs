@@ -3,6 +3,7 @@ mod tests {
     use std::fs::canonicalize;
     use std::path::PathBuf;
 
+    use crate::codegraph::symbol_graph::{SymbolEdgeKind, SymbolGraph, SymbolKind};
     use crate::codegraph::treesitter::language_id::LanguageId;
     use crate::codegraph::treesitter::parsers::AstLanguageParser;
     use crate::codegraph::treesitter::parsers::tests::{base_declaration_formatter_test, base_parser_test, base_skeletonizer_test};
@@ -15,6 +16,32 @@ mod tests {
     const PERSON_TS_SKELETON: &str = include_str!("cases/ts/person.ts.skeleton");
     const PERSON_TS_DECLS: &str = include_str!("cases/ts/person.ts.decl_json");
 
+    const SHAPES_TS_CODE: &str = include_str!("cases/ts/shapes.ts");
+    const WIDGET_TSX_CODE: &str = include_str!("cases/ts/widget.tsx");
+
+    fn build_graph(mut parser: TSParser, code: &str, path: &str) -> SymbolGraph {
+        let symbols = parser.parse(code, &PathBuf::from(path));
+        SymbolGraph::from_symbols(&symbols)
+    }
+
+    fn node_kind(graph: &SymbolGraph, qualified_name: &str) -> SymbolKind {
+        let nodes = graph.find_nodes_by_qualified_name(qualified_name);
+        assert_eq!(nodes.len(), 1, "node {}", qualified_name);
+        nodes[0].kind
+    }
+
+    /// Sorted (source symbol name, target symbol name) pairs
+    fn edges_of_kind(graph: &SymbolGraph, kind: SymbolEdgeKind) -> Vec<(String, String)> {
+        let mut edges = graph.edges_of_kind(kind)
+            .map(|edge| (
+                graph.get_node(&edge.source).unwrap().name.clone(),
+                graph.get_node(&edge.target).unwrap().name.clone(),
+            ))
+            .collect::<Vec<_>>();
+        edges.sort();
+        edges
+    }
+
     #[test]
     fn parser_test() {
         let mut parser: Box<dyn AstLanguageParser> = Box::new(TSParser::new().expect("TSParser::new"));
@@ -38,4 +65,58 @@ mod tests {
         assert!(file.exists());
         base_declaration_formatter_test(&LanguageId::TypeScript, &mut parser, &file, PERSON_TS_CODE, PERSON_TS_DECLS);
     }
+
+    #[test]
+    fn declaration_kinds_test() {
+        let graph = build_graph(TSParser::new().expect("TSParser::new"), SHAPES_TS_CODE, "/shapes.ts");
+        assert_eq!(node_kind(&graph, "Shape"), SymbolKind::Interface);
+        assert_eq!(node_kind(&graph, "NamedShape"), SymbolKind::Interface);
+        assert_eq!(node_kind(&graph, "Point"), SymbolKind::TypeAlias);
+        assert_eq!(node_kind(&graph, "Id"), SymbolKind::TypeAlias);
+        assert_eq!(node_kind(&graph, "Color"), SymbolKind::Enum);
+        assert_eq!(node_kind(&graph, "Base"), SymbolKind::Struct);
+        assert_eq!(node_kind(&graph, "Circle"), SymbolKind::Struct);
+        assert_eq!(node_kind(&graph, "Circle.area"), SymbolKind::Method);
+        assert_eq!(node_kind(&graph, "makeCircle"), SymbolKind::Function);
+        assert_eq!(node_kind(&graph, "totalArea"), SymbolKind::Function);
+        assert_eq!(node_kind(&graph, "Point.x"), SymbolKind::Field);
+        assert_eq!(node_kind(&graph, "Color.Green"), SymbolKind::Field);
+        for node in graph.nodes() {
+            assert_eq!(node.language, LanguageId::TypeScript);
+        }
+    }
+
+    #[test]
+    fn implements_and_extends_edges_test() {
+        let graph = build_graph(TSParser::new().expect("TSParser::new"), SHAPES_TS_CODE, "/shapes.ts");
+        assert_eq!(edges_of_kind(&graph, SymbolEdgeKind::Implements), vec![
+            ("Circle".to_string(), "NamedShape".to_string()),
+        ]);
+        assert_eq!(edges_of_kind(&graph, SymbolEdgeKind::Extends), vec![
+            ("Circle".to_string(), "Base".to_string()),
+            ("NamedShape".to_string(), "Named".to_string()),
+            ("NamedShape".to_string(), "Shape".to_string()),
+        ]);
+    }
+
+    #[test]
+    fn tsx_test() {
+        let graph = build_graph(TSParser::new_tsx().expect("TSParser::new_tsx"), WIDGET_TSX_CODE, "/widget.tsx");
+        assert_eq!(node_kind(&graph, "WidgetProps"), SymbolKind::Interface);
+        assert_eq!(node_kind(&graph, "Badge"), SymbolKind::Function);
+        assert_eq!(node_kind(&graph, "Widget"), SymbolKind::Function);
+        assert_eq!(node_kind(&graph, "Widget.onClick"), SymbolKind::Function);
+        assert_eq!(node_kind(&graph, "Panel.render"), SymbolKind::Method);
+        assert_eq!(edges_of_kind(&graph, SymbolEdgeKind::Implements), vec![
+            ("Panel".to_string(), "WidgetProps".to_string()),
+        ]);
+        // JSX tags produce no symbol nodes
+        for tag in ["div", "span", "h1", "section"] {
+            assert!(graph.find_nodes_by_name(tag).is_empty(), "{}", tag);
+        }
+        for node in graph.nodes() {
+            assert!(!node.name.is_empty());
+            assert_eq!(node.language, LanguageId::TypeScriptReact);
+        }
+    }
 }
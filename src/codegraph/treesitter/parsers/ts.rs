@@ -7,19 +7,20 @@ use itertools::Itertools;
 use parking_lot::RwLock;
 
 use similar::DiffableStr;
-use tree_sitter::{Node, Parser, Range};
+use tree_sitter::{Node, Parser, Tree, Range};
 use uuid::Uuid;
 
-use crate::codegraph::treesitter::ast_instance_structs::{AstSymbolFields, AstSymbolInstanceArc, ClassFieldDeclaration, CommentDefinition, FunctionArg, FunctionCall, FunctionDeclaration, ImportDeclaration, ImportType, StructDeclaration, TypeDef, VariableDefinition, VariableUsage};
+use crate::codegraph::treesitter::ast_instance_structs::{AstSymbolFields, AstSymbolInstanceArc, ClassFieldDeclaration, CommentDefinition, FunctionArg, FunctionCall, FunctionDeclaration, ImportDeclaration, ImportType, StructDeclaration, StructKind, TypeAlias, TypeDef, VariableDefinition, VariableUsage};
 use crate::codegraph::treesitter::language_id::LanguageId;
 use crate::codegraph::treesitter::parsers::{AstLanguageParser, internal_error, ParserError};
-use crate::codegraph::treesitter::parsers::utils::{CandidateInfo, get_guid};
+use crate::codegraph::treesitter::parsers::utils::{CandidateInfo, get_guid, parse_tree_until};
 use crate::codegraph::treesitter::skeletonizer::SkeletonFormatter;
 use crate::codegraph::treesitter::ast_instance_structs::SymbolInformation;
 use crate::codegraph::treesitter::structs::SymbolType;
 
 pub(crate) struct TSParser {
     pub parser: Parser,
+    pub language_id: LanguageId,
 }
 
 pub fn parse_type(parent: &Node, code: &str) -> Option<TypeDef> {
@@ -132,13 +133,34 @@ pub fn parse_type(parent: &Node, code: &str) -> Option<TypeDef> {
     None
 }
 
+/// Anonymous functions assigned to a variable take the variable's name, e.g. `const f = () => {}`
+fn assigned_name<'a>(node: &Node<'a>) -> Option<Node<'a>> {
+    if !matches!(node.kind(), "arrow_function" | "function_expression") {
+        return None;
+    }
+    let parent = node.parent()?;
+    if parent.kind() != "variable_declarator" || parent.child_by_field_name("value")? != *node {
+        return None;
+    }
+    parent.child_by_field_name("name")
+}
+
 impl TSParser {
     pub fn new() -> Result<Self, ParserError> {
         let mut parser = Parser::new();
         parser
             .set_language(&tree_sitter_typescript::LANGUAGE_TYPESCRIPT.into())
             .map_err(internal_error)?;
-        Ok(Self { parser })
+        Ok(Self { parser, language_id: LanguageId::TypeScript })
+    }
+
+    /// .tsx files use the grammar with JSX
+    pub fn new_tsx() -> Result<Self, ParserError> {
+        let mut parser = Parser::new();
+        parser
+            .set_language(&tree_sitter_typescript::LANGUAGE_TSX.into())
+            .map_err(internal_error)?;
+        Ok(Self { parser, language_id: LanguageId::TypeScriptReact })
     }
 
     pub fn parse_struct_declaration<'a>(
@@ -156,6 +178,9 @@ impl TSParser {
         decl.ast_fields.definition_range = info.node.range();
         decl.ast_fields.parent_guid = Some(info.parent_guid.clone());
         decl.ast_fields.guid = get_guid();
+        if info.node.kind() == "interface_declaration" {
+            decl.kind = StructKind::Interface;
+        }
 
         symbols.extend(self.find_error_usages(&info.node, code, &info.ast_fields.file_path, &decl.ast_fields.guid));
 
@@ -180,6 +205,15 @@ impl TSParser {
             let class_heritage = info.node.child(i).unwrap();
             symbols.extend(self.find_error_usages(&class_heritage, code, &info.ast_fields.file_path,
                                                   &decl.ast_fields.guid));
+            // interface A extends B, C
+            if class_heritage.kind() == "extends_type_clause" {
+                for i in 0..class_heritage.child_count() {
+                    let child = class_heritage.child(i).unwrap();
+                    if let Some(dtype) = parse_type(&child, code) {
+                        decl.inherited_types.push(dtype);
+                    }
+                }
+            }
             if class_heritage.kind() == "class_heritage" {
 
                 for i in 0..class_heritage.child_count() {
@@ -214,17 +248,23 @@ impl TSParser {
                                 }
                             }
                         }
+                        if let Some(current_dtype) = current_dtype {
+                            decl.inherited_types.push(current_dtype);
+                        }
+                    }
+                    if extends_clause.kind() == "implements_clause" {
+                        for i in 0..extends_clause.child_count() {
+                            let child = extends_clause.child(i).unwrap();
+                            if let Some(dtype) = parse_type(&child, code) {
+                                decl.implemented_types.push(dtype);
+                            }
+                        }
                     }
                 }
             }
         }
-        let mut body_mb = info.node.child_by_field_name("body");
-        // type_alias_declaration
-        if let None = body_mb {
-            body_mb = info.node.child_by_field_name("value");
-        }
 
-        if let Some(body) = body_mb {
+        if let Some(body) = info.node.child_by_field_name("body") {
             decl.ast_fields.definition_range = body.range();
             candidates.push_back(CandidateInfo {
                 ast_fields: decl.ast_fields.clone(),
@@ -251,6 +291,43 @@ impl TSParser {
         symbols
     }
 
+    fn parse_type_alias_declaration<'a>(&mut self, info: &CandidateInfo<'a>, code: &str, candidates: &mut VecDeque<CandidateInfo<'a>>) -> Vec<AstSymbolInstanceArc> {
+        let mut symbols: Vec<AstSymbolInstanceArc> = vec![];
+        let mut decl = TypeAlias::default();
+        decl.ast_fields = AstSymbolFields::from_fields(&info.ast_fields);
+        decl.ast_fields.full_range = info.node.range();
+        decl.ast_fields.declaration_range = info.node.range();
+        decl.ast_fields.definition_range = info.node.range();
+        decl.ast_fields.parent_guid = Some(info.parent_guid.clone());
+        decl.ast_fields.guid = get_guid();
+
+        symbols.extend(self.find_error_usages(&info.node, code, &info.ast_fields.file_path, &decl.ast_fields.guid));
+
+        if let Some(name) = info.node.child_by_field_name("name") {
+            decl.ast_fields.name = code.slice(name.byte_range()).to_string();
+        }
+        if let Some(value) = info.node.child_by_field_name("value") {
+            decl.ast_fields.definition_range = value.range();
+            decl.ast_fields.declaration_range = Range {
+                start_byte: decl.ast_fields.full_range.start_byte,
+                end_byte: value.start_byte(),
+                start_point: decl.ast_fields.full_range.start_point,
+                end_point: value.start_position(),
+            };
+            if let Some(dtype) = parse_type(&value, code) {
+                decl.types.push(dtype);
+            }
+            // Fields of an object type become children of the alias
+            candidates.push_back(CandidateInfo {
+                ast_fields: decl.ast_fields.clone(),
+                node: value,
+                parent_guid: decl.ast_fields.guid.clone(),
+            });
+        }
+        symbols.push(Arc::new(RwLock::new(Box::new(decl))));
+        symbols
+    }
+
     fn parse_variable_definition<'a>(&mut self, info: &CandidateInfo<'a>, code: &str, candidates: &mut VecDeque<CandidateInfo<'a>>) -> Vec<AstSymbolInstanceArc> {
         let mut symbols: Vec<AstSymbolInstanceArc> = vec![];
         symbols.extend(self.find_error_usages(&info.node, code, &info.ast_fields.file_path, &info.parent_guid));
@@ -313,6 +390,7 @@ impl TSParser {
         decl.ast_fields.full_range = info.node.range();
         decl.ast_fields.parent_guid = Some(info.parent_guid.clone());
         decl.ast_fields.guid = get_guid();
+        decl.kind = StructKind::Enum;
 
         symbols.extend(self.find_error_usages(&info.node, code, &decl.ast_fields.file_path, &info.parent_guid));
 
@@ -377,6 +455,9 @@ impl TSParser {
 
         if let Some(name) = info.node.child_by_field_name("name") {
             decl.ast_fields.name = code.slice(name.byte_range()).to_string();
+        } else if let Some(name) = assigned_name(&info.node) {
+            // const handler = () => {...}
+            decl.ast_fields.name = code.slice(name.byte_range()).to_string();
         }
 
         if let Some(type_parameters) = info.node.child_by_field_name("type_parameters") {
@@ -552,7 +633,7 @@ impl TSParser {
             "identifier" /*| "field_identifier"*/ => {
                 let mut usage = VariableUsage::default();
                 usage.ast_fields.file_path = path.clone();
-                usage.ast_fields.language = LanguageId::TypeScript;
+                usage.ast_fields.language = self.language_id;
                 usage.ast_fields.is_error = true;
                 usage.ast_fields.name = code.slice(parent.byte_range()).to_string();
                 usage.ast_fields.full_range = parent.range();
@@ -566,7 +647,7 @@ impl TSParser {
             "member_expression" => {
                 let mut usage = VariableUsage::default();
                 usage.ast_fields.file_path = path.clone();
-                usage.ast_fields.language = LanguageId::TypeScript;
+                usage.ast_fields.language = self.language_id;
                 usage.ast_fields.is_error = true;
                 if let Some(property) = parent.child_by_field_name("property") {
                     usage.ast_fields.name = code.slice(property.byte_range()).to_string();
@@ -602,9 +683,12 @@ impl TSParser {
         #[allow(unused)]
             let text = code.slice(info.node.byte_range());
         match kind {
-            "class_declaration" | "class" | "interface_declaration" | "type_alias_declaration" => {
+            "class_declaration" | "abstract_class_declaration" | "class" | "interface_declaration" => {
                 symbols.extend(self.parse_struct_declaration(info, code, candidates));
             }
+            "type_alias_declaration" => {
+                symbols.extend(self.parse_type_alias_declaration(info, code, candidates));
+            }
             /*"lexical_declaration" |*/ "variable_declarator" => {
                 symbols.extend(self.parse_variable_definition(info, code, candidates));
             }
@@ -743,6 +827,23 @@ impl TSParser {
                     symbols.push(Arc::new(RwLock::new(Box::new(def))));
                 }
             }
+            "jsx_opening_element" | "jsx_self_closing_element" | "jsx_closing_element" => {
+                // Intrinsic tags (div, span, ...) are not symbols in the code; only components and their props are handled
+                for i in 0..info.node.child_count() {
+                    let child = info.node.child(i).unwrap();
+                    if info.node.field_name_for_child(i as u32) == Some("name") {
+                        let tag = code.slice(child.byte_range());
+                        if info.node.kind() == "jsx_closing_element" || tag.starts_with(|c: char| c.is_lowercase()) {
+                            continue;
+                        }
+                    }
+                    candidates.push_back(CandidateInfo {
+                        ast_fields: info.ast_fields.clone(),
+                        node: child,
+                        parent_guid: info.parent_guid.clone(),
+                    })
+                }
+            }
             "comment" => {
                 let mut def = CommentDefinition::default();
                 def.ast_fields = AstSymbolFields::from_fields(&info.ast_fields);
@@ -783,7 +884,7 @@ impl TSParser {
         let mut ast_fields = AstSymbolFields::default();
         ast_fields.file_path = path.clone();
         ast_fields.is_error = false;
-        ast_fields.language = LanguageId::TypeScript;
+        ast_fields.language = self.language_id;
 
         let mut candidates = VecDeque::from(vec![CandidateInfo {
             ast_fields,
@@ -826,6 +927,18 @@ impl AstLanguageParser for TSParser {
         let symbols = self.parse_(&tree.root_node(), code, path);
         symbols
     }
+
+    fn parse_tree(&mut self, code: &str, old_tree: Option<&Tree>) -> Option<Tree> {
+        self.parser.parse(code, old_tree)
+    }
+
+    fn parse_tree_until(&mut self, code: &str, old_tree: Option<&Tree>, should_abort: &dyn Fn() -> bool) -> Option<Tree> {
+        parse_tree_until(&mut self.parser, code, old_tree, should_abort)
+    }
+
+    fn parse_top_level(&mut self, nodes: &[Node], code: &str, path: &PathBuf) -> Vec<AstSymbolInstanceArc> {
+        nodes.iter().flat_map(|node| self.parse_(node, code, path)).collect()
+    }
 }
 
 pub struct TypescriptSkeletonFormatter;
@@ -1,4 +1,4 @@
-use tree_sitter::Node;
+use tree_sitter::{Node, ParseOptions, ParseState, Parser, Tree};
 use uuid::Uuid;
 
 use crate::codegraph::treesitter::ast_instance_structs::{AstSymbolFields, AstSymbolInstanceArc};
@@ -7,6 +7,15 @@ pub(crate) fn get_guid() -> Uuid {
     Uuid::new_v4()
 }
 
+/// Parses `code`, letting tree-sitter's progress callback cancel the parse once `should_abort`
+/// returns true
+pub(crate) fn parse_tree_until(parser: &mut Parser, code: &str, old_tree: Option<&Tree>, should_abort: &dyn Fn() -> bool) -> Option<Tree> {
+    let bytes = code.as_bytes();
+    let mut progress = |_: &ParseState| should_abort();
+    let options = ParseOptions::new().progress_callback(&mut progress);
+    parser.parse_with_options(&mut |offset, _| if offset < bytes.len() { &bytes[offset..] } else { &[] }, old_tree, Some(options))
+}
+
 pub(crate) fn get_children_guids(parent_guid: &Uuid, children: &Vec<AstSymbolInstanceArc>) -> Vec<Uuid> {
     let mut result = Vec::new();
     for child in children {
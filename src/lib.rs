@@ -2,4 +2,5 @@ pub mod codegraph;
 pub mod cli;
 pub mod http;
 pub mod storage;
-pub mod services;
\ No newline at end of file
+pub mod services;
+pub mod server;
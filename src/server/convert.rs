@@ -0,0 +1,169 @@
+use serde::de::DeserializeOwned;
+use serde::Serialize;
+use uuid::Uuid;
+
+use crate::codegraph::symbol_graph::json::{SymbolEdgeJson, SymbolGraphJson, SymbolNodeJson};
+use crate::codegraph::symbol_graph::{Reference, Span, SymbolGraph, SymbolNode, SyntaxError};
+
+use super::proto;
+
+/// 枚举按 JSON 中的字符串表示
+fn enum_to_string<T: Serialize>(value: &T) -> String {
+    match serde_json::to_value(value) {
+        Ok(serde_json::Value::String(s)) => s,
+        other => format!("{:?}", other),
+    }
+}
+
+fn enum_from_string<T: DeserializeOwned>(field: &str, value: &str) -> Result<T, String> {
+    serde_json::from_value(serde_json::Value::String(value.to_string()))
+        .map_err(|e| format!("Invalid {} {:?}: {}", field, value, e))
+}
+
+fn parse_uuid(field: &str, value: &str) -> Result<Uuid, String> {
+    Uuid::parse_str(value).map_err(|e| format!("Invalid {} {:?}: {}", field, value, e))
+}
+
+pub fn span_to_proto(span: &Span) -> proto::Span {
+    proto::Span {
+        start_byte: span.start_byte as u64,
+        end_byte: span.end_byte as u64,
+        start_line: span.start_line as u64,
+        start_column: span.start_column as u64,
+        end_line: span.end_line as u64,
+        end_column: span.end_column as u64,
+    }
+}
+
+pub fn span_from_proto(span: Option<&proto::Span>) -> Span {
+    span.map(|span| Span {
+        start_byte: span.start_byte as usize,
+        end_byte: span.end_byte as usize,
+        start_line: span.start_line as usize,
+        start_column: span.start_column as usize,
+        end_line: span.end_line as usize,
+        end_column: span.end_column as usize,
+    }).unwrap_or_default()
+}
+
+pub fn node_to_proto(node: &SymbolNode) -> proto::Node {
+    proto::Node {
+        id: node.id.to_string(),
+        kind: enum_to_string(&node.kind),
+        name: node.name.clone(),
+        qualified_name: node.qualified_name.clone(),
+        language: enum_to_string(&node.language),
+        file_path: node.file_path.to_string_lossy().to_string(),
+        span: Some(span_to_proto(&node.span)),
+        declaration_span: Some(span_to_proto(&node.declaration_span)),
+        doc: node.doc.clone(),
+        attributes: node.attributes.iter()
+            .map(|(key, value)| (key.clone(), value.to_string()))
+            .collect(),
+    }
+}
+
+/// 与 JSON 存储格式相同的内容，节点和边保持图中的插入顺序
+pub fn graph_to_proto(graph: &SymbolGraph) -> proto::Graph {
+    let storage = SymbolGraphJson::from_graph(graph);
+    proto::Graph {
+        schema_version: storage.schema_version,
+        nodes: graph.nodes().map(node_to_proto).collect(),
+        edges: storage.edges.iter().map(|edge| proto::Edge {
+            source: edge.source.to_string(),
+            target: edge.target.to_string(),
+            kind: enum_to_string(&edge.kind),
+            metadata: edge.metadata.as_ref().map(|m| m.to_string()),
+        }).collect(),
+        syntax_errors: graph.syntax_errors().iter().map(|error| proto::SyntaxError {
+            file_path: error.file_path.to_string_lossy().to_string(),
+            span: Some(span_to_proto(&error.span)),
+            message: error.message.clone(),
+        }).collect(),
+    }
+}
+
+/// 转换为 JSON 存储格式，版本检查与 `SymbolGraph::from_json` 相同
+pub fn graph_from_proto(graph: &proto::Graph) -> Result<SymbolGraph, String> {
+    let mut nodes = vec![];
+    for node in &graph.nodes {
+        let mut attributes = std::collections::BTreeMap::new();
+        for (key, value) in &node.attributes {
+            let value = serde_json::from_str(value)
+                .map_err(|e| format!("Invalid attribute {} of node {}: {}", key, node.id, e))?;
+            attributes.insert(key.clone(), value);
+        }
+        nodes.push(SymbolNodeJson {
+            id: parse_uuid("node id", &node.id)?,
+            kind: enum_from_string("node kind", &node.kind)?,
+            name: node.name.clone(),
+            qualified_name: node.qualified_name.clone(),
+            language: enum_from_string("language", &node.language)?,
+            file_path: node.file_path.clone().into(),
+            span: span_from_proto(node.span.as_ref()),
+            declaration_span: span_from_proto(node.declaration_span.as_ref()),
+            doc: node.doc.clone(),
+            attributes,
+        });
+    }
+    let mut edges = vec![];
+    for edge in &graph.edges {
+        let metadata = match &edge.metadata {
+            Some(metadata) => Some(serde_json::from_str(metadata)
+                .map_err(|e| format!("Invalid edge metadata {}: {}", metadata, e))?),
+            None => None,
+        };
+        edges.push(SymbolEdgeJson {
+            source: parse_uuid("edge source", &edge.source)?,
+            target: parse_uuid("edge target", &edge.target)?,
+            kind: enum_from_string("edge kind", &edge.kind)?,
+            metadata,
+        });
+    }
+    let syntax_errors = graph.syntax_errors.iter().map(|error| SyntaxError {
+        file_path: error.file_path.clone().into(),
+        span: span_from_proto(error.span.as_ref()),
+        message: error.message.clone(),
+    }).collect();
+    SymbolGraphJson { schema_version: graph.schema_version, nodes, edges, syntax_errors }.to_graph()
+}
+
+pub fn reference_to_proto(reference: &Reference) -> proto::Reference {
+    proto::Reference {
+        source: reference.source.to_string(),
+        target: reference.target.to_string(),
+        kind: enum_to_string(&reference.kind),
+        span: reference.span.as_ref().map(span_to_proto),
+        inferred: reference.inferred,
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use std::path::PathBuf;
+
+    use crate::codegraph::symbol_graph::parse_code;
+    use crate::server::convert::{graph_from_proto, graph_to_proto};
+
+    const DOCS_GO_CODE: &str = include_str!("../codegraph/treesitter/parsers/tests/cases/go/docs.go");
+
+    #[test]
+    fn round_trip_test() {
+        let graph = parse_code(DOCS_GO_CODE, &PathBuf::from("/docs.go")).unwrap();
+        let proto = graph_to_proto(&graph);
+        assert_eq!(proto.nodes.len(), graph.node_count());
+        let palette = proto.nodes.iter().find(|n| n.qualified_name == "Palette").unwrap();
+        assert_eq!(palette.kind, "Struct");
+        assert_eq!(palette.language, "Go");
+
+        let restored = graph_from_proto(&proto).unwrap();
+        assert_eq!(restored.to_json().unwrap(), graph.to_json().unwrap());
+
+        let mut stale = proto.clone();
+        stale.schema_version = 0;
+        assert!(graph_from_proto(&stale).is_err());
+        let mut invalid = proto;
+        invalid.edges[0].kind = "Unknown".to_string();
+        assert!(graph_from_proto(&invalid).unwrap_err().contains("edge kind"));
+    }
+}
@@ -0,0 +1,22 @@
+pub mod convert;
+pub mod service;
+
+/// 由 `proto/codegraph.proto` 生成的消息和服务代码
+pub mod proto {
+    tonic::include_proto!("codegraph.v1");
+}
+
+pub use proto::code_graph_client::CodeGraphClient;
+pub use proto::code_graph_server::CodeGraphServer as CodeGraphGrpcServer;
+pub use service::CodeGraphService;
+
+/// 在 `addr` 上启动 gRPC 服务，直到服务出错才返回
+pub async fn serve(addr: &str) -> Result<(), Box<dyn std::error::Error>> {
+    let addr = addr.parse()?;
+    println!("🚀 CodeGraph gRPC server starting on {}", addr);
+    tonic::transport::Server::builder()
+        .add_service(CodeGraphService::new().into_server())
+        .serve(addr)
+        .await?;
+    Ok(())
+}
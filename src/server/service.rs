@@ -0,0 +1,173 @@
+use std::path::PathBuf;
+use std::pin::Pin;
+use std::sync::atomic::{AtomicBool, Ordering};
+use std::sync::Arc;
+use std::time::Duration;
+
+use tokio::sync::mpsc;
+use tokio_stream::wrappers::ReceiverStream;
+use tokio_stream::Stream;
+use tonic::{Request, Response, Status};
+
+use crate::codegraph::symbol_graph::{parse_code, parse_dir, parse_dir_each, parse_file, Cache, MemoryCache, ParseOptions, Span, SymbolGraph};
+use crate::codegraph::treesitter::parsers::ParserError;
+
+use super::convert::{graph_to_proto, node_to_proto, reference_to_proto};
+use super::proto::code_graph_server::{CodeGraph, CodeGraphServer};
+use super::proto::query_request::Query;
+use super::proto::{
+    file_graph, FileError, FileGraph, ParseDirRequest, ParseDirResponse, ParseRequest, ParseResponse, QueryRequest,
+    QueryResponse,
+};
+
+/// 流式响应中等待发送的文件数，客户端读取较慢时解析线程在此阻塞
+const STREAM_BUFFER: usize = 16;
+
+/// `new` 创建的服务在内存缓存中最多保留的文件数
+pub const DEFAULT_CACHE_ENTRIES: usize = 4096;
+
+/// gRPC 服务实现。各请求共享一个缓存，重复解析未变化的文件时直接使用缓存结果
+#[derive(Debug, Clone)]
+pub struct CodeGraphService {
+    cache: Option<Arc<dyn Cache>>,
+}
+
+impl CodeGraphService {
+    /// 使用最多保留 `DEFAULT_CACHE_ENTRIES` 个文件的内存缓存
+    pub fn new() -> Self {
+        Self::with_cache(Some(Arc::new(MemoryCache::with_capacity(DEFAULT_CACHE_ENTRIES))))
+    }
+
+    /// 使用调用方给出的缓存（由调用方决定容量和淘汰策略），None 时不缓存
+    pub fn with_cache(cache: Option<Arc<dyn Cache>>) -> Self {
+        Self { cache }
+    }
+
+    pub fn into_server(self) -> CodeGraphServer<Self> {
+        CodeGraphServer::new(self)
+    }
+
+    fn parse_options(&self, request: &ParseDirRequest) -> ParseOptions {
+        ParseOptions {
+            workers: request.workers as usize,
+            compute_interface_satisfaction: request.compute_interface_satisfaction,
+            cache: self.cache.clone(),
+            max_depth: request.max_depth.map(|depth| depth as usize),
+            exclude_globs: request.exclude_globs.clone(),
+            timeout: (request.timeout_ms > 0).then(|| Duration::from_millis(request.timeout_ms)),
+            ..Default::default()
+        }
+    }
+}
+
+impl Default for CodeGraphService {
+    fn default() -> Self {
+        Self::new()
+    }
+}
+
+fn parser_status(error: ParserError) -> Status {
+    Status::invalid_argument(error.message)
+}
+
+/// 解析在阻塞线程中进行，不占用异步运行时的工作线程
+async fn blocking<T, F>(f: F) -> Result<T, Status>
+where
+    T: Send + 'static,
+    F: FnOnce() -> Result<T, Status> + Send + 'static,
+{
+    tokio::task::spawn_blocking(f).await
+        .map_err(|e| Status::internal(format!("Parse task failed: {}", e)))?
+}
+
+/// 查询的 root 可以是单个文件或目录，目录中解析失败的文件被忽略
+fn parse_root(root: &PathBuf, options: &ParseOptions) -> Result<SymbolGraph, Status> {
+    if root.is_file() {
+        parse_file(root).map_err(parser_status)
+    } else {
+        parse_dir(root, options).map(|(graph, _errors)| graph).map_err(parser_status)
+    }
+}
+
+type FileGraphStream = Pin<Box<dyn Stream<Item = Result<FileGraph, Status>> + Send>>;
+
+#[tonic::async_trait]
+impl CodeGraph for CodeGraphService {
+    async fn parse(&self, request: Request<ParseRequest>) -> Result<Response<ParseResponse>, Status> {
+        let request = request.into_inner();
+        let path = PathBuf::from(&request.path);
+        let graph = blocking(move || match request.code {
+            Some(code) => parse_code(&code, &path),
+            None => parse_file(&path),
+        }.map_err(parser_status)).await?;
+        Ok(Response::new(ParseResponse { graph: Some(graph_to_proto(&graph)) }))
+    }
+
+    async fn parse_dir(&self, request: Request<ParseDirRequest>) -> Result<Response<ParseDirResponse>, Status> {
+        let request = request.into_inner();
+        let options = self.parse_options(&request);
+        let (graph, errors) = blocking(move || {
+            parse_dir(&PathBuf::from(&request.root), &options).map_err(parser_status)
+        }).await?;
+        Ok(Response::new(ParseDirResponse {
+            graph: Some(graph_to_proto(&graph)),
+            errors: errors.into_iter().map(|e| FileError {
+                path: e.path.to_string_lossy().to_string(),
+                message: e.error.message,
+            }).collect(),
+        }))
+    }
+
+    type ParseDirStreamStream = FileGraphStream;
+
+    async fn parse_dir_stream(&self, request: Request<ParseDirRequest>) -> Result<Response<Self::ParseDirStreamStream>, Status> {
+        let request = request.into_inner();
+        let cancel = Arc::new(AtomicBool::new(false));
+        let options = ParseOptions { cancel: Some(cancel.clone()), ..self.parse_options(&request) };
+        let (tx, rx) = mpsc::channel(STREAM_BUFFER);
+        tokio::task::spawn_blocking(move || {
+            let result = parse_dir_each(&PathBuf::from(&request.root), &options, |path, result| {
+                let result = match result {
+                    Ok(graph) => file_graph::Result::Graph(graph_to_proto(&graph)),
+                    Err(error) => file_graph::Result::Error(error.message),
+                };
+                // 客户端断开后发送失败，取消剩余文件的解析
+                let sent = tx.blocking_send(Ok(FileGraph {
+                    path: path.to_string_lossy().to_string(),
+                    result: Some(result),
+                }));
+                if sent.is_err() {
+                    cancel.store(true, Ordering::Relaxed);
+                }
+            });
+            if let Err(error) = result {
+                let _ = tx.blocking_send(Err(parser_status(error)));
+            }
+        });
+        Ok(Response::new(Box::pin(ReceiverStream::new(rx))))
+    }
+
+    async fn query(&self, request: Request<QueryRequest>) -> Result<Response<QueryResponse>, Status> {
+        let request = request.into_inner();
+        let query = request.query.ok_or_else(|| Status::invalid_argument("Missing query"))?;
+        let options = ParseOptions { cache: self.cache.clone(), ..Default::default() };
+        let response = blocking(move || {
+            let graph = parse_root(&PathBuf::from(&request.root), &options)?;
+            let mut response = QueryResponse::default();
+            match query {
+                Query::References(query) => {
+                    for node in graph.find_nodes_by_qualified_name(&query.qualified_name) {
+                        response.references.extend(graph.references_to(&node.id).iter().map(reference_to_proto));
+                    }
+                }
+                Query::Definition(query) => {
+                    let offset = query.byte_offset as usize;
+                    let span = Span { start_byte: offset, end_byte: offset, ..Default::default() };
+                    response.definition = graph.definition_of(&PathBuf::from(&query.file_path), &span).map(node_to_proto);
+                }
+            }
+            Ok(response)
+        }).await?;
+        Ok(Response::new(response))
+    }
+}
@@ -0,0 +1,117 @@
+use std::fs;
+use std::path::Path;
+
+use tokio::net::TcpListener;
+use tokio_stream::wrappers::TcpListenerStream;
+use tokio_stream::StreamExt;
+use tonic::transport::{Channel, Server};
+
+use codegraph_cli::codegraph::symbol_graph::{parse_code, SymbolGraph};
+use codegraph_cli::server::convert::graph_from_proto;
+use codegraph_cli::server::proto::query_request::Query;
+use codegraph_cli::server::proto::{file_graph, DefinitionQuery, ParseDirRequest, ParseRequest, QueryRequest, ReferencesQuery};
+use codegraph_cli::server::{CodeGraphClient, CodeGraphService};
+
+const MAIN_GO_CODE: &str = include_str!("../src/codegraph/treesitter/parsers/tests/cases/go/main.go");
+const CALLS_GO_CODE: &str = include_str!("../src/codegraph/treesitter/parsers/tests/cases/go/calls.go");
+
+/// 在随机端口上启动进程内服务并返回连接好的客户端
+async fn start_server() -> CodeGraphClient<Channel> {
+    let listener = TcpListener::bind("127.0.0.1:0").await.expect("Failed to bind listener");
+    let addr = listener.local_addr().unwrap();
+    tokio::spawn(async move {
+        Server::builder()
+            .add_service(CodeGraphService::new().into_server())
+            .serve_with_incoming(TcpListenerStream::new(listener))
+            .await
+            .expect("gRPC server failed");
+    });
+    CodeGraphClient::connect(format!("http://{}", addr)).await.expect("Failed to connect")
+}
+
+fn write_sources(dir: &Path) {
+    fs::write(dir.join("main.go"), MAIN_GO_CODE).unwrap();
+    fs::write(dir.join("calls.go"), CALLS_GO_CODE).unwrap();
+}
+
+/// 测试解析请求经过 protobuf 往返后与直接解析的结果相同
+#[tokio::test]
+async fn test_parse_round_trip() {
+    let mut client = start_server().await;
+    let response = client.parse(ParseRequest {
+        path: "/main.go".to_string(),
+        code: Some(MAIN_GO_CODE.to_string()),
+    }).await.expect("Parse failed").into_inner();
+
+    let graph: SymbolGraph = graph_from_proto(&response.graph.unwrap()).unwrap();
+    let expected = parse_code(MAIN_GO_CODE, &"/main.go".into()).unwrap();
+    assert_eq!(graph.to_json().unwrap(), expected.to_json().unwrap());
+
+    let status = client.parse(ParseRequest {
+        path: "/main.unknown".to_string(),
+        code: Some(String::new()),
+    }).await.unwrap_err();
+    assert_eq!(status.code(), tonic::Code::InvalidArgument);
+}
+
+/// 测试流式解析目录，每个文件返回一次
+#[tokio::test]
+async fn test_parse_dir_stream() {
+    let dir = tempfile::tempdir().unwrap();
+    write_sources(dir.path());
+    let mut client = start_server().await;
+    let request = ParseDirRequest { root: dir.path().to_string_lossy().to_string(), workers: 2, ..Default::default() };
+
+    let mut stream = client.parse_dir_stream(request.clone()).await.expect("ParseDirStream failed").into_inner();
+    let mut paths = vec![];
+    let mut nodes = 0;
+    while let Some(file) = stream.next().await {
+        let file = file.expect("Stream error");
+        match file.result {
+            Some(file_graph::Result::Graph(graph)) => nodes += graph.nodes.len(),
+            other => panic!("Unexpected result for {}: {:?}", file.path, other),
+        }
+        paths.push(file.path);
+    }
+    paths.sort();
+    assert_eq!(paths, vec![
+        dir.path().join("calls.go").to_string_lossy().to_string(),
+        dir.path().join("main.go").to_string_lossy().to_string(),
+    ]);
+
+    let merged = client.parse_dir(request).await.expect("ParseDir failed").into_inner();
+    assert!(merged.errors.is_empty());
+    assert!(nodes > 0);
+    assert!(merged.graph.unwrap().nodes.iter().any(|n| n.qualified_name == "NewPoint"));
+}
+
+/// 测试引用和定义查询
+#[tokio::test]
+async fn test_query() {
+    let dir = tempfile::tempdir().unwrap();
+    write_sources(dir.path());
+    let main_path = dir.path().join("main.go").to_string_lossy().to_string();
+    let mut client = start_server().await;
+
+    let references = client.query(QueryRequest {
+        root: main_path.clone(),
+        query: Some(Query::References(ReferencesQuery { qualified_name: "NewPoint".to_string() })),
+    }).await.expect("Query failed").into_inner();
+    assert_eq!(references.references.len(), 1);
+    assert_eq!(references.references[0].kind, "Call");
+    let call = references.references[0].span.as_ref().unwrap();
+    assert_eq!(&MAIN_GO_CODE[call.start_byte as usize..call.end_byte as usize], "NewPoint(1, 2)");
+
+    let offset = MAIN_GO_CODE.find("Point{X").unwrap() as u64;
+    let definition = client.query(QueryRequest {
+        root: dir.path().to_string_lossy().to_string(),
+        query: Some(Query::Definition(DefinitionQuery { file_path: main_path, byte_offset: offset })),
+    }).await.expect("Query failed").into_inner();
+    let definition = definition.definition.unwrap();
+    assert_eq!(definition.qualified_name, "Point");
+    assert_eq!(definition.kind, "Struct");
+
+    let root = dir.path().to_string_lossy().to_string();
+    let status = client.query(QueryRequest { root, query: None }).await.unwrap_err();
+    assert_eq!(status.code(), tonic::Code::InvalidArgument);
+}